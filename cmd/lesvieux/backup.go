@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"strings"
+
+	"github.com/gruyaume/lesvieux/internal/backup"
+	"github.com/gruyaume/lesvieux/internal/config"
+	"github.com/gruyaume/lesvieux/internal/db"
+)
+
+// backupCommand takes a snapshot of the SQLite database of the config file. It can run while the server is running.
+// Backups go to -out, or to the backup directory of the config file, where the configured number of backups is kept.
+func backupCommand(args []string) {
+	fs := flag.NewFlagSet("backup", flag.ExitOnError)
+	configFile := configFlags(fs)
+	out := fs.String("out", "", "The file to write the backup to. Defaults to a new file in the backup directory of the config file")
+	compress := fs.Bool("gzip", false, "Compress the backup with gzip. Backups are compressed when -out ends with .gz, or when backup.compress is set")
+	fs.Parse(args)
+	if err := runBackup(*configFile, fs, *out, *compress); err != nil {
+		log.Fatalf("backup: %s", err)
+	}
+}
+
+func runBackup(configFile string, fs *flag.FlagSet, out string, compress bool) error {
+	conf, err := loadSQLiteConfig(configFile, fs)
+	if err != nil {
+		return err
+	}
+	database, err := db.Open(conf.Database.DataSource)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+	if out != "" {
+		compress = compress || strings.HasSuffix(out, ".gz")
+		if err := backup.Create(context.Background(), database, out, compress); err != nil {
+			return err
+		}
+		log.Printf("Wrote backup to %s", out)
+		return nil
+	}
+	if conf.Backup.Dir == "" {
+		return errors.New("-out is required when backup.dir isn't set in the config file")
+	}
+	info, err := backup.CreateInDir(context.Background(), database, conf.Backup.Dir, conf.Backup.Keep, compress || conf.Backup.Compress)
+	if err != nil {
+		return err
+	}
+	log.Printf("Wrote backup %s (%d bytes) to %s", info.Name, info.Size, conf.Backup.Dir)
+	return nil
+}
+
+// restoreCommand replaces the SQLite database of the config file with a backup, once the backup is verified.
+// The server should be stopped while restoring, so that nothing written in the meantime is lost.
+func restoreCommand(args []string) {
+	fs := flag.NewFlagSet("restore", flag.ExitOnError)
+	configFile := configFlags(fs)
+	from := fs.String("from", "", "The backup to restore, compressed with gzip or not")
+	verifyOnly := fs.Bool("verify-only", false, "Verify the backup without restoring it")
+	fs.Parse(args)
+	if err := runRestore(*configFile, fs, *from, *verifyOnly); err != nil {
+		log.Fatalf("restore: %s", err)
+	}
+}
+
+func runRestore(configFile string, fs *flag.FlagSet, from string, verifyOnly bool) error {
+	if from == "" {
+		return errors.New("-from is required")
+	}
+	if verifyOnly {
+		info, err := backup.Verify(context.Background(), from)
+		if err != nil {
+			return err
+		}
+		log.Printf("Backup %s is valid, at schema version %d", info.Name, info.SchemaVersion)
+		return nil
+	}
+	conf, err := loadSQLiteConfig(configFile, fs)
+	if err != nil {
+		return err
+	}
+	database, err := db.Open(conf.Database.DataSource)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+	info, err := backup.Restore(context.Background(), database, from)
+	if err != nil {
+		return err
+	}
+	log.Printf("Restored backup %s, at schema version %d, to %s", info.Name, info.SchemaVersion, conf.Database.DataSource)
+	applied, err := db.MigrateUp(context.Background(), database, 0)
+	for _, migration := range applied {
+		log.Printf("Applied migration %d_%s", migration.Version, migration.Name)
+	}
+	return err
+}
+
+// loadSQLiteConfig validates the configuration given with the -config flag and the flags of fs,
+// whose database must be SQLite.
+func loadSQLiteConfig(configFile string, fs *flag.FlagSet) (config.Config, error) {
+	conf, err := loadConfig(configFile, fs)
+	if err != nil {
+		return config.Config{}, err
+	}
+	if conf.Database.Driver != db.SQLite {
+		return config.Config{}, errors.New("backups are only supported on SQLite: use the tools of your database instead")
+	}
+	return conf, nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gruyaume/lesvieux/internal/certs"
+)
+
+const certUsage = `Usage: lesvieux cert <command> [flags]
+
+Commands:
+  generate  Create a self-signed certificate and its key, for development
+`
+
+// certCommand manages the TLS certificate of the server.
+func certCommand(args []string) {
+	if len(args) == 0 || args[0] != "generate" {
+		fmt.Fprint(os.Stderr, certUsage)
+		os.Exit(2)
+	}
+	if err := certGenerate(args[1:]); err != nil {
+		log.Fatalf("cert generate: %s", err)
+	}
+}
+
+func certGenerate(args []string) error {
+	fs := flag.NewFlagSet("cert generate", flag.ExitOnError)
+	certFile := fs.String("cert", "cert.pem", "The certificate file to create")
+	keyFile := fs.String("key", "key.pem", "The key file to create")
+	hosts := fs.String("hosts", "localhost,127.0.0.1,::1", "The host names and IP addresses the certificate is valid for, separated by commas")
+	days := fs.Int("days", 365, "The number of days the certificate is valid for")
+	force := fs.Bool("force", false, "Overwrite the files if they already exist")
+	fs.Parse(args)
+	if *days < 1 {
+		return errors.New("-days must be at least 1")
+	}
+	for _, file := range []string{*certFile, *keyFile} {
+		if _, err := os.Stat(file); err == nil && !*force {
+			return fmt.Errorf("%s already exists, use -force to replace it", file)
+		}
+	}
+	var hostList []string
+	for _, host := range strings.Split(*hosts, ",") {
+		if host = strings.TrimSpace(host); host != "" {
+			hostList = append(hostList, host)
+		}
+	}
+	cert, key, err := certs.GenerateSelfSigned(hostList, time.Duration(*days)*24*time.Hour)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(*keyFile, key, 0o600); err != nil {
+		return err
+	}
+	if err := os.WriteFile(*certFile, cert, 0o644); err != nil {
+		return err
+	}
+	log.Printf("Generated a self-signed certificate for %s in %s, with its key in %s", strings.Join(hostList, ", "), *certFile, *keyFile)
+	return nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/gruyaume/lesvieux/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+const configUsage = `Usage: lesvieux config <command> [-config <file>] [flags]
+
+Commands:
+  check  Print the effective configuration, with secrets redacted, and report its problems
+`
+
+// configCommand inspects the configuration resolved from the config file, the environment and the flags.
+func configCommand(args []string) {
+	if len(args) == 0 || args[0] != "check" {
+		fmt.Fprint(os.Stderr, configUsage)
+		os.Exit(2)
+	}
+	if err := configCheck(args[1:]); err != nil {
+		log.Fatalf("config check: %s", err)
+	}
+}
+
+func configCheck(args []string) error {
+	fs := flag.NewFlagSet("config check", flag.ExitOnError)
+	configFile := configFlags(fs)
+	fs.Parse(args)
+	c, resolveErr := config.Resolve(*configFile, fs)
+	if c == nil {
+		return resolveErr
+	}
+	encoder := yaml.NewEncoder(os.Stdout)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(c.Redacted()); err != nil {
+		return err
+	}
+	_, checkErr := config.Check(*c)
+	if err := errors.Join(resolveErr, checkErr); err != nil {
+		fmt.Fprintln(os.Stderr, "The configuration is invalid:")
+		for _, problem := range strings.Split(err.Error(), "\n") {
+			fmt.Fprintf(os.Stderr, "  - %s\n", problem)
+		}
+		os.Exit(1)
+	}
+	fmt.Fprintln(os.Stderr, "The configuration is valid")
+	return nil
+}
+
+// configFlags defines the -config flag on fs, which defaults to the file named by LESVIEUX_CONFIG,
+// and a flag overriding every setting of the config file.
+func configFlags(fs *flag.FlagSet) *string {
+	configFile := fs.String("config", os.Getenv(config.FileEnv), "The config file of the server. Optional when the settings are given as LESVIEUX_* environment variables or flags")
+	config.AddFlags(fs)
+	return configFile
+}
+
+// loadConfig validates the configuration of the config file given with the -config flag,
+// overridden by the environment and by the setting flags of fs.
+func loadConfig(configFile string, fs *flag.FlagSet) (config.Config, error) {
+	conf, err := config.Load(configFile, fs)
+	if err != nil {
+		return config.Config{}, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	return conf, nil
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/gruyaume/lesvieux/internal/jwtkeys"
+)
+
+const jwtKeysUsage = `Usage: lesvieux jwt-keys <command> [flags]
+
+Commands:
+  generate  Create a keys file holding a single active key
+  rotate    Add a new key to a keys file and make it the active one
+  activate  Make an existing key the active one
+`
+
+// jwtKeysCommand manages the file of the keys used to sign login tokens.
+func jwtKeysCommand(args []string) {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, jwtKeysUsage)
+		os.Exit(2)
+	}
+	var err error
+	switch args[0] {
+	case "generate":
+		err = jwtKeysGenerate(args[1:])
+	case "rotate":
+		err = jwtKeysRotate(args[1:])
+	case "activate":
+		err = jwtKeysActivate(args[1:])
+	default:
+		fmt.Fprint(os.Stderr, jwtKeysUsage)
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatalf("jwt-keys %s: %s", args[0], err)
+	}
+}
+
+func jwtKeysGenerate(args []string) error {
+	fs := flag.NewFlagSet("jwt-keys generate", flag.ExitOnError)
+	file := fs.String("file", "", "The keys file to create")
+	force := fs.Bool("force", false, "Overwrite the file if it already exists, invalidating every issued token")
+	fs.Parse(args)
+	if *file == "" {
+		return errors.New("-file is required")
+	}
+	if _, err := os.Stat(*file); err == nil && !*force {
+		return fmt.Errorf("%s already exists, use rotate to add a key or -force to replace it", *file)
+	}
+	f, err := jwtkeys.Generate()
+	if err != nil {
+		return err
+	}
+	if err := jwtkeys.WriteFile(*file, f); err != nil {
+		return err
+	}
+	log.Printf("Generated key %s in %s", f.Active, *file)
+	return nil
+}
+
+func jwtKeysRotate(args []string) error {
+	fs := flag.NewFlagSet("jwt-keys rotate", flag.ExitOnError)
+	file := fs.String("file", "", "The keys file to update")
+	keep := fs.Int("keep", 2, "The number of keys to keep, including the new one. Older keys are removed and the tokens they signed stop working")
+	stage := fs.Bool("stage", false, "Add the new key without activating it, so that every server knows it before it is used")
+	fs.Parse(args)
+	if *file == "" {
+		return errors.New("-file is required")
+	}
+	f, err := jwtkeys.ReadFile(*file)
+	if err != nil {
+		return err
+	}
+	key, err := jwtkeys.NewKey()
+	if err != nil {
+		return err
+	}
+	f.Add(key, !*stage)
+	f.Prune(*keep)
+	if err := jwtkeys.WriteFile(*file, f); err != nil {
+		return err
+	}
+	if *stage {
+		log.Printf("Added key %s to %s. Activate it once every server has reloaded the file.", key.ID, *file)
+	} else {
+		log.Printf("Added and activated key %s in %s", key.ID, *file)
+	}
+	return nil
+}
+
+func jwtKeysActivate(args []string) error {
+	fs := flag.NewFlagSet("jwt-keys activate", flag.ExitOnError)
+	file := fs.String("file", "", "The keys file to update")
+	kid := fs.String("kid", "", "The id of the key to activate")
+	fs.Parse(args)
+	if *file == "" {
+		return errors.New("-file is required")
+	}
+	if *kid == "" {
+		return errors.New("-kid is required")
+	}
+	f, err := jwtkeys.ReadFile(*file)
+	if err != nil {
+		return err
+	}
+	if err := f.Activate(*kid); err != nil {
+		return err
+	}
+	if err := jwtkeys.WriteFile(*file, f); err != nil {
+		return err
+	}
+	log.Printf("Activated key %s in %s", *kid, *file)
+	return nil
+}
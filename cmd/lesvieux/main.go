@@ -1,9 +1,16 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
+	"fmt"
 	"log"
+	"log/slog"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/gruyaume/lesvieux/internal/config"
 	"github.com/gruyaume/lesvieux/internal/db"
@@ -12,25 +19,107 @@ import (
 
 func main() {
 	log.SetOutput(os.Stderr)
-	configFilePtr := flag.String("config", "", "The config file to be provided to the server")
-	flag.Parse()
-	if *configFilePtr == "" {
-		log.Fatalf("Providing a config file is required.")
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "jwt-keys":
+			jwtKeysCommand(os.Args[2:])
+			return
+		case "migrate":
+			migrateCommand(os.Args[2:])
+			return
+		case "backup":
+			backupCommand(os.Args[2:])
+			return
+		case "restore":
+			restoreCommand(os.Args[2:])
+			return
+		case "config":
+			configCommand(os.Args[2:])
+			return
+		case "cert":
+			certCommand(os.Args[2:])
+			return
+		}
 	}
-	conf, err := config.Validate(*configFilePtr)
+	configFile := configFlags(flag.CommandLine)
+	flag.Parse()
+	conf, err := loadConfig(*configFile, flag.CommandLine)
 	if err != nil {
-		log.Fatalf("Couldn't validate config file: %s", err)
+		log.Fatalf("Couldn't load configuration: %s", err)
+	}
+	slog.SetDefault(newLogger(conf.Logging))
+	if err := serve(conf); err != nil {
+		log.Fatalf("Server ran into error: %s", err)
 	}
-	dbQueries, err := db.Initialize(conf.DBPath)
+}
+
+// serve runs the server until it receives SIGINT or SIGTERM. It then stops gracefully: requests in flight are given
+// the shutdown timeout to complete, the background workers are stopped and the database is closed.
+func serve(conf config.Config) error {
+	dbQueries, err := db.InitializeDatabase(conf.Database.Driver, conf.Database.DataSource)
 	if err != nil {
-		log.Fatalf("Couldn't initialize database: %s", err)
+		return fmt.Errorf("couldn't initialize database: %w", err)
 	}
-	srv, err := server.New(conf.Port, conf.TLS.Cert, conf.TLS.Key, dbQueries)
+	defer func() {
+		if err := dbQueries.DB().Close(); err != nil {
+			slog.Error("couldn't close database", "error", err)
+		}
+	}()
+	srv, err := server.New(server.Options{
+		Port:             conf.Port,
+		CertFile:         conf.TLS.CertFile,
+		KeyFile:          conf.TLS.KeyFile,
+		TLSMinVersion:    conf.TLS.MinVersion,
+		TLSCipherSuites:  conf.TLS.CipherSuites,
+		ClientCAFile:     conf.TLS.ClientCAFile,
+		PlainHTTP:        conf.PlainHTTP,
+		JWTKeysFile:      conf.JWT.KeysFile,
+		Backups:          conf.Backup,
+		TrustedProxies:   conf.TrustedProxies,
+		MetricsAddress:   conf.MetricsAddress,
+		MFAIssuer:        conf.MFA.Issuer,
+		MFARequiredRoles: conf.MFA.RequiredRoles,
+		DrainPeriod:      conf.ShutdownDrainPeriod,
+	}, dbQueries)
 	if err != nil {
-		log.Fatalf("Couldn't create server: %s", err)
+		return fmt.Errorf("couldn't create server: %w", err)
 	}
-	log.Printf("Starting server at https://127.0.0.1%s", srv.Addr)
-	if err := srv.ListenAndServeTLS("", ""); err != nil {
-		log.Fatalf("Server ran into error: %s", err)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	serveErr := make(chan error, 1)
+	go func() {
+		if conf.MetricsAddress != "" {
+			slog.Info("serving metrics", "address", "http://"+conf.MetricsAddress)
+		}
+		slog.Info("starting server", "address", srv.URL())
+		serveErr <- srv.ListenAndServe()
+	}()
+	select {
+	case err = <-serveErr:
+	case <-ctx.Done():
+		slog.Info("shutting down", "timeout", conf.ShutdownTimeout.String())
+	}
+	stop()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), conf.ShutdownDrainPeriod+conf.ShutdownTimeout)
+	defer cancel()
+	if shutdownErr := srv.Shutdown(shutdownCtx); shutdownErr != nil {
+		slog.Error("couldn't shut down gracefully", "error", shutdownErr)
+	}
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	slog.Info("server stopped")
+	return nil
+}
+
+// newLogger returns a logger writing records of at least the configured level to standard error, as text or JSON.
+// Once it is the default logger, the records of the log package go through it too.
+func newLogger(conf config.Logging) *slog.Logger {
+	options := &slog.HandlerOptions{Level: conf.Level}
+	if conf.Format == config.LogFormatJSON {
+		return slog.New(slog.NewJSONHandler(os.Stderr, options))
 	}
+	return slog.New(slog.NewTextHandler(os.Stderr, options))
 }
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/gruyaume/lesvieux/internal/db"
+)
+
+const migrateUsage = `Usage: lesvieux migrate <command> [-config <file>] [flags]
+
+Commands:
+  status  List the migrations and whether they are applied
+  up      Apply the pending migrations
+  down    Revert the last applied migrations
+`
+
+// migrateCommand manages the schema of the configured database.
+// The server applies pending migrations when it starts, so up is only needed to migrate ahead of time.
+func migrateCommand(args []string) {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, migrateUsage)
+		os.Exit(2)
+	}
+	var err error
+	switch args[0] {
+	case "status":
+		err = migrateStatus(args[1:])
+	case "up":
+		err = migrateUp(args[1:])
+	case "down":
+		err = migrateDown(args[1:])
+	default:
+		fmt.Fprint(os.Stderr, migrateUsage)
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatalf("migrate %s: %s", args[0], err)
+	}
+}
+
+// openConfiguredDatabase opens the database of the configuration given with the -config flag and the flags of fs.
+func openConfiguredDatabase(configFile string, fs *flag.FlagSet) (*sql.DB, error) {
+	conf, err := loadConfig(configFile, fs)
+	if err != nil {
+		return nil, err
+	}
+	return db.OpenDatabase(conf.Database.Driver, conf.Database.DataSource)
+}
+
+func migrateStatus(args []string) error {
+	fs := flag.NewFlagSet("migrate status", flag.ExitOnError)
+	configFile := configFlags(fs)
+	fs.Parse(args)
+	database, err := openConfiguredDatabase(*configFile, fs)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+	statuses, err := db.MigrationsStatus(context.Background(), database)
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+	for _, status := range statuses {
+		appliedAt := status.AppliedAt
+		if appliedAt == "" {
+			appliedAt = "pending"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+	}
+	return w.Flush()
+}
+
+func migrateUp(args []string) error {
+	fs := flag.NewFlagSet("migrate up", flag.ExitOnError)
+	configFile := configFlags(fs)
+	steps := fs.Int("steps", 0, "The number of migrations to apply. All pending migrations are applied by default")
+	fs.Parse(args)
+	if *steps < 0 {
+		return errors.New("-steps can't be negative")
+	}
+	database, err := openConfiguredDatabase(*configFile, fs)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+	applied, err := db.MigrateUp(context.Background(), database, *steps)
+	for _, migration := range applied {
+		log.Printf("Applied migration %d_%s", migration.Version, migration.Name)
+	}
+	if err != nil {
+		return err
+	}
+	if len(applied) == 0 {
+		log.Println("No pending migrations")
+	}
+	return nil
+}
+
+func migrateDown(args []string) error {
+	fs := flag.NewFlagSet("migrate down", flag.ExitOnError)
+	configFile := configFlags(fs)
+	steps := fs.Int("steps", 1, "The number of migrations to revert")
+	fs.Parse(args)
+	if *steps < 1 {
+		return errors.New("-steps must be at least 1")
+	}
+	database, err := openConfiguredDatabase(*configFile, fs)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+	reverted, err := db.MigrateDown(context.Background(), database, *steps)
+	for _, migration := range reverted {
+		log.Printf("Reverted migration %d_%s", migration.Version, migration.Name)
+	}
+	if err != nil {
+		return err
+	}
+	if len(reverted) == 0 {
+		log.Println("No applied migrations")
+	}
+	return nil
+}
@@ -0,0 +1,356 @@
+// Package backup takes consistent snapshots of the SQLite database of LesVieux, and restores them.
+//
+// Snapshots are taken with the online backup API of SQLite, so the database can be backed up while the server
+// is running. Backups are SQLite database files, optionally compressed with gzip. Before being restored, a backup
+// is checked for corruption and for a schema newer than the one this version of LesVieux knows about.
+package backup
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gruyaume/lesvieux/internal/db"
+	"github.com/mattn/go-sqlite3"
+)
+
+// Config is where and how often the server takes scheduled backups.
+// Scheduled backups are disabled when Dir is empty.
+type Config struct {
+	Dir      string
+	Interval time.Duration
+	Keep     int
+	Compress bool
+}
+
+// Info describes a backup file.
+type Info struct {
+	Name          string
+	Size          int64
+	Compressed    bool
+	SchemaVersion int64
+	CreatedAt     time.Time
+}
+
+const (
+	fileNamePrefix  = "lesvieux-"
+	fileNameTime    = "20060102T150405.000Z"
+	fileNameSuffix  = ".db"
+	gzipSuffix      = ".gz"
+	gzipMagicNumber = "\x1f\x8b"
+)
+
+// FileName returns the name of a backup taken at t, which sorts in the order backups were taken.
+func FileName(t time.Time, compress bool) string {
+	name := fileNamePrefix + t.UTC().Format(fileNameTime) + fileNameSuffix
+	if compress {
+		name += gzipSuffix
+	}
+	return name
+}
+
+// parseFileName returns the time a backup named by FileName was taken at.
+func parseFileName(name string) (time.Time, bool) {
+	stamp, ok := strings.CutPrefix(strings.TrimSuffix(name, gzipSuffix), fileNamePrefix)
+	if !ok {
+		return time.Time{}, false
+	}
+	stamp, ok = strings.CutSuffix(stamp, fileNameSuffix)
+	if !ok {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(fileNameTime, stamp)
+	return t, err == nil
+}
+
+// Create writes a snapshot of the SQLite database src to path, compressed with gzip if compress is set.
+// The file only appears at path once it is complete.
+func Create(ctx context.Context, src *sql.DB, path string, compress bool) error {
+	tmpDir, err := os.MkdirTemp(filepath.Dir(path), ".lesvieux-backup-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+	snapshot := filepath.Join(tmpDir, "lesvieux.db")
+	if err := snapshotDatabase(ctx, src, snapshot); err != nil {
+		return err
+	}
+	if compress {
+		compressed := snapshot + gzipSuffix
+		if err := compressFile(snapshot, compressed); err != nil {
+			return err
+		}
+		snapshot = compressed
+	}
+	return os.Rename(snapshot, path)
+}
+
+// CreateInDir takes a backup in dir, named after the time it is taken, and removes the oldest backups of dir
+// so that keep are left. Backups are kept forever when keep is 0.
+func CreateInDir(ctx context.Context, src *sql.DB, dir string, keep int, compress bool) (Info, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return Info{}, err
+	}
+	now := time.Now()
+	name := FileName(now, compress)
+	if err := Create(ctx, src, filepath.Join(dir, name), compress); err != nil {
+		return Info{}, err
+	}
+	stat, err := os.Stat(filepath.Join(dir, name))
+	if err != nil {
+		return Info{}, err
+	}
+	if keep > 0 {
+		if _, err := Prune(dir, keep); err != nil {
+			return Info{}, fmt.Errorf("backup %s was taken but older backups couldn't be removed: %w", name, err)
+		}
+	}
+	createdAt, _ := parseFileName(name)
+	return Info{Name: name, Size: stat.Size(), Compressed: compress, CreatedAt: createdAt}, nil
+}
+
+// List returns the backups of dir, most recent first. Files that weren't named by FileName are ignored.
+func List(dir string) ([]Info, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var backups []Info
+	for _, entry := range entries {
+		createdAt, ok := parseFileName(entry.Name())
+		if !ok || !entry.Type().IsRegular() {
+			continue
+		}
+		stat, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		backups = append(backups, Info{
+			Name:       entry.Name(),
+			Size:       stat.Size(),
+			Compressed: strings.HasSuffix(entry.Name(), gzipSuffix),
+			CreatedAt:  createdAt,
+		})
+	}
+	sort.Slice(backups, func(i, j int) bool { return backups[i].CreatedAt.After(backups[j].CreatedAt) })
+	return backups, nil
+}
+
+// Prune removes the oldest backups of dir so that keep are left. It returns the names of the removed backups.
+func Prune(dir string, keep int) ([]string, error) {
+	backups, err := List(dir)
+	if err != nil {
+		return nil, err
+	}
+	var removed []string
+	for i := keep; i < len(backups); i++ {
+		if err := os.Remove(filepath.Join(dir, backups[i].Name)); err != nil {
+			return removed, err
+		}
+		removed = append(removed, backups[i].Name)
+	}
+	return removed, nil
+}
+
+// Verify checks that the backup at path is a LesVieux database that isn't corrupted,
+// with a schema this version of LesVieux can migrate.
+func Verify(ctx context.Context, path string) (Info, error) {
+	info, snapshot, cleanup, err := openBackup(ctx, path)
+	if err != nil {
+		return Info{}, err
+	}
+	defer cleanup()
+	snapshot.Close()
+	return info, nil
+}
+
+// Restore verifies the backup at path and replaces the content of the SQLite database dst with it.
+// Pending migrations are applied to the restored database when the server starts.
+func Restore(ctx context.Context, dst *sql.DB, path string) (Info, error) {
+	info, snapshot, cleanup, err := openBackup(ctx, path)
+	if err != nil {
+		return Info{}, err
+	}
+	defer cleanup()
+	defer snapshot.Close()
+	if err := copyDatabase(ctx, dst, snapshot); err != nil {
+		return Info{}, fmt.Errorf("couldn't restore backup: %w", err)
+	}
+	return info, nil
+}
+
+// openBackup verifies the backup at path and opens it, decompressed into a temporary file when needed.
+// cleanup removes the temporary file, once the database is closed.
+func openBackup(ctx context.Context, path string) (Info, *sql.DB, func(), error) {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return Info{}, nil, nil, err
+	}
+	info := Info{Name: filepath.Base(path), Size: stat.Size(), CreatedAt: stat.ModTime()}
+	if createdAt, ok := parseFileName(info.Name); ok {
+		info.CreatedAt = createdAt
+	}
+	tmpDir, err := os.MkdirTemp("", "lesvieux-restore-")
+	if err != nil {
+		return Info{}, nil, nil, err
+	}
+	cleanup := func() { os.RemoveAll(tmpDir) }
+	snapshotPath := filepath.Join(tmpDir, "lesvieux.db")
+	info.Compressed, err = decompressFile(path, snapshotPath)
+	if err != nil {
+		cleanup()
+		return Info{}, nil, nil, fmt.Errorf("couldn't read backup: %w", err)
+	}
+	snapshot, err := sql.Open("sqlite3", snapshotPath+"?mode=ro")
+	if err != nil {
+		cleanup()
+		return Info{}, nil, nil, err
+	}
+	info.SchemaVersion, err = checkSnapshot(ctx, snapshot)
+	if err != nil {
+		snapshot.Close()
+		cleanup()
+		return Info{}, nil, nil, fmt.Errorf("invalid backup %s: %w", info.Name, err)
+	}
+	return info, snapshot, cleanup, nil
+}
+
+// checkSnapshot runs the integrity check of SQLite on a backup and returns the version of its schema.
+func checkSnapshot(ctx context.Context, snapshot *sql.DB) (int64, error) {
+	var result string
+	if err := snapshot.QueryRowContext(ctx, "PRAGMA integrity_check").Scan(&result); err != nil {
+		return 0, fmt.Errorf("not a SQLite database: %w", err)
+	}
+	if result != "ok" {
+		return 0, fmt.Errorf("integrity check failed: %s", result)
+	}
+	var tables int
+	if err := snapshot.QueryRowContext(ctx, "SELECT count(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations'").Scan(&tables); err != nil {
+		return 0, err
+	}
+	if tables == 0 {
+		return 0, errors.New("not a LesVieux database: no schema_migrations table")
+	}
+	var version sql.NullInt64
+	if err := snapshot.QueryRowContext(ctx, "SELECT max(version) FROM schema_migrations").Scan(&version); err != nil {
+		return 0, err
+	}
+	migrations, err := db.Migrations(db.SQLite)
+	if err != nil {
+		return 0, err
+	}
+	if latest := migrations[len(migrations)-1].Version; version.Int64 > latest {
+		return 0, fmt.Errorf("schema version %d is newer than the latest known migration %d", version.Int64, latest)
+	}
+	return version.Int64, nil
+}
+
+// snapshotDatabase copies the SQLite database src to a new database file at path.
+func snapshotDatabase(ctx context.Context, src *sql.DB, path string) error {
+	dst, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+	return copyDatabase(ctx, dst, src)
+}
+
+// copyDatabase replaces the content of dst with the content of src with the online backup API of SQLite,
+// which sees src as it is at a single point in time even while it is being written to.
+func copyDatabase(ctx context.Context, dst *sql.DB, src *sql.DB) error {
+	dstConn, err := dst.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer dstConn.Close()
+	srcConn, err := src.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer srcConn.Close()
+	return dstConn.Raw(func(dstDriverConn any) error {
+		return srcConn.Raw(func(srcDriverConn any) error {
+			dstSQLite, ok := dstDriverConn.(*sqlite3.SQLiteConn)
+			if !ok {
+				return errors.New("backups are only supported on SQLite")
+			}
+			srcSQLite, ok := srcDriverConn.(*sqlite3.SQLiteConn)
+			if !ok {
+				return errors.New("backups are only supported on SQLite")
+			}
+			b, err := dstSQLite.Backup("main", srcSQLite, "main")
+			if err != nil {
+				return err
+			}
+			if _, err := b.Step(-1); err != nil {
+				b.Finish()
+				return err
+			}
+			return b.Finish()
+		})
+	})
+}
+
+func compressFile(src string, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	zw := gzip.NewWriter(out)
+	if _, err := io.Copy(zw, in); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	return out.Close()
+}
+
+// decompressFile copies src to dst, decompressing it if it was compressed with gzip.
+// It reports whether src was compressed.
+func decompressFile(src string, dst string) (bool, error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return false, err
+	}
+	defer in.Close()
+	r := bufio.NewReader(in)
+	magic, _ := r.Peek(len(gzipMagicNumber))
+	compressed := string(magic) == gzipMagicNumber
+	var content io.Reader = r
+	if compressed {
+		zr, err := gzip.NewReader(r)
+		if err != nil {
+			return false, err
+		}
+		defer zr.Close()
+		content = zr
+	}
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return false, err
+	}
+	defer out.Close()
+	if _, err := io.Copy(out, content); err != nil {
+		return false, err
+	}
+	return compressed, out.Close()
+}
@@ -0,0 +1,192 @@
+package backup_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gruyaume/lesvieux/internal/backup"
+	"github.com/gruyaume/lesvieux/internal/db"
+)
+
+func setupDatabase(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "lesvieux.db")
+	dbQueries, err := db.Initialize(path)
+	if err != nil {
+		t.Fatalf("couldn't initialize database: %s", err)
+	}
+	if _, err := dbQueries.CreateEmployer(context.Background(), "testemployer"); err != nil {
+		t.Fatalf("couldn't create employer: %s", err)
+	}
+	return path
+}
+
+func countEmployers(t *testing.T, path string) int64 {
+	t.Helper()
+	database, err := db.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	var count int64
+	if err := database.QueryRow("SELECT count(*) FROM employers").Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	return count
+}
+
+func TestBackupAndRestore(t *testing.T) {
+	ctx := context.Background()
+	cases := []struct {
+		name     string
+		compress bool
+	}{
+		{"plain", false},
+		{"gzip", true},
+	}
+	for _, tc := range cases {
+		compress := tc.compress
+		t.Run(tc.name, func(t *testing.T) {
+			path := setupDatabase(t)
+			database, err := db.Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer database.Close()
+			backupPath := filepath.Join(t.TempDir(), "backup.db")
+			if err := backup.Create(ctx, database, backupPath, compress); err != nil {
+				t.Fatalf("couldn't create backup: %s", err)
+			}
+
+			info, err := backup.Verify(ctx, backupPath)
+			if err != nil {
+				t.Fatalf("couldn't verify backup: %s", err)
+			}
+			if info.Compressed != compress {
+				t.Fatalf("expected compressed to be %t, got %t", compress, info.Compressed)
+			}
+			migrations, err := db.Migrations(db.SQLite)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.SchemaVersion != migrations[len(migrations)-1].Version {
+				t.Fatalf("expected schema version %d, got %d", migrations[len(migrations)-1].Version, info.SchemaVersion)
+			}
+
+			if _, err := database.Exec("DELETE FROM employers"); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := backup.Restore(ctx, database, backupPath); err != nil {
+				t.Fatalf("couldn't restore backup: %s", err)
+			}
+			if count := countEmployers(t, path); count != 1 {
+				t.Fatalf("expected the restored database to have 1 employer, got %d", count)
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsInvalidBackups(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	newerSchema := setupDatabase(t)
+	database, err := db.Open(newerSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := database.Exec("INSERT INTO schema_migrations (version, name, applied_at) VALUES (9999, 'future', '')"); err != nil {
+		t.Fatal(err)
+	}
+	database.Close()
+
+	notLesVieux := filepath.Join(dir, "other.db")
+	database, err = db.Open(notLesVieux)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := database.Exec("CREATE TABLE things (id INTEGER PRIMARY KEY)"); err != nil {
+		t.Fatal(err)
+	}
+	database.Close()
+
+	garbage := filepath.Join(dir, "garbage.db")
+	if err := os.WriteFile(garbage, []byte(strings.Repeat("not a database", 100)), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	truncatedGzip := filepath.Join(dir, "truncated.db.gz")
+	if err := os.WriteFile(truncatedGzip, []byte("\x1f\x8b\x08\x00"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name          string
+		path          string
+		expectedError string
+	}{
+		{"newer schema", newerSchema, "newer than the latest known migration"},
+		{"not a LesVieux database", notLesVieux, "no schema_migrations table"},
+		{"not a database", garbage, "not a SQLite database"},
+		{"truncated gzip", truncatedGzip, "couldn't read backup"},
+		{"missing file", filepath.Join(dir, "missing.db"), "no such file"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := backup.Verify(ctx, tc.path)
+			if err == nil {
+				t.Fatal("expected an error, got nil")
+			}
+			if !strings.Contains(err.Error(), tc.expectedError) {
+				t.Fatalf("expected error %q, got %q", tc.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestCreateInDirKeepsTheMostRecentBackups(t *testing.T) {
+	ctx := context.Background()
+	path := setupDatabase(t)
+	database, err := db.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("not a backup"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	var taken []string
+	for i := 0; i < 4; i++ {
+		info, err := backup.CreateInDir(ctx, database, dir, 2, true)
+		if err != nil {
+			t.Fatalf("couldn't create backup: %s", err)
+		}
+		taken = append(taken, info.Name)
+		time.Sleep(2 * time.Millisecond)
+	}
+	backups, err := backup.List(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 2 || backups[0].Name != taken[3] || backups[1].Name != taken[2] {
+		t.Fatalf("expected backups %s and %s to be kept, got %+v", taken[3], taken[2], backups)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "notes.txt")); err != nil {
+		t.Fatalf("files that aren't backups should be left alone: %s", err)
+	}
+}
+
+func TestFileNameSortsByTime(t *testing.T) {
+	earlier := backup.FileName(time.Date(2024, 12, 31, 23, 59, 59, 0, time.UTC), true)
+	later := backup.FileName(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), false)
+	if earlier != "lesvieux-20241231T235959.000Z.db.gz" {
+		t.Fatalf("unexpected file name %s", earlier)
+	}
+	if earlier >= later {
+		t.Fatalf("expected %s to sort before %s", earlier, later)
+	}
+}
@@ -0,0 +1,125 @@
+package certs_test
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gruyaume/lesvieux/internal/certs"
+)
+
+func TestGenerateSelfSigned(t *testing.T) {
+	certPEM, keyPEM, err := certs.GenerateSelfSigned([]string{"localhost", "127.0.0.1"}, 24*time.Hour)
+	if err != nil {
+		t.Fatalf("couldn't generate certificate: %s", err)
+	}
+	pair, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatalf("expected a valid key pair: %s", err)
+	}
+	cert, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cert.VerifyHostname("localhost"); err != nil {
+		t.Errorf("expected the certificate to be valid for localhost: %s", err)
+	}
+	if err := cert.VerifyHostname("127.0.0.1"); err != nil {
+		t.Errorf("expected the certificate to be valid for 127.0.0.1: %s", err)
+	}
+	if cert.NotAfter.Before(time.Now().Add(23*time.Hour)) || cert.NotAfter.After(time.Now().Add(25*time.Hour)) {
+		t.Errorf("expected the certificate to expire in a day, got %s", cert.NotAfter)
+	}
+
+	if _, _, err := certs.GenerateSelfSigned(nil, time.Hour); err == nil {
+		t.Errorf("expected an error without hosts")
+	}
+}
+
+// writePair writes a new certificate and key to the given files, dated at modTime.
+func writePair(t *testing.T, certFile string, keyFile string, host string, modTime time.Time) []byte {
+	t.Helper()
+	certPEM, keyPEM, err := certs.GenerateSelfSigned([]string{host}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for path, content := range map[string][]byte{certFile: certPEM, keyFile: keyPEM} {
+		if err := os.WriteFile(path, content, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pair, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pair.Certificate[0]
+}
+
+func servedCert(t *testing.T, r *certs.Reloader) []byte {
+	t.Helper()
+	cert, err := r.GetCertificate(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert.Certificate[0]
+}
+
+func TestReloader(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	start := time.Now().Add(-time.Hour)
+	first := writePair(t, certFile, keyFile, "first.example.com", start)
+
+	r, err := certs.NewReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("couldn't load certificate: %s", err)
+	}
+	if !bytes.Equal(servedCert(t, r), first) {
+		t.Fatalf("expected the certificate of the files to be served")
+	}
+
+	t.Run("unchanged files aren't read again", func(t *testing.T) {
+		reloaded, err := r.Reload()
+		if err != nil || reloaded {
+			t.Fatalf("expected no reload, got %t, %v", reloaded, err)
+		}
+	})
+
+	t.Run("renewed certificates are served", func(t *testing.T) {
+		second := writePair(t, certFile, keyFile, "second.example.com", start.Add(time.Minute))
+		reloaded, err := r.Reload()
+		if err != nil || !reloaded {
+			t.Fatalf("expected a reload, got %t, %v", reloaded, err)
+		}
+		if !bytes.Equal(servedCert(t, r), second) {
+			t.Fatalf("expected the renewed certificate to be served")
+		}
+	})
+
+	t.Run("invalid files keep the previous certificate", func(t *testing.T) {
+		previous := servedCert(t, r)
+		if err := os.WriteFile(certFile, []byte("not a certificate"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := r.Reload(); err == nil {
+			t.Fatalf("expected an error")
+		}
+		if !bytes.Equal(servedCert(t, r), previous) {
+			t.Fatalf("expected the previous certificate to be kept")
+		}
+	})
+
+	t.Run("missing files fail", func(t *testing.T) {
+		if _, err := certs.NewReloader(filepath.Join(dir, "missing.pem"), keyFile); err == nil {
+			t.Fatalf("expected an error")
+		}
+	})
+}
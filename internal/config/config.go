@@ -1,76 +1,447 @@
 package config
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
+	"flag"
 	"fmt"
+	"log/slog"
+	"net"
+	"net/netip"
 	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
-	"gopkg.in/yaml.v3"
+	"github.com/gruyaume/lesvieux/internal/backup"
+	"github.com/gruyaume/lesvieux/internal/db"
+	"github.com/gruyaume/lesvieux/internal/jwtkeys"
 )
 
 type TLSYaml struct {
-	Cert string `yaml:"cert"`
-	Key  string `yaml:"key"`
+	Cert         string   `yaml:"cert"`
+	Key          string   `yaml:"key"`
+	MinVersion   string   `yaml:"min_version"`
+	CipherSuites []string `yaml:"cipher_suites"`
+	ClientCA     string   `yaml:"client_ca"`
+}
+
+type JWTYaml struct {
+	KeysFile string `yaml:"keys_file"`
+}
+
+type DatabaseYaml struct {
+	Driver string `yaml:"driver"`
+	URL    string `yaml:"url"`
+}
+
+type BackupYaml struct {
+	Dir      string `yaml:"dir"`
+	Interval string `yaml:"interval"`
+	Keep     int    `yaml:"keep"`
+	Compress bool   `yaml:"compress"`
+}
+
+type LoggingYaml struct {
+	Format string `yaml:"format"`
+	Level  string `yaml:"level"`
+}
+
+type MFAYaml struct {
+	Issuer        string   `yaml:"issuer"`
+	RequiredRoles []string `yaml:"required_roles"`
 }
 
 type ConfigYAML struct {
-	DBPath string  `yaml:"db_path"`
-	Port   int     `yaml:"port"`
-	TLS    TLSYaml `yaml:"tls"`
+	DBPath   string       `yaml:"db_path"`
+	Database DatabaseYaml `yaml:"database"`
+	Port     int          `yaml:"port"`
+	TLS      TLSYaml      `yaml:"tls"`
+	JWT      JWTYaml      `yaml:"jwt"`
+	Backup   BackupYaml   `yaml:"backup"`
+	Logging  LoggingYaml  `yaml:"logging"`
+	MFA      MFAYaml      `yaml:"mfa"`
+
+	ShutdownTimeout     string `yaml:"shutdown_timeout"`
+	ShutdownDrainPeriod string `yaml:"shutdown_drain_period"`
+
+	PlainHTTP      bool     `yaml:"plain_http"`
+	TrustedProxies []string `yaml:"trusted_proxies"`
+	MetricsAddress string   `yaml:"metrics_address"`
 }
 
+// TLS holds the files of the certificate of the server and of its key, which are read again when they change,
+// and the policy of TLS connections: their minimum version and, for TLS 1.2, their cipher suites.
+// With a ClientCAFile, the admin API requires a client certificate verified against its authorities.
 type TLS struct {
-	Cert []byte
-	Key  []byte
+	CertFile     string
+	KeyFile      string
+	MinVersion   uint16
+	CipherSuites []uint16
+	ClientCAFile string
+}
+
+// JWT holds the path to the file of the keys used to sign login tokens.
+// It is optional: without it, a temporary key is generated when the server starts.
+type JWT struct {
+	KeysFile string
+}
+
+// Database holds the driver of the database, sqlite or postgres, and its data source:
+// the path of the file of a SQLite database, or the URL of a PostgreSQL database.
+type Database struct {
+	Driver     string
+	DataSource string
+}
+
+// DefaultShutdownTimeout is how long the server waits for the requests in flight when it is stopped, by default.
+const DefaultShutdownTimeout = 30 * time.Second
+
+// DefaultShutdownDrainPeriod is how long the server keeps serving with failing readiness checks once it is stopped,
+// by default.
+const DefaultShutdownDrainPeriod = 5 * time.Second
+
+// The formats logs can be written in.
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
+// Logging holds the format of the logs, text or json, and the minimum level of the records that are logged.
+type Logging struct {
+	Format string
+	Level  slog.Level
+}
+
+// DefaultMFAIssuer is the name accounts are listed under in authenticator apps, by default.
+const DefaultMFAIssuer = "LesVieux"
+
+// The roles whose accounts can have a second factor.
+const (
+	RoleAdmin    = "admin"
+	RoleEmployer = "employer"
+)
+
+// MFA holds the name accounts are listed under in authenticator apps, and the roles, admin or employer,
+// whose accounts must have a second factor to log in.
+type MFA struct {
+	Issuer        string
+	RequiredRoles []string
 }
 
 type Config struct {
-	DBPath string
-	Port   int
-	TLS    TLS
+	DBPath   string
+	Database Database
+	Port     int
+	TLS      TLS
+	JWT      JWT
+	Backup   backup.Config
+	Logging  Logging
+	MFA      MFA
+	// ShutdownTimeout is how long the server waits for the requests in flight when it is stopped.
+	ShutdownTimeout time.Duration
+	// ShutdownDrainPeriod is how long the server keeps serving once it is stopped, with readiness checks failing,
+	// before it stops accepting connections.
+	ShutdownDrainPeriod time.Duration
+	// PlainHTTP serves plain HTTP, for TLS to be terminated by a reverse proxy. TLS is then empty.
+	PlainHTTP bool
+	// TrustedProxies are the networks of the reverse proxies whose X-Forwarded-For and X-Forwarded-Proto headers are trusted.
+	TrustedProxies []netip.Prefix
+	// MetricsAddress is the address of a separate listener for /metrics and /status, such as 127.0.0.1:9090.
+	// When it is empty, they are served by the main listener.
+	MetricsAddress string
 }
 
+// Validate returns the configuration of the config file, overridden by the LESVIEUX_* environment variables.
 func Validate(filePath string) (Config, error) {
+	return Load(filePath, nil)
+}
+
+// Load returns the configuration resolved from the flags set on fs, the environment, the config file and the
+// defaults, as described by Resolve. The config file is optional when filePath is empty, and fs can be nil.
+// Every problem of the configuration is returned at once.
+func Load(filePath string, fs *flag.FlagSet) (Config, error) {
+	c, resolveErr := Resolve(filePath, fs)
+	if c == nil {
+		return Config{}, resolveErr
+	}
+	config, err := Check(*c)
+	if err := errors.Join(resolveErr, err); err != nil {
+		return Config{}, err
+	}
+	return config, nil
+}
+
+// Check validates settings and returns the configuration they describe. Every problem is returned at once,
+// joined in a single error.
+func Check(c ConfigYAML) (Config, error) {
 	config := Config{}
-	configYaml, err := os.ReadFile(filePath)
-	if err != nil {
-		return Config{}, fmt.Errorf("cannot read config file: %w", err)
+	var errs []error
+	if c.PlainHTTP {
+		if c.TLS.Cert != "" || c.TLS.Key != "" || c.TLS.MinVersion != "" || len(c.TLS.CipherSuites) > 0 || c.TLS.ClientCA != "" {
+			errs = append(errs, errors.New("`tls` can't be set along with `plain_http`"))
+		}
+	} else {
+		tlsConfig, err := validateTLS(c.TLS)
+		errs = append(errs, err)
+		config.TLS = tlsConfig
 	}
-	c := ConfigYAML{}
-	if err := yaml.Unmarshal(configYaml, &c); err != nil {
-		return Config{}, fmt.Errorf("cannot unmarshal config file: %w", err)
+	switch c.Database.Driver {
+	case "", db.SQLite:
+		if c.DBPath == "" {
+			errs = append(errs, errors.New("`db_path` is empty"))
+			break
+		}
+		if err := checkDBFile(c.DBPath); err != nil {
+			errs = append(errs, err)
+			break
+		}
+		config.Database = Database{Driver: db.SQLite, DataSource: c.DBPath}
+	case db.Postgres:
+		if c.Database.URL == "" {
+			errs = append(errs, errors.New("`database.url` is empty"))
+			break
+		}
+		config.Database = Database{Driver: db.Postgres, DataSource: c.Database.URL}
+	default:
+		errs = append(errs, fmt.Errorf("unknown `database.driver` %q: must be sqlite or postgres", c.Database.Driver))
 	}
-	if c.TLS.Cert == "" {
-		return Config{}, fmt.Errorf("tls.cert is empty")
+	if c.Port == 0 {
+		errs = append(errs, errors.New("port is empty"))
 	}
-	cert, err := os.ReadFile(c.TLS.Cert)
-	if err != nil {
-		return Config{}, fmt.Errorf("cannot read cert file: %w", err)
+	if c.JWT.KeysFile != "" {
+		if _, err := jwtkeys.ReadFile(c.JWT.KeysFile); err != nil {
+			errs = append(errs, err)
+		}
 	}
-	if c.TLS.Key == "" {
-		return Config{}, fmt.Errorf("tls.key is empty")
+	backupConfig, err := validateBackup(c.Backup, config.Database.Driver)
+	if err != nil {
+		errs = append(errs, err)
 	}
-	key, err := os.ReadFile(c.TLS.Key)
+	config.Backup = backupConfig
+	loggingConfig, err := validateLogging(c.Logging)
 	if err != nil {
-		return Config{}, fmt.Errorf("cannot read key file: %w", err)
+		errs = append(errs, err)
 	}
-	if c.DBPath == "" {
-		return Config{}, errors.New("`db_path` is empty")
+	config.Logging = loggingConfig
+	mfaConfig, err := validateMFA(c.MFA)
+	errs = append(errs, err)
+	config.MFA = mfaConfig
+	config.ShutdownTimeout = DefaultShutdownTimeout
+	if c.ShutdownTimeout != "" {
+		config.ShutdownTimeout, err = time.ParseDuration(c.ShutdownTimeout)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid `shutdown_timeout`: %w", err))
+		} else if config.ShutdownTimeout <= 0 {
+			errs = append(errs, errors.New("`shutdown_timeout` must be positive"))
+		}
 	}
-	dbfile, err := os.OpenFile(c.DBPath, os.O_CREATE|os.O_RDONLY, 0o644)
-	if err != nil {
-		return Config{}, fmt.Errorf("cannot open db file: %w", err)
+	config.ShutdownDrainPeriod = DefaultShutdownDrainPeriod
+	if c.ShutdownDrainPeriod != "" {
+		config.ShutdownDrainPeriod, err = time.ParseDuration(c.ShutdownDrainPeriod)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid `shutdown_drain_period`: %w", err))
+		} else if config.ShutdownDrainPeriod < 0 {
+			errs = append(errs, errors.New("`shutdown_drain_period` can't be negative"))
+		}
 	}
-	err = dbfile.Close()
-	if err != nil {
-		return Config{}, fmt.Errorf("cannot close db file: %w", err)
+	trustedProxies, err := validateTrustedProxies(c.TrustedProxies)
+	errs = append(errs, err)
+	config.TrustedProxies = trustedProxies
+	if c.MetricsAddress != "" {
+		if _, port, err := net.SplitHostPort(c.MetricsAddress); err != nil {
+			errs = append(errs, fmt.Errorf("invalid `metrics_address`: %w", err))
+		} else if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+			errs = append(errs, fmt.Errorf("invalid `metrics_address`: invalid port %q", port))
+		}
 	}
-	if c.Port == 0 {
-		return Config{}, errors.New("port is empty")
+	if err := errors.Join(errs...); err != nil {
+		return Config{}, err
 	}
+	config.PlainHTTP = c.PlainHTTP
+	config.MetricsAddress = c.MetricsAddress
 	config.Port = c.Port
-	config.TLS.Cert = cert
-	config.TLS.Key = key
 	config.DBPath = c.DBPath
+	config.JWT.KeysFile = c.JWT.KeysFile
 	return config, nil
 }
+
+// checkDBFile checks that the SQLite database at path can be opened for writing without creating
+// it: an existing file must be writable, otherwise its directory must be.
+func checkDBFile(path string) error {
+	info, err := os.Stat(path)
+	if err == nil {
+		if info.IsDir() {
+			return fmt.Errorf("cannot open db file: %s is a directory", path)
+		}
+		dbfile, err := os.OpenFile(path, os.O_RDWR, 0)
+		if err != nil {
+			return fmt.Errorf("cannot open db file: %w", err)
+		}
+		if err := dbfile.Close(); err != nil {
+			return fmt.Errorf("cannot close db file: %w", err)
+		}
+		return nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("cannot open db file: %w", err)
+	}
+	dir := filepath.Dir(path)
+	info, err = os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("cannot create db file: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("cannot create db file: %s is not a directory", dir)
+	}
+	if err := checkDirWritable(dir); err != nil {
+		return fmt.Errorf("cannot create db file: %w", err)
+	}
+	return nil
+}
+
+// validateTLS checks that the certificate and the key of the server can be read, and the TLS policy.
+// Connections are at least TLS 1.2 by default, with the cipher suites of crypto/tls.
+func validateTLS(c TLSYaml) (TLS, error) {
+	config := TLS{CertFile: c.Cert, KeyFile: c.Key, ClientCAFile: c.ClientCA}
+	var errs []error
+	if c.Cert == "" {
+		errs = append(errs, errors.New("tls.cert is empty"))
+	} else if _, err := os.ReadFile(c.Cert); err != nil {
+		errs = append(errs, fmt.Errorf("cannot read cert file: %w", err))
+	}
+	if c.Key == "" {
+		errs = append(errs, errors.New("tls.key is empty"))
+	} else if _, err := os.ReadFile(c.Key); err != nil {
+		errs = append(errs, fmt.Errorf("cannot read key file: %w", err))
+	}
+	switch c.MinVersion {
+	case "", "1.2":
+		config.MinVersion = tls.VersionTLS12
+	case "1.3":
+		config.MinVersion = tls.VersionTLS13
+	default:
+		errs = append(errs, fmt.Errorf("unknown `tls.min_version` %q: must be 1.2 or 1.3", c.MinVersion))
+	}
+	if len(c.CipherSuites) > 0 && config.MinVersion == tls.VersionTLS13 {
+		errs = append(errs, errors.New("`tls.cipher_suites` only apply to TLS 1.2: the cipher suites of TLS 1.3 can't be configured"))
+	}
+	for _, name := range c.CipherSuites {
+		id, ok := cipherSuite(name)
+		if !ok {
+			errs = append(errs, fmt.Errorf("unknown or insecure cipher suite %q in `tls.cipher_suites`", name))
+			continue
+		}
+		config.CipherSuites = append(config.CipherSuites, id)
+	}
+	if c.ClientCA != "" {
+		pem, err := os.ReadFile(c.ClientCA)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot read client CA file: %w", err))
+		} else if !x509.NewCertPool().AppendCertsFromPEM(pem) {
+			errs = append(errs, errors.New("`tls.client_ca` holds no PEM certificate"))
+		}
+	}
+	return config, errors.Join(errs...)
+}
+
+// cipherSuite returns the ID of a TLS 1.2 cipher suite crypto/tls considers secure, by its name.
+func cipherSuite(name string) (uint16, bool) {
+	for _, suite := range tls.CipherSuites() {
+		if suite.Name == name && slices.Contains(suite.SupportedVersions, tls.VersionTLS12) {
+			return suite.ID, true
+		}
+	}
+	return 0, false
+}
+
+// validateTrustedProxies parses the trusted proxies, given as networks in CIDR notation or as single addresses.
+func validateTrustedProxies(proxies []string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	var errs []error
+	for _, proxy := range proxies {
+		if addr, err := netip.ParseAddr(proxy); err == nil {
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(proxy)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid `trusted_proxies` entry %q: must be an IP address or a CIDR network", proxy))
+			continue
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, errors.Join(errs...)
+}
+
+// validateBackup checks the backup section. Backups are optional: without a directory, they are disabled,
+// and without an interval, backups are only taken on demand.
+func validateBackup(c BackupYaml, driver string) (backup.Config, error) {
+	if c.Dir == "" {
+		if c.Interval != "" || c.Keep != 0 {
+			return backup.Config{}, errors.New("`backup.dir` is empty")
+		}
+		return backup.Config{}, nil
+	}
+	if driver != db.SQLite {
+		return backup.Config{}, errors.New("`backup` is only supported with the sqlite database driver")
+	}
+	if c.Keep < 0 {
+		return backup.Config{}, errors.New("`backup.keep` can't be negative")
+	}
+	var interval time.Duration
+	if c.Interval != "" {
+		var err error
+		interval, err = time.ParseDuration(c.Interval)
+		if err != nil {
+			return backup.Config{}, fmt.Errorf("invalid `backup.interval`: %w", err)
+		}
+		if interval < time.Minute {
+			return backup.Config{}, errors.New("`backup.interval` must be at least 1m")
+		}
+	}
+	return backup.Config{Dir: c.Dir, Interval: interval, Keep: c.Keep, Compress: c.Compress}, nil
+}
+
+// validateLogging checks the logging section. Logs are written as text, from the info level, by default.
+func validateLogging(c LoggingYaml) (Logging, error) {
+	logging := Logging{Format: LogFormatText, Level: slog.LevelInfo}
+	switch c.Format {
+	case "":
+	case LogFormatText, LogFormatJSON:
+		logging.Format = c.Format
+	default:
+		return Logging{}, fmt.Errorf("unknown `logging.format` %q: must be text or json", c.Format)
+	}
+	if c.Level != "" {
+		if err := logging.Level.UnmarshalText([]byte(c.Level)); err != nil {
+			return Logging{}, fmt.Errorf("invalid `logging.level` %q: must be debug, info, warn or error", c.Level)
+		}
+	}
+	return logging, nil
+}
+
+// validateMFA checks the mfa section. No role is required to have a second factor by default.
+func validateMFA(c MFAYaml) (MFA, error) {
+	config := MFA{Issuer: DefaultMFAIssuer}
+	var errs []error
+	if c.Issuer != "" {
+		config.Issuer = c.Issuer
+	}
+	if strings.Contains(config.Issuer, ":") {
+		errs = append(errs, errors.New("`mfa.issuer` can't contain a colon"))
+	}
+	for _, role := range c.RequiredRoles {
+		if role != RoleAdmin && role != RoleEmployer {
+			errs = append(errs, fmt.Errorf("unknown role %q in `mfa.required_roles`: must be admin or employer", role))
+			continue
+		}
+		if !slices.Contains(config.RequiredRoles, role) {
+			config.RequiredRoles = append(config.RequiredRoles, role)
+		}
+	}
+	return config, errors.Join(errs...)
+}
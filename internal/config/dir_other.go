@@ -0,0 +1,9 @@
+//go:build !unix
+
+package config
+
+// checkDirWritable can't tell whether files can be created in dir without creating one on this platform,
+// so it leaves it to SQLite to report when the database is opened.
+func checkDirWritable(dir string) error {
+	return nil
+}
@@ -0,0 +1,172 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EnvPrefix prefixes the environment variables that override the settings of the config file.
+// A setting is overridden by the variable named after its key: LESVIEUX_TLS_CERT overrides `tls.cert`.
+const EnvPrefix = "LESVIEUX_"
+
+// FileEnv is the environment variable naming the config file when the -config flag isn't given.
+const FileEnv = EnvPrefix + "CONFIG"
+
+// setting is a value of ConfigYAML, which the config file, the environment and the flags can each set.
+type setting struct {
+	// key is the path of the setting in the config file, such as `tls.cert`. It is also the name of its flag.
+	key   string
+	value reflect.Value
+}
+
+// env returns the name of the environment variable of the setting: LESVIEUX_TLS_CERT for `tls.cert`.
+func (s setting) env() string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(s.key, ".", "_"))
+}
+
+// set parses a value given as text, in the environment or as a flag. Lists are separated by commas.
+func (s setting) set(text string) error {
+	switch s.value.Kind() {
+	case reflect.String:
+		s.value.SetString(text)
+	case reflect.Int:
+		v, err := strconv.Atoi(text)
+		if err != nil {
+			return errors.New("must be an integer")
+		}
+		s.value.SetInt(int64(v))
+	case reflect.Bool:
+		v, err := strconv.ParseBool(text)
+		if err != nil {
+			return errors.New("must be true or false")
+		}
+		s.value.SetBool(v)
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(text, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		s.value.Set(reflect.ValueOf(items))
+	}
+	return nil
+}
+
+// settings lists the settings of c, in the order of the fields of ConfigYAML, with values that set the fields of c.
+func settings(c *ConfigYAML) []setting {
+	var list []setting
+	var walk func(prefix string, v reflect.Value)
+	walk = func(prefix string, v reflect.Value) {
+		for i := 0; i < v.NumField(); i++ {
+			key := prefix + v.Type().Field(i).Tag.Get("yaml")
+			if v.Field(i).Kind() == reflect.Struct {
+				walk(key+".", v.Field(i))
+				continue
+			}
+			list = append(list, setting{key: key, value: v.Field(i)})
+		}
+	}
+	walk("", reflect.ValueOf(c).Elem())
+	return list
+}
+
+// defaults returns the settings that apply when no source sets them.
+func defaults() ConfigYAML {
+	return ConfigYAML{
+		Logging:             LoggingYaml{Format: LogFormatText, Level: "info"},
+		MFA:                 MFAYaml{Issuer: DefaultMFAIssuer},
+		ShutdownTimeout:     DefaultShutdownTimeout.String(),
+		ShutdownDrainPeriod: DefaultShutdownDrainPeriod.String(),
+	}
+}
+
+// settingFlag is the flag of a setting. Its value is only read once the flags are parsed, in Resolve.
+type settingFlag struct {
+	text   string
+	isBool bool
+}
+
+func (f *settingFlag) String() string { return f.text }
+
+func (f *settingFlag) Set(text string) error {
+	f.text = text
+	return nil
+}
+
+func (f *settingFlag) IsBoolFlag() bool { return f.isBool }
+
+// AddFlags defines a flag on fs for every setting, named after its key: -port, -tls.cert, -backup.compress.
+func AddFlags(fs *flag.FlagSet) {
+	for _, s := range settings(&ConfigYAML{}) {
+		f := &settingFlag{isBool: s.value.Kind() == reflect.Bool}
+		fs.Var(f, s.key, fmt.Sprintf("Overrides `%s` of the config file", s.key))
+	}
+}
+
+// Resolve returns the effective settings, from the highest precedence to the lowest:
+// the flags set on fs, the LESVIEUX_* environment variables, the config file and the defaults.
+// The config file is optional when filePath is empty, and fs can be nil.
+// The settings are checked by Check; Resolve only returns the values that couldn't be parsed, all at once.
+// The settings are nil when the config file can't be read.
+func Resolve(filePath string, fs *flag.FlagSet) (*ConfigYAML, error) {
+	c := defaults()
+	if filePath != "" {
+		configYaml, err := os.ReadFile(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read config file: %w", err)
+		}
+		if err := yaml.Unmarshal(configYaml, &c); err != nil {
+			return nil, fmt.Errorf("cannot unmarshal config file: %w", err)
+		}
+	}
+	flags := map[string]string{}
+	if fs != nil {
+		fs.Visit(func(f *flag.Flag) {
+			if sf, ok := f.Value.(*settingFlag); ok {
+				flags[f.Name] = sf.text
+			}
+		})
+	}
+	var errs []error
+	for _, s := range settings(&c) {
+		if text, ok := os.LookupEnv(s.env()); ok {
+			if err := s.set(text); err != nil {
+				errs = append(errs, fmt.Errorf("invalid `%s` in %s: %w", s.key, s.env(), err))
+			}
+		}
+		if text, ok := flags[s.key]; ok {
+			if err := s.set(text); err != nil {
+				errs = append(errs, fmt.Errorf("invalid `%s` in -%s: %w", s.key, s.key, err))
+			}
+		}
+	}
+	return &c, errors.Join(errs...)
+}
+
+// Redacted returns a copy of the settings that can be printed: the password of the database URL is hidden,
+// whether it is in its user info or in its query. URLs that can't be parsed are hidden entirely.
+func (c ConfigYAML) Redacted() ConfigYAML {
+	if c.Database.URL == "" {
+		return c
+	}
+	u, err := url.Parse(c.Database.URL)
+	if err != nil || u.Scheme == "" {
+		c.Database.URL = "xxxxx"
+		return c
+	}
+	if query := u.Query(); query.Has("password") {
+		query.Set("password", "xxxxx")
+		u.RawQuery = query.Encode()
+	}
+	c.Database.URL = u.Redacted()
+	return c
+}
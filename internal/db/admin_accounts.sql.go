@@ -66,11 +66,55 @@ func (q *Queries) GetAdminAccountByEmail(ctx context.Context, email string) (Adm
 
 const listAdminAccounts = `-- name: ListAdminAccounts :many
 SELECT id, email, password_hash FROM admin_accounts
-ORDER BY email
+WHERE (email, id) > (?, ?)
+ORDER BY email, id
+LIMIT ?
 `
 
-func (q *Queries) ListAdminAccounts(ctx context.Context) ([]AdminAccount, error) {
-	rows, err := q.db.QueryContext(ctx, listAdminAccounts)
+type ListAdminAccountsParams struct {
+	CursorKey string
+	CursorID  int64
+	Limit     int64
+}
+
+func (q *Queries) ListAdminAccounts(ctx context.Context, arg ListAdminAccountsParams) ([]AdminAccount, error) {
+	rows, err := q.db.QueryContext(ctx, listAdminAccounts, arg.CursorKey, arg.CursorID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AdminAccount
+	for rows.Next() {
+		var i AdminAccount
+		if err := rows.Scan(&i.ID, &i.Email, &i.PasswordHash); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAdminAccountsDesc = `-- name: ListAdminAccountsDesc :many
+SELECT id, email, password_hash FROM admin_accounts
+WHERE (email, id) < (?, ?)
+ORDER BY email DESC, id DESC
+LIMIT ?
+`
+
+type ListAdminAccountsDescParams struct {
+	CursorKey string
+	CursorID  int64
+	Limit     int64
+}
+
+func (q *Queries) ListAdminAccountsDesc(ctx context.Context, arg ListAdminAccountsDescParams) ([]AdminAccount, error) {
+	rows, err := q.db.QueryContext(ctx, listAdminAccountsDesc, arg.CursorKey, arg.CursorID, arg.Limit)
 	if err != nil {
 		return nil, err
 	}
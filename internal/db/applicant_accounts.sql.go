@@ -0,0 +1,157 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: applicant_accounts.sql
+
+package db
+
+import (
+	"context"
+)
+
+const createApplicantAccount = `-- name: CreateApplicantAccount :one
+INSERT INTO applicant_accounts (
+  email, password_hash, name, phone_number, city, postal_code, created_at
+) VALUES (
+  ?, ?, ?, ?, ?, ?, ?
+)
+RETURNING id, email, password_hash, name, phone_number, city, postal_code, created_at
+`
+
+type CreateApplicantAccountParams struct {
+	Email        string
+	PasswordHash string
+	Name         string
+	PhoneNumber  string
+	City         string
+	PostalCode   string
+	CreatedAt    string
+}
+
+func (q *Queries) CreateApplicantAccount(ctx context.Context, arg CreateApplicantAccountParams) (ApplicantAccount, error) {
+	row := q.db.QueryRowContext(ctx, createApplicantAccount,
+		arg.Email,
+		arg.PasswordHash,
+		arg.Name,
+		arg.PhoneNumber,
+		arg.City,
+		arg.PostalCode,
+		arg.CreatedAt,
+	)
+	var i ApplicantAccount
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.PasswordHash,
+		&i.Name,
+		&i.PhoneNumber,
+		&i.City,
+		&i.PostalCode,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteApplicantAccount = `-- name: DeleteApplicantAccount :exec
+DELETE FROM applicant_accounts
+WHERE id = ?
+`
+
+func (q *Queries) DeleteApplicantAccount(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, deleteApplicantAccount, id)
+	return err
+}
+
+const getApplicantAccount = `-- name: GetApplicantAccount :one
+SELECT id, email, password_hash, name, phone_number, city, postal_code, created_at FROM applicant_accounts
+WHERE id = ? LIMIT 1
+`
+
+func (q *Queries) GetApplicantAccount(ctx context.Context, id int64) (ApplicantAccount, error) {
+	row := q.db.QueryRowContext(ctx, getApplicantAccount, id)
+	var i ApplicantAccount
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.PasswordHash,
+		&i.Name,
+		&i.PhoneNumber,
+		&i.City,
+		&i.PostalCode,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getApplicantAccountByEmail = `-- name: GetApplicantAccountByEmail :one
+SELECT id, email, password_hash, name, phone_number, city, postal_code, created_at FROM applicant_accounts
+WHERE email = ? LIMIT 1
+`
+
+func (q *Queries) GetApplicantAccountByEmail(ctx context.Context, email string) (ApplicantAccount, error) {
+	row := q.db.QueryRowContext(ctx, getApplicantAccountByEmail, email)
+	var i ApplicantAccount
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.PasswordHash,
+		&i.Name,
+		&i.PhoneNumber,
+		&i.City,
+		&i.PostalCode,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const numApplicantAccounts = `-- name: NumApplicantAccounts :one
+SELECT COUNT(*) FROM applicant_accounts
+`
+
+func (q *Queries) NumApplicantAccounts(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, numApplicantAccounts)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const updateApplicantAccountPassword = `-- name: UpdateApplicantAccountPassword :exec
+UPDATE applicant_accounts
+SET password_hash = ?
+WHERE id = ?
+`
+
+type UpdateApplicantAccountPasswordParams struct {
+	PasswordHash string
+	ID           int64
+}
+
+func (q *Queries) UpdateApplicantAccountPassword(ctx context.Context, arg UpdateApplicantAccountPasswordParams) error {
+	_, err := q.db.ExecContext(ctx, updateApplicantAccountPassword, arg.PasswordHash, arg.ID)
+	return err
+}
+
+const updateApplicantAccountProfile = `-- name: UpdateApplicantAccountProfile :exec
+UPDATE applicant_accounts
+SET name = ?, phone_number = ?, city = ?, postal_code = ?
+WHERE id = ?
+`
+
+type UpdateApplicantAccountProfileParams struct {
+	Name        string
+	PhoneNumber string
+	City        string
+	PostalCode  string
+	ID          int64
+}
+
+func (q *Queries) UpdateApplicantAccountProfile(ctx context.Context, arg UpdateApplicantAccountProfileParams) error {
+	_, err := q.db.ExecContext(ctx, updateApplicantAccountProfile,
+		arg.Name,
+		arg.PhoneNumber,
+		arg.City,
+		arg.PostalCode,
+		arg.ID,
+	)
+	return err
+}
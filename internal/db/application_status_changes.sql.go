@@ -0,0 +1,88 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: application_status_changes.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+const createApplicationStatusChange = `-- name: CreateApplicationStatusChange :one
+INSERT INTO application_status_changes (
+  application_id, from_status, to_status, changed_at, actor_id, actor_role
+) VALUES (
+  ?, ?, ?, ?, ?, ?
+)
+RETURNING id, application_id, from_status, to_status, changed_at, actor_id, actor_role
+`
+
+type CreateApplicationStatusChangeParams struct {
+	ApplicationID int64
+	FromStatus    string
+	ToStatus      string
+	ChangedAt     string
+	ActorID       sql.NullInt64
+	ActorRole     sql.NullInt64
+}
+
+func (q *Queries) CreateApplicationStatusChange(ctx context.Context, arg CreateApplicationStatusChangeParams) (ApplicationStatusChange, error) {
+	row := q.db.QueryRowContext(ctx, createApplicationStatusChange,
+		arg.ApplicationID,
+		arg.FromStatus,
+		arg.ToStatus,
+		arg.ChangedAt,
+		arg.ActorID,
+		arg.ActorRole,
+	)
+	var i ApplicationStatusChange
+	err := row.Scan(
+		&i.ID,
+		&i.ApplicationID,
+		&i.FromStatus,
+		&i.ToStatus,
+		&i.ChangedAt,
+		&i.ActorID,
+		&i.ActorRole,
+	)
+	return i, err
+}
+
+const listApplicationStatusChanges = `-- name: ListApplicationStatusChanges :many
+SELECT id, application_id, from_status, to_status, changed_at, actor_id, actor_role FROM application_status_changes
+WHERE application_id = ?
+ORDER BY id
+`
+
+func (q *Queries) ListApplicationStatusChanges(ctx context.Context, applicationID int64) ([]ApplicationStatusChange, error) {
+	rows, err := q.db.QueryContext(ctx, listApplicationStatusChanges, applicationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ApplicationStatusChange
+	for rows.Next() {
+		var i ApplicationStatusChange
+		if err := rows.Scan(
+			&i.ID,
+			&i.ApplicationID,
+			&i.FromStatus,
+			&i.ToStatus,
+			&i.ChangedAt,
+			&i.ActorID,
+			&i.ActorRole,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,466 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: applications.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+const createApplication = `-- name: CreateApplication :one
+INSERT INTO applications (
+  job_post_id, applicant_id, cover_message, status, created_at, updated_at
+) VALUES (
+  ?, ?, ?, ?, ?, ?
+)
+RETURNING id, job_post_id, applicant_id, cover_message, status, created_at, updated_at
+`
+
+type CreateApplicationParams struct {
+	JobPostID    int64
+	ApplicantID  int64
+	CoverMessage string
+	Status       string
+	CreatedAt    string
+	UpdatedAt    string
+}
+
+func (q *Queries) CreateApplication(ctx context.Context, arg CreateApplicationParams) (Application, error) {
+	row := q.db.QueryRowContext(ctx, createApplication,
+		arg.JobPostID,
+		arg.ApplicantID,
+		arg.CoverMessage,
+		arg.Status,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+	)
+	var i Application
+	err := row.Scan(
+		&i.ID,
+		&i.JobPostID,
+		&i.ApplicantID,
+		&i.CoverMessage,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getApplicantApplication = `-- name: GetApplicantApplication :one
+SELECT applications.id, applications.job_post_id, applications.applicant_id, applications.cover_message, applications.status, applications.created_at, applications.updated_at, job_posts.title AS job_post_title, employers.name AS employer_name
+FROM applications
+JOIN job_posts ON job_posts.id = applications.job_post_id
+JOIN employers ON employers.id = job_posts.employer_id
+WHERE applications.id = ? AND applications.applicant_id = ? LIMIT 1
+`
+
+type GetApplicantApplicationParams struct {
+	ID          int64
+	ApplicantID int64
+}
+
+type GetApplicantApplicationRow struct {
+	Application  Application
+	JobPostTitle string
+	EmployerName string
+}
+
+func (q *Queries) GetApplicantApplication(ctx context.Context, arg GetApplicantApplicationParams) (GetApplicantApplicationRow, error) {
+	row := q.db.QueryRowContext(ctx, getApplicantApplication, arg.ID, arg.ApplicantID)
+	var i GetApplicantApplicationRow
+	err := row.Scan(
+		&i.Application.ID,
+		&i.Application.JobPostID,
+		&i.Application.ApplicantID,
+		&i.Application.CoverMessage,
+		&i.Application.Status,
+		&i.Application.CreatedAt,
+		&i.Application.UpdatedAt,
+		&i.JobPostTitle,
+		&i.EmployerName,
+	)
+	return i, err
+}
+
+const getApplicationForJobPost = `-- name: GetApplicationForJobPost :one
+SELECT id, job_post_id, applicant_id, cover_message, status, created_at, updated_at FROM applications
+WHERE job_post_id = ? AND applicant_id = ? LIMIT 1
+`
+
+type GetApplicationForJobPostParams struct {
+	JobPostID   int64
+	ApplicantID int64
+}
+
+func (q *Queries) GetApplicationForJobPost(ctx context.Context, arg GetApplicationForJobPostParams) (Application, error) {
+	row := q.db.QueryRowContext(ctx, getApplicationForJobPost, arg.JobPostID, arg.ApplicantID)
+	var i Application
+	err := row.Scan(
+		&i.ID,
+		&i.JobPostID,
+		&i.ApplicantID,
+		&i.CoverMessage,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getJobPostApplication = `-- name: GetJobPostApplication :one
+SELECT applications.id, applications.job_post_id, applications.applicant_id, applications.cover_message, applications.status, applications.created_at, applications.updated_at, applicant_accounts.email AS applicant_email, applicant_accounts.name AS applicant_name, applicant_accounts.phone_number AS applicant_phone_number, applicant_accounts.city AS applicant_city, applicant_accounts.postal_code AS applicant_postal_code
+FROM applications
+JOIN applicant_accounts ON applicant_accounts.id = applications.applicant_id
+WHERE applications.id = ? AND applications.job_post_id = ? LIMIT 1
+`
+
+type GetJobPostApplicationParams struct {
+	ID        int64
+	JobPostID int64
+}
+
+type GetJobPostApplicationRow struct {
+	Application          Application
+	ApplicantEmail       string
+	ApplicantName        string
+	ApplicantPhoneNumber string
+	ApplicantCity        string
+	ApplicantPostalCode  string
+}
+
+func (q *Queries) GetJobPostApplication(ctx context.Context, arg GetJobPostApplicationParams) (GetJobPostApplicationRow, error) {
+	row := q.db.QueryRowContext(ctx, getJobPostApplication, arg.ID, arg.JobPostID)
+	var i GetJobPostApplicationRow
+	err := row.Scan(
+		&i.Application.ID,
+		&i.Application.JobPostID,
+		&i.Application.ApplicantID,
+		&i.Application.CoverMessage,
+		&i.Application.Status,
+		&i.Application.CreatedAt,
+		&i.Application.UpdatedAt,
+		&i.ApplicantEmail,
+		&i.ApplicantName,
+		&i.ApplicantPhoneNumber,
+		&i.ApplicantCity,
+		&i.ApplicantPostalCode,
+	)
+	return i, err
+}
+
+const listApplicantApplicationsNewestFirst = `-- name: ListApplicantApplicationsNewestFirst :many
+SELECT applications.id, applications.job_post_id, applications.applicant_id, applications.cover_message, applications.status, applications.created_at, applications.updated_at, job_posts.title AS job_post_title, employers.name AS employer_name
+FROM applications
+JOIN job_posts ON job_posts.id = applications.job_post_id
+JOIN employers ON employers.id = job_posts.employer_id
+WHERE applications.applicant_id = ?
+  AND (? IS NULL OR applications.status = ?)
+  AND (applications.created_at, applications.id) < (?, ?)
+ORDER BY applications.created_at DESC, applications.id DESC
+LIMIT ?
+`
+
+type ListApplicantApplicationsNewestFirstParams struct {
+	ApplicantID int64
+	Status      sql.NullString
+	CursorKey   string
+	CursorID    int64
+	Limit       int64
+}
+
+type ListApplicantApplicationsNewestFirstRow struct {
+	Application  Application
+	JobPostTitle string
+	EmployerName string
+}
+
+func (q *Queries) ListApplicantApplicationsNewestFirst(ctx context.Context, arg ListApplicantApplicationsNewestFirstParams) ([]ListApplicantApplicationsNewestFirstRow, error) {
+	rows, err := q.db.QueryContext(ctx, listApplicantApplicationsNewestFirst,
+		arg.ApplicantID,
+		arg.Status,
+		arg.Status,
+		arg.CursorKey,
+		arg.CursorID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListApplicantApplicationsNewestFirstRow
+	for rows.Next() {
+		var i ListApplicantApplicationsNewestFirstRow
+		if err := rows.Scan(
+			&i.Application.ID,
+			&i.Application.JobPostID,
+			&i.Application.ApplicantID,
+			&i.Application.CoverMessage,
+			&i.Application.Status,
+			&i.Application.CreatedAt,
+			&i.Application.UpdatedAt,
+			&i.JobPostTitle,
+			&i.EmployerName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listApplicantApplicationsOldestFirst = `-- name: ListApplicantApplicationsOldestFirst :many
+SELECT applications.id, applications.job_post_id, applications.applicant_id, applications.cover_message, applications.status, applications.created_at, applications.updated_at, job_posts.title AS job_post_title, employers.name AS employer_name
+FROM applications
+JOIN job_posts ON job_posts.id = applications.job_post_id
+JOIN employers ON employers.id = job_posts.employer_id
+WHERE applications.applicant_id = ?
+  AND (? IS NULL OR applications.status = ?)
+  AND (applications.created_at, applications.id) > (?, ?)
+ORDER BY applications.created_at, applications.id
+LIMIT ?
+`
+
+type ListApplicantApplicationsOldestFirstParams struct {
+	ApplicantID int64
+	Status      sql.NullString
+	CursorKey   string
+	CursorID    int64
+	Limit       int64
+}
+
+type ListApplicantApplicationsOldestFirstRow struct {
+	Application  Application
+	JobPostTitle string
+	EmployerName string
+}
+
+func (q *Queries) ListApplicantApplicationsOldestFirst(ctx context.Context, arg ListApplicantApplicationsOldestFirstParams) ([]ListApplicantApplicationsOldestFirstRow, error) {
+	rows, err := q.db.QueryContext(ctx, listApplicantApplicationsOldestFirst,
+		arg.ApplicantID,
+		arg.Status,
+		arg.Status,
+		arg.CursorKey,
+		arg.CursorID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListApplicantApplicationsOldestFirstRow
+	for rows.Next() {
+		var i ListApplicantApplicationsOldestFirstRow
+		if err := rows.Scan(
+			&i.Application.ID,
+			&i.Application.JobPostID,
+			&i.Application.ApplicantID,
+			&i.Application.CoverMessage,
+			&i.Application.Status,
+			&i.Application.CreatedAt,
+			&i.Application.UpdatedAt,
+			&i.JobPostTitle,
+			&i.EmployerName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listJobPostApplicationsNewestFirst = `-- name: ListJobPostApplicationsNewestFirst :many
+SELECT applications.id, applications.job_post_id, applications.applicant_id, applications.cover_message, applications.status, applications.created_at, applications.updated_at, applicant_accounts.email AS applicant_email, applicant_accounts.name AS applicant_name, applicant_accounts.phone_number AS applicant_phone_number, applicant_accounts.city AS applicant_city, applicant_accounts.postal_code AS applicant_postal_code
+FROM applications
+JOIN applicant_accounts ON applicant_accounts.id = applications.applicant_id
+WHERE applications.job_post_id = ?
+  AND (? IS NULL OR applications.status = ?)
+  AND (applications.created_at, applications.id) < (?, ?)
+ORDER BY applications.created_at DESC, applications.id DESC
+LIMIT ?
+`
+
+type ListJobPostApplicationsNewestFirstParams struct {
+	JobPostID int64
+	Status    sql.NullString
+	CursorKey string
+	CursorID  int64
+	Limit     int64
+}
+
+type ListJobPostApplicationsNewestFirstRow struct {
+	Application          Application
+	ApplicantEmail       string
+	ApplicantName        string
+	ApplicantPhoneNumber string
+	ApplicantCity        string
+	ApplicantPostalCode  string
+}
+
+func (q *Queries) ListJobPostApplicationsNewestFirst(ctx context.Context, arg ListJobPostApplicationsNewestFirstParams) ([]ListJobPostApplicationsNewestFirstRow, error) {
+	rows, err := q.db.QueryContext(ctx, listJobPostApplicationsNewestFirst,
+		arg.JobPostID,
+		arg.Status,
+		arg.Status,
+		arg.CursorKey,
+		arg.CursorID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListJobPostApplicationsNewestFirstRow
+	for rows.Next() {
+		var i ListJobPostApplicationsNewestFirstRow
+		if err := rows.Scan(
+			&i.Application.ID,
+			&i.Application.JobPostID,
+			&i.Application.ApplicantID,
+			&i.Application.CoverMessage,
+			&i.Application.Status,
+			&i.Application.CreatedAt,
+			&i.Application.UpdatedAt,
+			&i.ApplicantEmail,
+			&i.ApplicantName,
+			&i.ApplicantPhoneNumber,
+			&i.ApplicantCity,
+			&i.ApplicantPostalCode,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listJobPostApplicationsOldestFirst = `-- name: ListJobPostApplicationsOldestFirst :many
+SELECT applications.id, applications.job_post_id, applications.applicant_id, applications.cover_message, applications.status, applications.created_at, applications.updated_at, applicant_accounts.email AS applicant_email, applicant_accounts.name AS applicant_name, applicant_accounts.phone_number AS applicant_phone_number, applicant_accounts.city AS applicant_city, applicant_accounts.postal_code AS applicant_postal_code
+FROM applications
+JOIN applicant_accounts ON applicant_accounts.id = applications.applicant_id
+WHERE applications.job_post_id = ?
+  AND (? IS NULL OR applications.status = ?)
+  AND (applications.created_at, applications.id) > (?, ?)
+ORDER BY applications.created_at, applications.id
+LIMIT ?
+`
+
+type ListJobPostApplicationsOldestFirstParams struct {
+	JobPostID int64
+	Status    sql.NullString
+	CursorKey string
+	CursorID  int64
+	Limit     int64
+}
+
+type ListJobPostApplicationsOldestFirstRow struct {
+	Application          Application
+	ApplicantEmail       string
+	ApplicantName        string
+	ApplicantPhoneNumber string
+	ApplicantCity        string
+	ApplicantPostalCode  string
+}
+
+func (q *Queries) ListJobPostApplicationsOldestFirst(ctx context.Context, arg ListJobPostApplicationsOldestFirstParams) ([]ListJobPostApplicationsOldestFirstRow, error) {
+	rows, err := q.db.QueryContext(ctx, listJobPostApplicationsOldestFirst,
+		arg.JobPostID,
+		arg.Status,
+		arg.Status,
+		arg.CursorKey,
+		arg.CursorID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListJobPostApplicationsOldestFirstRow
+	for rows.Next() {
+		var i ListJobPostApplicationsOldestFirstRow
+		if err := rows.Scan(
+			&i.Application.ID,
+			&i.Application.JobPostID,
+			&i.Application.ApplicantID,
+			&i.Application.CoverMessage,
+			&i.Application.Status,
+			&i.Application.CreatedAt,
+			&i.Application.UpdatedAt,
+			&i.ApplicantEmail,
+			&i.ApplicantName,
+			&i.ApplicantPhoneNumber,
+			&i.ApplicantCity,
+			&i.ApplicantPostalCode,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const numEmployerApplications = `-- name: NumEmployerApplications :one
+SELECT COUNT(*) FROM applications
+JOIN job_posts ON job_posts.id = applications.job_post_id
+WHERE job_posts.employer_id = ?
+`
+
+func (q *Queries) NumEmployerApplications(ctx context.Context, employerID int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, numEmployerApplications, employerID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const updateApplicationStatus = `-- name: UpdateApplicationStatus :execrows
+UPDATE applications
+SET status = ?, updated_at = ?
+WHERE id = ? AND status = ?
+`
+
+type UpdateApplicationStatusParams struct {
+	Status     string
+	UpdatedAt  string
+	ID         int64
+	FromStatus string
+}
+
+func (q *Queries) UpdateApplicationStatus(ctx context.Context, arg UpdateApplicationStatusParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, updateApplicationStatus,
+		arg.Status,
+		arg.UpdatedAt,
+		arg.ID,
+		arg.FromStatus,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,219 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: audit_events.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+const createAuditEvent = `-- name: CreateAuditEvent :one
+INSERT INTO audit_events (
+  occurred_at, actor_id, actor_role, action, target_type, target_id, before_summary, after_summary, ip, user_agent
+) VALUES (
+  ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+)
+RETURNING id, occurred_at, actor_id, actor_role, action, target_type, target_id, before_summary, after_summary, ip, user_agent
+`
+
+type CreateAuditEventParams struct {
+	OccurredAt    string
+	ActorID       sql.NullInt64
+	ActorRole     sql.NullInt64
+	Action        string
+	TargetType    string
+	TargetID      sql.NullInt64
+	BeforeSummary sql.NullString
+	AfterSummary  sql.NullString
+	Ip            string
+	UserAgent     string
+}
+
+func (q *Queries) CreateAuditEvent(ctx context.Context, arg CreateAuditEventParams) (AuditEvent, error) {
+	row := q.db.QueryRowContext(ctx, createAuditEvent,
+		arg.OccurredAt,
+		arg.ActorID,
+		arg.ActorRole,
+		arg.Action,
+		arg.TargetType,
+		arg.TargetID,
+		arg.BeforeSummary,
+		arg.AfterSummary,
+		arg.Ip,
+		arg.UserAgent,
+	)
+	var i AuditEvent
+	err := row.Scan(
+		&i.ID,
+		&i.OccurredAt,
+		&i.ActorID,
+		&i.ActorRole,
+		&i.Action,
+		&i.TargetType,
+		&i.TargetID,
+		&i.BeforeSummary,
+		&i.AfterSummary,
+		&i.Ip,
+		&i.UserAgent,
+	)
+	return i, err
+}
+
+const listAuditEventsNewestFirst = `-- name: ListAuditEventsNewestFirst :many
+SELECT id, occurred_at, actor_id, actor_role, action, target_type, target_id, before_summary, after_summary, ip, user_agent FROM audit_events
+WHERE (? IS NULL OR actor_id = ?)
+  AND (? IS NULL OR actor_role = ?)
+  AND (? IS NULL OR action = ?)
+  AND (? IS NULL OR target_type = ?)
+  AND (? IS NULL OR target_id = ?)
+  AND occurred_at >= COALESCE(?, occurred_at)
+  AND occurred_at <= COALESCE(?, occurred_at)
+  AND (occurred_at, id) < (?, ?)
+ORDER BY occurred_at DESC, id DESC
+LIMIT ?
+`
+
+type ListAuditEventsNewestFirstParams struct {
+	ActorID        sql.NullInt64
+	ActorRole      sql.NullInt64
+	Action         sql.NullString
+	TargetType     sql.NullString
+	TargetID       sql.NullInt64
+	OccurredAfter  sql.NullString
+	OccurredBefore sql.NullString
+	CursorKey      string
+	CursorID       int64
+	Limit          int64
+}
+
+func (q *Queries) ListAuditEventsNewestFirst(ctx context.Context, arg ListAuditEventsNewestFirstParams) ([]AuditEvent, error) {
+	rows, err := q.db.QueryContext(ctx, listAuditEventsNewestFirst,
+		arg.ActorID,
+		arg.ActorID,
+		arg.ActorRole,
+		arg.ActorRole,
+		arg.Action,
+		arg.Action,
+		arg.TargetType,
+		arg.TargetType,
+		arg.TargetID,
+		arg.TargetID,
+		arg.OccurredAfter,
+		arg.OccurredBefore,
+		arg.CursorKey,
+		arg.CursorID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AuditEvent
+	for rows.Next() {
+		var i AuditEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.OccurredAt,
+			&i.ActorID,
+			&i.ActorRole,
+			&i.Action,
+			&i.TargetType,
+			&i.TargetID,
+			&i.BeforeSummary,
+			&i.AfterSummary,
+			&i.Ip,
+			&i.UserAgent,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAuditEventsOldestFirst = `-- name: ListAuditEventsOldestFirst :many
+SELECT id, occurred_at, actor_id, actor_role, action, target_type, target_id, before_summary, after_summary, ip, user_agent FROM audit_events
+WHERE (? IS NULL OR actor_id = ?)
+  AND (? IS NULL OR actor_role = ?)
+  AND (? IS NULL OR action = ?)
+  AND (? IS NULL OR target_type = ?)
+  AND (? IS NULL OR target_id = ?)
+  AND occurred_at >= COALESCE(?, occurred_at)
+  AND occurred_at <= COALESCE(?, occurred_at)
+  AND (occurred_at, id) > (?, ?)
+ORDER BY occurred_at, id
+LIMIT ?
+`
+
+type ListAuditEventsOldestFirstParams struct {
+	ActorID        sql.NullInt64
+	ActorRole      sql.NullInt64
+	Action         sql.NullString
+	TargetType     sql.NullString
+	TargetID       sql.NullInt64
+	OccurredAfter  sql.NullString
+	OccurredBefore sql.NullString
+	CursorKey      string
+	CursorID       int64
+	Limit          int64
+}
+
+func (q *Queries) ListAuditEventsOldestFirst(ctx context.Context, arg ListAuditEventsOldestFirstParams) ([]AuditEvent, error) {
+	rows, err := q.db.QueryContext(ctx, listAuditEventsOldestFirst,
+		arg.ActorID,
+		arg.ActorID,
+		arg.ActorRole,
+		arg.ActorRole,
+		arg.Action,
+		arg.Action,
+		arg.TargetType,
+		arg.TargetType,
+		arg.TargetID,
+		arg.TargetID,
+		arg.OccurredAfter,
+		arg.OccurredBefore,
+		arg.CursorKey,
+		arg.CursorID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AuditEvent
+	for rows.Next() {
+		var i AuditEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.OccurredAt,
+			&i.ActorID,
+			&i.ActorRole,
+			&i.Action,
+			&i.TargetType,
+			&i.TargetID,
+			&i.BeforeSummary,
+			&i.AfterSummary,
+			&i.Ip,
+			&i.UserAgent,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -90,14 +90,91 @@ func (q *Queries) GetEmployerAccountByEmail(ctx context.Context, email string) (
 	return i, err
 }
 
+const getEmployerAccountByID = `-- name: GetEmployerAccountByID :one
+SELECT id, email, password_hash, employer_id FROM employer_accounts
+WHERE id = ? LIMIT 1
+`
+
+func (q *Queries) GetEmployerAccountByID(ctx context.Context, id int64) (EmployerAccount, error) {
+	row := q.db.QueryRowContext(ctx, getEmployerAccountByID, id)
+	var i EmployerAccount
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.PasswordHash,
+		&i.EmployerID,
+	)
+	return i, err
+}
+
 const listEmployerAccounts = `-- name: ListEmployerAccounts :many
 SELECT id, email, password_hash, employer_id FROM employer_accounts
-where employer_id = ?
-ORDER BY email
+where employer_id = ? AND (email, id) > (?, ?)
+ORDER BY email, id
+LIMIT ?
+`
+
+type ListEmployerAccountsParams struct {
+	EmployerID int64
+	CursorKey  string
+	CursorID   int64
+	Limit      int64
+}
+
+func (q *Queries) ListEmployerAccounts(ctx context.Context, arg ListEmployerAccountsParams) ([]EmployerAccount, error) {
+	rows, err := q.db.QueryContext(ctx, listEmployerAccounts,
+		arg.EmployerID,
+		arg.CursorKey,
+		arg.CursorID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []EmployerAccount
+	for rows.Next() {
+		var i EmployerAccount
+		if err := rows.Scan(
+			&i.ID,
+			&i.Email,
+			&i.PasswordHash,
+			&i.EmployerID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEmployerAccountsDesc = `-- name: ListEmployerAccountsDesc :many
+SELECT id, email, password_hash, employer_id FROM employer_accounts
+where employer_id = ? AND (email, id) < (?, ?)
+ORDER BY email DESC, id DESC
+LIMIT ?
 `
 
-func (q *Queries) ListEmployerAccounts(ctx context.Context, employerID int64) ([]EmployerAccount, error) {
-	rows, err := q.db.QueryContext(ctx, listEmployerAccounts, employerID)
+type ListEmployerAccountsDescParams struct {
+	EmployerID int64
+	CursorKey  string
+	CursorID   int64
+	Limit      int64
+}
+
+func (q *Queries) ListEmployerAccountsDesc(ctx context.Context, arg ListEmployerAccountsDescParams) ([]EmployerAccount, error) {
+	rows, err := q.db.QueryContext(ctx, listEmployerAccountsDesc,
+		arg.EmployerID,
+		arg.CursorKey,
+		arg.CursorID,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -135,6 +212,18 @@ func (q *Queries) NumEmployerAccounts(ctx context.Context) (int64, error) {
 	return count, err
 }
 
+const numEmployerAccountsOfEmployer = `-- name: NumEmployerAccountsOfEmployer :one
+SELECT COUNT(*) FROM employer_accounts
+WHERE employer_id = ?
+`
+
+func (q *Queries) NumEmployerAccountsOfEmployer(ctx context.Context, employerID int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, numEmployerAccountsOfEmployer, employerID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const updateEmployerAccount = `-- name: UpdateEmployerAccount :exec
 UPDATE employer_accounts
 set password_hash = ?
@@ -49,11 +49,55 @@ func (q *Queries) GetEmployer(ctx context.Context, id int64) (Employer, error) {
 
 const listEmployers = `-- name: ListEmployers :many
 SELECT id, name FROM employers
-ORDER BY name
+WHERE (name, id) > (?, ?)
+ORDER BY name, id
+LIMIT ?
 `
 
-func (q *Queries) ListEmployers(ctx context.Context) ([]Employer, error) {
-	rows, err := q.db.QueryContext(ctx, listEmployers)
+type ListEmployersParams struct {
+	CursorKey string
+	CursorID  int64
+	Limit     int64
+}
+
+func (q *Queries) ListEmployers(ctx context.Context, arg ListEmployersParams) ([]Employer, error) {
+	rows, err := q.db.QueryContext(ctx, listEmployers, arg.CursorKey, arg.CursorID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Employer
+	for rows.Next() {
+		var i Employer
+		if err := rows.Scan(&i.ID, &i.Name); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEmployersDesc = `-- name: ListEmployersDesc :many
+SELECT id, name FROM employers
+WHERE (name, id) < (?, ?)
+ORDER BY name DESC, id DESC
+LIMIT ?
+`
+
+type ListEmployersDescParams struct {
+	CursorKey string
+	CursorID  int64
+	Limit     int64
+}
+
+func (q *Queries) ListEmployersDesc(ctx context.Context, arg ListEmployersDescParams) ([]Employer, error) {
+	rows, err := q.db.QueryContext(ctx, listEmployersDesc, arg.CursorKey, arg.CursorID, arg.Limit)
 	if err != nil {
 		return nil, err
 	}
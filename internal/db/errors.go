@@ -0,0 +1,25 @@
+package db
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/mattn/go-sqlite3"
+)
+
+// postgresUniqueViolation is the SQLSTATE of PostgreSQL for a violated unique constraint.
+const postgresUniqueViolation = "23505"
+
+// IsUniqueViolation reports whether err is the error of a write that violated a unique constraint,
+// on SQLite or on PostgreSQL. Writes racing each other past an existence check fail with it.
+func IsUniqueViolation(err error) bool {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique || sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == postgresUniqueViolation
+	}
+	return false
+}
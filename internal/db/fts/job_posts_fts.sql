@@ -0,0 +1,34 @@
+CREATE VIRTUAL TABLE IF NOT EXISTS job_posts_fts USING fts5(
+    title,
+    content,
+    employer_name,
+    tokenize = 'unicode61 remove_diacritics 2'
+);
+
+CREATE TRIGGER IF NOT EXISTS job_posts_fts_insert AFTER INSERT ON job_posts BEGIN
+    INSERT INTO job_posts_fts (rowid, title, content, employer_name)
+    VALUES (new.id, new.title, new.content, (SELECT name FROM employers WHERE id = new.employer_id));
+END;
+
+CREATE TRIGGER IF NOT EXISTS job_posts_fts_update AFTER UPDATE OF title, content, employer_id ON job_posts BEGIN
+    UPDATE job_posts_fts
+    SET title = new.title, content = new.content, employer_name = (SELECT name FROM employers WHERE id = new.employer_id)
+    WHERE rowid = new.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS job_posts_fts_delete AFTER DELETE ON job_posts BEGIN
+    DELETE FROM job_posts_fts WHERE rowid = old.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS job_posts_fts_employer_update AFTER UPDATE OF name ON employers BEGIN
+    UPDATE job_posts_fts
+    SET employer_name = new.name
+    WHERE rowid IN (SELECT id FROM job_posts WHERE employer_id = new.id);
+END;
+
+-- Index the job posts written before the search index existed
+INSERT INTO job_posts_fts (rowid, title, content, employer_name)
+SELECT job_posts.id, job_posts.title, job_posts.content, employers.name
+FROM job_posts
+LEFT JOIN employers ON employers.id = job_posts.employer_id
+WHERE job_posts.id NOT IN (SELECT rowid FROM job_posts_fts);
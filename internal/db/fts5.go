@@ -0,0 +1,7 @@
+//go:build sqlite_fts5 || fts5
+
+package db
+
+// FullTextSearch reports whether SQLite was built with the FTS5 extension,
+// which job post search relies on. Build with the sqlite_fts5 tag to enable it.
+const FullTextSearch = true
@@ -4,39 +4,152 @@ import (
 	"context"
 	"database/sql"
 	_ "embed"
+	"fmt"
+	"log/slog"
+	"strings"
 
 	_ "github.com/mattn/go-sqlite3"
 )
 
-//go:embed schema/admin_accounts.sql
-var adminAccountsTableDdl string
+// The drivers of the databases LesVieux can store its data in.
+const (
+	SQLite   = "sqlite"
+	Postgres = "postgres"
+)
 
-//go:embed schema/employers.sql
-var employersTableDdl string
+//go:embed fts/job_posts_fts.sql
+var jobPostsFtsTableDdl string
 
-//go:embed schema/employer_accounts.sql
-var employerAccountsTableDdl string
+// ForeignKeyViolation is a row referencing a row of its parent table that doesn't exist.
+type ForeignKeyViolation struct {
+	Table  string
+	RowID  int64
+	Parent string
+}
 
-//go:embed schema/job_posts.sql
-var jobPostsTableDdl string
+// Open opens the SQLite database at dbPath, without changing its schema.
+// Foreign keys are enforced on every connection.
+func Open(dbPath string) (*sql.DB, error) {
+	separator := "?"
+	if strings.Contains(dbPath, "?") {
+		separator = "&"
+	}
+	return sql.Open("sqlite3", dbPath+separator+"_foreign_keys=on")
+}
 
+// OpenDatabase opens a database without changing its schema. The data source is the path of
+// the file of a SQLite database, and the URL of a PostgreSQL database.
+func OpenDatabase(driver string, dataSource string) (*sql.DB, error) {
+	switch driver {
+	case SQLite:
+		return Open(dataSource)
+	case Postgres:
+		return sql.Open("pgx", dataSource)
+	}
+	return nil, fmt.Errorf("unknown database driver %q", driver)
+}
+
+// Initialize opens the SQLite database at dbPath and applies the pending migrations.
 func Initialize(dbPath string) (*Queries, error) {
-	database, err := sql.Open("sqlite3", dbPath)
+	return InitializeDatabase(SQLite, dbPath)
+}
+
+// InitializeDatabase opens a database and applies the pending migrations.
+// The SQLite search index isn't versioned since it depends on the build: it is created or caught up when search is enabled.
+// Rows referencing missing rows, left by versions of LesVieux that didn't enforce foreign keys, are logged.
+func InitializeDatabase(driver string, dataSource string) (*Queries, error) {
+	database, err := OpenDatabase(driver, dataSource)
 	if err != nil {
 		return nil, err
 	}
-	if _, err := database.ExecContext(context.Background(), adminAccountsTableDdl); err != nil {
+	if _, err := MigrateUp(context.Background(), database, 0); err != nil {
 		return nil, err
 	}
-	if _, err := database.ExecContext(context.Background(), employersTableDdl); err != nil {
-		return nil, err
+	if driver == Postgres {
+		return newPostgresQueries(database)
 	}
-	if _, err := database.ExecContext(context.Background(), employerAccountsTableDdl); err != nil {
-		return nil, err
+	if FullTextSearch {
+		if _, err := database.ExecContext(context.Background(), jobPostsFtsTableDdl); err != nil {
+			return nil, err
+		}
 	}
-	if _, err := database.ExecContext(context.Background(), jobPostsTableDdl); err != nil {
+	violations, err := CheckForeignKeys(context.Background(), database)
+	if err != nil {
 		return nil, err
 	}
+	for _, count := range countForeignKeyViolations(violations) {
+		slog.Warn("integrity check: rows reference missing rows", "table", count.table, "parent", count.parent, "rows", count.rows)
+	}
 	queries := New(database)
 	return queries, nil
 }
+
+// Driver returns the driver of the database the queries run on.
+func (q *Queries) Driver() string {
+	if _, ok := q.db.(postgresDB); ok {
+		return Postgres
+	}
+	return SQLite
+}
+
+// DB returns the database the queries run on, or nil for queries bound to a transaction.
+func (q *Queries) DB() *sql.DB {
+	switch handle := q.db.(type) {
+	case *sql.DB:
+		return handle
+	case postgresDB:
+		database, _ := handle.db.(*sql.DB)
+		return database
+	}
+	return nil
+}
+
+// FullTextSearch reports whether job posts can be searched: always on PostgreSQL,
+// and when built with FTS5 on SQLite.
+func (q *Queries) FullTextSearch() bool {
+	return q.Driver() == Postgres || FullTextSearch
+}
+
+// CheckForeignKeys returns the rows of the database that reference a row that doesn't exist.
+// PostgreSQL always enforces foreign keys, so only SQLite databases can have any.
+func CheckForeignKeys(ctx context.Context, database *sql.DB) ([]ForeignKeyViolation, error) {
+	if driverOf(database) == Postgres {
+		return nil, nil
+	}
+	rows, err := database.QueryContext(ctx, "PRAGMA foreign_key_check")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var violations []ForeignKeyViolation
+	for rows.Next() {
+		var violation ForeignKeyViolation
+		var rowID sql.NullInt64
+		var foreignKeyID int64
+		if err := rows.Scan(&violation.Table, &rowID, &violation.Parent, &foreignKeyID); err != nil {
+			return nil, err
+		}
+		violation.RowID = rowID.Int64
+		violations = append(violations, violation)
+	}
+	return violations, rows.Err()
+}
+
+type foreignKeyViolationCount struct {
+	table  string
+	parent string
+	rows   int
+}
+
+// countForeignKeyViolations groups violations by table and parent table, in the order they were found.
+func countForeignKeyViolations(violations []ForeignKeyViolation) []foreignKeyViolationCount {
+	var counts []foreignKeyViolationCount
+	for _, violation := range violations {
+		if n := len(counts); n > 0 && counts[n-1].table == violation.Table && counts[n-1].parent == violation.Parent {
+			counts[n-1].rows++
+			continue
+		}
+		counts = append(counts, foreignKeyViolationCount{table: violation.Table, parent: violation.Parent, rows: 1})
+	}
+	return counts
+}
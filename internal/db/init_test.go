@@ -0,0 +1,160 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gruyaume/lesvieux/internal/db"
+)
+
+func TestForeignKeysEnforced(t *testing.T) {
+	queries, err := db.Initialize(openTestDatabase(t))
+	if err != nil {
+		t.Fatalf("couldn't initialize database: %s", err)
+	}
+	ctx := context.Background()
+	_, err = queries.CreateJobPost(ctx, db.CreateJobPostParams{
+		Title:      "Gardener",
+		Content:    "Tend the garden",
+		CreatedAt:  "2024-01-01T00:00:00Z",
+		Status:     "draft",
+		EmployerID: 1,
+	})
+	if err == nil {
+		t.Fatalf("expected job post of a missing employer to be refused")
+	}
+
+	employer, err := queries.CreateEmployer(ctx, "Les Vieux")
+	if err != nil {
+		t.Fatalf("couldn't create employer: %s", err)
+	}
+	jobPost, err := queries.CreateJobPost(ctx, db.CreateJobPostParams{
+		Title:      "Gardener",
+		Content:    "Tend the garden",
+		CreatedAt:  "2024-01-01T00:00:00Z",
+		Status:     "draft",
+		EmployerID: employer.ID,
+	})
+	if err != nil {
+		t.Fatalf("couldn't create job post: %s", err)
+	}
+	if err := queries.DeleteEmployer(ctx, employer.ID); err != nil {
+		t.Fatalf("couldn't delete employer: %s", err)
+	}
+	if _, err := queries.GetJobPost(ctx, jobPost.ID); err == nil {
+		t.Fatalf("expected job post to be deleted along with its employer")
+	}
+}
+
+func TestCheckForeignKeysReportsOrphans(t *testing.T) {
+	path := openTestDatabase(t)
+	database, err := db.Open(path)
+	if err != nil {
+		t.Fatalf("couldn't open database: %s", err)
+	}
+	defer database.Close()
+	ctx := context.Background()
+	// A database written before foreign keys were enforced, where an employer was deleted but not its job posts and accounts
+	_, err = database.ExecContext(ctx, `
+		CREATE TABLE employers (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT NOT NULL);
+		CREATE TABLE employer_accounts (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			email TEXT NOT NULL UNIQUE,
+			password_hash TEXT NOT NULL,
+			employer_id INTEGER NOT NULL,
+			FOREIGN KEY (employer_id) REFERENCES employers(id) ON DELETE CASCADE
+		);
+		CREATE TABLE job_posts (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			title TEXT NOT NULL,
+			content TEXT NOT NULL,
+			created_at TEXT NOT NULL,
+			status TEXT NOT NULL DEFAULT 'draft',
+			employer_id INTEGER NOT NULL,
+			FOREIGN KEY(employer_id) REFERENCES employers(employer_id)
+		);
+		PRAGMA foreign_keys = OFF;
+		INSERT INTO employer_accounts (email, password_hash, employer_id) VALUES ('gardener@lesvieux.com', 'hash', 2);
+		INSERT INTO job_posts (title, content, created_at, employer_id) VALUES ('Gardener', 'Tend the garden', '2024-01-01T00:00:00Z', 2);
+		INSERT INTO job_posts (title, content, created_at, employer_id) VALUES ('Cook', 'Cook lunch', '2024-01-01T00:00:00Z', 2);
+		PRAGMA foreign_keys = ON;
+	`)
+	if err != nil {
+		t.Fatalf("couldn't create legacy schema: %s", err)
+	}
+
+	queries, err := db.Initialize(path)
+	if err != nil {
+		t.Fatalf("couldn't initialize database: %s", err)
+	}
+	if _, err := queries.GetJobPost(ctx, 2); err != nil {
+		t.Fatalf("expected orphan job post to be kept, got %s", err)
+	}
+	migrated, err := db.Open(path)
+	if err != nil {
+		t.Fatalf("couldn't open database: %s", err)
+	}
+	defer migrated.Close()
+	violations, err := db.CheckForeignKeys(ctx, migrated)
+	if err != nil {
+		t.Fatalf("couldn't check foreign keys: %s", err)
+	}
+	expected := map[string]int{"employer_accounts": 1, "job_posts": 2}
+	found := map[string]int{}
+	for _, violation := range violations {
+		if violation.Parent != "employers" {
+			t.Fatalf("expected violations of employers, got %v", violation)
+		}
+		found[violation.Table]++
+	}
+	if len(found) != len(expected) || found["employer_accounts"] != 1 || found["job_posts"] != 2 {
+		t.Fatalf("expected violations %v, got %v", expected, found)
+	}
+}
+
+func TestAuditEventsAreAppendOnly(t *testing.T) {
+	queries, err := db.Initialize(openTestDatabase(t))
+	if err != nil {
+		t.Fatalf("couldn't initialize database: %s", err)
+	}
+	ctx := context.Background()
+	event, err := queries.CreateAuditEvent(ctx, db.CreateAuditEventParams{
+		OccurredAt: "2024-01-01T00:00:00Z",
+		Action:     "employer.create",
+		TargetType: "employer",
+	})
+	if err != nil {
+		t.Fatalf("couldn't create audit event: %s", err)
+	}
+	if _, err := queries.DB().ExecContext(ctx, "UPDATE audit_events SET action = 'employer.delete' WHERE id = ?", event.ID); err == nil {
+		t.Fatalf("expected audit events to be immutable")
+	}
+	if _, err := queries.DB().ExecContext(ctx, "DELETE FROM audit_events WHERE id = ?", event.ID); err == nil {
+		t.Fatalf("expected audit events not to be deletable")
+	}
+}
+
+func TestIsUniqueViolation(t *testing.T) {
+	queries, err := db.Initialize(openTestDatabase(t))
+	if err != nil {
+		t.Fatalf("couldn't initialize database: %s", err)
+	}
+	ctx := context.Background()
+	params := db.CreateApplicantAccountParams{
+		Email:        "jeanne@example.com",
+		PasswordHash: "hash",
+		Name:         "Jeanne",
+		CreatedAt:    "2024-01-01T00:00:00Z",
+	}
+	if _, err := queries.CreateApplicantAccount(ctx, params); err != nil {
+		t.Fatalf("couldn't create applicant account: %s", err)
+	}
+	_, err = queries.CreateApplicantAccount(ctx, params)
+	if !db.IsUniqueViolation(err) {
+		t.Fatalf("expected a unique violation, got %v", err)
+	}
+	_, err = queries.GetApplicantAccount(ctx, 42)
+	if db.IsUniqueViolation(err) {
+		t.Fatalf("expected %v not to be a unique violation", err)
+	}
+}
@@ -0,0 +1,114 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: job_post_status_changes.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+const createJobPostStatusChange = `-- name: CreateJobPostStatusChange :one
+INSERT INTO job_post_status_changes (
+  job_post_id, from_status, to_status, changed_at, actor_id, actor_role, reason
+) VALUES (
+  ?, ?, ?, ?, ?, ?, ?
+)
+RETURNING id, job_post_id, from_status, to_status, changed_at, actor_id, actor_role, reason
+`
+
+type CreateJobPostStatusChangeParams struct {
+	JobPostID  int64
+	FromStatus string
+	ToStatus   string
+	ChangedAt  string
+	ActorID    sql.NullInt64
+	ActorRole  sql.NullInt64
+	Reason     sql.NullString
+}
+
+func (q *Queries) CreateJobPostStatusChange(ctx context.Context, arg CreateJobPostStatusChangeParams) (JobPostStatusChange, error) {
+	row := q.db.QueryRowContext(ctx, createJobPostStatusChange,
+		arg.JobPostID,
+		arg.FromStatus,
+		arg.ToStatus,
+		arg.ChangedAt,
+		arg.ActorID,
+		arg.ActorRole,
+		arg.Reason,
+	)
+	var i JobPostStatusChange
+	err := row.Scan(
+		&i.ID,
+		&i.JobPostID,
+		&i.FromStatus,
+		&i.ToStatus,
+		&i.ChangedAt,
+		&i.ActorID,
+		&i.ActorRole,
+		&i.Reason,
+	)
+	return i, err
+}
+
+const getLatestJobPostStatusChange = `-- name: GetLatestJobPostStatusChange :one
+SELECT id, job_post_id, from_status, to_status, changed_at, actor_id, actor_role, reason FROM job_post_status_changes
+WHERE job_post_id = ?
+ORDER BY id DESC LIMIT 1
+`
+
+func (q *Queries) GetLatestJobPostStatusChange(ctx context.Context, jobPostID int64) (JobPostStatusChange, error) {
+	row := q.db.QueryRowContext(ctx, getLatestJobPostStatusChange, jobPostID)
+	var i JobPostStatusChange
+	err := row.Scan(
+		&i.ID,
+		&i.JobPostID,
+		&i.FromStatus,
+		&i.ToStatus,
+		&i.ChangedAt,
+		&i.ActorID,
+		&i.ActorRole,
+		&i.Reason,
+	)
+	return i, err
+}
+
+const listJobPostStatusChanges = `-- name: ListJobPostStatusChanges :many
+SELECT id, job_post_id, from_status, to_status, changed_at, actor_id, actor_role, reason FROM job_post_status_changes
+WHERE job_post_id = ?
+ORDER BY id
+`
+
+func (q *Queries) ListJobPostStatusChanges(ctx context.Context, jobPostID int64) ([]JobPostStatusChange, error) {
+	rows, err := q.db.QueryContext(ctx, listJobPostStatusChanges, jobPostID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []JobPostStatusChange
+	for rows.Next() {
+		var i JobPostStatusChange
+		if err := rows.Scan(
+			&i.ID,
+			&i.JobPostID,
+			&i.FromStatus,
+			&i.ToStatus,
+			&i.ChangedAt,
+			&i.ActorID,
+			&i.ActorRole,
+			&i.Reason,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -7,23 +7,42 @@ package db
 
 import (
 	"context"
+	"database/sql"
 )
 
 const createJobPost = `-- name: CreateJobPost :one
 INSERT INTO job_posts (
-  title, content, created_at, status, employer_id
+  title, content, created_at, status, employer_id,
+  employment_type, weekly_hours, work_mode, city, postal_code,
+  salary_min, salary_max, salary_currency, salary_period,
+  physical_demands, flexible_schedule, accessible_workplace
 ) VALUES (
-  ?, ?, ?, ?, ?
+  ?, ?, ?, ?, ?,
+  ?, ?, ?, ?, ?,
+  ?, ?, ?, ?,
+  ?, ?, ?
 )
-RETURNING id, title, content, created_at, status, employer_id
+RETURNING id, title, content, created_at, status, employer_id, published_at, expires_at, employment_type, weekly_hours, work_mode, city, postal_code, salary_min, salary_max, salary_currency, salary_period, physical_demands, flexible_schedule, accessible_workplace
 `
 
 type CreateJobPostParams struct {
-	Title      string
-	Content    string
-	CreatedAt  string
-	Status     string
-	EmployerID int64
+	Title               string
+	Content             string
+	CreatedAt           string
+	Status              string
+	EmployerID          int64
+	EmploymentType      sql.NullString
+	WeeklyHours         sql.NullInt64
+	WorkMode            sql.NullString
+	City                sql.NullString
+	PostalCode          sql.NullString
+	SalaryMin           sql.NullInt64
+	SalaryMax           sql.NullInt64
+	SalaryCurrency      sql.NullString
+	SalaryPeriod        sql.NullString
+	PhysicalDemands     sql.NullString
+	FlexibleSchedule    bool
+	AccessibleWorkplace bool
 }
 
 func (q *Queries) CreateJobPost(ctx context.Context, arg CreateJobPostParams) (JobPost, error) {
@@ -33,6 +52,18 @@ func (q *Queries) CreateJobPost(ctx context.Context, arg CreateJobPostParams) (J
 		arg.CreatedAt,
 		arg.Status,
 		arg.EmployerID,
+		arg.EmploymentType,
+		arg.WeeklyHours,
+		arg.WorkMode,
+		arg.City,
+		arg.PostalCode,
+		arg.SalaryMin,
+		arg.SalaryMax,
+		arg.SalaryCurrency,
+		arg.SalaryPeriod,
+		arg.PhysicalDemands,
+		arg.FlexibleSchedule,
+		arg.AccessibleWorkplace,
 	)
 	var i JobPost
 	err := row.Scan(
@@ -42,6 +73,20 @@ func (q *Queries) CreateJobPost(ctx context.Context, arg CreateJobPostParams) (J
 		&i.CreatedAt,
 		&i.Status,
 		&i.EmployerID,
+		&i.PublishedAt,
+		&i.ExpiresAt,
+		&i.EmploymentType,
+		&i.WeeklyHours,
+		&i.WorkMode,
+		&i.City,
+		&i.PostalCode,
+		&i.SalaryMin,
+		&i.SalaryMax,
+		&i.SalaryCurrency,
+		&i.SalaryPeriod,
+		&i.PhysicalDemands,
+		&i.FlexibleSchedule,
+		&i.AccessibleWorkplace,
 	)
 	return i, err
 }
@@ -57,7 +102,7 @@ func (q *Queries) DeleteJobPost(ctx context.Context, id int64) error {
 }
 
 const getJobPost = `-- name: GetJobPost :one
-SELECT id, title, content, created_at, status, employer_id FROM job_posts
+SELECT id, title, content, created_at, status, employer_id, published_at, expires_at, employment_type, weekly_hours, work_mode, city, postal_code, salary_min, salary_max, salary_currency, salary_period, physical_demands, flexible_schedule, accessible_workplace FROM job_posts
 WHERE id = ? LIMIT 1
 `
 
@@ -71,12 +116,118 @@ func (q *Queries) GetJobPost(ctx context.Context, id int64) (JobPost, error) {
 		&i.CreatedAt,
 		&i.Status,
 		&i.EmployerID,
+		&i.PublishedAt,
+		&i.ExpiresAt,
+		&i.EmploymentType,
+		&i.WeeklyHours,
+		&i.WorkMode,
+		&i.City,
+		&i.PostalCode,
+		&i.SalaryMin,
+		&i.SalaryMax,
+		&i.SalaryCurrency,
+		&i.SalaryPeriod,
+		&i.PhysicalDemands,
+		&i.FlexibleSchedule,
+		&i.AccessibleWorkplace,
 	)
 	return i, err
 }
 
+const getPublishedJobPost = `-- name: GetPublishedJobPost :one
+SELECT job_posts.id, job_posts.title, job_posts.content, job_posts.created_at, job_posts.status, job_posts.employer_id, job_posts.published_at, job_posts.expires_at, job_posts.employment_type, job_posts.weekly_hours, job_posts.work_mode, job_posts.city, job_posts.postal_code, job_posts.salary_min, job_posts.salary_max, job_posts.salary_currency, job_posts.salary_period, job_posts.physical_demands, job_posts.flexible_schedule, job_posts.accessible_workplace, employers.name AS employer_name
+FROM job_posts
+JOIN employers ON employers.id = job_posts.employer_id
+WHERE job_posts.id = ? AND job_posts.status = 'published'
+LIMIT 1
+`
+
+type GetPublishedJobPostRow struct {
+	JobPost      JobPost
+	EmployerName string
+}
+
+func (q *Queries) GetPublishedJobPost(ctx context.Context, id int64) (GetPublishedJobPostRow, error) {
+	row := q.db.QueryRowContext(ctx, getPublishedJobPost, id)
+	var i GetPublishedJobPostRow
+	err := row.Scan(
+		&i.JobPost.ID,
+		&i.JobPost.Title,
+		&i.JobPost.Content,
+		&i.JobPost.CreatedAt,
+		&i.JobPost.Status,
+		&i.JobPost.EmployerID,
+		&i.JobPost.PublishedAt,
+		&i.JobPost.ExpiresAt,
+		&i.JobPost.EmploymentType,
+		&i.JobPost.WeeklyHours,
+		&i.JobPost.WorkMode,
+		&i.JobPost.City,
+		&i.JobPost.PostalCode,
+		&i.JobPost.SalaryMin,
+		&i.JobPost.SalaryMax,
+		&i.JobPost.SalaryCurrency,
+		&i.JobPost.SalaryPeriod,
+		&i.JobPost.PhysicalDemands,
+		&i.JobPost.FlexibleSchedule,
+		&i.JobPost.AccessibleWorkplace,
+		&i.EmployerName,
+	)
+	return i, err
+}
+
+const listExpiredJobPosts = `-- name: ListExpiredJobPosts :many
+SELECT id, title, content, created_at, status, employer_id, published_at, expires_at, employment_type, weekly_hours, work_mode, city, postal_code, salary_min, salary_max, salary_currency, salary_period, physical_demands, flexible_schedule, accessible_workplace FROM job_posts
+WHERE status = 'published' AND expires_at IS NOT NULL AND expires_at <= ?
+ORDER BY expires_at
+`
+
+func (q *Queries) ListExpiredJobPosts(ctx context.Context, expiresAt sql.NullString) ([]JobPost, error) {
+	rows, err := q.db.QueryContext(ctx, listExpiredJobPosts, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []JobPost
+	for rows.Next() {
+		var i JobPost
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Content,
+			&i.CreatedAt,
+			&i.Status,
+			&i.EmployerID,
+			&i.PublishedAt,
+			&i.ExpiresAt,
+			&i.EmploymentType,
+			&i.WeeklyHours,
+			&i.WorkMode,
+			&i.City,
+			&i.PostalCode,
+			&i.SalaryMin,
+			&i.SalaryMax,
+			&i.SalaryCurrency,
+			&i.SalaryPeriod,
+			&i.PhysicalDemands,
+			&i.FlexibleSchedule,
+			&i.AccessibleWorkplace,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listJobPosts = `-- name: ListJobPosts :many
-SELECT id, title, content, created_at, status, employer_id FROM job_posts
+SELECT id, title, content, created_at, status, employer_id, published_at, expires_at, employment_type, weekly_hours, work_mode, city, postal_code, salary_min, salary_max, salary_currency, salary_period, physical_demands, flexible_schedule, accessible_workplace FROM job_posts
 ORDER BY created_at DESC
 `
 
@@ -96,6 +247,20 @@ func (q *Queries) ListJobPosts(ctx context.Context) ([]JobPost, error) {
 			&i.CreatedAt,
 			&i.Status,
 			&i.EmployerID,
+			&i.PublishedAt,
+			&i.ExpiresAt,
+			&i.EmploymentType,
+			&i.WeeklyHours,
+			&i.WorkMode,
+			&i.City,
+			&i.PostalCode,
+			&i.SalaryMin,
+			&i.SalaryMax,
+			&i.SalaryCurrency,
+			&i.SalaryPeriod,
+			&i.PhysicalDemands,
+			&i.FlexibleSchedule,
+			&i.AccessibleWorkplace,
 		); err != nil {
 			return nil, err
 		}
@@ -110,14 +275,110 @@ func (q *Queries) ListJobPosts(ctx context.Context) ([]JobPost, error) {
 	return items, nil
 }
 
-const listJobPostsByAccount = `-- name: ListJobPostsByAccount :many
-SELECT id, title, content, created_at, status, employer_id FROM job_posts
-WHERE employer_id = ?
-ORDER BY created_at DESC
+const listJobPostsNewestFirst = `-- name: ListJobPostsNewestFirst :many
+SELECT id, title, content, created_at, status, employer_id, published_at, expires_at, employment_type, weekly_hours, work_mode, city, postal_code, salary_min, salary_max, salary_currency, salary_period, physical_demands, flexible_schedule, accessible_workplace FROM job_posts
+WHERE status = COALESCE(?, status)
+  AND employer_id = COALESCE(?, employer_id)
+  AND created_at >= COALESCE(?, created_at)
+  AND created_at <= COALESCE(?, created_at)
+  AND (created_at, id) < (?, ?)
+ORDER BY created_at DESC, id DESC
+LIMIT ?
 `
 
-func (q *Queries) ListJobPostsByAccount(ctx context.Context, employerID int64) ([]JobPost, error) {
-	rows, err := q.db.QueryContext(ctx, listJobPostsByAccount, employerID)
+type ListJobPostsNewestFirstParams struct {
+	Status        sql.NullString
+	EmployerID    sql.NullInt64
+	CreatedAfter  sql.NullString
+	CreatedBefore sql.NullString
+	CursorKey     string
+	CursorID      int64
+	Limit         int64
+}
+
+func (q *Queries) ListJobPostsNewestFirst(ctx context.Context, arg ListJobPostsNewestFirstParams) ([]JobPost, error) {
+	rows, err := q.db.QueryContext(ctx, listJobPostsNewestFirst,
+		arg.Status,
+		arg.EmployerID,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.CursorKey,
+		arg.CursorID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []JobPost
+	for rows.Next() {
+		var i JobPost
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Content,
+			&i.CreatedAt,
+			&i.Status,
+			&i.EmployerID,
+			&i.PublishedAt,
+			&i.ExpiresAt,
+			&i.EmploymentType,
+			&i.WeeklyHours,
+			&i.WorkMode,
+			&i.City,
+			&i.PostalCode,
+			&i.SalaryMin,
+			&i.SalaryMax,
+			&i.SalaryCurrency,
+			&i.SalaryPeriod,
+			&i.PhysicalDemands,
+			&i.FlexibleSchedule,
+			&i.AccessibleWorkplace,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listJobPostsOldestFirst = `-- name: ListJobPostsOldestFirst :many
+SELECT id, title, content, created_at, status, employer_id, published_at, expires_at, employment_type, weekly_hours, work_mode, city, postal_code, salary_min, salary_max, salary_currency, salary_period, physical_demands, flexible_schedule, accessible_workplace FROM job_posts
+WHERE status = COALESCE(?, status)
+  AND employer_id = COALESCE(?, employer_id)
+  AND created_at >= COALESCE(?, created_at)
+  AND created_at <= COALESCE(?, created_at)
+  AND (created_at, id) > (?, ?)
+ORDER BY created_at, id
+LIMIT ?
+`
+
+type ListJobPostsOldestFirstParams struct {
+	Status        sql.NullString
+	EmployerID    sql.NullInt64
+	CreatedAfter  sql.NullString
+	CreatedBefore sql.NullString
+	CursorKey     string
+	CursorID      int64
+	Limit         int64
+}
+
+func (q *Queries) ListJobPostsOldestFirst(ctx context.Context, arg ListJobPostsOldestFirstParams) ([]JobPost, error) {
+	rows, err := q.db.QueryContext(ctx, listJobPostsOldestFirst,
+		arg.Status,
+		arg.EmployerID,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.CursorKey,
+		arg.CursorID,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -132,6 +393,20 @@ func (q *Queries) ListJobPostsByAccount(ctx context.Context, employerID int64) (
 			&i.CreatedAt,
 			&i.Status,
 			&i.EmployerID,
+			&i.PublishedAt,
+			&i.ExpiresAt,
+			&i.EmploymentType,
+			&i.WeeklyHours,
+			&i.WorkMode,
+			&i.City,
+			&i.PostalCode,
+			&i.SalaryMin,
+			&i.SalaryMax,
+			&i.SalaryCurrency,
+			&i.SalaryPeriod,
+			&i.PhysicalDemands,
+			&i.FlexibleSchedule,
+			&i.AccessibleWorkplace,
 		); err != nil {
 			return nil, err
 		}
@@ -146,25 +421,361 @@ func (q *Queries) ListJobPostsByAccount(ctx context.Context, employerID int64) (
 	return items, nil
 }
 
+const listPublishedJobPostsNewestFirst = `-- name: ListPublishedJobPostsNewestFirst :many
+SELECT job_posts.id, job_posts.title, job_posts.content, job_posts.created_at, job_posts.status, job_posts.employer_id, job_posts.published_at, job_posts.expires_at, job_posts.employment_type, job_posts.weekly_hours, job_posts.work_mode, job_posts.city, job_posts.postal_code, job_posts.salary_min, job_posts.salary_max, job_posts.salary_currency, job_posts.salary_period, job_posts.physical_demands, job_posts.flexible_schedule, job_posts.accessible_workplace, employers.name AS employer_name
+FROM job_posts
+JOIN employers ON employers.id = job_posts.employer_id
+WHERE job_posts.status = 'published'
+  AND job_posts.employer_id = COALESCE(?, job_posts.employer_id)
+  AND job_posts.published_at >= COALESCE(?, job_posts.published_at)
+  AND job_posts.published_at <= COALESCE(?, job_posts.published_at)
+  AND (? IS NULL OR job_posts.employment_type = ?)
+  AND (? IS NULL OR job_posts.work_mode = ?)
+  AND (? IS NULL OR job_posts.physical_demands = ?)
+  AND (? IS NULL OR job_posts.city = ? COLLATE NOCASE)
+  AND (? IS NULL OR job_posts.postal_code = ?)
+  AND (? IS NULL OR job_posts.weekly_hours <= ?)
+  AND (? IS NULL OR job_posts.salary_currency = ?)
+  AND (? IS NULL OR IFNULL(job_posts.salary_max, job_posts.salary_min) >= ?)
+  AND (? IS NULL OR job_posts.flexible_schedule = ?)
+  AND (? IS NULL OR job_posts.accessible_workplace = ?)
+  AND (job_posts.published_at, job_posts.id) < (?, ?)
+ORDER BY job_posts.published_at DESC, job_posts.id DESC
+LIMIT ?
+`
+
+type ListPublishedJobPostsNewestFirstParams struct {
+	EmployerID          sql.NullInt64
+	PublishedAfter      sql.NullString
+	PublishedBefore     sql.NullString
+	EmploymentType      sql.NullString
+	WorkMode            sql.NullString
+	PhysicalDemands     sql.NullString
+	City                sql.NullString
+	PostalCode          sql.NullString
+	MaxWeeklyHours      sql.NullInt64
+	SalaryCurrency      sql.NullString
+	MinSalary           sql.NullInt64
+	FlexibleSchedule    sql.NullBool
+	AccessibleWorkplace sql.NullBool
+	CursorKey           sql.NullString
+	CursorID            int64
+	Limit               int64
+}
+
+type ListPublishedJobPostsNewestFirstRow struct {
+	JobPost      JobPost
+	EmployerName string
+}
+
+func (q *Queries) ListPublishedJobPostsNewestFirst(ctx context.Context, arg ListPublishedJobPostsNewestFirstParams) ([]ListPublishedJobPostsNewestFirstRow, error) {
+	rows, err := q.db.QueryContext(ctx, listPublishedJobPostsNewestFirst,
+		arg.EmployerID,
+		arg.PublishedAfter,
+		arg.PublishedBefore,
+		arg.EmploymentType,
+		arg.EmploymentType,
+		arg.WorkMode,
+		arg.WorkMode,
+		arg.PhysicalDemands,
+		arg.PhysicalDemands,
+		arg.City,
+		arg.City,
+		arg.PostalCode,
+		arg.PostalCode,
+		arg.MaxWeeklyHours,
+		arg.MaxWeeklyHours,
+		arg.SalaryCurrency,
+		arg.SalaryCurrency,
+		arg.MinSalary,
+		arg.MinSalary,
+		arg.FlexibleSchedule,
+		arg.FlexibleSchedule,
+		arg.AccessibleWorkplace,
+		arg.AccessibleWorkplace,
+		arg.CursorKey,
+		arg.CursorID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListPublishedJobPostsNewestFirstRow
+	for rows.Next() {
+		var i ListPublishedJobPostsNewestFirstRow
+		if err := rows.Scan(
+			&i.JobPost.ID,
+			&i.JobPost.Title,
+			&i.JobPost.Content,
+			&i.JobPost.CreatedAt,
+			&i.JobPost.Status,
+			&i.JobPost.EmployerID,
+			&i.JobPost.PublishedAt,
+			&i.JobPost.ExpiresAt,
+			&i.JobPost.EmploymentType,
+			&i.JobPost.WeeklyHours,
+			&i.JobPost.WorkMode,
+			&i.JobPost.City,
+			&i.JobPost.PostalCode,
+			&i.JobPost.SalaryMin,
+			&i.JobPost.SalaryMax,
+			&i.JobPost.SalaryCurrency,
+			&i.JobPost.SalaryPeriod,
+			&i.JobPost.PhysicalDemands,
+			&i.JobPost.FlexibleSchedule,
+			&i.JobPost.AccessibleWorkplace,
+			&i.EmployerName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPublishedJobPostsOldestFirst = `-- name: ListPublishedJobPostsOldestFirst :many
+SELECT job_posts.id, job_posts.title, job_posts.content, job_posts.created_at, job_posts.status, job_posts.employer_id, job_posts.published_at, job_posts.expires_at, job_posts.employment_type, job_posts.weekly_hours, job_posts.work_mode, job_posts.city, job_posts.postal_code, job_posts.salary_min, job_posts.salary_max, job_posts.salary_currency, job_posts.salary_period, job_posts.physical_demands, job_posts.flexible_schedule, job_posts.accessible_workplace, employers.name AS employer_name
+FROM job_posts
+JOIN employers ON employers.id = job_posts.employer_id
+WHERE job_posts.status = 'published'
+  AND job_posts.employer_id = COALESCE(?, job_posts.employer_id)
+  AND job_posts.published_at >= COALESCE(?, job_posts.published_at)
+  AND job_posts.published_at <= COALESCE(?, job_posts.published_at)
+  AND (? IS NULL OR job_posts.employment_type = ?)
+  AND (? IS NULL OR job_posts.work_mode = ?)
+  AND (? IS NULL OR job_posts.physical_demands = ?)
+  AND (? IS NULL OR job_posts.city = ? COLLATE NOCASE)
+  AND (? IS NULL OR job_posts.postal_code = ?)
+  AND (? IS NULL OR job_posts.weekly_hours <= ?)
+  AND (? IS NULL OR job_posts.salary_currency = ?)
+  AND (? IS NULL OR IFNULL(job_posts.salary_max, job_posts.salary_min) >= ?)
+  AND (? IS NULL OR job_posts.flexible_schedule = ?)
+  AND (? IS NULL OR job_posts.accessible_workplace = ?)
+  AND (job_posts.published_at, job_posts.id) > (?, ?)
+ORDER BY job_posts.published_at, job_posts.id
+LIMIT ?
+`
+
+type ListPublishedJobPostsOldestFirstParams struct {
+	EmployerID          sql.NullInt64
+	PublishedAfter      sql.NullString
+	PublishedBefore     sql.NullString
+	EmploymentType      sql.NullString
+	WorkMode            sql.NullString
+	PhysicalDemands     sql.NullString
+	City                sql.NullString
+	PostalCode          sql.NullString
+	MaxWeeklyHours      sql.NullInt64
+	SalaryCurrency      sql.NullString
+	MinSalary           sql.NullInt64
+	FlexibleSchedule    sql.NullBool
+	AccessibleWorkplace sql.NullBool
+	CursorKey           sql.NullString
+	CursorID            int64
+	Limit               int64
+}
+
+type ListPublishedJobPostsOldestFirstRow struct {
+	JobPost      JobPost
+	EmployerName string
+}
+
+func (q *Queries) ListPublishedJobPostsOldestFirst(ctx context.Context, arg ListPublishedJobPostsOldestFirstParams) ([]ListPublishedJobPostsOldestFirstRow, error) {
+	rows, err := q.db.QueryContext(ctx, listPublishedJobPostsOldestFirst,
+		arg.EmployerID,
+		arg.PublishedAfter,
+		arg.PublishedBefore,
+		arg.EmploymentType,
+		arg.EmploymentType,
+		arg.WorkMode,
+		arg.WorkMode,
+		arg.PhysicalDemands,
+		arg.PhysicalDemands,
+		arg.City,
+		arg.City,
+		arg.PostalCode,
+		arg.PostalCode,
+		arg.MaxWeeklyHours,
+		arg.MaxWeeklyHours,
+		arg.SalaryCurrency,
+		arg.SalaryCurrency,
+		arg.MinSalary,
+		arg.MinSalary,
+		arg.FlexibleSchedule,
+		arg.FlexibleSchedule,
+		arg.AccessibleWorkplace,
+		arg.AccessibleWorkplace,
+		arg.CursorKey,
+		arg.CursorID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListPublishedJobPostsOldestFirstRow
+	for rows.Next() {
+		var i ListPublishedJobPostsOldestFirstRow
+		if err := rows.Scan(
+			&i.JobPost.ID,
+			&i.JobPost.Title,
+			&i.JobPost.Content,
+			&i.JobPost.CreatedAt,
+			&i.JobPost.Status,
+			&i.JobPost.EmployerID,
+			&i.JobPost.PublishedAt,
+			&i.JobPost.ExpiresAt,
+			&i.JobPost.EmploymentType,
+			&i.JobPost.WeeklyHours,
+			&i.JobPost.WorkMode,
+			&i.JobPost.City,
+			&i.JobPost.PostalCode,
+			&i.JobPost.SalaryMin,
+			&i.JobPost.SalaryMax,
+			&i.JobPost.SalaryCurrency,
+			&i.JobPost.SalaryPeriod,
+			&i.JobPost.PhysicalDemands,
+			&i.JobPost.FlexibleSchedule,
+			&i.JobPost.AccessibleWorkplace,
+			&i.EmployerName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const numEmployerJobPosts = `-- name: NumEmployerJobPosts :one
+SELECT COUNT(*) FROM job_posts
+WHERE employer_id = ?
+`
+
+func (q *Queries) NumEmployerJobPosts(ctx context.Context, employerID int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, numEmployerJobPosts, employerID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const numJobPostsByStatus = `-- name: NumJobPostsByStatus :many
+SELECT status, COUNT(*) AS count FROM job_posts
+GROUP BY status
+`
+
+type NumJobPostsByStatusRow struct {
+	Status string
+	Count  int64
+}
+
+func (q *Queries) NumJobPostsByStatus(ctx context.Context) ([]NumJobPostsByStatusRow, error) {
+	rows, err := q.db.QueryContext(ctx, numJobPostsByStatus)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []NumJobPostsByStatusRow
+	for rows.Next() {
+		var i NumJobPostsByStatusRow
+		if err := rows.Scan(&i.Status, &i.Count); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updateJobPost = `-- name: UpdateJobPost :exec
 UPDATE job_posts
-set title = ?, content = ?, status = ?
+set title = ?, content = ?, expires_at = ?,
+  employment_type = ?, weekly_hours = ?, work_mode = ?, city = ?, postal_code = ?,
+  salary_min = ?, salary_max = ?, salary_currency = ?, salary_period = ?,
+  physical_demands = ?, flexible_schedule = ?, accessible_workplace = ?
 WHERE id = ?
 `
 
 type UpdateJobPostParams struct {
-	Title   string
-	Content string
-	Status  string
-	ID      int64
+	Title               string
+	Content             string
+	ExpiresAt           sql.NullString
+	EmploymentType      sql.NullString
+	WeeklyHours         sql.NullInt64
+	WorkMode            sql.NullString
+	City                sql.NullString
+	PostalCode          sql.NullString
+	SalaryMin           sql.NullInt64
+	SalaryMax           sql.NullInt64
+	SalaryCurrency      sql.NullString
+	SalaryPeriod        sql.NullString
+	PhysicalDemands     sql.NullString
+	FlexibleSchedule    bool
+	AccessibleWorkplace bool
+	ID                  int64
 }
 
 func (q *Queries) UpdateJobPost(ctx context.Context, arg UpdateJobPostParams) error {
 	_, err := q.db.ExecContext(ctx, updateJobPost,
 		arg.Title,
 		arg.Content,
-		arg.Status,
+		arg.ExpiresAt,
+		arg.EmploymentType,
+		arg.WeeklyHours,
+		arg.WorkMode,
+		arg.City,
+		arg.PostalCode,
+		arg.SalaryMin,
+		arg.SalaryMax,
+		arg.SalaryCurrency,
+		arg.SalaryPeriod,
+		arg.PhysicalDemands,
+		arg.FlexibleSchedule,
+		arg.AccessibleWorkplace,
 		arg.ID,
 	)
 	return err
 }
+
+const updateJobPostStatus = `-- name: UpdateJobPostStatus :execrows
+UPDATE job_posts
+set status = ?, published_at = ?
+WHERE id = ? AND status = ?
+`
+
+type UpdateJobPostStatusParams struct {
+	Status      string
+	PublishedAt sql.NullString
+	ID          int64
+	FromStatus  string
+}
+
+func (q *Queries) UpdateJobPostStatus(ctx context.Context, arg UpdateJobPostStatusParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, updateJobPostStatus,
+		arg.Status,
+		arg.PublishedAt,
+		arg.ID,
+		arg.FromStatus,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,125 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: job_posts_fts.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+const searchPublishedJobPosts = `-- name: SearchPublishedJobPosts :many
+SELECT job_posts.id, job_posts.title, job_posts.content, job_posts.created_at, job_posts.status, job_posts.employer_id, job_posts.published_at, job_posts.expires_at, job_posts.employment_type, job_posts.weekly_hours, job_posts.work_mode, job_posts.city, job_posts.postal_code, job_posts.salary_min, job_posts.salary_max, job_posts.salary_currency, job_posts.salary_period, job_posts.physical_demands, job_posts.flexible_schedule, job_posts.accessible_workplace, employers.name AS employer_name, highlight(job_posts_fts, 0, char(2), char(3)) AS title_highlight, snippet(job_posts_fts, 1, char(2), char(3), '…', 24) AS content_snippet
+FROM job_posts_fts
+JOIN job_posts ON job_posts.id = job_posts_fts.rowid
+JOIN employers ON employers.id = job_posts.employer_id
+WHERE job_posts_fts MATCH ? AND job_posts.status = 'published'
+  AND (? IS NULL OR job_posts.employment_type = ?)
+  AND (? IS NULL OR job_posts.work_mode = ?)
+  AND (? IS NULL OR job_posts.physical_demands = ?)
+  AND (? IS NULL OR job_posts.city = ? COLLATE NOCASE)
+  AND (? IS NULL OR job_posts.postal_code = ?)
+  AND (? IS NULL OR job_posts.weekly_hours <= ?)
+  AND (? IS NULL OR job_posts.salary_currency = ?)
+  AND (? IS NULL OR IFNULL(job_posts.salary_max, job_posts.salary_min) >= ?)
+  AND (? IS NULL OR job_posts.flexible_schedule = ?)
+  AND (? IS NULL OR job_posts.accessible_workplace = ?)
+ORDER BY bm25(job_posts_fts, 10.0, 1.0, 5.0), job_posts.id
+LIMIT ? OFFSET ?
+`
+
+type SearchPublishedJobPostsParams struct {
+	Query               string
+	EmploymentType      sql.NullString
+	WorkMode            sql.NullString
+	PhysicalDemands     sql.NullString
+	City                sql.NullString
+	PostalCode          sql.NullString
+	MaxWeeklyHours      sql.NullInt64
+	SalaryCurrency      sql.NullString
+	MinSalary           sql.NullInt64
+	FlexibleSchedule    sql.NullBool
+	AccessibleWorkplace sql.NullBool
+	Limit               int64
+	Offset              int64
+}
+
+type SearchPublishedJobPostsRow struct {
+	JobPost        JobPost
+	EmployerName   string
+	TitleHighlight string
+	ContentSnippet string
+}
+
+func (q *Queries) SearchPublishedJobPosts(ctx context.Context, arg SearchPublishedJobPostsParams) ([]SearchPublishedJobPostsRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchPublishedJobPosts,
+		arg.Query,
+		arg.EmploymentType,
+		arg.EmploymentType,
+		arg.WorkMode,
+		arg.WorkMode,
+		arg.PhysicalDemands,
+		arg.PhysicalDemands,
+		arg.City,
+		arg.City,
+		arg.PostalCode,
+		arg.PostalCode,
+		arg.MaxWeeklyHours,
+		arg.MaxWeeklyHours,
+		arg.SalaryCurrency,
+		arg.SalaryCurrency,
+		arg.MinSalary,
+		arg.MinSalary,
+		arg.FlexibleSchedule,
+		arg.FlexibleSchedule,
+		arg.AccessibleWorkplace,
+		arg.AccessibleWorkplace,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchPublishedJobPostsRow
+	for rows.Next() {
+		var i SearchPublishedJobPostsRow
+		if err := rows.Scan(
+			&i.JobPost.ID,
+			&i.JobPost.Title,
+			&i.JobPost.Content,
+			&i.JobPost.CreatedAt,
+			&i.JobPost.Status,
+			&i.JobPost.EmployerID,
+			&i.JobPost.PublishedAt,
+			&i.JobPost.ExpiresAt,
+			&i.JobPost.EmploymentType,
+			&i.JobPost.WeeklyHours,
+			&i.JobPost.WorkMode,
+			&i.JobPost.City,
+			&i.JobPost.PostalCode,
+			&i.JobPost.SalaryMin,
+			&i.JobPost.SalaryMax,
+			&i.JobPost.SalaryCurrency,
+			&i.JobPost.SalaryPeriod,
+			&i.JobPost.PhysicalDemands,
+			&i.JobPost.FlexibleSchedule,
+			&i.JobPost.AccessibleWorkplace,
+			&i.EmployerName,
+			&i.TitleHighlight,
+			&i.ContentSnippet,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
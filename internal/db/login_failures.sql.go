@@ -0,0 +1,153 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: login_failures.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+const deleteLoginFailure = `-- name: DeleteLoginFailure :execrows
+DELETE FROM login_failures
+WHERE role = ? AND email = ?
+`
+
+type DeleteLoginFailureParams struct {
+	Role  int64
+	Email string
+}
+
+func (q *Queries) DeleteLoginFailure(ctx context.Context, arg DeleteLoginFailureParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteLoginFailure, arg.Role, arg.Email)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteStaleLoginFailures = `-- name: DeleteStaleLoginFailures :execrows
+DELETE FROM login_failures
+WHERE last_failed_at <= ? AND (locked_until IS NULL OR locked_until <= ?)
+`
+
+type DeleteStaleLoginFailuresParams struct {
+	FailedBefore string
+	Now          sql.NullString
+}
+
+func (q *Queries) DeleteStaleLoginFailures(ctx context.Context, arg DeleteStaleLoginFailuresParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteStaleLoginFailures, arg.FailedBefore, arg.Now)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getLoginFailure = `-- name: GetLoginFailure :one
+SELECT role, email, failed_attempts, last_failed_at, locked_until FROM login_failures
+WHERE role = ? AND email = ? LIMIT 1
+`
+
+type GetLoginFailureParams struct {
+	Role  int64
+	Email string
+}
+
+func (q *Queries) GetLoginFailure(ctx context.Context, arg GetLoginFailureParams) (LoginFailure, error) {
+	row := q.db.QueryRowContext(ctx, getLoginFailure, arg.Role, arg.Email)
+	var i LoginFailure
+	err := row.Scan(
+		&i.Role,
+		&i.Email,
+		&i.FailedAttempts,
+		&i.LastFailedAt,
+		&i.LockedUntil,
+	)
+	return i, err
+}
+
+const listLockedLogins = `-- name: ListLockedLogins :many
+SELECT role, email, failed_attempts, last_failed_at, locked_until FROM login_failures
+WHERE locked_until > ?
+ORDER BY locked_until DESC, role, email
+`
+
+func (q *Queries) ListLockedLogins(ctx context.Context, lockedUntil sql.NullString) ([]LoginFailure, error) {
+	rows, err := q.db.QueryContext(ctx, listLockedLogins, lockedUntil)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []LoginFailure
+	for rows.Next() {
+		var i LoginFailure
+		if err := rows.Scan(
+			&i.Role,
+			&i.Email,
+			&i.FailedAttempts,
+			&i.LastFailedAt,
+			&i.LockedUntil,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockLogin = `-- name: LockLogin :exec
+UPDATE login_failures
+SET locked_until = ?
+WHERE role = ? AND email = ?
+`
+
+type LockLoginParams struct {
+	LockedUntil sql.NullString
+	Role        int64
+	Email       string
+}
+
+func (q *Queries) LockLogin(ctx context.Context, arg LockLoginParams) error {
+	_, err := q.db.ExecContext(ctx, lockLogin, arg.LockedUntil, arg.Role, arg.Email)
+	return err
+}
+
+const recordLoginFailure = `-- name: RecordLoginFailure :one
+INSERT INTO login_failures (
+  role, email, failed_attempts, last_failed_at
+) VALUES (
+  ?, ?, 1, ?
+)
+ON CONFLICT (role, email) DO UPDATE SET
+  failed_attempts = login_failures.failed_attempts + 1,
+  last_failed_at = excluded.last_failed_at
+RETURNING role, email, failed_attempts, last_failed_at, locked_until
+`
+
+type RecordLoginFailureParams struct {
+	Role         int64
+	Email        string
+	LastFailedAt string
+}
+
+func (q *Queries) RecordLoginFailure(ctx context.Context, arg RecordLoginFailureParams) (LoginFailure, error) {
+	row := q.db.QueryRowContext(ctx, recordLoginFailure, arg.Role, arg.Email, arg.LastFailedAt)
+	var i LoginFailure
+	err := row.Scan(
+		&i.Role,
+		&i.Email,
+		&i.FailedAttempts,
+		&i.LastFailedAt,
+		&i.LockedUntil,
+	)
+	return i, err
+}
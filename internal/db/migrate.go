@@ -0,0 +1,230 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+//go:embed migrations/*.sql postgres/migrations/*.sql
+var migrationFiles embed.FS
+
+// migrationDirs are the directories of the migrations of each driver.
+var migrationDirs = map[string]string{
+	SQLite:   "migrations",
+	Postgres: "postgres/migrations",
+}
+
+// migrationFileName matches the files of the migrations directory: <version>_<name>.<up|down>.sql
+var migrationFileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+const schemaMigrationsTableDdl = `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version INTEGER PRIMARY KEY,
+    name TEXT NOT NULL,
+    applied_at TEXT NOT NULL
+)`
+
+// Migration is a versioned change of the database schema, with the SQL to apply it and to revert it.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus is a migration along with when it was applied to the database. AppliedAt is empty for pending migrations.
+type MigrationStatus struct {
+	Migration
+	AppliedAt string
+}
+
+// Migrations returns the embedded migrations of a driver, ordered by version.
+func Migrations(driver string) ([]Migration, error) {
+	dir, ok := migrationDirs[driver]
+	if !ok {
+		return nil, fmt.Errorf("unknown database driver %q", driver)
+	}
+	entries, err := fs.ReadDir(migrationFiles, dir)
+	if err != nil {
+		return nil, err
+	}
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		match := migrationFileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("unexpected migration file name %q", entry.Name())
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %q: %w", entry.Name(), err)
+		}
+		content, err := migrationFiles.ReadFile(dir + "/" + entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has two names: %q and %q", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s must have both an up and a down file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// MigrationsStatus returns every known migration, and whether it was applied to the database.
+// It fails if the database was migrated by a more recent version of LesVieux.
+func MigrationsStatus(ctx context.Context, database *sql.DB) ([]MigrationStatus, error) {
+	migrations, err := Migrations(driverOf(database))
+	if err != nil {
+		return nil, err
+	}
+	applied, err := appliedMigrations(ctx, database)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, migration := range migrations {
+		statuses = append(statuses, MigrationStatus{Migration: migration, AppliedAt: applied[migration.Version]})
+		delete(applied, migration.Version)
+	}
+	for version := range applied {
+		return nil, fmt.Errorf("database has migration %d applied, which this version of LesVieux doesn't know about", version)
+	}
+	return statuses, nil
+}
+
+// MigrateUp applies up to steps pending migrations, in order, or all of them if steps is 0.
+// Each migration is applied in its own transaction. It returns the applied migrations.
+func MigrateUp(ctx context.Context, database *sql.DB, steps int) ([]Migration, error) {
+	if _, err := database.ExecContext(ctx, schemaMigrationsTableDdl); err != nil {
+		return nil, err
+	}
+	statuses, err := MigrationsStatus(ctx, database)
+	if err != nil {
+		return nil, err
+	}
+	var migrated []Migration
+	for _, status := range statuses {
+		if status.AppliedAt != "" {
+			continue
+		}
+		if steps > 0 && len(migrated) == steps {
+			break
+		}
+		err := runMigration(ctx, database, status.Migration, status.Up, func(tx *sql.Tx) error {
+			_, err := tx.ExecContext(ctx, rebind(driverOf(database), "INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)"),
+				status.Version, status.Name, time.Now().UTC().Format(time.RFC3339))
+			return err
+		})
+		if err != nil {
+			return migrated, err
+		}
+		migrated = append(migrated, status.Migration)
+	}
+	return migrated, nil
+}
+
+// MigrateDown reverts the last steps applied migrations, most recent first. It returns the reverted migrations.
+func MigrateDown(ctx context.Context, database *sql.DB, steps int) ([]Migration, error) {
+	statuses, err := MigrationsStatus(ctx, database)
+	if err != nil {
+		return nil, err
+	}
+	var reverted []Migration
+	for i := len(statuses) - 1; i >= 0 && len(reverted) < steps; i-- {
+		status := statuses[i]
+		if status.AppliedAt == "" {
+			continue
+		}
+		err := runMigration(ctx, database, status.Migration, status.Down, func(tx *sql.Tx) error {
+			_, err := tx.ExecContext(ctx, rebind(driverOf(database), "DELETE FROM schema_migrations WHERE version = ?"), status.Version)
+			return err
+		})
+		if err != nil {
+			return reverted, err
+		}
+		reverted = append(reverted, status.Migration)
+	}
+	return reverted, nil
+}
+
+// runMigration executes the SQL of a migration and records it in schema_migrations within one transaction.
+// On SQLite, foreign keys are off while it runs, as required to rebuild a table: dropping the old table would
+// otherwise cascade to the rows referencing it. Rows left without their parent are reported by CheckForeignKeys.
+func runMigration(ctx context.Context, database *sql.DB, migration Migration, query string, record func(*sql.Tx) error) error {
+	conn, err := database.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if driverOf(database) == SQLite {
+		if _, err := conn.ExecContext(ctx, "PRAGMA foreign_keys = OFF"); err != nil {
+			return err
+		}
+		defer conn.ExecContext(ctx, "PRAGMA foreign_keys = ON")
+	}
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, query); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("migration %d_%s failed: %w", migration.Version, migration.Name, err)
+	}
+	if err := record(tx); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("migration %d_%s failed: %w", migration.Version, migration.Name, err)
+	}
+	return tx.Commit()
+}
+
+// appliedMigrations returns when each applied migration was applied, by version.
+// Databases without a schema_migrations table have no migration applied.
+func appliedMigrations(ctx context.Context, database *sql.DB) (map[int64]string, error) {
+	applied := map[int64]string{}
+	query := "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations'"
+	if driverOf(database) == Postgres {
+		query = "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = 'schema_migrations'"
+	}
+	var tables int
+	err := database.QueryRowContext(ctx, query).Scan(&tables)
+	if err != nil || tables == 0 {
+		return applied, err
+	}
+	rows, err := database.QueryContext(ctx, "SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var version int64
+		var appliedAt string
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt
+	}
+	return applied, rows.Err()
+}
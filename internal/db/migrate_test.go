@@ -0,0 +1,170 @@
+package db_test
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gruyaume/lesvieux/internal/db"
+)
+
+func openTestDatabase(t *testing.T) string {
+	t.Helper()
+	return filepath.Join(t.TempDir(), "lesvieux.db")
+}
+
+func TestMigrateUpDown(t *testing.T) {
+	database, err := db.Open(openTestDatabase(t))
+	if err != nil {
+		t.Fatalf("couldn't open database: %s", err)
+	}
+	defer database.Close()
+	ctx := context.Background()
+	migrations, err := db.Migrations(db.SQLite)
+	if err != nil {
+		t.Fatalf("couldn't load migrations: %s", err)
+	}
+
+	applied, err := db.MigrateUp(ctx, database, 0)
+	if err != nil {
+		t.Fatalf("couldn't migrate up: %s", err)
+	}
+	if len(applied) != len(migrations) {
+		t.Fatalf("expected %d migrations applied, got %d", len(migrations), len(applied))
+	}
+	statuses, err := db.MigrationsStatus(ctx, database)
+	if err != nil {
+		t.Fatalf("couldn't get migrations status: %s", err)
+	}
+	for _, status := range statuses {
+		if status.AppliedAt == "" {
+			t.Fatalf("migration %d should be applied", status.Version)
+		}
+	}
+
+	applied, err = db.MigrateUp(ctx, database, 0)
+	if err != nil {
+		t.Fatalf("couldn't migrate up: %s", err)
+	}
+	if len(applied) != 0 {
+		t.Fatalf("expected no migrations to apply, got %d", len(applied))
+	}
+
+	reverted, err := db.MigrateDown(ctx, database, len(migrations))
+	if err != nil {
+		t.Fatalf("couldn't migrate down: %s", err)
+	}
+	if len(reverted) != len(migrations) || reverted[0].Version != migrations[len(migrations)-1].Version {
+		t.Fatalf("expected every migration reverted, most recent first, got %v", reverted)
+	}
+	var tables int
+	err = database.QueryRowContext(ctx, "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name NOT IN ('schema_migrations', 'sqlite_sequence')").Scan(&tables)
+	if err != nil {
+		t.Fatalf("couldn't count tables: %s", err)
+	}
+	if tables != 0 {
+		t.Fatalf("expected no tables left, got %d", tables)
+	}
+
+	applied, err = db.MigrateUp(ctx, database, 1)
+	if err != nil {
+		t.Fatalf("couldn't migrate up: %s", err)
+	}
+	if len(applied) != 1 || applied[0].Version != migrations[0].Version {
+		t.Fatalf("expected the first migration applied, got %v", applied)
+	}
+}
+
+// Foreign keys are enforced, so the job posts reverted to before 0008 must still reference an existing column.
+func TestMigrateDownKeepsJobPostsWritable(t *testing.T) {
+	database, err := db.Open(openTestDatabase(t))
+	if err != nil {
+		t.Fatalf("couldn't open database: %s", err)
+	}
+	defer database.Close()
+	ctx := context.Background()
+	applied, err := db.MigrateUp(ctx, database, 0)
+	if err != nil {
+		t.Fatalf("couldn't migrate up: %s", err)
+	}
+	steps := 0
+	for _, migration := range applied {
+		if migration.Version >= 8 {
+			steps++
+		}
+	}
+	if _, err := db.MigrateDown(ctx, database, steps); err != nil {
+		t.Fatalf("couldn't migrate down: %s", err)
+	}
+	if _, err := database.ExecContext(ctx, "INSERT INTO employers (id, name) VALUES (1, 'Les Jardins')"); err != nil {
+		t.Fatalf("couldn't create employer: %s", err)
+	}
+	_, err = database.ExecContext(ctx, "INSERT INTO job_posts (title, content, created_at, employer_id) VALUES ('Gardener', 'Tend the garden.', '2024-01-01T00:00:00Z', 1)")
+	if err != nil {
+		t.Fatalf("couldn't create job post after migrating down: %s", err)
+	}
+	_, err = database.ExecContext(ctx, "INSERT INTO job_posts (title, content, created_at, employer_id) VALUES ('Gardener', 'Tend the garden.', '2024-01-01T00:00:00Z', 2)")
+	if err == nil {
+		t.Fatal("expected a job post of a missing employer to be refused")
+	}
+}
+
+func TestInitializeAdoptsDatabaseWithoutMigrations(t *testing.T) {
+	path := openTestDatabase(t)
+	database, err := db.Open(path)
+	if err != nil {
+		t.Fatalf("couldn't open database: %s", err)
+	}
+	defer database.Close()
+	ctx := context.Background()
+	// The schema and data of a database created before versioned migrations
+	_, err = database.ExecContext(ctx, `
+		CREATE TABLE employers (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT NOT NULL);
+		CREATE TABLE job_posts (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			title TEXT NOT NULL,
+			content TEXT NOT NULL,
+			created_at TEXT NOT NULL,
+			status TEXT NOT NULL DEFAULT 'draft',
+			employer_id INTEGER NOT NULL
+		);
+		INSERT INTO employers (name) VALUES ('Les Vieux');
+		INSERT INTO job_posts (title, content, created_at, employer_id) VALUES ('Gardener', 'Tend the garden', '2024-01-01T00:00:00Z', 1);
+	`)
+	if err != nil {
+		t.Fatalf("couldn't create legacy schema: %s", err)
+	}
+
+	queries, err := db.Initialize(path)
+	if err != nil {
+		t.Fatalf("couldn't initialize database: %s", err)
+	}
+	jobPost, err := queries.GetJobPost(ctx, 1)
+	if err != nil {
+		t.Fatalf("couldn't get job post: %s", err)
+	}
+	if jobPost.Title != "Gardener" || jobPost.FlexibleSchedule {
+		t.Fatalf("expected existing job post with default details, got %v", jobPost)
+	}
+}
+
+func TestMigrationsStatusFailsOnUnknownMigration(t *testing.T) {
+	database, err := db.Open(openTestDatabase(t))
+	if err != nil {
+		t.Fatalf("couldn't open database: %s", err)
+	}
+	defer database.Close()
+	ctx := context.Background()
+	if _, err := db.MigrateUp(ctx, database, 0); err != nil {
+		t.Fatalf("couldn't migrate up: %s", err)
+	}
+	_, err = database.ExecContext(ctx, "INSERT INTO schema_migrations (version, name, applied_at) VALUES (9999, 'future', '2099-01-01T00:00:00Z')")
+	if err != nil {
+		t.Fatalf("couldn't record migration: %s", err)
+	}
+	_, err = db.MigrationsStatus(ctx, database)
+	if err == nil || !strings.Contains(err.Error(), "doesn't know about") {
+		t.Fatalf("expected unknown migration error, got %v", err)
+	}
+}
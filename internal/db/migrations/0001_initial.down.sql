@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS job_posts;
+DROP TABLE IF EXISTS employer_accounts;
+DROP TABLE IF EXISTS employers;
+DROP TABLE IF EXISTS admin_accounts;
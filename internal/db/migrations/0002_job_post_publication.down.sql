@@ -0,0 +1,4 @@
+DROP TABLE job_post_status_changes;
+
+ALTER TABLE job_posts DROP COLUMN expires_at;
+ALTER TABLE job_posts DROP COLUMN published_at;
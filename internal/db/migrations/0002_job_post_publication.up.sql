@@ -0,0 +1,14 @@
+ALTER TABLE job_posts ADD COLUMN published_at TEXT;
+ALTER TABLE job_posts ADD COLUMN expires_at TEXT;
+
+CREATE TABLE job_post_status_changes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    job_post_id INTEGER NOT NULL,
+    from_status TEXT NOT NULL,
+    to_status TEXT NOT NULL,
+    changed_at TEXT NOT NULL,
+    actor_id INTEGER,
+    actor_role INTEGER,
+    reason TEXT,
+    FOREIGN KEY (job_post_id) REFERENCES job_posts(id) ON DELETE CASCADE
+);
@@ -0,0 +1,4 @@
+DROP INDEX employer_accounts_employer_id_email;
+DROP INDEX employers_name;
+DROP INDEX job_posts_status_published_at;
+DROP INDEX job_posts_created_at;
@@ -0,0 +1,4 @@
+CREATE INDEX job_posts_created_at ON job_posts (created_at, id);
+CREATE INDEX job_posts_status_published_at ON job_posts (status, published_at, id);
+CREATE INDEX employers_name ON employers (name, id);
+CREATE INDEX employer_accounts_employer_id_email ON employer_accounts (employer_id, email, id);
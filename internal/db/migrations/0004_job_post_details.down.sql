@@ -0,0 +1,12 @@
+ALTER TABLE job_posts DROP COLUMN accessible_workplace;
+ALTER TABLE job_posts DROP COLUMN flexible_schedule;
+ALTER TABLE job_posts DROP COLUMN physical_demands;
+ALTER TABLE job_posts DROP COLUMN salary_period;
+ALTER TABLE job_posts DROP COLUMN salary_currency;
+ALTER TABLE job_posts DROP COLUMN salary_max;
+ALTER TABLE job_posts DROP COLUMN salary_min;
+ALTER TABLE job_posts DROP COLUMN postal_code;
+ALTER TABLE job_posts DROP COLUMN city;
+ALTER TABLE job_posts DROP COLUMN work_mode;
+ALTER TABLE job_posts DROP COLUMN weekly_hours;
+ALTER TABLE job_posts DROP COLUMN employment_type;
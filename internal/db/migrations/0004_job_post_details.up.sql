@@ -0,0 +1,12 @@
+ALTER TABLE job_posts ADD COLUMN employment_type TEXT CHECK (employment_type IN ('part_time', 'full_time'));
+ALTER TABLE job_posts ADD COLUMN weekly_hours INTEGER CHECK (weekly_hours BETWEEN 1 AND 80);
+ALTER TABLE job_posts ADD COLUMN work_mode TEXT CHECK (work_mode IN ('remote', 'hybrid', 'on_site'));
+ALTER TABLE job_posts ADD COLUMN city TEXT;
+ALTER TABLE job_posts ADD COLUMN postal_code TEXT;
+ALTER TABLE job_posts ADD COLUMN salary_min INTEGER CHECK (salary_min >= 0);
+ALTER TABLE job_posts ADD COLUMN salary_max INTEGER CHECK (salary_max >= salary_min);
+ALTER TABLE job_posts ADD COLUMN salary_currency TEXT CHECK (length(salary_currency) = 3);
+ALTER TABLE job_posts ADD COLUMN salary_period TEXT CHECK (salary_period IN ('hour', 'month', 'year'));
+ALTER TABLE job_posts ADD COLUMN physical_demands TEXT CHECK (physical_demands IN ('low', 'moderate', 'high'));
+ALTER TABLE job_posts ADD COLUMN flexible_schedule BOOLEAN NOT NULL DEFAULT 0;
+ALTER TABLE job_posts ADD COLUMN accessible_workplace BOOLEAN NOT NULL DEFAULT 0;
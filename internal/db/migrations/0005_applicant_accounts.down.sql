@@ -0,0 +1 @@
+DROP TABLE applicant_accounts;
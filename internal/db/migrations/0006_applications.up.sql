@@ -0,0 +1,28 @@
+CREATE TABLE applications (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    job_post_id INTEGER NOT NULL,
+    applicant_id INTEGER NOT NULL,
+    cover_message TEXT NOT NULL,
+    status TEXT NOT NULL CHECK (status IN ('received', 'reviewing', 'interview', 'offered', 'declined')),
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL,
+    UNIQUE (job_post_id, applicant_id),
+    FOREIGN KEY (job_post_id) REFERENCES job_posts(id) ON DELETE CASCADE,
+    FOREIGN KEY (applicant_id) REFERENCES applicant_accounts(id) ON DELETE CASCADE
+);
+
+CREATE INDEX applications_job_post_id_created_at ON applications (job_post_id, created_at, id);
+CREATE INDEX applications_applicant_id_created_at ON applications (applicant_id, created_at, id);
+
+CREATE TABLE application_status_changes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    application_id INTEGER NOT NULL,
+    from_status TEXT NOT NULL,
+    to_status TEXT NOT NULL,
+    changed_at TEXT NOT NULL,
+    actor_id INTEGER,
+    actor_role INTEGER,
+    FOREIGN KEY (application_id) REFERENCES applications(id) ON DELETE CASCADE
+);
+
+CREATE INDEX application_status_changes_application_id ON application_status_changes (application_id, id);
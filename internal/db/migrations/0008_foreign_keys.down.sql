@@ -0,0 +1,39 @@
+-- Only the cascade is dropped: the foreign key is kept on employers(id), since the former reference to a column
+-- that doesn't exist would fail every write to job_posts now that foreign keys are enforced.
+CREATE TABLE old_job_posts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    title TEXT NOT NULL,
+    content TEXT NOT NULL,
+    created_at TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'draft',
+    employer_id INTEGER NOT NULL,
+    published_at TEXT,
+    expires_at TEXT,
+    employment_type TEXT CHECK (employment_type IN ('part_time', 'full_time')),
+    weekly_hours INTEGER CHECK (weekly_hours BETWEEN 1 AND 80),
+    work_mode TEXT CHECK (work_mode IN ('remote', 'hybrid', 'on_site')),
+    city TEXT,
+    postal_code TEXT,
+    salary_min INTEGER CHECK (salary_min >= 0),
+    salary_max INTEGER CHECK (salary_max >= salary_min),
+    salary_currency TEXT CHECK (length(salary_currency) = 3),
+    salary_period TEXT CHECK (salary_period IN ('hour', 'month', 'year')),
+    physical_demands TEXT CHECK (physical_demands IN ('low', 'moderate', 'high')),
+    flexible_schedule BOOLEAN NOT NULL DEFAULT 0,
+    accessible_workplace BOOLEAN NOT NULL DEFAULT 0,
+    FOREIGN KEY (employer_id) REFERENCES employers(id)
+);
+
+INSERT INTO old_job_posts SELECT
+    id, title, content, created_at, status, employer_id, published_at, expires_at,
+    employment_type, weekly_hours, work_mode, city, postal_code,
+    salary_min, salary_max, salary_currency, salary_period, physical_demands,
+    flexible_schedule, accessible_workplace
+FROM job_posts;
+
+DROP TABLE job_posts;
+
+ALTER TABLE old_job_posts RENAME TO job_posts;
+
+CREATE INDEX job_posts_created_at ON job_posts (created_at, id);
+CREATE INDEX job_posts_status_published_at ON job_posts (status, published_at, id);
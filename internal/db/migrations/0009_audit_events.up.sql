@@ -0,0 +1,29 @@
+-- The audit log is append-only: events can't be changed or removed once recorded.
+-- Actors and targets aren't foreign keys, so that events outlive the rows they are about.
+CREATE TABLE audit_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    occurred_at TEXT NOT NULL,
+    actor_id INTEGER,
+    actor_role INTEGER,
+    action TEXT NOT NULL,
+    target_type TEXT NOT NULL,
+    target_id INTEGER,
+    before_summary TEXT,
+    after_summary TEXT,
+    ip TEXT NOT NULL,
+    user_agent TEXT NOT NULL
+);
+
+CREATE INDEX audit_events_occurred_at ON audit_events (occurred_at, id);
+CREATE INDEX audit_events_actor ON audit_events (actor_role, actor_id);
+CREATE INDEX audit_events_target ON audit_events (target_type, target_id);
+
+CREATE TRIGGER audit_events_no_update BEFORE UPDATE ON audit_events
+BEGIN
+    SELECT RAISE(ABORT, 'audit events are append-only');
+END;
+
+CREATE TRIGGER audit_events_no_delete BEFORE DELETE ON audit_events
+BEGIN
+    SELECT RAISE(ABORT, 'audit events are append-only');
+END;
@@ -0,0 +1,12 @@
+-- Consecutive failed logins, by role and email. Failures are recorded for every email logins are attempted with,
+-- whether an account has it or not, so that lockouts don't reveal which accounts exist.
+CREATE TABLE login_failures (
+    role INTEGER NOT NULL,
+    email TEXT NOT NULL,
+    failed_attempts INTEGER NOT NULL,
+    last_failed_at TEXT NOT NULL,
+    locked_until TEXT,
+    PRIMARY KEY (role, email)
+);
+
+CREATE INDEX login_failures_locked_until ON login_failures (locked_until);
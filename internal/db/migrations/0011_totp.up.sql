@@ -0,0 +1,21 @@
+-- The TOTP second factors of admin and employer accounts, by account id and role.
+-- A factor is pending until a first code confirms it. last_used_step is the time step of the last code accepted,
+-- for codes not to be replayed.
+CREATE TABLE totp_factors (
+    account_id INTEGER NOT NULL,
+    role INTEGER NOT NULL,
+    secret TEXT NOT NULL,
+    created_at TEXT NOT NULL,
+    confirmed_at TEXT,
+    last_used_step INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (account_id, role)
+);
+
+-- The one-time recovery codes of confirmed TOTP factors, as SHA-256 hashes. Codes are deleted once used.
+CREATE TABLE recovery_codes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    account_id INTEGER NOT NULL,
+    role INTEGER NOT NULL,
+    code_hash TEXT NOT NULL,
+    UNIQUE (account_id, role, code_hash)
+);
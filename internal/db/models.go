@@ -4,12 +4,61 @@
 
 package db
 
+import (
+	"database/sql"
+)
+
 type AdminAccount struct {
 	ID           int64
 	Email        string
 	PasswordHash string
 }
 
+type ApplicantAccount struct {
+	ID           int64
+	Email        string
+	PasswordHash string
+	Name         string
+	PhoneNumber  string
+	City         string
+	PostalCode   string
+	CreatedAt    string
+}
+
+type Application struct {
+	ID           int64
+	JobPostID    int64
+	ApplicantID  int64
+	CoverMessage string
+	Status       string
+	CreatedAt    string
+	UpdatedAt    string
+}
+
+type ApplicationStatusChange struct {
+	ID            int64
+	ApplicationID int64
+	FromStatus    string
+	ToStatus      string
+	ChangedAt     string
+	ActorID       sql.NullInt64
+	ActorRole     sql.NullInt64
+}
+
+type AuditEvent struct {
+	ID            int64
+	OccurredAt    string
+	ActorID       sql.NullInt64
+	ActorRole     sql.NullInt64
+	Action        string
+	TargetType    string
+	TargetID      sql.NullInt64
+	BeforeSummary sql.NullString
+	AfterSummary  sql.NullString
+	Ip            string
+	UserAgent     string
+}
+
 type Employer struct {
 	ID   int64
 	Name string
@@ -23,10 +72,68 @@ type EmployerAccount struct {
 }
 
 type JobPost struct {
+	ID                  int64
+	Title               string
+	Content             string
+	CreatedAt           string
+	Status              string
+	EmployerID          int64
+	PublishedAt         sql.NullString
+	ExpiresAt           sql.NullString
+	EmploymentType      sql.NullString
+	WeeklyHours         sql.NullInt64
+	WorkMode            sql.NullString
+	City                sql.NullString
+	PostalCode          sql.NullString
+	SalaryMin           sql.NullInt64
+	SalaryMax           sql.NullInt64
+	SalaryCurrency      sql.NullString
+	SalaryPeriod        sql.NullString
+	PhysicalDemands     sql.NullString
+	FlexibleSchedule    bool
+	AccessibleWorkplace bool
+}
+
+type JobPostStatusChange struct {
 	ID         int64
-	Title      string
-	Content    string
-	CreatedAt  string
-	Status     string
-	EmployerID int64
+	JobPostID  int64
+	FromStatus string
+	ToStatus   string
+	ChangedAt  string
+	ActorID    sql.NullInt64
+	ActorRole  sql.NullInt64
+	Reason     sql.NullString
+}
+
+type LoginFailure struct {
+	Role           int64
+	Email          string
+	FailedAttempts int64
+	LastFailedAt   string
+	LockedUntil    sql.NullString
+}
+
+type RecoveryCode struct {
+	ID        int64
+	AccountID int64
+	Role      int64
+	CodeHash  string
+}
+
+type Session struct {
+	ID               int64
+	AccountID        int64
+	Role             int64
+	RefreshTokenHash string
+	CreatedAt        string
+	ExpiresAt        string
+}
+
+type TotpFactor struct {
+	AccountID    int64
+	Role         int64
+	Secret       string
+	CreatedAt    string
+	ConfirmedAt  sql.NullString
+	LastUsedStep int64
 }
@@ -0,0 +1,187 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"regexp"
+	"strings"
+
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+//go:embed postgres/queries/*.sql
+var postgresQueryFiles embed.FS
+
+// sqliteQueryFiles are the queries sqlc generates the code from, to check that each one has a PostgreSQL version.
+//
+//go:embed queries/*.sql
+var sqliteQueryFiles embed.FS
+
+// queryHeader matches the comment sqlc starts every query with: -- name: <name> :<kind>
+var queryHeader = regexp.MustCompile(`(?m)^-- name: (\w+) :(\w+)`)
+
+// postgresDB runs the queries generated by sqlc on PostgreSQL.
+// Queries are generated from their SQLite version, so each one is swapped for its PostgreSQL version,
+// which has the same name and takes the same parameters, in the same order.
+type postgresDB struct {
+	db      DBTX
+	queries map[string]string
+}
+
+// namedQuery is a query of a file of queries, along with its kind, such as one or many.
+type namedQuery struct {
+	kind string
+	text string
+}
+
+// readQueries returns the queries of the files of dir, by name.
+func readQueries(fsys fs.FS, dir string) (map[string]namedQuery, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	queries := map[string]namedQuery{}
+	for _, entry := range entries {
+		content, err := fs.ReadFile(fsys, dir+"/"+entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		text := string(content)
+		headers := queryHeader.FindAllStringSubmatchIndex(text, -1)
+		for i, header := range headers {
+			end := len(text)
+			if i+1 < len(headers) {
+				end = headers[i+1][0]
+			}
+			name := text[header[2]:header[3]]
+			if _, ok := queries[name]; ok {
+				return nil, fmt.Errorf("query %s is defined twice in %s", name, entry.Name())
+			}
+			queries[name] = namedQuery{kind: text[header[4]:header[5]], text: strings.TrimSpace(text[header[0]:end])}
+		}
+	}
+	return queries, nil
+}
+
+// loadPostgresQueries returns the PostgreSQL version of every query, by name. It fails when a query generated
+// by sqlc has no PostgreSQL version of the same kind, rather than letting its SQLite version reach PostgreSQL.
+func loadPostgresQueries() (map[string]string, error) {
+	sqliteQueries, err := readQueries(sqliteQueryFiles, "queries")
+	if err != nil {
+		return nil, err
+	}
+	postgresQueries, err := readQueries(postgresQueryFiles, "postgres/queries")
+	if err != nil {
+		return nil, err
+	}
+	var errs []error
+	for name, sqliteQuery := range sqliteQueries {
+		postgresQuery, ok := postgresQueries[name]
+		if !ok {
+			errs = append(errs, fmt.Errorf("query %s has no PostgreSQL version", name))
+		} else if postgresQuery.kind != sqliteQuery.kind {
+			errs = append(errs, fmt.Errorf("query %s is :%s on SQLite and :%s on PostgreSQL", name, sqliteQuery.kind, postgresQuery.kind))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	queries := map[string]string{}
+	for name, query := range postgresQueries {
+		queries[name] = query.text
+	}
+	return queries, nil
+}
+
+// newPostgresQueries returns the queries of LesVieux running on a PostgreSQL database.
+func newPostgresQueries(database *sql.DB) (*Queries, error) {
+	queries, err := loadPostgresQueries()
+	if err != nil {
+		return nil, err
+	}
+	return New(postgresDB{db: database, queries: queries}), nil
+}
+
+// postgresQuery returns the PostgreSQL version of a query generated by sqlc.
+// Queries that weren't generated by sqlc are left as they are.
+func (p postgresDB) postgresQuery(query string) (string, error) {
+	match := queryHeader.FindStringSubmatch(query)
+	if match == nil {
+		return query, nil
+	}
+	postgresQuery, ok := p.queries[match[1]]
+	if !ok {
+		return "", fmt.Errorf("query %s has no PostgreSQL version", match[1])
+	}
+	return postgresQuery, nil
+}
+
+func (p postgresDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	query, err := p.postgresQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	return p.db.ExecContext(ctx, query, args...)
+}
+
+func (p postgresDB) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	query, err := p.postgresQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	return p.db.PrepareContext(ctx, query)
+}
+
+func (p postgresDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	query, err := p.postgresQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	return p.db.QueryContext(ctx, query, args...)
+}
+
+// QueryRowContext can't return an error of its own: a query without a PostgreSQL version is logged, and its row
+// is run with a canceled context, so that Scan and Err fail with context.Canceled instead of the query reaching
+// PostgreSQL. loadPostgresQueries already refuses to start without every query, so this shouldn't happen.
+func (p postgresDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	postgresQuery, err := p.postgresQuery(query)
+	if err != nil {
+		slog.Error("couldn't run query", "error", err)
+		canceled, cancel := context.WithCancelCause(ctx)
+		cancel(err)
+		return p.db.QueryRowContext(canceled, query, args...)
+	}
+	return p.db.QueryRowContext(ctx, postgresQuery, args...)
+}
+
+// driverOf returns the driver a database was opened with.
+func driverOf(database *sql.DB) string {
+	if _, ok := database.Driver().(*stdlib.Driver); ok {
+		return Postgres
+	}
+	return SQLite
+}
+
+// rebind swaps the ? placeholders of a query for the numbered placeholders of PostgreSQL, when needed.
+// It's meant for the few queries that aren't generated by sqlc, which don't have ? in their strings.
+func rebind(driver string, query string) string {
+	if driver != Postgres {
+		return query
+	}
+	var rebound strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			fmt.Fprintf(&rebound, "$%d", n)
+			continue
+		}
+		rebound.WriteRune(r)
+	}
+	return rebound.String()
+}
@@ -0,0 +1,9 @@
+DROP TABLE sessions;
+DROP TABLE application_status_changes;
+DROP TABLE applications;
+DROP TABLE applicant_accounts;
+DROP TABLE job_post_status_changes;
+DROP TABLE job_posts;
+DROP TABLE employer_accounts;
+DROP TABLE employers;
+DROP TABLE admin_accounts;
@@ -0,0 +1,115 @@
+-- PostgreSQL is supported from version 8 of the schema, which this migration creates as a whole.
+-- Later changes of the schema are migrations with the same version for SQLite and PostgreSQL.
+CREATE TABLE admin_accounts (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    email TEXT NOT NULL UNIQUE,
+    password_hash TEXT NOT NULL
+);
+
+CREATE TABLE employers (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    name TEXT NOT NULL
+);
+
+CREATE INDEX employers_name ON employers (name, id);
+
+CREATE TABLE employer_accounts (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    email TEXT NOT NULL UNIQUE,
+    password_hash TEXT NOT NULL,
+    employer_id BIGINT NOT NULL,
+    FOREIGN KEY (employer_id) REFERENCES employers(id) ON DELETE CASCADE
+);
+
+CREATE INDEX employer_accounts_employer_id_email ON employer_accounts (employer_id, email, id);
+
+CREATE TABLE job_posts (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    title TEXT NOT NULL,
+    content TEXT NOT NULL,
+    created_at TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'draft',
+    employer_id BIGINT NOT NULL,
+    published_at TEXT,
+    expires_at TEXT,
+    employment_type TEXT CHECK (employment_type IN ('part_time', 'full_time')),
+    weekly_hours BIGINT CHECK (weekly_hours BETWEEN 1 AND 80),
+    work_mode TEXT CHECK (work_mode IN ('remote', 'hybrid', 'on_site')),
+    city TEXT,
+    postal_code TEXT,
+    salary_min BIGINT CHECK (salary_min >= 0),
+    salary_max BIGINT CHECK (salary_max >= salary_min),
+    salary_currency TEXT CHECK (length(salary_currency) = 3),
+    salary_period TEXT CHECK (salary_period IN ('hour', 'month', 'year')),
+    physical_demands TEXT CHECK (physical_demands IN ('low', 'moderate', 'high')),
+    flexible_schedule BOOLEAN NOT NULL DEFAULT FALSE,
+    accessible_workplace BOOLEAN NOT NULL DEFAULT FALSE,
+    FOREIGN KEY (employer_id) REFERENCES employers(id) ON DELETE CASCADE
+);
+
+CREATE INDEX job_posts_created_at ON job_posts (created_at, id);
+CREATE INDEX job_posts_status_published_at ON job_posts (status, published_at, id);
+CREATE INDEX job_posts_employer_id ON job_posts (employer_id);
+
+CREATE TABLE job_post_status_changes (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    job_post_id BIGINT NOT NULL,
+    from_status TEXT NOT NULL,
+    to_status TEXT NOT NULL,
+    changed_at TEXT NOT NULL,
+    actor_id BIGINT,
+    actor_role BIGINT,
+    reason TEXT,
+    FOREIGN KEY (job_post_id) REFERENCES job_posts(id) ON DELETE CASCADE
+);
+
+CREATE TABLE applicant_accounts (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    email TEXT NOT NULL UNIQUE,
+    password_hash TEXT NOT NULL,
+    name TEXT NOT NULL DEFAULT '',
+    phone_number TEXT NOT NULL DEFAULT '',
+    city TEXT NOT NULL DEFAULT '',
+    postal_code TEXT NOT NULL DEFAULT '',
+    created_at TEXT NOT NULL
+);
+
+CREATE TABLE applications (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    job_post_id BIGINT NOT NULL,
+    applicant_id BIGINT NOT NULL,
+    cover_message TEXT NOT NULL,
+    status TEXT NOT NULL CHECK (status IN ('received', 'reviewing', 'interview', 'offered', 'declined')),
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL,
+    UNIQUE (job_post_id, applicant_id),
+    FOREIGN KEY (job_post_id) REFERENCES job_posts(id) ON DELETE CASCADE,
+    FOREIGN KEY (applicant_id) REFERENCES applicant_accounts(id) ON DELETE CASCADE
+);
+
+CREATE INDEX applications_job_post_id_created_at ON applications (job_post_id, created_at, id);
+CREATE INDEX applications_applicant_id_created_at ON applications (applicant_id, created_at, id);
+
+CREATE TABLE application_status_changes (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    application_id BIGINT NOT NULL,
+    from_status TEXT NOT NULL,
+    to_status TEXT NOT NULL,
+    changed_at TEXT NOT NULL,
+    actor_id BIGINT,
+    actor_role BIGINT,
+    FOREIGN KEY (application_id) REFERENCES applications(id) ON DELETE CASCADE
+);
+
+CREATE INDEX application_status_changes_application_id ON application_status_changes (application_id, id);
+
+CREATE TABLE sessions (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    account_id BIGINT NOT NULL,
+    role BIGINT NOT NULL,
+    refresh_token_hash TEXT NOT NULL UNIQUE,
+    created_at TEXT NOT NULL,
+    expires_at TEXT NOT NULL
+);
+
+CREATE INDEX sessions_account_id_role ON sessions (account_id, role);
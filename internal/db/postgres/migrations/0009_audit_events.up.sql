@@ -0,0 +1,28 @@
+-- The audit log is append-only: events can't be changed or removed once recorded.
+-- Actors and targets aren't foreign keys, so that events outlive the rows they are about.
+CREATE TABLE audit_events (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    occurred_at TEXT NOT NULL,
+    actor_id BIGINT,
+    actor_role BIGINT,
+    action TEXT NOT NULL,
+    target_type TEXT NOT NULL,
+    target_id BIGINT,
+    before_summary TEXT,
+    after_summary TEXT,
+    ip TEXT NOT NULL,
+    user_agent TEXT NOT NULL
+);
+
+CREATE INDEX audit_events_occurred_at ON audit_events (occurred_at, id);
+CREATE INDEX audit_events_actor ON audit_events (actor_role, actor_id);
+CREATE INDEX audit_events_target ON audit_events (target_type, target_id);
+
+CREATE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit events are append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_events_append_only BEFORE UPDATE OR DELETE ON audit_events
+FOR EACH ROW EXECUTE FUNCTION audit_events_append_only();
@@ -0,0 +1,39 @@
+-- name: GetAdminAccount :one
+SELECT * FROM admin_accounts
+WHERE id = $1 LIMIT 1;
+
+-- name: GetAdminAccountByEmail :one
+SELECT * FROM admin_accounts
+WHERE email = $1 LIMIT 1;
+
+-- name: ListAdminAccounts :many
+SELECT * FROM admin_accounts
+WHERE (email, id) > ($1::text, $2::bigint)
+ORDER BY email, id
+LIMIT $3;
+
+-- name: ListAdminAccountsDesc :many
+SELECT * FROM admin_accounts
+WHERE (email, id) < ($1::text, $2::bigint)
+ORDER BY email DESC, id DESC
+LIMIT $3;
+
+-- name: CreateAdminAccount :one
+INSERT INTO admin_accounts (
+  email, password_hash
+) VALUES (
+  $1, $2
+)
+RETURNING *;
+
+-- name: UpdateAdminAccount :exec
+UPDATE admin_accounts
+set password_hash = $1
+WHERE id = $2;
+
+-- name: DeleteAdminAccount :exec
+DELETE FROM admin_accounts
+WHERE id = $1;
+
+-- name: NumAdminAccounts :one
+SELECT COUNT(*) FROM admin_accounts;
@@ -0,0 +1,32 @@
+-- name: GetApplicantAccount :one
+SELECT * FROM applicant_accounts
+WHERE id = $1 LIMIT 1;
+
+-- name: GetApplicantAccountByEmail :one
+SELECT * FROM applicant_accounts
+WHERE email = $1 LIMIT 1;
+
+-- name: CreateApplicantAccount :one
+INSERT INTO applicant_accounts (
+  email, password_hash, name, phone_number, city, postal_code, created_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+)
+RETURNING *;
+
+-- name: UpdateApplicantAccountProfile :exec
+UPDATE applicant_accounts
+SET name = $1, phone_number = $2, city = $3, postal_code = $4
+WHERE id = $5;
+
+-- name: UpdateApplicantAccountPassword :exec
+UPDATE applicant_accounts
+SET password_hash = $1
+WHERE id = $2;
+
+-- name: DeleteApplicantAccount :exec
+DELETE FROM applicant_accounts
+WHERE id = $1;
+
+-- name: NumApplicantAccounts :one
+SELECT COUNT(*) FROM applicant_accounts;
@@ -0,0 +1,12 @@
+-- name: CreateApplicationStatusChange :one
+INSERT INTO application_status_changes (
+  application_id, from_status, to_status, changed_at, actor_id, actor_role
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+)
+RETURNING *;
+
+-- name: ListApplicationStatusChanges :many
+SELECT * FROM application_status_changes
+WHERE application_id = $1
+ORDER BY id;
@@ -0,0 +1,82 @@
+-- name: CreateApplication :one
+INSERT INTO applications (
+  job_post_id, applicant_id, cover_message, status, created_at, updated_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+)
+RETURNING *;
+
+-- name: GetApplicantApplication :one
+SELECT applications.*, job_posts.title AS job_post_title, employers.name AS employer_name
+FROM applications
+JOIN job_posts ON job_posts.id = applications.job_post_id
+JOIN employers ON employers.id = job_posts.employer_id
+WHERE applications.id = $1 AND applications.applicant_id = $2 LIMIT 1;
+
+-- name: ListApplicantApplicationsNewestFirst :many
+SELECT applications.*, job_posts.title AS job_post_title, employers.name AS employer_name
+FROM applications
+JOIN job_posts ON job_posts.id = applications.job_post_id
+JOIN employers ON employers.id = job_posts.employer_id
+WHERE applications.applicant_id = $1
+  AND ($2::text IS NULL OR applications.status = $3)
+  AND (applications.created_at, applications.id) < ($4::text, $5::bigint)
+ORDER BY applications.created_at DESC, applications.id DESC
+LIMIT $6;
+
+-- name: ListApplicantApplicationsOldestFirst :many
+SELECT applications.*, job_posts.title AS job_post_title, employers.name AS employer_name
+FROM applications
+JOIN job_posts ON job_posts.id = applications.job_post_id
+JOIN employers ON employers.id = job_posts.employer_id
+WHERE applications.applicant_id = $1
+  AND ($2::text IS NULL OR applications.status = $3)
+  AND (applications.created_at, applications.id) > ($4::text, $5::bigint)
+ORDER BY applications.created_at, applications.id
+LIMIT $6;
+
+-- name: GetJobPostApplication :one
+SELECT applications.*, applicant_accounts.email AS applicant_email, applicant_accounts.name AS applicant_name,
+  applicant_accounts.phone_number AS applicant_phone_number, applicant_accounts.city AS applicant_city,
+  applicant_accounts.postal_code AS applicant_postal_code
+FROM applications
+JOIN applicant_accounts ON applicant_accounts.id = applications.applicant_id
+WHERE applications.id = $1 AND applications.job_post_id = $2 LIMIT 1;
+
+-- name: ListJobPostApplicationsOldestFirst :many
+SELECT applications.*, applicant_accounts.email AS applicant_email, applicant_accounts.name AS applicant_name,
+  applicant_accounts.phone_number AS applicant_phone_number, applicant_accounts.city AS applicant_city,
+  applicant_accounts.postal_code AS applicant_postal_code
+FROM applications
+JOIN applicant_accounts ON applicant_accounts.id = applications.applicant_id
+WHERE applications.job_post_id = $1
+  AND ($2::text IS NULL OR applications.status = $3)
+  AND (applications.created_at, applications.id) > ($4::text, $5::bigint)
+ORDER BY applications.created_at, applications.id
+LIMIT $6;
+
+-- name: ListJobPostApplicationsNewestFirst :many
+SELECT applications.*, applicant_accounts.email AS applicant_email, applicant_accounts.name AS applicant_name,
+  applicant_accounts.phone_number AS applicant_phone_number, applicant_accounts.city AS applicant_city,
+  applicant_accounts.postal_code AS applicant_postal_code
+FROM applications
+JOIN applicant_accounts ON applicant_accounts.id = applications.applicant_id
+WHERE applications.job_post_id = $1
+  AND ($2::text IS NULL OR applications.status = $3)
+  AND (applications.created_at, applications.id) < ($4::text, $5::bigint)
+ORDER BY applications.created_at DESC, applications.id DESC
+LIMIT $6;
+
+-- name: UpdateApplicationStatus :execrows
+UPDATE applications
+SET status = $1, updated_at = $2
+WHERE id = $3 AND status = $4;
+
+-- name: GetApplicationForJobPost :one
+SELECT * FROM applications
+WHERE job_post_id = $1 AND applicant_id = $2 LIMIT 1;
+
+-- name: NumEmployerApplications :one
+SELECT COUNT(*) FROM applications
+JOIN job_posts ON job_posts.id = applications.job_post_id
+WHERE job_posts.employer_id = $1;
@@ -0,0 +1,33 @@
+-- name: CreateAuditEvent :one
+INSERT INTO audit_events (
+  occurred_at, actor_id, actor_role, action, target_type, target_id, before_summary, after_summary, ip, user_agent
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+)
+RETURNING *;
+
+-- name: ListAuditEventsNewestFirst :many
+SELECT * FROM audit_events
+WHERE ($1::bigint IS NULL OR actor_id = $2)
+  AND ($3::bigint IS NULL OR actor_role = $4)
+  AND ($5::text IS NULL OR action = $6)
+  AND ($7::text IS NULL OR target_type = $8)
+  AND ($9::bigint IS NULL OR target_id = $10)
+  AND occurred_at >= COALESCE($11, occurred_at)
+  AND occurred_at <= COALESCE($12, occurred_at)
+  AND (occurred_at, id) < ($13::text, $14::bigint)
+ORDER BY occurred_at DESC, id DESC
+LIMIT $15;
+
+-- name: ListAuditEventsOldestFirst :many
+SELECT * FROM audit_events
+WHERE ($1::bigint IS NULL OR actor_id = $2)
+  AND ($3::bigint IS NULL OR actor_role = $4)
+  AND ($5::text IS NULL OR action = $6)
+  AND ($7::text IS NULL OR target_type = $8)
+  AND ($9::bigint IS NULL OR target_id = $10)
+  AND occurred_at >= COALESCE($11, occurred_at)
+  AND occurred_at <= COALESCE($12, occurred_at)
+  AND (occurred_at, id) > ($13::text, $14::bigint)
+ORDER BY occurred_at, id
+LIMIT $15;
@@ -0,0 +1,47 @@
+-- name: GetEmployerAccount :one
+SELECT * FROM employer_accounts
+where employer_id = $1 and id = $2 LIMIT 1;
+
+-- name: GetEmployerAccountByID :one
+SELECT * FROM employer_accounts
+WHERE id = $1 LIMIT 1;
+
+-- name: GetEmployerAccountByEmail :one
+SELECT * FROM employer_accounts
+WHERE email = $1 LIMIT 1;
+
+-- name: ListEmployerAccounts :many
+SELECT * FROM employer_accounts
+where employer_id = $1 AND (email, id) > ($2::text, $3::bigint)
+ORDER BY email, id
+LIMIT $4;
+
+-- name: ListEmployerAccountsDesc :many
+SELECT * FROM employer_accounts
+where employer_id = $1 AND (email, id) < ($2::text, $3::bigint)
+ORDER BY email DESC, id DESC
+LIMIT $4;
+
+-- name: CreateEmployerAccount :one
+INSERT INTO employer_accounts (
+  email, password_hash, employer_id
+) VALUES (
+  $1, $2, $3
+)
+RETURNING *;
+
+-- name: UpdateEmployerAccount :exec
+UPDATE employer_accounts
+set password_hash = $1
+WHERE id = $2;
+
+-- name: DeleteEmployerAccount :exec
+DELETE FROM employer_accounts
+where employer_id = $1 and id = $2;
+
+-- name: NumEmployerAccounts :one
+SELECT COUNT(*) FROM employer_accounts;
+
+-- name: NumEmployerAccountsOfEmployer :one
+SELECT COUNT(*) FROM employer_accounts
+WHERE employer_id = $1;
@@ -0,0 +1,35 @@
+-- name: GetEmployer :one
+SELECT * FROM employers
+WHERE id = $1 LIMIT 1;
+
+-- name: ListEmployers :many
+SELECT * FROM employers
+WHERE (name, id) > ($1::text, $2::bigint)
+ORDER BY name, id
+LIMIT $3;
+
+-- name: ListEmployersDesc :many
+SELECT * FROM employers
+WHERE (name, id) < ($1::text, $2::bigint)
+ORDER BY name DESC, id DESC
+LIMIT $3;
+
+-- name: CreateEmployer :one
+INSERT INTO employers (
+  name
+) VALUES (
+  $1
+)
+RETURNING *;
+
+-- name: UpdateEmployer :exec
+UPDATE employers
+SET name = $1
+WHERE id = $2;
+
+-- name: DeleteEmployer :exec
+DELETE FROM employers
+WHERE id = $1;
+
+-- name: NumEmployers :one
+SELECT COUNT(*) FROM employers;
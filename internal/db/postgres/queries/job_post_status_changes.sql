@@ -0,0 +1,17 @@
+-- name: CreateJobPostStatusChange :one
+INSERT INTO job_post_status_changes (
+  job_post_id, from_status, to_status, changed_at, actor_id, actor_role, reason
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+)
+RETURNING *;
+
+-- name: ListJobPostStatusChanges :many
+SELECT * FROM job_post_status_changes
+WHERE job_post_id = $1
+ORDER BY id;
+
+-- name: GetLatestJobPostStatusChange :one
+SELECT * FROM job_post_status_changes
+WHERE job_post_id = $1
+ORDER BY id DESC LIMIT 1;
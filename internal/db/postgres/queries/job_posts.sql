@@ -0,0 +1,122 @@
+-- name: ListJobPosts :many
+SELECT * FROM job_posts
+ORDER BY created_at DESC;
+
+-- name: ListExpiredJobPosts :many
+SELECT * FROM job_posts
+WHERE status = 'published' AND expires_at IS NOT NULL AND expires_at <= $1
+ORDER BY expires_at;
+
+-- name: GetJobPost :one
+SELECT * FROM job_posts
+WHERE id = $1 LIMIT 1;
+
+-- name: CreateJobPost :one
+INSERT INTO job_posts (
+  title, content, created_at, status, employer_id,
+  employment_type, weekly_hours, work_mode, city, postal_code,
+  salary_min, salary_max, salary_currency, salary_period,
+  physical_demands, flexible_schedule, accessible_workplace
+) VALUES (
+  $1, $2, $3, $4, $5,
+  $6, $7, $8, $9, $10,
+  $11, $12, $13, $14,
+  $15, $16, $17
+)
+RETURNING *;
+
+-- name: UpdateJobPost :exec
+UPDATE job_posts
+set title = $1, content = $2, expires_at = $3,
+  employment_type = $4, weekly_hours = $5, work_mode = $6, city = $7, postal_code = $8,
+  salary_min = $9, salary_max = $10, salary_currency = $11, salary_period = $12,
+  physical_demands = $13, flexible_schedule = $14, accessible_workplace = $15
+WHERE id = $16;
+
+-- name: UpdateJobPostStatus :execrows
+UPDATE job_posts
+set status = $1, published_at = $2
+WHERE id = $3 AND status = $4;
+
+-- name: DeleteJobPost :exec
+DELETE FROM job_posts
+WHERE id = $1;
+
+-- name: GetPublishedJobPost :one
+SELECT job_posts.*, employers.name AS employer_name
+FROM job_posts
+JOIN employers ON employers.id = job_posts.employer_id
+WHERE job_posts.id = $1 AND job_posts.status = 'published'
+LIMIT 1;
+
+-- name: ListJobPostsNewestFirst :many
+SELECT * FROM job_posts
+WHERE status = COALESCE($1, status)
+  AND employer_id = COALESCE($2, employer_id)
+  AND created_at >= COALESCE($3, created_at)
+  AND created_at <= COALESCE($4, created_at)
+  AND (created_at, id) < ($5::text, $6::bigint)
+ORDER BY created_at DESC, id DESC
+LIMIT $7;
+
+-- name: ListJobPostsOldestFirst :many
+SELECT * FROM job_posts
+WHERE status = COALESCE($1, status)
+  AND employer_id = COALESCE($2, employer_id)
+  AND created_at >= COALESCE($3, created_at)
+  AND created_at <= COALESCE($4, created_at)
+  AND (created_at, id) > ($5::text, $6::bigint)
+ORDER BY created_at, id
+LIMIT $7;
+
+-- name: ListPublishedJobPostsNewestFirst :many
+SELECT job_posts.*, employers.name AS employer_name
+FROM job_posts
+JOIN employers ON employers.id = job_posts.employer_id
+WHERE job_posts.status = 'published'
+  AND job_posts.employer_id = COALESCE($1, job_posts.employer_id)
+  AND job_posts.published_at >= COALESCE($2, job_posts.published_at)
+  AND job_posts.published_at <= COALESCE($3, job_posts.published_at)
+  AND ($4::text IS NULL OR job_posts.employment_type = $5)
+  AND ($6::text IS NULL OR job_posts.work_mode = $7)
+  AND ($8::text IS NULL OR job_posts.physical_demands = $9)
+  AND ($10::text IS NULL OR lower(job_posts.city) = lower($11::text))
+  AND ($12::text IS NULL OR job_posts.postal_code = $13)
+  AND ($14::bigint IS NULL OR job_posts.weekly_hours <= $15)
+  AND ($16::text IS NULL OR job_posts.salary_currency = $17)
+  AND ($18::bigint IS NULL OR COALESCE(job_posts.salary_max, job_posts.salary_min) >= $19)
+  AND ($20::boolean IS NULL OR job_posts.flexible_schedule = $21)
+  AND ($22::boolean IS NULL OR job_posts.accessible_workplace = $23)
+  AND (job_posts.published_at, job_posts.id) < ($24::text, $25::bigint)
+ORDER BY job_posts.published_at DESC, job_posts.id DESC
+LIMIT $26;
+
+-- name: ListPublishedJobPostsOldestFirst :many
+SELECT job_posts.*, employers.name AS employer_name
+FROM job_posts
+JOIN employers ON employers.id = job_posts.employer_id
+WHERE job_posts.status = 'published'
+  AND job_posts.employer_id = COALESCE($1, job_posts.employer_id)
+  AND job_posts.published_at >= COALESCE($2, job_posts.published_at)
+  AND job_posts.published_at <= COALESCE($3, job_posts.published_at)
+  AND ($4::text IS NULL OR job_posts.employment_type = $5)
+  AND ($6::text IS NULL OR job_posts.work_mode = $7)
+  AND ($8::text IS NULL OR job_posts.physical_demands = $9)
+  AND ($10::text IS NULL OR lower(job_posts.city) = lower($11::text))
+  AND ($12::text IS NULL OR job_posts.postal_code = $13)
+  AND ($14::bigint IS NULL OR job_posts.weekly_hours <= $15)
+  AND ($16::text IS NULL OR job_posts.salary_currency = $17)
+  AND ($18::bigint IS NULL OR COALESCE(job_posts.salary_max, job_posts.salary_min) >= $19)
+  AND ($20::boolean IS NULL OR job_posts.flexible_schedule = $21)
+  AND ($22::boolean IS NULL OR job_posts.accessible_workplace = $23)
+  AND (job_posts.published_at, job_posts.id) > ($24::text, $25::bigint)
+ORDER BY job_posts.published_at, job_posts.id
+LIMIT $26;
+
+-- name: NumEmployerJobPosts :one
+SELECT COUNT(*) FROM job_posts
+WHERE employer_id = $1;
+
+-- name: NumJobPostsByStatus :many
+SELECT status, COUNT(*) AS count FROM job_posts
+GROUP BY status;
@@ -0,0 +1,27 @@
+-- name: SearchPublishedJobPosts :many
+SELECT job_posts.*, employers.name AS employer_name,
+  ts_headline('simple', job_posts.title, search.query,
+    'HighlightAll=true, StartSel=' || chr(2) || ', StopSel=' || chr(3)) AS title_highlight,
+  ts_headline('simple', job_posts.content, search.query,
+    'MaxWords=24, MinWords=12, StartSel=' || chr(2) || ', StopSel=' || chr(3)) AS content_snippet
+FROM job_posts
+JOIN employers ON employers.id = job_posts.employer_id
+CROSS JOIN LATERAL (
+  SELECT to_tsquery('simple', $1) AS query,
+    setweight(to_tsvector('simple', job_posts.title), 'A') ||
+    setweight(to_tsvector('simple', employers.name), 'B') ||
+    setweight(to_tsvector('simple', job_posts.content), 'D') AS document
+) AS search
+WHERE search.document @@ search.query AND job_posts.status = 'published'
+  AND ($2::text IS NULL OR job_posts.employment_type = $3)
+  AND ($4::text IS NULL OR job_posts.work_mode = $5)
+  AND ($6::text IS NULL OR job_posts.physical_demands = $7)
+  AND ($8::text IS NULL OR lower(job_posts.city) = lower($9::text))
+  AND ($10::text IS NULL OR job_posts.postal_code = $11)
+  AND ($12::bigint IS NULL OR job_posts.weekly_hours <= $13)
+  AND ($14::text IS NULL OR job_posts.salary_currency = $15)
+  AND ($16::bigint IS NULL OR COALESCE(job_posts.salary_max, job_posts.salary_min) >= $17)
+  AND ($18::boolean IS NULL OR job_posts.flexible_schedule = $19)
+  AND ($20::boolean IS NULL OR job_posts.accessible_workplace = $21)
+ORDER BY ts_rank(search.document, search.query) DESC, job_posts.id
+LIMIT $22 OFFSET $23;
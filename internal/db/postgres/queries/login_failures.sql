@@ -0,0 +1,32 @@
+-- name: GetLoginFailure :one
+SELECT * FROM login_failures
+WHERE role = $1 AND email = $2 LIMIT 1;
+
+-- name: RecordLoginFailure :one
+INSERT INTO login_failures (
+  role, email, failed_attempts, last_failed_at
+) VALUES (
+  $1, $2, 1, $3
+)
+ON CONFLICT (role, email) DO UPDATE SET
+  failed_attempts = login_failures.failed_attempts + 1,
+  last_failed_at = excluded.last_failed_at
+RETURNING *;
+
+-- name: LockLogin :exec
+UPDATE login_failures
+SET locked_until = $1
+WHERE role = $2 AND email = $3;
+
+-- name: DeleteLoginFailure :execrows
+DELETE FROM login_failures
+WHERE role = $1 AND email = $2;
+
+-- name: ListLockedLogins :many
+SELECT * FROM login_failures
+WHERE locked_until > $1
+ORDER BY locked_until DESC, role, email;
+
+-- name: DeleteStaleLoginFailures :execrows
+DELETE FROM login_failures
+WHERE last_failed_at <= $1 AND (locked_until IS NULL OR locked_until <= $2);
@@ -0,0 +1,42 @@
+-- name: CreateSession :one
+INSERT INTO sessions (
+  account_id, role, refresh_token_hash, created_at, expires_at
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+RETURNING *;
+
+-- name: GetSession :one
+SELECT * FROM sessions
+WHERE id = $1 LIMIT 1;
+
+-- name: GetSessionByRefreshTokenHash :one
+SELECT * FROM sessions
+WHERE refresh_token_hash = $1 LIMIT 1;
+
+-- name: RotateSessionRefreshToken :execrows
+UPDATE sessions
+SET refresh_token_hash = $1, expires_at = $2
+WHERE id = $3 AND refresh_token_hash = $4;
+
+-- name: DeleteSession :exec
+DELETE FROM sessions
+WHERE id = $1;
+
+-- name: DeleteAccountSessions :exec
+DELETE FROM sessions
+WHERE account_id = $1 AND role = $2;
+
+-- name: DeleteOtherAccountSessions :exec
+DELETE FROM sessions
+WHERE account_id = $1 AND role = $2 AND id != $3;
+
+-- name: DeleteEmployerSessions :exec
+DELETE FROM sessions
+WHERE role = $1 AND account_id IN (
+  SELECT id FROM employer_accounts WHERE employer_id = $2
+);
+
+-- name: DeleteExpiredSessions :execrows
+DELETE FROM sessions
+WHERE expires_at <= $1;
@@ -0,0 +1,61 @@
+-- name: CreateTOTPFactor :one
+INSERT INTO totp_factors (
+  account_id, role, secret, created_at
+) VALUES (
+  $1, $2, $3, $4
+)
+ON CONFLICT (account_id, role) DO UPDATE SET
+  secret = excluded.secret,
+  created_at = excluded.created_at,
+  last_used_step = 0
+WHERE totp_factors.confirmed_at IS NULL
+RETURNING *;
+
+-- name: GetTOTPFactor :one
+SELECT * FROM totp_factors
+WHERE account_id = $1 AND role = $2 LIMIT 1;
+
+-- name: ConfirmTOTPFactor :execrows
+UPDATE totp_factors
+SET confirmed_at = $1, last_used_step = $2
+WHERE account_id = $3 AND role = $4 AND confirmed_at IS NULL;
+
+-- name: UseTOTPStep :execrows
+UPDATE totp_factors
+SET last_used_step = $1
+WHERE account_id = $2 AND role = $3 AND last_used_step < $4;
+
+-- name: DeleteAccountTOTPFactor :execrows
+DELETE FROM totp_factors
+WHERE account_id = $1 AND role = $2;
+
+-- name: DeleteEmployerTOTPFactors :exec
+DELETE FROM totp_factors
+WHERE role = $1 AND account_id IN (
+  SELECT id FROM employer_accounts WHERE employer_id = $2
+);
+
+-- name: CreateRecoveryCode :exec
+INSERT INTO recovery_codes (
+  account_id, role, code_hash
+) VALUES (
+  $1, $2, $3
+);
+
+-- name: UseRecoveryCode :execrows
+DELETE FROM recovery_codes
+WHERE account_id = $1 AND role = $2 AND code_hash = $3;
+
+-- name: CountRecoveryCodes :one
+SELECT COUNT(*) FROM recovery_codes
+WHERE account_id = $1 AND role = $2;
+
+-- name: DeleteAccountRecoveryCodes :exec
+DELETE FROM recovery_codes
+WHERE account_id = $1 AND role = $2;
+
+-- name: DeleteEmployerRecoveryCodes :exec
+DELETE FROM recovery_codes
+WHERE role = $1 AND account_id IN (
+  SELECT id FROM employer_accounts WHERE employer_id = $2
+);
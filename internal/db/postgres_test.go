@@ -0,0 +1,413 @@
+package db_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gruyaume/lesvieux/internal/db"
+)
+
+var (
+	queryHeader       = regexp.MustCompile(`(?m)^-- name: (\w+) :(\w+)`)
+	sqliteParameter   = regexp.MustCompile(`\?|sqlc\.n?arg\(`)
+	postgresParameter = regexp.MustCompile(`\$(\d+)`)
+)
+
+type testQuery struct {
+	kind string
+	text string
+}
+
+func readQueries(t *testing.T, dir string) map[string]testQuery {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join(dir, "*.sql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	queries := map[string]testQuery{}
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		text := string(content)
+		headers := queryHeader.FindAllStringSubmatchIndex(text, -1)
+		for i, header := range headers {
+			end := len(text)
+			if i+1 < len(headers) {
+				end = headers[i+1][0]
+			}
+			queries[text[header[2]:header[3]]] = testQuery{kind: text[header[4]:header[5]], text: text[header[1]:end]}
+		}
+	}
+	return queries
+}
+
+// The PostgreSQL queries replace the queries sqlc generates from the SQLite ones,
+// so they must have the same names, kinds and number of parameters.
+func TestPostgresQueriesMatchSQLiteQueries(t *testing.T) {
+	sqliteQueries := readQueries(t, "queries")
+	postgresQueries := readQueries(t, filepath.Join("postgres", "queries"))
+	if len(sqliteQueries) == 0 {
+		t.Fatal("no queries found")
+	}
+	for name, sqliteQuery := range sqliteQueries {
+		postgresQuery, ok := postgresQueries[name]
+		if !ok {
+			t.Errorf("query %s has no PostgreSQL version", name)
+			continue
+		}
+		if postgresQuery.kind != sqliteQuery.kind {
+			t.Errorf("query %s is :%s on SQLite and :%s on PostgreSQL", name, sqliteQuery.kind, postgresQuery.kind)
+		}
+		parameters := len(sqliteParameter.FindAllString(sqliteQuery.text, -1))
+		used := map[int]bool{}
+		for _, match := range postgresParameter.FindAllStringSubmatch(postgresQuery.text, -1) {
+			n, _ := strconv.Atoi(match[1])
+			used[n] = true
+		}
+		if len(used) != parameters {
+			t.Errorf("query %s takes %d parameters on SQLite and %d on PostgreSQL", name, parameters, len(used))
+			continue
+		}
+		for n := 1; n <= parameters; n++ {
+			if !used[n] {
+				t.Errorf("query %s doesn't use parameter $%d on PostgreSQL", name, n)
+			}
+		}
+	}
+	for name := range postgresQueries {
+		if _, ok := sqliteQueries[name]; !ok {
+			t.Errorf("PostgreSQL query %s has no SQLite version", name)
+		}
+	}
+}
+
+// Every migration added after PostgreSQL support must exist for both databases, with the same version.
+func TestPostgresMigrationsMatchSQLiteMigrations(t *testing.T) {
+	sqliteMigrations, err := db.Migrations(db.SQLite)
+	if err != nil {
+		t.Fatalf("couldn't load SQLite migrations: %s", err)
+	}
+	postgresMigrations, err := db.Migrations(db.Postgres)
+	if err != nil {
+		t.Fatalf("couldn't load PostgreSQL migrations: %s", err)
+	}
+	if len(postgresMigrations) == 0 {
+		t.Fatal("no PostgreSQL migrations found")
+	}
+	first := postgresMigrations[0].Version
+	var sqliteVersions, postgresVersions []int64
+	for _, migration := range sqliteMigrations {
+		if migration.Version >= first {
+			sqliteVersions = append(sqliteVersions, migration.Version)
+		}
+	}
+	for _, migration := range postgresMigrations {
+		postgresVersions = append(postgresVersions, migration.Version)
+	}
+	if len(sqliteVersions) != len(postgresVersions) {
+		t.Fatalf("expected PostgreSQL migrations %v, got %v", sqliteVersions, postgresVersions)
+	}
+	for i := range sqliteVersions {
+		if sqliteVersions[i] != postgresVersions[i] {
+			t.Fatalf("expected PostgreSQL migrations %v, got %v", sqliteVersions, postgresVersions)
+		}
+	}
+}
+
+// TestPostgresMigrateUpDown runs against the database of LESVIEUX_TEST_POSTGRES_URL, when set.
+func TestPostgresMigrateUpDown(t *testing.T) {
+	url := os.Getenv("LESVIEUX_TEST_POSTGRES_URL")
+	if url == "" {
+		t.Skip("LESVIEUX_TEST_POSTGRES_URL is not set")
+	}
+	database, err := db.OpenDatabase(db.Postgres, url)
+	if err != nil {
+		t.Fatalf("couldn't open database: %s", err)
+	}
+	defer database.Close()
+	ctx := context.Background()
+	schema := "lesvieux_test_migrations"
+	if _, err := database.ExecContext(ctx, "DROP SCHEMA IF EXISTS "+schema+" CASCADE"); err != nil {
+		t.Fatalf("couldn't drop schema: %s", err)
+	}
+	if _, err := database.ExecContext(ctx, "CREATE SCHEMA "+schema); err != nil {
+		t.Fatalf("couldn't create schema: %s", err)
+	}
+	defer database.Exec("DROP SCHEMA " + schema + " CASCADE")
+
+	separator := "?"
+	if strings.Contains(url, "?") {
+		separator = "&"
+	}
+	schemaDatabase, err := db.OpenDatabase(db.Postgres, url+separator+"search_path="+schema)
+	if err != nil {
+		t.Fatalf("couldn't open database: %s", err)
+	}
+	defer schemaDatabase.Close()
+	applied, err := db.MigrateUp(ctx, schemaDatabase, 0)
+	if err != nil {
+		t.Fatalf("couldn't migrate up: %s", err)
+	}
+	if len(applied) == 0 {
+		t.Fatal("expected migrations to be applied")
+	}
+	reverted, err := db.MigrateDown(ctx, schemaDatabase, len(applied))
+	if err != nil {
+		t.Fatalf("couldn't migrate down: %s", err)
+	}
+	if len(reverted) != len(applied) {
+		t.Fatalf("expected %d migrations reverted, got %d", len(applied), len(reverted))
+	}
+}
+
+var (
+	sqliteParameterOccurrence = regexp.MustCompile(`\?|sqlc\.n?arg\(\w+\)`)
+	createTable               = regexp.MustCompile(`(?is)CREATE TABLE (?:IF NOT EXISTS )?(\w+) \((.*?)\n\);`)
+	addColumn                 = regexp.MustCompile(`(?i)ALTER TABLE (\w+) ADD COLUMN (?:IF NOT EXISTS )?(\w+)`)
+	insertColumns             = regexp.MustCompile(`(?is)INSERT INTO\s+\w+\s*\(([^)]*)\)\s*VALUES\s*\(`)
+	comparedColumn            = regexp.MustCompile(`(?i)(\w+)(?:\.(\w+))?\s*(?:=|<>|!=|<=|>=|<|>|\bLIKE)\s*$`)
+	limitKeyword              = regexp.MustCompile(`(?i)\b(LIMIT|OFFSET)\s*$`)
+	aliasedColumn             = regexp.MustCompile(`(?is)^.*\s+AS\s+(\w+)$`)
+	plainColumn               = regexp.MustCompile(`^(?:\w+\.)?(\w+)$`)
+	embeddedTable             = regexp.MustCompile(`^(?:sqlc\.embed\((\w+)\)|(\w+)\.\*)$`)
+	commentLine               = regexp.MustCompile(`(?m)^\s*--.*$`)
+)
+
+// scanTopLevel calls f with each byte of s outside string literals, along with its depth of parentheses.
+// It stops when f returns true, and returns the offset of that byte, or -1.
+func scanTopLevel(s string, f func(i int, depth int) bool) int {
+	depth := 0
+	quoted := false
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\'':
+			quoted = !quoted
+		case quoted:
+		case s[i] == '(':
+			depth++
+		case s[i] == ')':
+			depth--
+		default:
+			if f(i, depth) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// indexTopLevel returns the offset of the first keyword of s outside parentheses and string literals, or -1.
+func indexTopLevel(s string, keyword string) int {
+	upper := strings.ToUpper(s)
+	isWord := func(i int) bool {
+		return i >= 0 && i < len(s) && (s[i] == '_' || s[i] >= '0' && s[i] <= '9' || s[i] >= 'a' && s[i] <= 'z' || s[i] >= 'A' && s[i] <= 'Z')
+	}
+	return scanTopLevel(s, func(i int, depth int) bool {
+		return depth == 0 && strings.HasPrefix(upper[i:], keyword) && !isWord(i-1) && !isWord(i+len(keyword))
+	})
+}
+
+// splitTopLevel splits s on its commas outside parentheses and string literals.
+func splitTopLevel(s string) []string {
+	var items []string
+	start := 0
+	scanTopLevel(s, func(i int, depth int) bool {
+		if depth == 0 && s[i] == ',' {
+			items = append(items, strings.TrimSpace(s[start:i]))
+			start = i + 1
+		}
+		return false
+	})
+	return append(items, strings.TrimSpace(s[start:]))
+}
+
+// resultColumns returns the names of the columns a query returns, in order, with the columns of tables expanded.
+// Expressions without a name are "?".
+func resultColumns(t *testing.T, name string, query string, tables map[string][]string) []string {
+	t.Helper()
+	query = strings.TrimSuffix(strings.TrimSpace(commentLine.ReplaceAllString(query, "")), ";")
+	var list, from string
+	if i := indexTopLevel(query, "RETURNING"); i >= 0 {
+		list = query[i+len("RETURNING"):]
+	} else if strings.HasPrefix(strings.ToUpper(query), "SELECT") {
+		i := indexTopLevel(query, "FROM")
+		if i < 0 {
+			t.Fatalf("query %s: no FROM", name)
+		}
+		list = query[len("SELECT"):i]
+		from = strings.Fields(query[i+len("FROM"):])[0]
+	} else {
+		return nil
+	}
+	var columns []string
+	for _, item := range splitTopLevel(list) {
+		item = strings.Join(strings.Fields(item), " ")
+		table := ""
+		if item == "*" {
+			table = from
+		} else if match := embeddedTable.FindStringSubmatch(item); match != nil {
+			table = match[1] + match[2]
+		}
+		switch match := aliasedColumn.FindStringSubmatch(item); {
+		case table != "":
+			tableColumns, ok := tables[table]
+			if !ok {
+				t.Fatalf("query %s: unknown table %s", name, table)
+			}
+			columns = append(columns, tableColumns...)
+		case match != nil:
+			columns = append(columns, match[1])
+		case plainColumn.MatchString(item):
+			columns = append(columns, plainColumn.FindStringSubmatch(item)[1])
+		default:
+			columns = append(columns, "?")
+		}
+	}
+	return columns
+}
+
+// parameterColumns returns what the parameters of a query are compared to or inserted into, by number, when that
+// can be told: the column inserted into, the column on the other side of a comparison, or limit and offset.
+func parameterColumns(query string, parameters *regexp.Regexp, number func(match string, occurrence int) int) map[int][]string {
+	query = commentLine.ReplaceAllString(query, "")
+	inserted := map[int]string{}
+	if match := insertColumns.FindStringSubmatchIndex(query); match != nil {
+		names := splitTopLevel(query[match[2]:match[3]])
+		start := match[1]
+		end := start + scanTopLevel(query[start:], func(i int, depth int) bool { return depth < 0 })
+		offset := start
+		for i, value := range strings.Split(query[start:end], ",") {
+			if i < len(names) && parameters.MatchString(strings.TrimSpace(value)) {
+				inserted[offset+strings.Index(value, strings.TrimSpace(value))] = names[i]
+			}
+			offset += len(value) + 1
+		}
+	}
+	columns := map[int][]string{}
+	for occurrence, match := range parameters.FindAllStringIndex(query, -1) {
+		n := number(query[match[0]:match[1]], occurrence+1)
+		prefix := query[:match[0]]
+		if column, ok := inserted[match[0]]; ok {
+			columns[n] = append(columns[n], column)
+		} else if m := comparedColumn.FindStringSubmatch(prefix); m != nil {
+			columns[n] = append(columns[n], strings.ToLower(lastIdentifier(m)))
+		} else if m := limitKeyword.FindStringSubmatch(prefix); m != nil {
+			columns[n] = append(columns[n], strings.ToLower(m[1]))
+		}
+	}
+	return columns
+}
+
+// lastIdentifier returns the column of a match of comparedColumn, without its table.
+func lastIdentifier(match []string) string {
+	if match[2] != "" {
+		return match[2]
+	}
+	return match[1]
+}
+
+// sqliteTableColumns returns the columns of the tables of the SQLite schema, in order.
+func sqliteTableColumns(t *testing.T) map[string][]string {
+	t.Helper()
+	database, err := db.Open(openTestDatabase(t))
+	if err != nil {
+		t.Fatalf("couldn't open database: %s", err)
+	}
+	defer database.Close()
+	ctx := context.Background()
+	if _, err := db.MigrateUp(ctx, database, 0); err != nil {
+		t.Fatalf("couldn't migrate up: %s", err)
+	}
+	rows, err := database.QueryContext(ctx, "SELECT m.name, p.name FROM sqlite_master m JOIN pragma_table_info(m.name) p WHERE m.type = 'table' ORDER BY m.name, p.cid")
+	if err != nil {
+		t.Fatalf("couldn't list columns: %s", err)
+	}
+	defer rows.Close()
+	tables := map[string][]string{}
+	for rows.Next() {
+		var table, column string
+		if err := rows.Scan(&table, &column); err != nil {
+			t.Fatalf("couldn't list columns: %s", err)
+		}
+		tables[table] = append(tables[table], column)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("couldn't list columns: %s", err)
+	}
+	return tables
+}
+
+// postgresTableColumns returns the columns of the tables created by the PostgreSQL migrations, in order.
+func postgresTableColumns(t *testing.T) map[string][]string {
+	t.Helper()
+	migrations, err := db.Migrations(db.Postgres)
+	if err != nil {
+		t.Fatalf("couldn't load PostgreSQL migrations: %s", err)
+	}
+	tables := map[string][]string{}
+	for _, migration := range migrations {
+		for _, match := range createTable.FindAllStringSubmatch(migration.Up, -1) {
+			var columns []string
+			for _, definition := range splitTopLevel(match[2]) {
+				column := strings.Fields(definition)[0]
+				switch strings.ToUpper(column) {
+				case "PRIMARY", "FOREIGN", "UNIQUE", "CHECK", "CONSTRAINT":
+					continue
+				}
+				columns = append(columns, column)
+			}
+			tables[match[1]] = columns
+		}
+		for _, match := range addColumn.FindAllStringSubmatch(migration.Up, -1) {
+			tables[match[1]] = append(tables[match[1]], match[2])
+		}
+	}
+	return tables
+}
+
+// The generated code scans the columns of the SQLite queries and binds their parameters in their order,
+// so the PostgreSQL queries must return the same columns and take the same parameters, in the same order.
+func TestPostgresQueriesMatchSQLiteColumns(t *testing.T) {
+	sqliteQueries := readQueries(t, "queries")
+	postgresQueries := readQueries(t, filepath.Join("postgres", "queries"))
+	sqliteTables := sqliteTableColumns(t)
+	postgresTables := postgresTableColumns(t)
+	for name, sqliteQuery := range sqliteQueries {
+		postgresQuery, ok := postgresQueries[name]
+		if !ok {
+			continue
+		}
+		sqliteColumns := resultColumns(t, name, sqliteQuery.text, sqliteTables)
+		postgresColumns := resultColumns(t, name, postgresQuery.text, postgresTables)
+		if len(sqliteColumns) != len(postgresColumns) {
+			t.Errorf("query %s returns %d columns on SQLite and %d on PostgreSQL: %v, %v", name, len(sqliteColumns), len(postgresColumns), sqliteColumns, postgresColumns)
+		} else {
+			for i := range sqliteColumns {
+				if sqliteColumns[i] != "?" && postgresColumns[i] != "?" && sqliteColumns[i] != postgresColumns[i] {
+					t.Errorf("query %s returns %s on SQLite and %s on PostgreSQL as column %d", name, sqliteColumns[i], postgresColumns[i], i+1)
+				}
+			}
+		}
+
+		sqliteParameters := parameterColumns(sqliteQuery.text, sqliteParameterOccurrence, func(_ string, occurrence int) int { return occurrence })
+		postgresParameters := parameterColumns(postgresQuery.text, postgresParameter, func(match string, _ int) int {
+			n, _ := strconv.Atoi(match[1:])
+			return n
+		})
+		for n, sqliteNames := range sqliteParameters {
+			for _, postgresName := range postgresParameters[n] {
+				if !slices.Contains(sqliteNames, postgresName) {
+					t.Errorf("parameter %d of query %s is %v on SQLite and %s on PostgreSQL", n, name, sqliteNames, postgresName)
+				}
+			}
+		}
+	}
+}
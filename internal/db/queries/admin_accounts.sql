@@ -8,7 +8,15 @@ WHERE email = ? LIMIT 1;
 
 -- name: ListAdminAccounts :many
 SELECT * FROM admin_accounts
-ORDER BY email;
+WHERE (email, id) > (sqlc.arg(cursor_key), sqlc.arg(cursor_id))
+ORDER BY email, id
+LIMIT sqlc.arg(limit);
+
+-- name: ListAdminAccountsDesc :many
+SELECT * FROM admin_accounts
+WHERE (email, id) < (sqlc.arg(cursor_key), sqlc.arg(cursor_id))
+ORDER BY email DESC, id DESC
+LIMIT sqlc.arg(limit);
 
 -- name: CreateAdminAccount :one
 INSERT INTO admin_accounts (
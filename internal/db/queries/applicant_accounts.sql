@@ -0,0 +1,32 @@
+-- name: GetApplicantAccount :one
+SELECT * FROM applicant_accounts
+WHERE id = ? LIMIT 1;
+
+-- name: GetApplicantAccountByEmail :one
+SELECT * FROM applicant_accounts
+WHERE email = ? LIMIT 1;
+
+-- name: CreateApplicantAccount :one
+INSERT INTO applicant_accounts (
+  email, password_hash, name, phone_number, city, postal_code, created_at
+) VALUES (
+  ?, ?, ?, ?, ?, ?, ?
+)
+RETURNING *;
+
+-- name: UpdateApplicantAccountProfile :exec
+UPDATE applicant_accounts
+SET name = ?, phone_number = ?, city = ?, postal_code = ?
+WHERE id = ?;
+
+-- name: UpdateApplicantAccountPassword :exec
+UPDATE applicant_accounts
+SET password_hash = ?
+WHERE id = ?;
+
+-- name: DeleteApplicantAccount :exec
+DELETE FROM applicant_accounts
+WHERE id = ?;
+
+-- name: NumApplicantAccounts :one
+SELECT COUNT(*) FROM applicant_accounts;
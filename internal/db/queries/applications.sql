@@ -0,0 +1,82 @@
+-- name: CreateApplication :one
+INSERT INTO applications (
+  job_post_id, applicant_id, cover_message, status, created_at, updated_at
+) VALUES (
+  ?, ?, ?, ?, ?, ?
+)
+RETURNING *;
+
+-- name: GetApplicantApplication :one
+SELECT sqlc.embed(applications), job_posts.title AS job_post_title, employers.name AS employer_name
+FROM applications
+JOIN job_posts ON job_posts.id = applications.job_post_id
+JOIN employers ON employers.id = job_posts.employer_id
+WHERE applications.id = ? AND applications.applicant_id = ? LIMIT 1;
+
+-- name: ListApplicantApplicationsNewestFirst :many
+SELECT sqlc.embed(applications), job_posts.title AS job_post_title, employers.name AS employer_name
+FROM applications
+JOIN job_posts ON job_posts.id = applications.job_post_id
+JOIN employers ON employers.id = job_posts.employer_id
+WHERE applications.applicant_id = sqlc.arg(applicant_id)
+  AND (sqlc.narg(status) IS NULL OR applications.status = sqlc.narg(status))
+  AND (applications.created_at, applications.id) < (sqlc.arg(cursor_key), sqlc.arg(cursor_id))
+ORDER BY applications.created_at DESC, applications.id DESC
+LIMIT sqlc.arg(limit);
+
+-- name: ListApplicantApplicationsOldestFirst :many
+SELECT sqlc.embed(applications), job_posts.title AS job_post_title, employers.name AS employer_name
+FROM applications
+JOIN job_posts ON job_posts.id = applications.job_post_id
+JOIN employers ON employers.id = job_posts.employer_id
+WHERE applications.applicant_id = sqlc.arg(applicant_id)
+  AND (sqlc.narg(status) IS NULL OR applications.status = sqlc.narg(status))
+  AND (applications.created_at, applications.id) > (sqlc.arg(cursor_key), sqlc.arg(cursor_id))
+ORDER BY applications.created_at, applications.id
+LIMIT sqlc.arg(limit);
+
+-- name: GetJobPostApplication :one
+SELECT sqlc.embed(applications), applicant_accounts.email AS applicant_email, applicant_accounts.name AS applicant_name,
+  applicant_accounts.phone_number AS applicant_phone_number, applicant_accounts.city AS applicant_city,
+  applicant_accounts.postal_code AS applicant_postal_code
+FROM applications
+JOIN applicant_accounts ON applicant_accounts.id = applications.applicant_id
+WHERE applications.id = ? AND applications.job_post_id = ? LIMIT 1;
+
+-- name: ListJobPostApplicationsOldestFirst :many
+SELECT sqlc.embed(applications), applicant_accounts.email AS applicant_email, applicant_accounts.name AS applicant_name,
+  applicant_accounts.phone_number AS applicant_phone_number, applicant_accounts.city AS applicant_city,
+  applicant_accounts.postal_code AS applicant_postal_code
+FROM applications
+JOIN applicant_accounts ON applicant_accounts.id = applications.applicant_id
+WHERE applications.job_post_id = sqlc.arg(job_post_id)
+  AND (sqlc.narg(status) IS NULL OR applications.status = sqlc.narg(status))
+  AND (applications.created_at, applications.id) > (sqlc.arg(cursor_key), sqlc.arg(cursor_id))
+ORDER BY applications.created_at, applications.id
+LIMIT sqlc.arg(limit);
+
+-- name: ListJobPostApplicationsNewestFirst :many
+SELECT sqlc.embed(applications), applicant_accounts.email AS applicant_email, applicant_accounts.name AS applicant_name,
+  applicant_accounts.phone_number AS applicant_phone_number, applicant_accounts.city AS applicant_city,
+  applicant_accounts.postal_code AS applicant_postal_code
+FROM applications
+JOIN applicant_accounts ON applicant_accounts.id = applications.applicant_id
+WHERE applications.job_post_id = sqlc.arg(job_post_id)
+  AND (sqlc.narg(status) IS NULL OR applications.status = sqlc.narg(status))
+  AND (applications.created_at, applications.id) < (sqlc.arg(cursor_key), sqlc.arg(cursor_id))
+ORDER BY applications.created_at DESC, applications.id DESC
+LIMIT sqlc.arg(limit);
+
+-- name: UpdateApplicationStatus :execrows
+UPDATE applications
+SET status = ?, updated_at = ?
+WHERE id = ? AND status = sqlc.arg(from_status);
+
+-- name: GetApplicationForJobPost :one
+SELECT * FROM applications
+WHERE job_post_id = ? AND applicant_id = ? LIMIT 1;
+
+-- name: NumEmployerApplications :one
+SELECT COUNT(*) FROM applications
+JOIN job_posts ON job_posts.id = applications.job_post_id
+WHERE job_posts.employer_id = ?;
@@ -0,0 +1,33 @@
+-- name: CreateAuditEvent :one
+INSERT INTO audit_events (
+  occurred_at, actor_id, actor_role, action, target_type, target_id, before_summary, after_summary, ip, user_agent
+) VALUES (
+  ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+)
+RETURNING *;
+
+-- name: ListAuditEventsNewestFirst :many
+SELECT * FROM audit_events
+WHERE (sqlc.narg(actor_id) IS NULL OR actor_id = sqlc.narg(actor_id))
+  AND (sqlc.narg(actor_role) IS NULL OR actor_role = sqlc.narg(actor_role))
+  AND (sqlc.narg(action) IS NULL OR action = sqlc.narg(action))
+  AND (sqlc.narg(target_type) IS NULL OR target_type = sqlc.narg(target_type))
+  AND (sqlc.narg(target_id) IS NULL OR target_id = sqlc.narg(target_id))
+  AND occurred_at >= COALESCE(sqlc.narg(occurred_after), occurred_at)
+  AND occurred_at <= COALESCE(sqlc.narg(occurred_before), occurred_at)
+  AND (occurred_at, id) < (sqlc.arg(cursor_key), sqlc.arg(cursor_id))
+ORDER BY occurred_at DESC, id DESC
+LIMIT sqlc.arg(limit);
+
+-- name: ListAuditEventsOldestFirst :many
+SELECT * FROM audit_events
+WHERE (sqlc.narg(actor_id) IS NULL OR actor_id = sqlc.narg(actor_id))
+  AND (sqlc.narg(actor_role) IS NULL OR actor_role = sqlc.narg(actor_role))
+  AND (sqlc.narg(action) IS NULL OR action = sqlc.narg(action))
+  AND (sqlc.narg(target_type) IS NULL OR target_type = sqlc.narg(target_type))
+  AND (sqlc.narg(target_id) IS NULL OR target_id = sqlc.narg(target_id))
+  AND occurred_at >= COALESCE(sqlc.narg(occurred_after), occurred_at)
+  AND occurred_at <= COALESCE(sqlc.narg(occurred_before), occurred_at)
+  AND (occurred_at, id) > (sqlc.arg(cursor_key), sqlc.arg(cursor_id))
+ORDER BY occurred_at, id
+LIMIT sqlc.arg(limit);
@@ -2,14 +2,25 @@
 SELECT * FROM employer_accounts
 where employer_id = ? and id = ? LIMIT 1;
 
+-- name: GetEmployerAccountByID :one
+SELECT * FROM employer_accounts
+WHERE id = ? LIMIT 1;
+
 -- name: GetEmployerAccountByEmail :one
 SELECT * FROM employer_accounts
 WHERE email = ? LIMIT 1;
 
 -- name: ListEmployerAccounts :many
 SELECT * FROM employer_accounts
-where employer_id = ?
-ORDER BY email;
+where employer_id = ? AND (email, id) > (sqlc.arg(cursor_key), sqlc.arg(cursor_id))
+ORDER BY email, id
+LIMIT sqlc.arg(limit);
+
+-- name: ListEmployerAccountsDesc :many
+SELECT * FROM employer_accounts
+where employer_id = ? AND (email, id) < (sqlc.arg(cursor_key), sqlc.arg(cursor_id))
+ORDER BY email DESC, id DESC
+LIMIT sqlc.arg(limit);
 
 -- name: CreateEmployerAccount :one
 INSERT INTO employer_accounts (
@@ -29,4 +40,7 @@ DELETE FROM employer_accounts
 where employer_id = ? and id = ?;
 
 -- name: NumEmployerAccounts :one
-SELECT COUNT(*) FROM employer_accounts;
\ No newline at end of file
+SELECT COUNT(*) FROM employer_accounts;
+-- name: NumEmployerAccountsOfEmployer :one
+SELECT COUNT(*) FROM employer_accounts
+WHERE employer_id = ?;
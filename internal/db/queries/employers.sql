@@ -4,7 +4,15 @@ WHERE id = ? LIMIT 1;
 
 -- name: ListEmployers :many
 SELECT * FROM employers
-ORDER BY name;
+WHERE (name, id) > (sqlc.arg(cursor_key), sqlc.arg(cursor_id))
+ORDER BY name, id
+LIMIT sqlc.arg(limit);
+
+-- name: ListEmployersDesc :many
+SELECT * FROM employers
+WHERE (name, id) < (sqlc.arg(cursor_key), sqlc.arg(cursor_id))
+ORDER BY name DESC, id DESC
+LIMIT sqlc.arg(limit);
 
 -- name: CreateEmployer :one
 INSERT INTO employers (
@@ -0,0 +1,17 @@
+-- name: CreateJobPostStatusChange :one
+INSERT INTO job_post_status_changes (
+  job_post_id, from_status, to_status, changed_at, actor_id, actor_role, reason
+) VALUES (
+  ?, ?, ?, ?, ?, ?, ?
+)
+RETURNING *;
+
+-- name: ListJobPostStatusChanges :many
+SELECT * FROM job_post_status_changes
+WHERE job_post_id = ?
+ORDER BY id;
+
+-- name: GetLatestJobPostStatusChange :one
+SELECT * FROM job_post_status_changes
+WHERE job_post_id = ?
+ORDER BY id DESC LIMIT 1;
\ No newline at end of file
@@ -2,28 +2,120 @@
 SELECT * FROM job_posts
 ORDER BY created_at DESC;
 
--- name: GetJobPost :one
+-- name: ListExpiredJobPosts :many
 SELECT * FROM job_posts
-WHERE id = ? LIMIT 1;
+WHERE status = 'published' AND expires_at IS NOT NULL AND expires_at <= ?
+ORDER BY expires_at;
 
--- name: ListJobPostsByAccount :many
+-- name: GetJobPost :one
 SELECT * FROM job_posts
-WHERE employer_id = ?
-ORDER BY created_at DESC;
+WHERE id = ? LIMIT 1;
 
 -- name: CreateJobPost :one
 INSERT INTO job_posts (
-  title, content, created_at, status, employer_id
+  title, content, created_at, status, employer_id,
+  employment_type, weekly_hours, work_mode, city, postal_code,
+  salary_min, salary_max, salary_currency, salary_period,
+  physical_demands, flexible_schedule, accessible_workplace
 ) VALUES (
-  ?, ?, ?, ?, ?
+  ?, ?, ?, ?, ?,
+  ?, ?, ?, ?, ?,
+  ?, ?, ?, ?,
+  ?, ?, ?
 )
 RETURNING *;
 
 -- name: UpdateJobPost :exec
 UPDATE job_posts
-set title = ?, content = ?, status = ?
+set title = ?, content = ?, expires_at = ?,
+  employment_type = ?, weekly_hours = ?, work_mode = ?, city = ?, postal_code = ?,
+  salary_min = ?, salary_max = ?, salary_currency = ?, salary_period = ?,
+  physical_demands = ?, flexible_schedule = ?, accessible_workplace = ?
 WHERE id = ?;
 
+-- name: UpdateJobPostStatus :execrows
+UPDATE job_posts
+set status = ?, published_at = ?
+WHERE id = ? AND status = sqlc.arg(from_status);
+
 -- name: DeleteJobPost :exec
 DELETE FROM job_posts
-WHERE id = ?;
\ No newline at end of file
+WHERE id = ?;
+-- name: GetPublishedJobPost :one
+SELECT sqlc.embed(job_posts), employers.name AS employer_name
+FROM job_posts
+JOIN employers ON employers.id = job_posts.employer_id
+WHERE job_posts.id = ? AND job_posts.status = 'published'
+LIMIT 1;
+
+-- name: ListJobPostsNewestFirst :many
+SELECT * FROM job_posts
+WHERE status = COALESCE(sqlc.narg(status), status)
+  AND employer_id = COALESCE(sqlc.narg(employer_id), employer_id)
+  AND created_at >= COALESCE(sqlc.narg(created_after), created_at)
+  AND created_at <= COALESCE(sqlc.narg(created_before), created_at)
+  AND (created_at, id) < (sqlc.arg(cursor_key), sqlc.arg(cursor_id))
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(limit);
+
+-- name: ListJobPostsOldestFirst :many
+SELECT * FROM job_posts
+WHERE status = COALESCE(sqlc.narg(status), status)
+  AND employer_id = COALESCE(sqlc.narg(employer_id), employer_id)
+  AND created_at >= COALESCE(sqlc.narg(created_after), created_at)
+  AND created_at <= COALESCE(sqlc.narg(created_before), created_at)
+  AND (created_at, id) > (sqlc.arg(cursor_key), sqlc.arg(cursor_id))
+ORDER BY created_at, id
+LIMIT sqlc.arg(limit);
+
+-- name: ListPublishedJobPostsNewestFirst :many
+SELECT sqlc.embed(job_posts), employers.name AS employer_name
+FROM job_posts
+JOIN employers ON employers.id = job_posts.employer_id
+WHERE job_posts.status = 'published'
+  AND job_posts.employer_id = COALESCE(sqlc.narg(employer_id), job_posts.employer_id)
+  AND job_posts.published_at >= COALESCE(sqlc.narg(published_after), job_posts.published_at)
+  AND job_posts.published_at <= COALESCE(sqlc.narg(published_before), job_posts.published_at)
+  AND (sqlc.narg(employment_type) IS NULL OR job_posts.employment_type = sqlc.narg(employment_type))
+  AND (sqlc.narg(work_mode) IS NULL OR job_posts.work_mode = sqlc.narg(work_mode))
+  AND (sqlc.narg(physical_demands) IS NULL OR job_posts.physical_demands = sqlc.narg(physical_demands))
+  AND (sqlc.narg(city) IS NULL OR job_posts.city = sqlc.narg(city) COLLATE NOCASE)
+  AND (sqlc.narg(postal_code) IS NULL OR job_posts.postal_code = sqlc.narg(postal_code))
+  AND (sqlc.narg(max_weekly_hours) IS NULL OR job_posts.weekly_hours <= sqlc.narg(max_weekly_hours))
+  AND (sqlc.narg(salary_currency) IS NULL OR job_posts.salary_currency = sqlc.narg(salary_currency))
+  AND (sqlc.narg(min_salary) IS NULL OR IFNULL(job_posts.salary_max, job_posts.salary_min) >= sqlc.narg(min_salary))
+  AND (sqlc.narg(flexible_schedule) IS NULL OR job_posts.flexible_schedule = sqlc.narg(flexible_schedule))
+  AND (sqlc.narg(accessible_workplace) IS NULL OR job_posts.accessible_workplace = sqlc.narg(accessible_workplace))
+  AND (job_posts.published_at, job_posts.id) < (sqlc.arg(cursor_key), sqlc.arg(cursor_id))
+ORDER BY job_posts.published_at DESC, job_posts.id DESC
+LIMIT sqlc.arg(limit);
+
+-- name: ListPublishedJobPostsOldestFirst :many
+SELECT sqlc.embed(job_posts), employers.name AS employer_name
+FROM job_posts
+JOIN employers ON employers.id = job_posts.employer_id
+WHERE job_posts.status = 'published'
+  AND job_posts.employer_id = COALESCE(sqlc.narg(employer_id), job_posts.employer_id)
+  AND job_posts.published_at >= COALESCE(sqlc.narg(published_after), job_posts.published_at)
+  AND job_posts.published_at <= COALESCE(sqlc.narg(published_before), job_posts.published_at)
+  AND (sqlc.narg(employment_type) IS NULL OR job_posts.employment_type = sqlc.narg(employment_type))
+  AND (sqlc.narg(work_mode) IS NULL OR job_posts.work_mode = sqlc.narg(work_mode))
+  AND (sqlc.narg(physical_demands) IS NULL OR job_posts.physical_demands = sqlc.narg(physical_demands))
+  AND (sqlc.narg(city) IS NULL OR job_posts.city = sqlc.narg(city) COLLATE NOCASE)
+  AND (sqlc.narg(postal_code) IS NULL OR job_posts.postal_code = sqlc.narg(postal_code))
+  AND (sqlc.narg(max_weekly_hours) IS NULL OR job_posts.weekly_hours <= sqlc.narg(max_weekly_hours))
+  AND (sqlc.narg(salary_currency) IS NULL OR job_posts.salary_currency = sqlc.narg(salary_currency))
+  AND (sqlc.narg(min_salary) IS NULL OR IFNULL(job_posts.salary_max, job_posts.salary_min) >= sqlc.narg(min_salary))
+  AND (sqlc.narg(flexible_schedule) IS NULL OR job_posts.flexible_schedule = sqlc.narg(flexible_schedule))
+  AND (sqlc.narg(accessible_workplace) IS NULL OR job_posts.accessible_workplace = sqlc.narg(accessible_workplace))
+  AND (job_posts.published_at, job_posts.id) > (sqlc.arg(cursor_key), sqlc.arg(cursor_id))
+ORDER BY job_posts.published_at, job_posts.id
+LIMIT sqlc.arg(limit);
+
+-- name: NumEmployerJobPosts :one
+SELECT COUNT(*) FROM job_posts
+WHERE employer_id = ?;
+
+-- name: NumJobPostsByStatus :many
+SELECT status, COUNT(*) AS count FROM job_posts
+GROUP BY status;
@@ -0,0 +1,20 @@
+-- name: SearchPublishedJobPosts :many
+SELECT sqlc.embed(job_posts), employers.name AS employer_name,
+  highlight(job_posts_fts, 0, char(2), char(3)) AS title_highlight,
+  snippet(job_posts_fts, 1, char(2), char(3), '…', 24) AS content_snippet
+FROM job_posts_fts
+JOIN job_posts ON job_posts.id = job_posts_fts.rowid
+JOIN employers ON employers.id = job_posts.employer_id
+WHERE job_posts_fts MATCH sqlc.arg(query) AND job_posts.status = 'published'
+  AND (sqlc.narg(employment_type) IS NULL OR job_posts.employment_type = sqlc.narg(employment_type))
+  AND (sqlc.narg(work_mode) IS NULL OR job_posts.work_mode = sqlc.narg(work_mode))
+  AND (sqlc.narg(physical_demands) IS NULL OR job_posts.physical_demands = sqlc.narg(physical_demands))
+  AND (sqlc.narg(city) IS NULL OR job_posts.city = sqlc.narg(city) COLLATE NOCASE)
+  AND (sqlc.narg(postal_code) IS NULL OR job_posts.postal_code = sqlc.narg(postal_code))
+  AND (sqlc.narg(max_weekly_hours) IS NULL OR job_posts.weekly_hours <= sqlc.narg(max_weekly_hours))
+  AND (sqlc.narg(salary_currency) IS NULL OR job_posts.salary_currency = sqlc.narg(salary_currency))
+  AND (sqlc.narg(min_salary) IS NULL OR IFNULL(job_posts.salary_max, job_posts.salary_min) >= sqlc.narg(min_salary))
+  AND (sqlc.narg(flexible_schedule) IS NULL OR job_posts.flexible_schedule = sqlc.narg(flexible_schedule))
+  AND (sqlc.narg(accessible_workplace) IS NULL OR job_posts.accessible_workplace = sqlc.narg(accessible_workplace))
+ORDER BY bm25(job_posts_fts, 10.0, 1.0, 5.0), job_posts.id
+LIMIT sqlc.arg(limit) OFFSET sqlc.arg(offset);
@@ -0,0 +1,32 @@
+-- name: GetLoginFailure :one
+SELECT * FROM login_failures
+WHERE role = ? AND email = ? LIMIT 1;
+
+-- name: RecordLoginFailure :one
+INSERT INTO login_failures (
+  role, email, failed_attempts, last_failed_at
+) VALUES (
+  ?, ?, 1, ?
+)
+ON CONFLICT (role, email) DO UPDATE SET
+  failed_attempts = login_failures.failed_attempts + 1,
+  last_failed_at = excluded.last_failed_at
+RETURNING *;
+
+-- name: LockLogin :exec
+UPDATE login_failures
+SET locked_until = ?
+WHERE role = ? AND email = ?;
+
+-- name: DeleteLoginFailure :execrows
+DELETE FROM login_failures
+WHERE role = ? AND email = ?;
+
+-- name: ListLockedLogins :many
+SELECT * FROM login_failures
+WHERE locked_until > ?
+ORDER BY locked_until DESC, role, email;
+
+-- name: DeleteStaleLoginFailures :execrows
+DELETE FROM login_failures
+WHERE last_failed_at <= sqlc.arg(failed_before) AND (locked_until IS NULL OR locked_until <= sqlc.arg(now));
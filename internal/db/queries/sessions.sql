@@ -0,0 +1,42 @@
+-- name: CreateSession :one
+INSERT INTO sessions (
+  account_id, role, refresh_token_hash, created_at, expires_at
+) VALUES (
+  ?, ?, ?, ?, ?
+)
+RETURNING *;
+
+-- name: GetSession :one
+SELECT * FROM sessions
+WHERE id = ? LIMIT 1;
+
+-- name: GetSessionByRefreshTokenHash :one
+SELECT * FROM sessions
+WHERE refresh_token_hash = ? LIMIT 1;
+
+-- name: RotateSessionRefreshToken :execrows
+UPDATE sessions
+SET refresh_token_hash = sqlc.arg(new_refresh_token_hash), expires_at = sqlc.arg(expires_at)
+WHERE id = sqlc.arg(id) AND refresh_token_hash = sqlc.arg(refresh_token_hash);
+
+-- name: DeleteSession :exec
+DELETE FROM sessions
+WHERE id = ?;
+
+-- name: DeleteAccountSessions :exec
+DELETE FROM sessions
+WHERE account_id = ? AND role = ?;
+
+-- name: DeleteOtherAccountSessions :exec
+DELETE FROM sessions
+WHERE account_id = ? AND role = ? AND id != sqlc.arg(keep_id);
+
+-- name: DeleteEmployerSessions :exec
+DELETE FROM sessions
+WHERE role = ? AND account_id IN (
+  SELECT id FROM employer_accounts WHERE employer_id = ?
+);
+
+-- name: DeleteExpiredSessions :execrows
+DELETE FROM sessions
+WHERE expires_at <= ?;
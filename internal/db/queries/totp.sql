@@ -0,0 +1,61 @@
+-- name: CreateTOTPFactor :one
+INSERT INTO totp_factors (
+  account_id, role, secret, created_at
+) VALUES (
+  ?, ?, ?, ?
+)
+ON CONFLICT (account_id, role) DO UPDATE SET
+  secret = excluded.secret,
+  created_at = excluded.created_at,
+  last_used_step = 0
+WHERE totp_factors.confirmed_at IS NULL
+RETURNING *;
+
+-- name: GetTOTPFactor :one
+SELECT * FROM totp_factors
+WHERE account_id = ? AND role = ? LIMIT 1;
+
+-- name: ConfirmTOTPFactor :execrows
+UPDATE totp_factors
+SET confirmed_at = sqlc.arg(confirmed_at), last_used_step = sqlc.arg(step)
+WHERE account_id = sqlc.arg(account_id) AND role = sqlc.arg(role) AND confirmed_at IS NULL;
+
+-- name: UseTOTPStep :execrows
+UPDATE totp_factors
+SET last_used_step = sqlc.arg(step)
+WHERE account_id = sqlc.arg(account_id) AND role = sqlc.arg(role) AND last_used_step < sqlc.arg(step);
+
+-- name: DeleteAccountTOTPFactor :execrows
+DELETE FROM totp_factors
+WHERE account_id = ? AND role = ?;
+
+-- name: DeleteEmployerTOTPFactors :exec
+DELETE FROM totp_factors
+WHERE role = ? AND account_id IN (
+  SELECT id FROM employer_accounts WHERE employer_id = ?
+);
+
+-- name: CreateRecoveryCode :exec
+INSERT INTO recovery_codes (
+  account_id, role, code_hash
+) VALUES (
+  ?, ?, ?
+);
+
+-- name: UseRecoveryCode :execrows
+DELETE FROM recovery_codes
+WHERE account_id = ? AND role = ? AND code_hash = ?;
+
+-- name: CountRecoveryCodes :one
+SELECT COUNT(*) FROM recovery_codes
+WHERE account_id = ? AND role = ?;
+
+-- name: DeleteAccountRecoveryCodes :exec
+DELETE FROM recovery_codes
+WHERE account_id = ? AND role = ?;
+
+-- name: DeleteEmployerRecoveryCodes :exec
+DELETE FROM recovery_codes
+WHERE role = ? AND account_id IN (
+  SELECT id FROM employer_accounts WHERE employer_id = ?
+);
@@ -1,5 +0,0 @@
-CREATE TABLE IF NOT EXISTS admin_accounts (
-    id INTEGER PRIMARY KEY AUTOINCREMENT,
-    email TEXT NOT NULL UNIQUE,
-	password_hash TEXT NOT NULL
-);
\ No newline at end of file
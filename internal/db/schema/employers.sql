@@ -1,4 +0,0 @@
-CREATE TABLE IF NOT EXISTS employers (
-    id INTEGER PRIMARY KEY AUTOINCREMENT,
-    name TEXT NOT NULL
-);
@@ -0,0 +1,182 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: sessions.sql
+
+package db
+
+import (
+	"context"
+)
+
+const createSession = `-- name: CreateSession :one
+INSERT INTO sessions (
+  account_id, role, refresh_token_hash, created_at, expires_at
+) VALUES (
+  ?, ?, ?, ?, ?
+)
+RETURNING id, account_id, role, refresh_token_hash, created_at, expires_at
+`
+
+type CreateSessionParams struct {
+	AccountID        int64
+	Role             int64
+	RefreshTokenHash string
+	CreatedAt        string
+	ExpiresAt        string
+}
+
+func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error) {
+	row := q.db.QueryRowContext(ctx, createSession,
+		arg.AccountID,
+		arg.Role,
+		arg.RefreshTokenHash,
+		arg.CreatedAt,
+		arg.ExpiresAt,
+	)
+	var i Session
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.Role,
+		&i.RefreshTokenHash,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
+const deleteAccountSessions = `-- name: DeleteAccountSessions :exec
+DELETE FROM sessions
+WHERE account_id = ? AND role = ?
+`
+
+type DeleteAccountSessionsParams struct {
+	AccountID int64
+	Role      int64
+}
+
+func (q *Queries) DeleteAccountSessions(ctx context.Context, arg DeleteAccountSessionsParams) error {
+	_, err := q.db.ExecContext(ctx, deleteAccountSessions, arg.AccountID, arg.Role)
+	return err
+}
+
+const deleteEmployerSessions = `-- name: DeleteEmployerSessions :exec
+DELETE FROM sessions
+WHERE role = ? AND account_id IN (
+  SELECT id FROM employer_accounts WHERE employer_id = ?
+)
+`
+
+type DeleteEmployerSessionsParams struct {
+	Role       int64
+	EmployerID int64
+}
+
+func (q *Queries) DeleteEmployerSessions(ctx context.Context, arg DeleteEmployerSessionsParams) error {
+	_, err := q.db.ExecContext(ctx, deleteEmployerSessions, arg.Role, arg.EmployerID)
+	return err
+}
+
+const deleteExpiredSessions = `-- name: DeleteExpiredSessions :execrows
+DELETE FROM sessions
+WHERE expires_at <= ?
+`
+
+func (q *Queries) DeleteExpiredSessions(ctx context.Context, expiresAt string) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteExpiredSessions, expiresAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteOtherAccountSessions = `-- name: DeleteOtherAccountSessions :exec
+DELETE FROM sessions
+WHERE account_id = ? AND role = ? AND id != ?
+`
+
+type DeleteOtherAccountSessionsParams struct {
+	AccountID int64
+	Role      int64
+	KeepID    int64
+}
+
+func (q *Queries) DeleteOtherAccountSessions(ctx context.Context, arg DeleteOtherAccountSessionsParams) error {
+	_, err := q.db.ExecContext(ctx, deleteOtherAccountSessions, arg.AccountID, arg.Role, arg.KeepID)
+	return err
+}
+
+const deleteSession = `-- name: DeleteSession :exec
+DELETE FROM sessions
+WHERE id = ?
+`
+
+func (q *Queries) DeleteSession(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, deleteSession, id)
+	return err
+}
+
+const getSession = `-- name: GetSession :one
+SELECT id, account_id, role, refresh_token_hash, created_at, expires_at FROM sessions
+WHERE id = ? LIMIT 1
+`
+
+func (q *Queries) GetSession(ctx context.Context, id int64) (Session, error) {
+	row := q.db.QueryRowContext(ctx, getSession, id)
+	var i Session
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.Role,
+		&i.RefreshTokenHash,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
+const getSessionByRefreshTokenHash = `-- name: GetSessionByRefreshTokenHash :one
+SELECT id, account_id, role, refresh_token_hash, created_at, expires_at FROM sessions
+WHERE refresh_token_hash = ? LIMIT 1
+`
+
+func (q *Queries) GetSessionByRefreshTokenHash(ctx context.Context, refreshTokenHash string) (Session, error) {
+	row := q.db.QueryRowContext(ctx, getSessionByRefreshTokenHash, refreshTokenHash)
+	var i Session
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.Role,
+		&i.RefreshTokenHash,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
+const rotateSessionRefreshToken = `-- name: RotateSessionRefreshToken :execrows
+UPDATE sessions
+SET refresh_token_hash = ?, expires_at = ?
+WHERE id = ? AND refresh_token_hash = ?
+`
+
+type RotateSessionRefreshTokenParams struct {
+	NewRefreshTokenHash string
+	ExpiresAt           string
+	ID                  int64
+	RefreshTokenHash    string
+}
+
+func (q *Queries) RotateSessionRefreshToken(ctx context.Context, arg RotateSessionRefreshTokenParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, rotateSessionRefreshToken,
+		arg.NewRefreshTokenHash,
+		arg.ExpiresAt,
+		arg.ID,
+		arg.RefreshTokenHash,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,34 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// ExecTx runs fn with queries bound to a new transaction.
+// The transaction is committed if fn returns nil, and rolled back otherwise.
+func (q *Queries) ExecTx(ctx context.Context, fn func(*Queries) error) error {
+	database := q.DB()
+	withTx := q.WithTx
+	if handle, ok := q.db.(postgresDB); ok {
+		withTx = func(tx *sql.Tx) *Queries {
+			return New(postgresDB{db: tx, queries: handle.queries})
+		}
+	}
+	if database == nil {
+		return errors.New("transactions are only supported on a database handle")
+	}
+	tx, err := database.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(withTx(tx)); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("%w (rollback failed: %s)", err, rbErr)
+		}
+		return err
+	}
+	return tx.Commit()
+}
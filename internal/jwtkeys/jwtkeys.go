@@ -0,0 +1,216 @@
+// Package jwtkeys manages the secrets used to sign and verify the JWTs handed out at login.
+//
+// Keys are stored in a JSON file shared by every instance of the server. Tokens are signed with the
+// active key and carry its id in their kid header, so they can be verified as long as their key is in the file.
+// This lets keys be rotated without logging everyone out: a new key is added and activated, and the
+// previous one is kept until the tokens it signed have expired.
+package jwtkeys
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// SecretSize is the size in bytes of generated secrets, and the minimum size of loaded ones.
+const SecretSize = 32
+
+// Key is a secret used to sign JWTs, identified by the kid header of the tokens it signs.
+type Key struct {
+	ID        string `json:"kid"`
+	Secret    []byte `json:"secret"`
+	CreatedAt string `json:"created_at"`
+}
+
+// File is the content of a key file.
+// Tokens are signed with the Active key, and verified with any of the Keys.
+type File struct {
+	Active string `json:"active"`
+	Keys   []Key  `json:"keys"`
+}
+
+// NewKey generates a key with a random id and secret.
+func NewKey() (Key, error) {
+	secret := make([]byte, SecretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return Key{}, fmt.Errorf("failed to generate JWT secret: %w", err)
+	}
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return Key{}, fmt.Errorf("failed to generate key id: %w", err)
+	}
+	return Key{
+		ID:        hex.EncodeToString(id),
+		Secret:    secret,
+		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+	}, nil
+}
+
+// Generate returns a File holding a single new key, which is active.
+func Generate() (File, error) {
+	key, err := NewKey()
+	if err != nil {
+		return File{}, err
+	}
+	return File{Active: key.ID, Keys: []Key{key}}, nil
+}
+
+// Validate checks that the active key is part of the file, and that key ids are unique and secrets long enough.
+func (f File) Validate() error {
+	if len(f.Keys) == 0 {
+		return errors.New("no keys")
+	}
+	seen := make(map[string]bool, len(f.Keys))
+	for _, key := range f.Keys {
+		if key.ID == "" {
+			return errors.New("key without kid")
+		}
+		if seen[key.ID] {
+			return fmt.Errorf("duplicate kid %q", key.ID)
+		}
+		seen[key.ID] = true
+		if len(key.Secret) < SecretSize {
+			return fmt.Errorf("secret of key %q is shorter than %d bytes", key.ID, SecretSize)
+		}
+	}
+	if !seen[f.Active] {
+		return fmt.Errorf("active key %q not found", f.Active)
+	}
+	return nil
+}
+
+// Key returns the key with the given id.
+func (f File) Key(id string) (Key, bool) {
+	for _, key := range f.Keys {
+		if key.ID == id {
+			return key, true
+		}
+	}
+	return Key{}, false
+}
+
+// Add adds a key to the file, and makes it the active key if activate is true.
+// Adding a key without activating it lets every instance learn about it before it is used to sign tokens.
+func (f *File) Add(key Key, activate bool) {
+	f.Keys = append(f.Keys, key)
+	if activate {
+		f.Active = key.ID
+	}
+}
+
+// Activate makes the key with the given id the one new tokens are signed with.
+func (f *File) Activate(id string) error {
+	if _, ok := f.Key(id); !ok {
+		return fmt.Errorf("key %q not found", id)
+	}
+	f.Active = id
+	return nil
+}
+
+// Prune removes the oldest keys so that at most keep keys are left. The active key is always kept.
+func (f *File) Prune(keep int) {
+	if keep < 1 {
+		keep = 1
+	}
+	others := keep - 1
+	var kept []Key
+	for i := len(f.Keys) - 1; i >= 0; i-- {
+		key := f.Keys[i]
+		if key.ID == f.Active {
+			kept = append([]Key{key}, kept...)
+		} else if others > 0 {
+			kept = append([]Key{key}, kept...)
+			others--
+		}
+	}
+	f.Keys = kept
+}
+
+// ReadFile reads and validates a key file.
+func ReadFile(path string) (File, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return File{}, fmt.Errorf("cannot read JWT keys file: %w", err)
+	}
+	var f File
+	if err := json.Unmarshal(b, &f); err != nil {
+		return File{}, fmt.Errorf("cannot unmarshal JWT keys file: %w", err)
+	}
+	if err := f.Validate(); err != nil {
+		return File{}, fmt.Errorf("invalid JWT keys file: %w", err)
+	}
+	return f, nil
+}
+
+// WriteFile validates f and writes it to path, readable by its owner only.
+// The file is replaced atomically so that servers reloading it never see a partial write.
+func WriteFile(path string, f File) error {
+	if err := f.Validate(); err != nil {
+		return fmt.Errorf("invalid JWT keys: %w", err)
+	}
+	b, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("cannot write JWT keys file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return fmt.Errorf("cannot write JWT keys file: %w", err)
+	}
+	if _, err := tmp.Write(append(b, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("cannot write JWT keys file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("cannot write JWT keys file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("cannot write JWT keys file: %w", err)
+	}
+	return nil
+}
+
+// Keyring holds the keys a server signs and verifies tokens with.
+// It is safe for concurrent use, and its keys can be replaced while the server runs.
+type Keyring struct {
+	mu   sync.RWMutex
+	file File
+}
+
+// NewKeyring returns a Keyring holding the keys of f, which must be valid.
+func NewKeyring(f File) *Keyring {
+	return &Keyring{file: f}
+}
+
+// SigningKey returns the key new tokens are signed with.
+func (k *Keyring) SigningKey() Key {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	key, _ := k.file.Key(k.file.Active)
+	return key
+}
+
+// VerificationKey returns the secret of the key with the given id.
+func (k *Keyring) VerificationKey(id string) ([]byte, bool) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	key, ok := k.file.Key(id)
+	return key.Secret, ok
+}
+
+// Set replaces the keys of the keyring with the keys of f, which must be valid.
+func (k *Keyring) Set(f File) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.file = f
+}
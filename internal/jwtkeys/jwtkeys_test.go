@@ -0,0 +1,108 @@
+package jwtkeys_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gruyaume/lesvieux/internal/jwtkeys"
+)
+
+func TestWriteReadFileSuccess(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jwt_keys.json")
+	f, err := jwtkeys.Generate()
+	if err != nil {
+		t.Fatalf("couldn't generate keys: %s", err)
+	}
+	if err := jwtkeys.WriteFile(path, f); err != nil {
+		t.Fatalf("couldn't write keys: %s", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("couldn't stat keys file: %s", err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Fatalf("expected mode 0600, got %o", info.Mode().Perm())
+	}
+	read, err := jwtkeys.ReadFile(path)
+	if err != nil {
+		t.Fatalf("couldn't read keys: %s", err)
+	}
+	if read.Active != f.Active || len(read.Keys) != 1 || string(read.Keys[0].Secret) != string(f.Keys[0].Secret) {
+		t.Fatalf("expected %v, got %v", f, read)
+	}
+}
+
+func TestValidateFail(t *testing.T) {
+	key, err := jwtkeys.NewKey()
+	if err != nil {
+		t.Fatalf("couldn't generate key: %s", err)
+	}
+	cases := []struct {
+		Name          string
+		File          jwtkeys.File
+		ExpectedError string
+	}{
+		{"no keys", jwtkeys.File{}, "no keys"},
+		{"missing active key", jwtkeys.File{Active: "other", Keys: []jwtkeys.Key{key}}, "active key \"other\" not found"},
+		{"duplicate kid", jwtkeys.File{Active: key.ID, Keys: []jwtkeys.Key{key, key}}, "duplicate kid"},
+		{"short secret", jwtkeys.File{Active: "short", Keys: []jwtkeys.Key{{ID: "short", Secret: []byte("secret")}}}, "shorter than 32 bytes"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			err := tc.File.Validate()
+			if err == nil {
+				t.Fatalf("Expected error, got nil")
+			}
+			if !strings.Contains(err.Error(), tc.ExpectedError) {
+				t.Errorf("Expected error: %s, got: %s", tc.ExpectedError, err)
+			}
+		})
+	}
+}
+
+func TestRotation(t *testing.T) {
+	f, err := jwtkeys.Generate()
+	if err != nil {
+		t.Fatalf("couldn't generate keys: %s", err)
+	}
+	first := f.Active
+	keyring := jwtkeys.NewKeyring(f)
+
+	staged, err := jwtkeys.NewKey()
+	if err != nil {
+		t.Fatalf("couldn't generate key: %s", err)
+	}
+	f.Add(staged, false)
+	keyring.Set(f)
+	if keyring.SigningKey().ID != first {
+		t.Fatalf("staged key should not be used for signing")
+	}
+	if _, ok := keyring.VerificationKey(staged.ID); !ok {
+		t.Fatalf("staged key should be used for verification")
+	}
+
+	if err := f.Activate(staged.ID); err != nil {
+		t.Fatalf("couldn't activate key: %s", err)
+	}
+	if err := f.Activate("unknown"); err == nil {
+		t.Fatalf("activating an unknown key should fail")
+	}
+	third, err := jwtkeys.NewKey()
+	if err != nil {
+		t.Fatalf("couldn't generate key: %s", err)
+	}
+	f.Add(third, false)
+	f.Prune(2)
+	keyring.Set(f)
+	if keyring.SigningKey().ID != staged.ID {
+		t.Fatalf("expected signing key %q, got %q", staged.ID, keyring.SigningKey().ID)
+	}
+	if _, ok := keyring.VerificationKey(first); ok {
+		t.Fatalf("oldest key should have been pruned")
+	}
+	if _, ok := keyring.VerificationKey(third.ID); !ok {
+		t.Fatalf("newest key should have been kept")
+	}
+}
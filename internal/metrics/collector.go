@@ -0,0 +1,136 @@
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/gruyaume/lesvieux/internal/db"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// countsCacheTTL is how long the counts of the database are reused between scrapes,
+	// so that several Prometheus servers scraping at once only query the database once.
+	countsCacheTTL = 5 * time.Second
+	// countsQueryTimeout bounds the time a scrape waits for the database.
+	countsQueryTimeout = 5 * time.Second
+)
+
+// counts are the numbers of records of the database that are reported as metrics.
+type counts struct {
+	jobPostsByStatus map[string]int64
+	employers        int64
+	accountsByRole   map[string]int64
+}
+
+// countsCollector is a Prometheus collector reporting the counts of the database, which it queries when it is scraped.
+// Counts are cached for countsCacheTTL. When the database can't be queried, the last counts are reported.
+type countsCollector struct {
+	ctx     context.Context
+	queries *db.Queries
+
+	jobPosts         *prometheus.Desc
+	jobPostsByStatus *prometheus.Desc
+	employers        *prometheus.Desc
+	accounts         *prometheus.Desc
+
+	mu          sync.Mutex
+	counts      *counts
+	collectedAt time.Time
+}
+
+// newCountsCollector returns a collector querying the database until ctx is done.
+func newCountsCollector(ctx context.Context, queries *db.Queries) *countsCollector {
+	return &countsCollector{
+		ctx:              ctx,
+		queries:          queries,
+		jobPosts:         prometheus.NewDesc("job_posts_total", "Total number of job posts", nil, nil),
+		jobPostsByStatus: prometheus.NewDesc("job_posts_by_status", "Number of job posts in each status", []string{"status"}, nil),
+		employers:        prometheus.NewDesc("employers_total", "Total number of employers", nil, nil),
+		accounts:         prometheus.NewDesc("accounts_total", "Number of accounts of each role", []string{"role"}, nil),
+	}
+}
+
+func (c *countsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.jobPosts
+	ch <- c.jobPostsByStatus
+	ch <- c.employers
+	ch <- c.accounts
+}
+
+func (c *countsCollector) Collect(ch chan<- prometheus.Metric) {
+	counts := c.currentCounts()
+	if counts == nil {
+		return
+	}
+	var jobPosts int64
+	for status, count := range counts.jobPostsByStatus {
+		jobPosts += count
+		ch <- prometheus.MustNewConstMetric(c.jobPostsByStatus, prometheus.GaugeValue, float64(count), status)
+	}
+	ch <- prometheus.MustNewConstMetric(c.jobPosts, prometheus.GaugeValue, float64(jobPosts))
+	ch <- prometheus.MustNewConstMetric(c.employers, prometheus.GaugeValue, float64(counts.employers))
+	for role, count := range counts.accountsByRole {
+		ch <- prometheus.MustNewConstMetric(c.accounts, prometheus.GaugeValue, float64(count), role)
+	}
+}
+
+// currentCounts returns the cached counts, queried again when they are older than countsCacheTTL.
+// It returns nil when the database was never queried successfully.
+func (c *countsCollector) currentCounts() *counts {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.counts != nil && time.Since(c.collectedAt) < countsCacheTTL {
+		return c.counts
+	}
+	if c.ctx.Err() != nil {
+		return c.counts
+	}
+	ctx, cancel := context.WithTimeout(c.ctx, countsQueryTimeout)
+	defer cancel()
+	counts, err := queryCounts(ctx, c.queries)
+	if err != nil {
+		slog.Error("couldn't count records for metrics", "error", err)
+		return c.counts
+	}
+	c.counts = counts
+	c.collectedAt = time.Now()
+	return c.counts
+}
+
+// queryCounts counts the records of the database with aggregate queries.
+func queryCounts(ctx context.Context, queries *db.Queries) (*counts, error) {
+	jobPosts, err := queries.NumJobPostsByStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+	employers, err := queries.NumEmployers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c := &counts{
+		jobPostsByStatus: make(map[string]int64, len(jobPosts)),
+		employers:        employers,
+		accountsByRole:   make(map[string]int64, 3),
+	}
+	// Statuses without job posts are reported as 0, rather than left out.
+	for _, status := range JobPostStatuses {
+		c.jobPostsByStatus[status] = 0
+	}
+	for _, row := range jobPosts {
+		c.jobPostsByStatus[row.Status] = row.Count
+	}
+	accounts := map[string]func(context.Context) (int64, error){
+		RoleAdmin:     queries.NumAdminAccounts,
+		RoleEmployer:  queries.NumEmployerAccounts,
+		RoleApplicant: queries.NumApplicantAccounts,
+	}
+	for role, count := range accounts {
+		if c.accountsByRole[role], err = count(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
@@ -2,9 +2,7 @@ package metrics
 
 import (
 	"context"
-	"log"
 	"net/http"
-	"time"
 
 	"github.com/gruyaume/lesvieux/internal/db"
 	"github.com/prometheus/client_golang/prometheus"
@@ -12,45 +10,70 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
+// The roles of the accounts, as they are labeled in metrics.
+const (
+	RoleAdmin     = "admin"
+	RoleEmployer  = "employer"
+	RoleApplicant = "applicant"
+)
+
+// JobPostStatuses are the statuses of job posts, which job_posts_by_status reports even when no job post has them.
+var JobPostStatuses = []string{"draft", "pending_review", "published", "rejected", "closed", "expired"}
+
+// The results of logins, as they are labeled in metrics.
+const (
+	LoginSucceeded = "succeeded"
+	LoginFailed    = "failed"
+)
+
+// The reasons logins are refused before their password is checked, as they are labeled in metrics.
+const (
+	ThrottledByIP      = "ip_rate_limit"
+	ThrottledByAccount = "account_rate_limit"
+	ThrottledByLockout = "lockout"
+)
+
 type PrometheusMetrics struct {
 	http.Handler
 	registry *prometheus.Registry
-	JobPosts prometheus.Gauge
+
+	Logins                prometheus.CounterVec
+	LoginsThrottled       prometheus.CounterVec
+	LoginLockouts         prometheus.CounterVec
+	ApplicationsSubmitted prometheus.Counter
 
 	RequestsTotal    prometheus.CounterVec
 	RequestsDuration prometheus.HistogramVec
 }
 
 // NewMetricsSubsystem returns the metrics endpoint HTTP handler and the Prometheus metrics collectors for the server and middleware.
-func NewMetricsSubsystem(db *db.Queries) *PrometheusMetrics {
+// The counts of the database are queried when the metrics are scraped, until ctx is done: the last counts are reported after that.
+func NewMetricsSubsystem(ctx context.Context, db *db.Queries) *PrometheusMetrics {
 	metricsBackend := newPrometheusMetrics()
+	metricsBackend.registry.MustRegister(newCountsCollector(ctx, db))
 	metricsBackend.Handler = promhttp.HandlerFor(metricsBackend.registry, promhttp.HandlerOpts{})
-	ticker := time.NewTicker(120 * time.Second)
-	go func() {
-		for ; ; <-ticker.C {
-			jobPosts, err := db.ListJobPosts(context.Background())
-			if err != nil {
-				log.Println("error listing job posts:", err)
-				continue
-			}
-			metricsBackend.GenerateMetrics(jobPosts)
-		}
-	}()
 	return metricsBackend
 }
 
-// newPrometheusMetrics reads the status of the database, calculates all of the values of the metrics,
-// registers these metrics to the prometheus registry, and returns the registry and the metrics.
+// newPrometheusMetrics registers the metrics the server and middleware record to the prometheus registry,
+// and returns the registry and the metrics.
 // The registry and metrics can be modified from this struct from anywhere in the codebase.
 func newPrometheusMetrics() *PrometheusMetrics {
 	m := &PrometheusMetrics{
 		registry: prometheus.NewRegistry(),
-		JobPosts: jobPostsMetric(),
+
+		Logins:                loginsMetric(),
+		LoginsThrottled:       loginsThrottledMetric(),
+		LoginLockouts:         loginLockoutsMetric(),
+		ApplicationsSubmitted: applicationsSubmittedMetric(),
 
 		RequestsTotal:    requestsTotalMetric(),
 		RequestsDuration: requestDurationMetric(),
 	}
-	m.registry.MustRegister(m.JobPosts)
+	m.registry.MustRegister(m.Logins)
+	m.registry.MustRegister(m.LoginsThrottled)
+	m.registry.MustRegister(m.LoginLockouts)
+	m.registry.MustRegister(m.ApplicationsSubmitted)
 
 	m.registry.MustRegister(m.RequestsTotal)
 	m.registry.MustRegister(m.RequestsDuration)
@@ -60,16 +83,56 @@ func newPrometheusMetrics() *PrometheusMetrics {
 	return m
 }
 
-// GenerateMetrics receives the live list of job posts to calculate the most recent values for the metrics
-// defined for prometheus
-func (pm *PrometheusMetrics) GenerateMetrics(jobPosts []db.JobPost) {
-	pm.JobPosts.Set(float64(len(jobPosts)))
+// RecordLogin counts a login attempt of an account of the given role, which succeeded or failed.
+func (pm *PrometheusMetrics) RecordLogin(role string, result string) {
+	pm.Logins.WithLabelValues(role, result).Inc()
+}
+
+func loginsMetric() prometheus.CounterVec {
+	metric := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "logins_total",
+			Help: "Tracks the login attempts of each role, by result.",
+		}, []string{"role", "result"},
+	)
+	return *metric
+}
+
+// RecordLoginThrottled counts a login attempt of the given role refused for the given reason,
+// without its password being checked.
+func (pm *PrometheusMetrics) RecordLoginThrottled(role string, reason string) {
+	pm.LoginsThrottled.WithLabelValues(role, reason).Inc()
+}
+
+// RecordLoginLockout counts a lockout of the logins of an email of the given role, after repeated failures.
+func (pm *PrometheusMetrics) RecordLoginLockout(role string) {
+	pm.LoginLockouts.WithLabelValues(role).Inc()
+}
+
+func loginsThrottledMetric() prometheus.CounterVec {
+	metric := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "logins_throttled_total",
+			Help: "Tracks the login attempts of each role refused by rate limits and lockouts, by reason.",
+		}, []string{"role", "reason"},
+	)
+	return *metric
+}
+
+func loginLockoutsMetric() prometheus.CounterVec {
+	metric := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "login_lockouts_total",
+			Help: "Tracks the lockouts of the logins of each role after repeated failures.",
+		}, []string{"role"},
+	)
+	return *metric
 }
 
-func jobPostsMetric() prometheus.Gauge {
-	metric := prometheus.NewGauge(prometheus.GaugeOpts{
-		Name: "job_posts_total",
-		Help: "Total number of job posts",
+func applicationsSubmittedMetric() prometheus.Counter {
+	metric := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "applications_submitted_total",
+		Help: "Tracks the applications submitted to job posts.",
 	})
 	return metric
 }
@@ -79,18 +142,22 @@ func requestsTotalMetric() prometheus.CounterVec {
 		prometheus.CounterOpts{
 			Name: "http_requests_total",
 			Help: "Tracks the number of HTTP requests.",
-		}, []string{"method", "code"},
+		}, []string{"route", "method", "code"},
 	)
 	return *metric
 }
 
+// requestDurationBuckets are the upper bounds, in seconds, of the buckets of request latencies.
+// Most API requests take a few milliseconds, so the buckets are finer below 100ms.
+var requestDurationBuckets = []float64{0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5}
+
 func requestDurationMetric() prometheus.HistogramVec {
 	metric := prometheus.NewHistogramVec(
 		prometheus.HistogramOpts{
 			Name:    "http_request_duration_seconds",
 			Help:    "Tracks the latencies for HTTP requests.",
-			Buckets: prometheus.ExponentialBuckets(0.1, 1.5, 5),
-		}, []string{"method", "code"},
+			Buckets: requestDurationBuckets,
+		}, []string{"route", "method", "code"},
 	)
 	return *metric
 }
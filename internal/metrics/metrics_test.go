@@ -22,7 +22,7 @@ func TestPrometheusHandler(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	m := metrics.NewMetricsSubsystem(dbQueries)
+	m := metrics.NewMetricsSubsystem(context.Background(), dbQueries)
 
 	request, err := http.NewRequest("GET", "/", nil)
 	if err != nil {
@@ -50,15 +50,21 @@ func TestMetrics(t *testing.T) {
 		t.Fatal(err)
 	}
 
+	employer, err := dbQueries.CreateEmployer(context.Background(), "my employer")
+	if err != nil {
+		t.Fatalf("couldn't create test employer: %s", err)
+	}
 	createJobPost1Params := db.CreateJobPostParams{
-		Title:     "my title 1",
-		Content:   "my content 1",
-		CreatedAt: "creation time 1",
+		Title:      "my title 1",
+		Content:    "my content 1",
+		CreatedAt:  "creation time 1",
+		EmployerID: employer.ID,
 	}
 	createJobPost2Params := db.CreateJobPostParams{
-		Title:     "my title 2",
-		Content:   "my content 2",
-		CreatedAt: "creation time 2",
+		Title:      "my title 2",
+		Content:    "my content 2",
+		CreatedAt:  "creation time 2",
+		EmployerID: employer.ID,
 	}
 	_, err = dbQueries.CreateJobPost(context.Background(), createJobPost1Params)
 	if err != nil {
@@ -69,12 +75,7 @@ func TestMetrics(t *testing.T) {
 		t.Fatalf("couldn't create test job post: %s", err)
 	}
 
-	m := metrics.NewMetricsSubsystem(dbQueries)
-	jobPosts, err := dbQueries.ListJobPosts(context.Background())
-	if err != nil {
-		t.Fatalf("couldn't list job posts: %s", err)
-	}
-	m.GenerateMetrics(jobPosts)
+	m := metrics.NewMetricsSubsystem(context.Background(), dbQueries)
 
 	request, _ := http.NewRequest("GET", "/", nil)
 	recorder := httptest.NewRecorder()
@@ -94,3 +95,74 @@ func TestMetrics(t *testing.T) {
 		}
 	}
 }
+
+// scrape returns the metrics of the subsystem, as Prometheus would read them.
+func scrape(m *metrics.PrometheusMetrics) string {
+	request, _ := http.NewRequest("GET", "/", nil)
+	recorder := httptest.NewRecorder()
+	m.Handler.ServeHTTP(recorder, request)
+	return recorder.Body.String()
+}
+
+// TestCountMetrics tests that the records of the database are counted when metrics are scraped, and cached between scrapes.
+func TestCountMetrics(t *testing.T) {
+	dbQueries, err := db.Initialize(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	employer, err := dbQueries.CreateEmployer(ctx, "my employer")
+	if err != nil {
+		t.Fatalf("couldn't create test employer: %s", err)
+	}
+	for _, status := range []string{"draft", "published", "published"} {
+		_, err := dbQueries.CreateJobPost(ctx, db.CreateJobPostParams{Title: "title", Content: "content", Status: status, EmployerID: employer.ID})
+		if err != nil {
+			t.Fatalf("couldn't create test job post: %s", err)
+		}
+	}
+	m := metrics.NewMetricsSubsystem(ctx, dbQueries)
+
+	body := scrape(m)
+	expected := []string{
+		`job_posts_total 3`,
+		`job_posts_by_status{status="draft"} 1`,
+		`job_posts_by_status{status="published"} 2`,
+		`job_posts_by_status{status="pending_review"} 0`,
+		`job_posts_by_status{status="expired"} 0`,
+		`employers_total 1`,
+		`accounts_total{role="admin"} 0`,
+		`accounts_total{role="employer"} 0`,
+		`accounts_total{role="applicant"} 0`,
+	}
+	for _, line := range expected {
+		if !strings.Contains(body, line) {
+			t.Errorf("expected %q in the metrics output", line)
+		}
+	}
+
+	if _, err := dbQueries.CreateEmployer(ctx, "other employer"); err != nil {
+		t.Fatalf("couldn't create test employer: %s", err)
+	}
+	if body := scrape(m); !strings.Contains(body, "employers_total 1") {
+		t.Errorf("expected the counts to be cached between scrapes")
+	}
+}
+
+// TestCountMetricsStopWithContext tests that the database isn't queried anymore once the context of the metrics is done.
+func TestCountMetricsStopWithContext(t *testing.T) {
+	dbQueries, err := db.Initialize(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	m := metrics.NewMetricsSubsystem(ctx, dbQueries)
+	body := scrape(m)
+	if strings.Contains(body, "employers_total") {
+		t.Errorf("expected no counts once the context is done")
+	}
+	if !strings.Contains(body, "go_goroutines") {
+		t.Errorf("expected the other metrics to be reported")
+	}
+}
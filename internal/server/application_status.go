@@ -0,0 +1,83 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gruyaume/lesvieux/internal/db"
+)
+
+const (
+	ApplicationStatusReceived  = "received"
+	ApplicationStatusReviewing = "reviewing"
+	ApplicationStatusInterview = "interview"
+	ApplicationStatusOffered   = "offered"
+	ApplicationStatusDeclined  = "declined"
+)
+
+// errApplicationStatusConflict is returned when an application changed status since it was read.
+var errApplicationStatusConflict = errors.New("application status was changed by someone else")
+
+// employerApplicationTransitions are the status changes an employer can make on the applications to its posts.
+// Applications move forward one step at a time, and can be declined until an offer is made.
+var employerApplicationTransitions = map[string][]string{
+	ApplicationStatusReceived:  {ApplicationStatusReviewing, ApplicationStatusDeclined},
+	ApplicationStatusReviewing: {ApplicationStatusInterview, ApplicationStatusDeclined},
+	ApplicationStatusInterview: {ApplicationStatusOffered, ApplicationStatusDeclined},
+}
+
+func isValidApplicationStatus(status string) bool {
+	switch status {
+	case ApplicationStatusReceived, ApplicationStatusReviewing, ApplicationStatusInterview,
+		ApplicationStatusOffered, ApplicationStatusDeclined:
+		return true
+	}
+	return false
+}
+
+// validateApplicationStatusTransition returns an error if an employer can't move an application from one status to another.
+func validateApplicationStatusTransition(from string, to string) error {
+	if !isValidApplicationStatus(to) {
+		return fmt.Errorf("invalid status %q", to)
+	}
+	for _, allowed := range employerApplicationTransitions[from] {
+		if allowed == to {
+			return nil
+		}
+	}
+	return fmt.Errorf("status can't change from %q to %q", from, to)
+}
+
+// transitionApplicationStatus applies a status change to an application, and records when it happened and who made it.
+// The transition must already be validated, and queries should be bound to a transaction.
+func transitionApplicationStatus(ctx context.Context, queries *db.Queries, application db.Application, to string, actor *jobPostActor) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+	updated, err := queries.UpdateApplicationStatus(ctx, db.UpdateApplicationStatusParams{
+		Status:     to,
+		UpdatedAt:  now,
+		ID:         application.ID,
+		FromStatus: application.Status,
+	})
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return errApplicationStatusConflict
+	}
+	return recordApplicationStatusChange(ctx, queries, application.ID, application.Status, to, now, actor)
+}
+
+func recordApplicationStatusChange(ctx context.Context, queries *db.Queries, applicationID int64, from string, to string, changedAt string, actor *jobPostActor) error {
+	_, err := queries.CreateApplicationStatusChange(ctx, db.CreateApplicationStatusChangeParams{
+		ApplicationID: applicationID,
+		FromStatus:    from,
+		ToStatus:      to,
+		ChangedAt:     changedAt,
+		ActorID:       sql.NullInt64{Int64: actor.ID, Valid: true},
+		ActorRole:     sql.NullInt64{Int64: actor.Role, Valid: true},
+	})
+	return err
+}
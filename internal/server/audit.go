@@ -0,0 +1,132 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gruyaume/lesvieux/internal/db"
+)
+
+// The actions recorded in the audit log, named <target type>.<verb>.
+const (
+	AuditActionAdminAccountCreate                  = "admin_account.create"
+	AuditActionAdminAccountDelete                  = "admin_account.delete"
+	AuditActionAdminAccountChangePassword          = "admin_account.change_password"
+	AuditActionAdminAccountResetMFA                = "admin_account.reset_mfa"
+	AuditActionAdminAccountEnrollMFA               = "admin_account.enroll_mfa"
+	AuditActionAdminAccountEnableMFA               = "admin_account.enable_mfa"
+	AuditActionAdminAccountDisableMFA              = "admin_account.disable_mfa"
+	AuditActionAdminAccountRegenerateRecoveryCodes = "admin_account.regenerate_recovery_codes"
+
+	AuditActionEmployerCreate = "employer.create"
+	AuditActionEmployerDelete = "employer.delete"
+
+	AuditActionEmployerAccountCreate                  = "employer_account.create"
+	AuditActionEmployerAccountDelete                  = "employer_account.delete"
+	AuditActionEmployerAccountChangePassword          = "employer_account.change_password"
+	AuditActionEmployerAccountResetMFA                = "employer_account.reset_mfa"
+	AuditActionEmployerAccountEnrollMFA               = "employer_account.enroll_mfa"
+	AuditActionEmployerAccountEnableMFA               = "employer_account.enable_mfa"
+	AuditActionEmployerAccountDisableMFA              = "employer_account.disable_mfa"
+	AuditActionEmployerAccountRegenerateRecoveryCodes = "employer_account.regenerate_recovery_codes"
+
+	AuditActionJobPostCreate       = "job_post.create"
+	AuditActionJobPostUpdate       = "job_post.update"
+	AuditActionJobPostDelete       = "job_post.delete"
+	AuditActionJobPostChangeStatus = "job_post.change_status"
+
+	AuditActionApplicationChangeStatus = "application.change_status"
+
+	AuditActionBackupCreate = "backup.create"
+
+	AuditActionLoginLockoutUnlock = "login_lockout.unlock"
+)
+
+// The types of the targets of audited actions.
+const (
+	AuditTargetAdminAccount    = "admin_account"
+	AuditTargetEmployer        = "employer"
+	AuditTargetEmployerAccount = "employer_account"
+	AuditTargetJobPost         = "job_post"
+	AuditTargetApplication     = "application"
+	AuditTargetBackup          = "backup"
+	AuditTargetLoginLockout    = "login_lockout"
+)
+
+// auditEvent is a privileged action to record in the audit log.
+// Before and After summarize the target around the action. They are stored as JSON, and must never hold secrets.
+type auditEvent struct {
+	Action     string
+	TargetType string
+	TargetID   int64
+	Before     any
+	After      any
+}
+
+// recordAuditEvent appends an event to the audit log, attributed to the account the request is authenticated as,
+// if any. queries should be bound to the transaction of the action, so that the action isn't applied without its event.
+func recordAuditEvent(ctx context.Context, queries *db.Queries, r *http.Request, event auditEvent) error {
+	params := db.CreateAuditEventParams{
+		OccurredAt: time.Now().UTC().Format(time.RFC3339),
+		Action:     event.Action,
+		TargetType: event.TargetType,
+		Ip:         clientIP(r),
+		UserAgent:  r.UserAgent(),
+	}
+	if actorID, ok := r.Context().Value(userIDKey).(int64); ok {
+		params.ActorID = sql.NullInt64{Int64: actorID, Valid: true}
+	}
+	if actorRole, ok := r.Context().Value(roleKey).(int64); ok {
+		params.ActorRole = sql.NullInt64{Int64: actorRole, Valid: true}
+	}
+	if event.TargetID != 0 {
+		params.TargetID = sql.NullInt64{Int64: event.TargetID, Valid: true}
+	}
+	var err error
+	if params.BeforeSummary, err = auditSummary(event.Before); err != nil {
+		return err
+	}
+	if params.AfterSummary, err = auditSummary(event.After); err != nil {
+		return err
+	}
+	_, err = queries.CreateAuditEvent(ctx, params)
+	return err
+}
+
+// jobPostStatusAuditEvent is the audit event of a job post moving to another status.
+func jobPostStatusAuditEvent(jobPost db.JobPost, to string, reason string) auditEvent {
+	after := map[string]any{"status": to}
+	if reason != "" {
+		after["reason"] = reason
+	}
+	return auditEvent{
+		Action:     AuditActionJobPostChangeStatus,
+		TargetType: AuditTargetJobPost,
+		TargetID:   jobPost.ID,
+		Before:     map[string]any{"status": jobPost.Status},
+		After:      after,
+	}
+}
+
+// jobPostAuditSummary summarizes a job post for the audit log, without its content.
+func jobPostAuditSummary(jobPost db.JobPost) map[string]any {
+	return map[string]any{
+		"title":       jobPost.Title,
+		"status":      jobPost.Status,
+		"employer_id": jobPost.EmployerID,
+	}
+}
+
+func auditSummary(summary any) (sql.NullString, error) {
+	if summary == nil {
+		return sql.NullString{}, nil
+	}
+	b, err := json.Marshal(summary)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: string(b), Valid: true}, nil
+}
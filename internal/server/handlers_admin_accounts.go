@@ -4,7 +4,6 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
-	"log"
 	"net/http"
 	"strconv"
 
@@ -35,12 +34,31 @@ type ChangeAdminAccountPasswordResponse struct {
 
 func ListAdminAccounts(env *HandlerConfig) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		accounts, err := env.DBQueries.ListAdminAccounts(context.Background())
+		page, err := parsePageRequest(r, "email", "-email")
 		if err != nil {
-			log.Println(err.Error())
+			writeError(w, http.StatusBadRequest, "%s", err)
+			return
+		}
+		var accounts []db.AdminAccount
+		if page.Descending() {
+			accounts, err = env.DBQueries.ListAdminAccountsDesc(context.Background(), db.ListAdminAccountsDescParams{
+				CursorKey: page.CursorKey(),
+				CursorID:  page.CursorID(),
+				Limit:     page.FetchLimit(),
+			})
+		} else {
+			accounts, err = env.DBQueries.ListAdminAccounts(context.Background(), db.ListAdminAccountsParams{
+				CursorKey: page.CursorKey(),
+				CursorID:  page.CursorID(),
+				Limit:     page.FetchLimit(),
+			})
+		}
+		if err != nil {
+			logError(r, "internal error", err)
 			writeError(w, http.StatusInternalServerError, "internal error")
 			return
 		}
+		accounts, nextCursor := paginate(page, accounts, func(a db.AdminAccount) (string, int64) { return a.Email, a.ID })
 		accountsResponse := make([]GetAdminAccountResponse, 0, len(accounts))
 		for i := range accounts {
 			accountsResponse = append(accountsResponse, GetAdminAccountResponse{
@@ -48,7 +66,7 @@ func ListAdminAccounts(env *HandlerConfig) http.HandlerFunc {
 				Email: accounts[i].Email,
 			})
 		}
-		err = writeJSON(w, accountsResponse)
+		err = writeJSONPage(w, accountsResponse, nextCursor)
 		if err != nil {
 			writeError(w, http.StatusInternalServerError, "internal error")
 			return
@@ -73,7 +91,7 @@ func GetAdminAccount(env *HandlerConfig) http.HandlerFunc {
 				writeError(w, http.StatusNotFound, "Admin Account not found")
 				return
 			}
-			log.Println(err.Error())
+			logError(r, "internal error", err)
 			writeError(w, http.StatusInternalServerError, "internal error")
 			return
 		}
@@ -92,7 +110,7 @@ func GetAdminAccount(env *HandlerConfig) http.HandlerFunc {
 
 func GetMyAdminAccount(env *HandlerConfig) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		claims, err := getClaimsFromAuthorizationHeader(r.Header.Get("Authorization"), env.JWTSecret)
+		claims, err := getClaimsFromAuthorizationHeader(r.Header.Get("Authorization"), env.JWTKeys)
 		if err != nil {
 			writeError(w, http.StatusUnauthorized, err.Error())
 			return
@@ -152,7 +170,7 @@ func CreateAdminAccount(env *HandlerConfig) http.HandlerFunc {
 
 		passwordHash, err := GeneratePasswordHash(account.Password)
 		if err != nil {
-			log.Println("Failed to generate password hash: " + err.Error())
+			logError(r, "couldn't generate password hash", err)
 			writeError(w, http.StatusInternalServerError, "internal error")
 			return
 		}
@@ -160,9 +178,22 @@ func CreateAdminAccount(env *HandlerConfig) http.HandlerFunc {
 			Email:        account.Email,
 			PasswordHash: passwordHash,
 		}
-		newAdminAccount, err := env.DBQueries.CreateAdminAccount(context.Background(), newAdminAccountParams)
+		var newAdminAccount db.AdminAccount
+		err = env.DBQueries.ExecTx(context.Background(), func(q *db.Queries) error {
+			var err error
+			newAdminAccount, err = q.CreateAdminAccount(context.Background(), newAdminAccountParams)
+			if err != nil {
+				return err
+			}
+			return recordAuditEvent(context.Background(), q, r, auditEvent{
+				Action:     AuditActionAdminAccountCreate,
+				TargetType: AuditTargetAdminAccount,
+				TargetID:   newAdminAccount.ID,
+				After:      map[string]any{"email": newAdminAccount.Email},
+			})
+		})
 		if err != nil {
-			log.Println("Failed to create account: " + err.Error())
+			logError(r, "couldn't create account", err)
 			writeError(w, http.StatusInternalServerError, "internal error")
 			return
 		}
@@ -186,20 +217,36 @@ func DeleteAdminAccount(env *HandlerConfig) http.HandlerFunc {
 			writeError(w, http.StatusBadRequest, "Invalid id")
 			return
 		}
-		_, err = env.DBQueries.GetAdminAccount(context.Background(), idInt)
+		account, err := env.DBQueries.GetAdminAccount(context.Background(), idInt)
 		if err != nil {
 			if err == sql.ErrNoRows {
 				writeError(w, http.StatusNotFound, "Admin Account not found")
 				return
 			}
-			log.Println(err.Error())
+			logError(r, "internal error", err)
 			writeError(w, http.StatusInternalServerError, "internal error")
 			return
 		}
 
-		err = env.DBQueries.DeleteAdminAccount(context.Background(), idInt)
+		err = env.DBQueries.ExecTx(context.Background(), func(q *db.Queries) error {
+			if err := q.DeleteAdminAccount(context.Background(), idInt); err != nil {
+				return err
+			}
+			if err := revokeAccountSessions(context.Background(), q, idInt, AdminRole, 0); err != nil {
+				return err
+			}
+			if _, err := deleteSecondFactor(context.Background(), q, idInt, AdminRole); err != nil {
+				return err
+			}
+			return recordAuditEvent(context.Background(), q, r, auditEvent{
+				Action:     AuditActionAdminAccountDelete,
+				TargetType: AuditTargetAdminAccount,
+				TargetID:   idInt,
+				Before:     map[string]any{"email": account.Email},
+			})
+		})
 		if err != nil {
-			log.Println(err.Error())
+			logError(r, "internal error", err)
 			writeError(w, http.StatusInternalServerError, "internal error")
 			return
 		}
@@ -233,7 +280,7 @@ func ChangeAdminAccountPassword(env *HandlerConfig) http.HandlerFunc {
 				writeError(w, http.StatusNotFound, "Admin Account not found")
 				return
 			}
-			log.Println(err.Error())
+			logError(r, "internal error", err)
 			writeError(w, http.StatusInternalServerError, "internal error")
 			return
 		}
@@ -256,7 +303,7 @@ func ChangeAdminAccountPassword(env *HandlerConfig) http.HandlerFunc {
 		}
 		passwordHash, err := GeneratePasswordHash(changeAdminAccountPassword.Password)
 		if err != nil {
-			log.Println("Failed to generate password hash: " + err.Error())
+			logError(r, "couldn't generate password hash", err)
 			writeError(w, http.StatusInternalServerError, "internal error")
 			return
 		}
@@ -264,9 +311,27 @@ func ChangeAdminAccountPassword(env *HandlerConfig) http.HandlerFunc {
 			ID:           idInt,
 			PasswordHash: passwordHash,
 		}
-		err = env.DBQueries.UpdateAdminAccount(context.Background(), updateAdminAccountParams)
+		// Admins changing their own password stay logged in on the session they used to do it
+		var keepSessionID int64
+		if idInt == r.Context().Value(userIDKey).(int64) {
+			keepSessionID = r.Context().Value(sessionIDKey).(int64)
+		}
+		err = env.DBQueries.ExecTx(context.Background(), func(q *db.Queries) error {
+			if err := q.UpdateAdminAccount(context.Background(), updateAdminAccountParams); err != nil {
+				return err
+			}
+			if err := revokeAccountSessions(context.Background(), q, idInt, AdminRole, keepSessionID); err != nil {
+				return err
+			}
+			return recordAuditEvent(context.Background(), q, r, auditEvent{
+				Action:     AuditActionAdminAccountChangePassword,
+				TargetType: AuditTargetAdminAccount,
+				TargetID:   idInt,
+				After:      map[string]any{"other_sessions_revoked": true},
+			})
+		})
 		if err != nil {
-			log.Println(err.Error())
+			logError(r, "internal error", err)
 			writeError(w, http.StatusInternalServerError, "internal error")
 			return
 		}
@@ -284,7 +349,8 @@ func ChangeAdminAccountPassword(env *HandlerConfig) http.HandlerFunc {
 
 func ChangeMyAdminAccountPassword(env *HandlerConfig) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		claims, err := getClaimsFromAuthorizationHeader(r.Header.Get("Authorization"), env.JWTSecret)
+		sessionID := r.Context().Value(sessionIDKey).(int64)
+		claims, err := getClaimsFromAuthorizationHeader(r.Header.Get("Authorization"), env.JWTKeys)
 		if err != nil {
 			writeError(w, http.StatusUnauthorized, err.Error())
 			return
@@ -319,7 +385,7 @@ func ChangeMyAdminAccountPassword(env *HandlerConfig) http.HandlerFunc {
 		}
 		passwordHash, err := GeneratePasswordHash(changeAdminAccountPassword.Password)
 		if err != nil {
-			log.Println("Failed to generate password hash: " + err.Error())
+			logError(r, "couldn't generate password hash", err)
 			writeError(w, http.StatusInternalServerError, "internal error")
 			return
 		}
@@ -327,9 +393,22 @@ func ChangeMyAdminAccountPassword(env *HandlerConfig) http.HandlerFunc {
 			ID:           idInt,
 			PasswordHash: passwordHash,
 		}
-		err = env.DBQueries.UpdateAdminAccount(context.Background(), updateAdminAccountParams)
+		err = env.DBQueries.ExecTx(context.Background(), func(q *db.Queries) error {
+			if err := q.UpdateAdminAccount(context.Background(), updateAdminAccountParams); err != nil {
+				return err
+			}
+			if err := revokeAccountSessions(context.Background(), q, idInt, AdminRole, sessionID); err != nil {
+				return err
+			}
+			return recordAuditEvent(context.Background(), q, r, auditEvent{
+				Action:     AuditActionAdminAccountChangePassword,
+				TargetType: AuditTargetAdminAccount,
+				TargetID:   idInt,
+				After:      map[string]any{"other_sessions_revoked": true},
+			})
+		})
 		if err != nil {
-			log.Println(err.Error())
+			logError(r, "internal error", err)
 			writeError(w, http.StatusInternalServerError, "internal error")
 			return
 		}
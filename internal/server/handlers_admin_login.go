@@ -4,12 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
-	"log"
 	"net/http"
-	"time"
-
-	"github.com/golang-jwt/jwt"
-	"golang.org/x/crypto/bcrypt"
 )
 
 type LoginParams struct {
@@ -18,35 +13,15 @@ type LoginParams struct {
 }
 
 type LoginResponse struct {
-	Token string `json:"token"`
-}
-
-type jwtAdminClaims struct {
-	ID    int64  `json:"id"`
-	Email string `json:"email"`
-	Role  int64  `json:"role"`
-	jwt.StandardClaims
-}
-
-// Helper function to generate a JWT
-func generateAdminJWT(id int64, email string, jwtSecret []byte) (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwtAdminClaims{
-		ID:    id,
-		Email: email,
-		Role:  AdminRole,
-		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: time.Now().Add(ClaimValidity).Unix(),
-		},
-	})
-	tokenString, err := token.SignedString(jwtSecret)
-	if err != nil {
-		return "", err
-	}
-	return tokenString, nil
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
 }
 
 func AdminLogin(env *HandlerConfig) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		if !checkAdminClientCert(w, r) {
+			return
+		}
 		var loginRequest LoginParams
 		if err := json.NewDecoder(r.Body).Decode(&loginRequest); err != nil {
 			writeError(w, http.StatusBadRequest, "Invalid JSON format")
@@ -60,28 +35,33 @@ func AdminLogin(env *HandlerConfig) http.HandlerFunc {
 			writeError(w, http.StatusBadRequest, "Password is required")
 			return
 		}
+		if !env.logins.admit(w, r, AdminRole, loginRequest.Email) {
+			return
+		}
 		account, err := env.DBQueries.GetAdminAccountByEmail(context.Background(), loginRequest.Email)
-		if err != nil {
-			if err == sql.ErrNoRows {
-				writeError(w, http.StatusUnauthorized, "The username or password is incorrect. Try again.")
-				return
-			}
-			log.Println(err.Error())
+		if err != nil && err != sql.ErrNoRows {
+			logError(r, "internal error", err)
 			writeError(w, http.StatusInternalServerError, "internal error")
 			return
 		}
-		if err := bcrypt.CompareHashAndPassword([]byte(account.PasswordHash), []byte(loginRequest.Password)); err != nil {
-			writeError(w, http.StatusUnauthorized, "The username or password is incorrect. Try again.")
+		// Unknown emails go through a password check too, not to be told apart by response times.
+		if !checkPassword(account.PasswordHash, loginRequest.Password) {
+			env.logins.fail(w, r, AdminRole, loginRequest.Email, "The username or password is incorrect. Try again.")
+			return
+		}
+		if requireSecondFactor(w, r, env, account.ID, account.Email, AdminRole) {
 			return
 		}
-		jwt, err := generateAdminJWT(account.ID, account.Email, env.JWTSecret)
+		accessToken, refreshToken, err := startSession(context.Background(), env.DBQueries, env.JWTKeys, account.ID, account.Email, AdminRole)
 		if err != nil {
-			log.Println(err.Error())
+			logError(r, "internal error", err)
 			writeError(w, http.StatusInternalServerError, "internal error")
 			return
 		}
+		env.logins.succeed(r, AdminRole, loginRequest.Email)
 		loginResponse := LoginResponse{
-			Token: jwt,
+			Token:        accessToken,
+			RefreshToken: refreshToken,
 		}
 		w.WriteHeader(http.StatusOK)
 		err = writeJSON(w, loginResponse)
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/golang-jwt/jwt"
+	"github.com/gruyaume/lesvieux/internal/jwtkeys"
 )
 
 type AdminLoginParams struct {
@@ -16,7 +17,8 @@ type AdminLoginParams struct {
 }
 
 type AdminLoginResponseResult struct {
-	Token string `json:"token"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
 }
 
 type AdminLoginResponse struct {
@@ -86,7 +88,12 @@ func TestAdminLoginEndToEnd(t *testing.T) {
 			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 				return nil, fmt.Errorf("Unexpected signing method: %v", token.Header["alg"])
 			}
-			return []byte(config.JWTSecret), nil
+			kid, _ := token.Header["kid"].(string)
+			secret, ok := config.JWTKeys.VerificationKey(kid)
+			if !ok {
+				return nil, fmt.Errorf("Unknown key id: %q", kid)
+			}
+			return secret, nil
 		})
 		if err != nil {
 			t.Fatalf("couldn't parse token: %s", err)
@@ -170,3 +177,61 @@ func TestAdminLoginEndToEnd(t *testing.T) {
 		}
 	})
 }
+
+func TestAdminLoginKeyRotation(t *testing.T) {
+	ts, config, err := setupServer()
+	if err != nil {
+		t.Fatalf("couldn't create test server: %s", err)
+	}
+	defer ts.Close()
+	client := ts.Client()
+	var oldToken string
+	t.Run("prepare admin account", prepareAdminAccount(ts.URL, client, &oldToken))
+
+	keys, err := jwtkeys.Generate()
+	if err != nil {
+		t.Fatalf("couldn't generate keys: %s", err)
+	}
+	oldKey := config.JWTKeys.SigningKey()
+	newKey := keys.Keys[0]
+	keys.Add(oldKey, false)
+	config.JWTKeys.Set(keys)
+
+	t.Run("Token signed with previous key is still accepted", func(t *testing.T) {
+		statusCode, _, err := getMyAdminAccount(ts.URL, client, oldToken)
+		if err != nil {
+			t.Fatalf("couldn't get admin account: %s", err)
+		}
+		if statusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, statusCode)
+		}
+	})
+
+	t.Run("New tokens are signed with the active key", func(t *testing.T) {
+		statusCode, loginResponse, err := adminLogin(ts.URL, client, &AdminLoginParams{Email: adminUser.Email, Password: adminUser.Password})
+		if err != nil {
+			t.Fatalf("couldn't login admin user: %s", err)
+		}
+		if statusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, statusCode)
+		}
+		token, _, err := new(jwt.Parser).ParseUnverified(loginResponse.Result.Token, jwt.MapClaims{})
+		if err != nil {
+			t.Fatalf("couldn't parse token: %s", err)
+		}
+		if token.Header["kid"] != newKey.ID {
+			t.Fatalf("expected kid %q, got %v", newKey.ID, token.Header["kid"])
+		}
+	})
+
+	t.Run("Token signed with removed key is rejected", func(t *testing.T) {
+		config.JWTKeys.Set(jwtkeys.File{Active: newKey.ID, Keys: []jwtkeys.Key{newKey}})
+		statusCode, _, err := getMyAdminAccount(ts.URL, client, oldToken)
+		if err != nil {
+			t.Fatalf("couldn't get admin account: %s", err)
+		}
+		if statusCode != http.StatusUnauthorized {
+			t.Fatalf("expected status %d, got %d", http.StatusUnauthorized, statusCode)
+		}
+	})
+}
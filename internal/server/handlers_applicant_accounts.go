@@ -0,0 +1,276 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/gruyaume/lesvieux/internal/db"
+)
+
+type ApplicantProfile struct {
+	Name        string `json:"name"`
+	PhoneNumber string `json:"phone_number"`
+	City        string `json:"city"`
+	PostalCode  string `json:"postal_code"`
+}
+
+type CreateApplicantAccountParams struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	ApplicantProfile
+}
+
+type CreateApplicantAccountResponse struct {
+	ID int64 `json:"id"`
+}
+
+type GetApplicantAccountResponse struct {
+	ID        int64  `json:"id"`
+	Email     string `json:"email"`
+	CreatedAt string `json:"created_at"`
+	ApplicantProfile
+}
+
+type UpdateApplicantAccountResponse struct {
+	ID int64 `json:"id"`
+}
+
+type ChangeApplicantAccountPasswordParams struct {
+	Password string `json:"password"`
+}
+
+type ChangeApplicantAccountPasswordResponse struct {
+	ID int64 `json:"id"`
+}
+
+func newGetApplicantAccountResponse(account db.ApplicantAccount) GetApplicantAccountResponse {
+	return GetApplicantAccountResponse{
+		ID:        account.ID,
+		Email:     account.Email,
+		CreatedAt: account.CreatedAt,
+		ApplicantProfile: ApplicantProfile{
+			Name:        account.Name,
+			PhoneNumber: account.PhoneNumber,
+			City:        account.City,
+			PostalCode:  account.PostalCode,
+		},
+	}
+}
+
+// normalize trims the profile fields, which are all optional.
+func (p *ApplicantProfile) normalize() {
+	p.Name = strings.TrimSpace(p.Name)
+	p.PhoneNumber = strings.TrimSpace(p.PhoneNumber)
+	p.City = strings.TrimSpace(p.City)
+	p.PostalCode = strings.ToUpper(strings.TrimSpace(p.PostalCode))
+}
+
+// CreateApplicantAccount lets anyone sign up as an applicant, and returns the id of the created row
+func CreateApplicantAccount(env *HandlerConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var account CreateApplicantAccountParams
+		if err := json.NewDecoder(r.Body).Decode(&account); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid JSON format")
+			return
+		}
+		if account.Email == "" {
+			writeError(w, http.StatusBadRequest, "Email is required")
+			return
+		}
+		if address, err := mail.ParseAddress(account.Email); err != nil || address.Address != account.Email {
+			writeError(w, http.StatusBadRequest, "Invalid email")
+			return
+		}
+		if account.Password == "" {
+			writeError(w, http.StatusBadRequest, "Password is required")
+			return
+		}
+		if !validatePassword(account.Password) {
+			writeError(
+				w,
+				http.StatusBadRequest,
+				"Password must have 8 or more characters, must include at least one capital letter, one lowercase letter, and either a number or a symbol.",
+			)
+			return
+		}
+		account.ApplicantProfile.normalize()
+
+		_, err := env.DBQueries.GetApplicantAccountByEmail(context.Background(), account.Email)
+		if err == nil {
+			writeError(w, http.StatusConflict, "Account already exists")
+			return
+		}
+		if err != sql.ErrNoRows {
+			logError(r, "internal error", err)
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+
+		passwordHash, err := GeneratePasswordHash(account.Password)
+		if err != nil {
+			logError(r, "couldn't generate password hash", err)
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		newApplicantAccount, err := env.DBQueries.CreateApplicantAccount(context.Background(), db.CreateApplicantAccountParams{
+			Email:        account.Email,
+			PasswordHash: passwordHash,
+			Name:         account.Name,
+			PhoneNumber:  account.PhoneNumber,
+			City:         account.City,
+			PostalCode:   account.PostalCode,
+			CreatedAt:    time.Now().UTC().Format(time.RFC3339),
+		})
+		if err != nil {
+			// An account created with the same email since the check above.
+			if db.IsUniqueViolation(err) {
+				writeError(w, http.StatusConflict, "Account already exists")
+				return
+			}
+			logError(r, "couldn't create account", err)
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		response := CreateApplicantAccountResponse{ID: newApplicantAccount.ID}
+		err = writeJSON(w, response)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+	}
+}
+
+// GetMyApplicantAccount returns the profile of the logged in applicant
+func GetMyApplicantAccount(env *HandlerConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := r.Context().Value(userIDKey).(int64)
+		account, err := env.DBQueries.GetApplicantAccount(context.Background(), userID)
+		if err != nil {
+			logError(r, "internal error", err)
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		err = writeJSON(w, newGetApplicantAccountResponse(account))
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+	}
+}
+
+// UpdateMyApplicantAccount replaces the profile of the logged in applicant
+func UpdateMyApplicantAccount(env *HandlerConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := r.Context().Value(userIDKey).(int64)
+		var profile ApplicantProfile
+		if err := json.NewDecoder(r.Body).Decode(&profile); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid JSON format")
+			return
+		}
+		profile.normalize()
+		err := env.DBQueries.UpdateApplicantAccountProfile(context.Background(), db.UpdateApplicantAccountProfileParams{
+			Name:        profile.Name,
+			PhoneNumber: profile.PhoneNumber,
+			City:        profile.City,
+			PostalCode:  profile.PostalCode,
+			ID:          userID,
+		})
+		if err != nil {
+			logError(r, "internal error", err)
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		err = writeJSON(w, UpdateApplicantAccountResponse{ID: userID})
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+	}
+}
+
+// ChangeMyApplicantAccountPassword replaces the password of the logged in applicant.
+// The other sessions of the account are logged out.
+func ChangeMyApplicantAccountPassword(env *HandlerConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := r.Context().Value(userIDKey).(int64)
+		sessionID := r.Context().Value(sessionIDKey).(int64)
+		var changeApplicantAccountPassword ChangeApplicantAccountPasswordParams
+		if err := json.NewDecoder(r.Body).Decode(&changeApplicantAccountPassword); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid JSON format")
+			return
+		}
+		if changeApplicantAccountPassword.Password == "" {
+			writeError(w, http.StatusBadRequest, "Password is required")
+			return
+		}
+		if !validatePassword(changeApplicantAccountPassword.Password) {
+			writeError(
+				w,
+				http.StatusBadRequest,
+				"Password must have 8 or more characters, must include at least one capital letter, one lowercase letter, and either a number or a symbol.",
+			)
+			return
+		}
+		passwordHash, err := GeneratePasswordHash(changeApplicantAccountPassword.Password)
+		if err != nil {
+			logError(r, "couldn't generate password hash", err)
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		err = env.DBQueries.ExecTx(context.Background(), func(q *db.Queries) error {
+			err := q.UpdateApplicantAccountPassword(context.Background(), db.UpdateApplicantAccountPasswordParams{
+				PasswordHash: passwordHash,
+				ID:           userID,
+			})
+			if err != nil {
+				return err
+			}
+			return revokeAccountSessions(context.Background(), q, userID, ApplicantRole, sessionID)
+		})
+		if err != nil {
+			logError(r, "internal error", err)
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		err = writeJSON(w, ChangeApplicantAccountPasswordResponse{ID: userID})
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+	}
+}
+
+// DeleteMyApplicantAccount deletes the account of the logged in applicant.
+// All its sessions are logged out.
+func DeleteMyApplicantAccount(env *HandlerConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := r.Context().Value(userIDKey).(int64)
+		err := env.DBQueries.ExecTx(context.Background(), func(q *db.Queries) error {
+			if err := q.DeleteApplicantAccount(context.Background(), userID); err != nil {
+				return err
+			}
+			return revokeAccountSessions(context.Background(), q, userID, ApplicantRole, 0)
+		})
+		if err != nil {
+			logError(r, "internal error", err)
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		response := map[string]any{"id": userID}
+		err = writeJSON(w, response)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+	}
+}
@@ -0,0 +1,293 @@
+package server_test
+
+import (
+	"net/http"
+	"testing"
+)
+
+type ApplicantProfile struct {
+	Name        string `json:"name"`
+	PhoneNumber string `json:"phone_number"`
+	City        string `json:"city"`
+	PostalCode  string `json:"postal_code"`
+}
+
+type CreateApplicantAccountParams struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	ApplicantProfile
+}
+
+type CreateApplicantAccountResponseResult struct {
+	ID int64 `json:"id"`
+}
+
+type CreateApplicantAccountResponse struct {
+	Result CreateApplicantAccountResponseResult `json:"result"`
+	Error  string                               `json:"error,omitempty"`
+}
+
+type GetApplicantAccountResponseResult struct {
+	ID    int64  `json:"id"`
+	Email string `json:"email"`
+	ApplicantProfile
+}
+
+type GetApplicantAccountResponse struct {
+	Result GetApplicantAccountResponseResult `json:"result"`
+	Error  string                            `json:"error,omitempty"`
+}
+
+type ApplicantAccountIDResponse struct {
+	Result CreateApplicantAccountResponseResult `json:"result"`
+	Error  string                               `json:"error,omitempty"`
+}
+
+type ChangeApplicantPasswordParams struct {
+	Password string `json:"password"`
+}
+
+func createApplicantAccount(url string, client *http.Client, data *CreateApplicantAccountParams) (int, *CreateApplicantAccountResponse, error) {
+	var response CreateApplicantAccountResponse
+	statusCode, err := apiRequest("POST", url, client, "", "/applicants/accounts", data, &response)
+	return statusCode, &response, err
+}
+
+func getMyApplicantAccount(url string, client *http.Client, token string) (int, *GetApplicantAccountResponse, error) {
+	var response GetApplicantAccountResponse
+	statusCode, err := apiRequest("GET", url, client, token, "/applicants/accounts/me", nil, &response)
+	return statusCode, &response, err
+}
+
+func updateMyApplicantAccount(url string, client *http.Client, token string, data *ApplicantProfile) (int, *ApplicantAccountIDResponse, error) {
+	var response ApplicantAccountIDResponse
+	statusCode, err := apiRequest("PUT", url, client, token, "/applicants/accounts/me", data, &response)
+	return statusCode, &response, err
+}
+
+func changeMyApplicantAccountPassword(url string, client *http.Client, token string, data *ChangeApplicantPasswordParams) (int, *ApplicantAccountIDResponse, error) {
+	var response ApplicantAccountIDResponse
+	statusCode, err := apiRequest("POST", url, client, token, "/applicants/accounts/me/change_password", data, &response)
+	return statusCode, &response, err
+}
+
+func deleteMyApplicantAccount(url string, client *http.Client, token string) (int, *ApplicantAccountIDResponse, error) {
+	var response ApplicantAccountIDResponse
+	statusCode, err := apiRequest("DELETE", url, client, token, "/applicants/accounts/me", nil, &response)
+	return statusCode, &response, err
+}
+
+func TestCreateApplicantAccount(t *testing.T) {
+	ts, _, err := setupServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ts.Close()
+	client := ts.Client()
+
+	testCases := []struct {
+		desc   string
+		params CreateApplicantAccountParams
+		status int
+		error  string
+	}{
+		{
+			desc:   "success",
+			params: validApplicantAccount,
+			status: http.StatusCreated,
+		},
+		{
+			desc:   "already exists",
+			params: validApplicantAccount,
+			status: http.StatusConflict,
+			error:  "Account already exists",
+		},
+		{
+			desc:   "missing email",
+			params: CreateApplicantAccountParams{Password: "Applicant123!"},
+			status: http.StatusBadRequest,
+			error:  "Email is required",
+		},
+		{
+			desc:   "invalid email",
+			params: CreateApplicantAccountParams{Email: "Jeanne <jeanne@example.com>", Password: "Applicant123!"},
+			status: http.StatusBadRequest,
+			error:  "Invalid email",
+		},
+		{
+			desc:   "missing password",
+			params: CreateApplicantAccountParams{Email: "marcel@example.com"},
+			status: http.StatusBadRequest,
+			error:  "Password is required",
+		},
+		{
+			desc:   "weak password",
+			params: CreateApplicantAccountParams{Email: "marcel@example.com", Password: "password"},
+			status: http.StatusBadRequest,
+			error:  "Password must have 8 or more characters, must include at least one capital letter, one lowercase letter, and either a number or a symbol.",
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			statusCode, resp, err := createApplicantAccount(ts.URL, client, &tC.params)
+			if err != nil {
+				t.Fatalf("couldn't create applicant account: %s", err)
+			}
+			if statusCode != tC.status {
+				t.Fatalf("expected status %d, got %d", tC.status, statusCode)
+			}
+			if resp.Error != tC.error {
+				t.Fatalf("expected error %q, got %q", tC.error, resp.Error)
+			}
+		})
+	}
+}
+
+func TestMyApplicantAccountEndToEnd(t *testing.T) {
+	ts, _, err := setupServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ts.Close()
+	client := ts.Client()
+	var applicantToken string
+	t.Run("prepare applicant account and token", prepareApplicantAccount(ts.URL, client, &applicantToken))
+
+	t.Run("Get my account - success", func(t *testing.T) {
+		statusCode, resp, err := getMyApplicantAccount(ts.URL, client, applicantToken)
+		if err != nil {
+			t.Fatalf("couldn't get applicant account: %s", err)
+		}
+		if statusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusOK, statusCode, resp.Error)
+		}
+		if resp.Result.Email != validApplicantAccount.Email || resp.Result.Name != validApplicantAccount.Name {
+			t.Fatalf("unexpected account: %+v", resp.Result)
+		}
+	})
+
+	t.Run("Update my account - success", func(t *testing.T) {
+		profile := &ApplicantProfile{Name: "Jeanne Martin", PhoneNumber: "+33 6 12 34 56 78", City: " Lyon ", PostalCode: "69001"}
+		statusCode, resp, err := updateMyApplicantAccount(ts.URL, client, applicantToken, profile)
+		if err != nil {
+			t.Fatalf("couldn't update applicant account: %s", err)
+		}
+		if statusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusOK, statusCode, resp.Error)
+		}
+		statusCode, getResp, err := getMyApplicantAccount(ts.URL, client, applicantToken)
+		if err != nil || statusCode != http.StatusOK {
+			t.Fatalf("couldn't get applicant account: %v (status %d)", err, statusCode)
+		}
+		expected := ApplicantProfile{Name: "Jeanne Martin", PhoneNumber: "+33 6 12 34 56 78", City: "Lyon", PostalCode: "69001"}
+		if getResp.Result.ApplicantProfile != expected {
+			t.Fatalf("expected profile %+v, got %+v", expected, getResp.Result.ApplicantProfile)
+		}
+	})
+
+	t.Run("Change my password - weak password", func(t *testing.T) {
+		statusCode, _, err := changeMyApplicantAccountPassword(ts.URL, client, applicantToken, &ChangeApplicantPasswordParams{Password: "weak"})
+		if err != nil {
+			t.Fatalf("couldn't change password: %s", err)
+		}
+		if statusCode != http.StatusBadRequest {
+			t.Fatalf("expected status %d, got %d", http.StatusBadRequest, statusCode)
+		}
+	})
+
+	t.Run("Change my password - success", func(t *testing.T) {
+		statusCode, _, err := changeMyApplicantAccountPassword(ts.URL, client, applicantToken, &ChangeApplicantPasswordParams{Password: "Newpass123!"})
+		if err != nil {
+			t.Fatalf("couldn't change password: %s", err)
+		}
+		if statusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, statusCode)
+		}
+		statusCode, _, err = applicantLogin(ts.URL, client, &ApplicantLoginParams{Email: validApplicantAccount.Email, Password: "Newpass123!"})
+		if err != nil || statusCode != http.StatusOK {
+			t.Fatalf("couldn't login with the new password: %v (status %d)", err, statusCode)
+		}
+	})
+
+	t.Run("Delete my account - success", func(t *testing.T) {
+		statusCode, _, err := deleteMyApplicantAccount(ts.URL, client, applicantToken)
+		if err != nil {
+			t.Fatalf("couldn't delete applicant account: %s", err)
+		}
+		if statusCode != http.StatusAccepted {
+			t.Fatalf("expected status %d, got %d", http.StatusAccepted, statusCode)
+		}
+	})
+
+	t.Run("Get my account - deleted", func(t *testing.T) {
+		statusCode, _, err := getMyApplicantAccount(ts.URL, client, applicantToken)
+		if err != nil {
+			t.Fatalf("couldn't get applicant account: %s", err)
+		}
+		if statusCode != http.StatusUnauthorized {
+			t.Fatalf("expected status %d, got %d", http.StatusUnauthorized, statusCode)
+		}
+	})
+}
+
+func TestApplicantAccountRoles(t *testing.T) {
+	ts, _, err := setupServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ts.Close()
+	client := ts.Client()
+	var adminToken string
+	var employerToken string
+	var applicantToken string
+	t.Run("prepare admin account and token", prepareAdminAccount(ts.URL, client, &adminToken))
+	t.Run("prepare employer account and token", prepareEmployerAccount(ts.URL, client, &adminToken, &employerToken))
+	t.Run("prepare applicant account with the email of the admin", func(t *testing.T) {
+		account := &CreateApplicantAccountParams{Email: "admin@example.com", Password: "Applicant123!"}
+		statusCode, _, err := createApplicantAccount(ts.URL, client, account)
+		if err != nil || statusCode != http.StatusCreated {
+			t.Fatalf("couldn't create applicant account: %v (status %d)", err, statusCode)
+		}
+		statusCode, _, err = createAdminAccount(ts.URL, client, adminToken, &CreateAdminAccountParams{Email: account.Email, Password: "Admin123!"})
+		if err != nil || statusCode != http.StatusCreated {
+			t.Fatalf("couldn't create admin account: %v (status %d)", err, statusCode)
+		}
+		statusCode, loginResp, err := applicantLogin(ts.URL, client, &ApplicantLoginParams{Email: account.Email, Password: account.Password})
+		if err != nil || statusCode != http.StatusOK {
+			t.Fatalf("couldn't login applicant: %v (status %d)", err, statusCode)
+		}
+		applicantToken = loginResp.Result.Token
+	})
+
+	t.Run("Applicant can't reach the admin account with the same email", func(t *testing.T) {
+		statusCode, _, err := changeMyAdminAccountPassword(ts.URL, client, applicantToken, &ChangeAdminPasswordRequest{Password: "Hijacked123!"})
+		if err != nil {
+			t.Fatalf("couldn't change password: %s", err)
+		}
+		if statusCode != http.StatusForbidden {
+			t.Fatalf("expected status %d, got %d", http.StatusForbidden, statusCode)
+		}
+	})
+
+	t.Run("Applicant can't manage job posts", func(t *testing.T) {
+		statusCode, _, err := createMyJobPost(ts.URL, client, applicantToken, &validJobPost)
+		if err != nil {
+			t.Fatalf("couldn't create job post: %s", err)
+		}
+		if statusCode != http.StatusForbidden {
+			t.Fatalf("expected status %d, got %d", http.StatusForbidden, statusCode)
+		}
+	})
+
+	for desc, token := range map[string]string{"admin": adminToken, "employer": employerToken} {
+		t.Run("Get my applicant account - "+desc+" forbidden", func(t *testing.T) {
+			statusCode, _, err := getMyApplicantAccount(ts.URL, client, token)
+			if err != nil {
+				t.Fatalf("couldn't get applicant account: %s", err)
+			}
+			if statusCode != http.StatusForbidden {
+				t.Fatalf("expected status %d, got %d", http.StatusForbidden, statusCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+)
+
+type ApplicantLoginParams struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type ApplicantLoginResponse struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+func ApplicantsLogin(env *HandlerConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var loginRequest ApplicantLoginParams
+		if err := json.NewDecoder(r.Body).Decode(&loginRequest); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid JSON format")
+			return
+		}
+		if loginRequest.Email == "" {
+			writeError(w, http.StatusBadRequest, "Email is required")
+			return
+		}
+		if loginRequest.Password == "" {
+			writeError(w, http.StatusBadRequest, "Password is required")
+			return
+		}
+		if !env.logins.admit(w, r, ApplicantRole, loginRequest.Email) {
+			return
+		}
+		account, err := env.DBQueries.GetApplicantAccountByEmail(context.Background(), loginRequest.Email)
+		if err != nil && err != sql.ErrNoRows {
+			logError(r, "internal error", err)
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		// Unknown emails go through a password check too, not to be told apart by response times.
+		if !checkPassword(account.PasswordHash, loginRequest.Password) {
+			env.logins.fail(w, r, ApplicantRole, loginRequest.Email, "The email or password is incorrect. Try again.")
+			return
+		}
+		accessToken, refreshToken, err := startSession(context.Background(), env.DBQueries, env.JWTKeys, account.ID, account.Email, ApplicantRole)
+		if err != nil {
+			logError(r, "internal error", err)
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		env.logins.succeed(r, ApplicantRole, loginRequest.Email)
+		loginResponse := ApplicantLoginResponse{
+			Token:        accessToken,
+			RefreshToken: refreshToken,
+		}
+		w.WriteHeader(http.StatusOK)
+		err = writeJSON(w, loginResponse)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package server_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt"
+)
+
+type ApplicantLoginParams struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type ApplicantLoginResponseResult struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+type ApplicantLoginResponse struct {
+	Result ApplicantLoginResponseResult `json:"result"`
+	Error  string                       `json:"error,omitempty"`
+}
+
+func applicantLogin(url string, client *http.Client, data *ApplicantLoginParams) (int, *ApplicantLoginResponse, error) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return 0, nil, err
+	}
+	req, err := http.NewRequest("POST", url+"/api/v1/applicants/login", strings.NewReader(string(body)))
+	if err != nil {
+		return 0, nil, err
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer res.Body.Close()
+	var loginResponse ApplicantLoginResponse
+	if err := json.NewDecoder(res.Body).Decode(&loginResponse); err != nil {
+		return 0, nil, err
+	}
+	return res.StatusCode, &loginResponse, nil
+}
+
+func TestApplicantLoginEndToEnd(t *testing.T) {
+	ts, config, err := setupServer()
+	if err != nil {
+		t.Fatalf("couldn't create test server: %s", err)
+	}
+	defer ts.Close()
+	client := ts.Client()
+
+	t.Run("Create applicant account", func(t *testing.T) {
+		statusCode, _, err := createApplicantAccount(ts.URL, client, &validApplicantAccount)
+		if err != nil {
+			t.Fatalf("couldn't create applicant account: %s", err)
+		}
+		if statusCode != http.StatusCreated {
+			t.Fatalf("expected status %d, got %d", http.StatusCreated, statusCode)
+		}
+	})
+
+	t.Run("Login success", func(t *testing.T) {
+		applicant := &ApplicantLoginParams{
+			Email:    validApplicantAccount.Email,
+			Password: validApplicantAccount.Password,
+		}
+		statusCode, loginResponse, err := applicantLogin(ts.URL, client, applicant)
+		if err != nil {
+			t.Fatalf("couldn't login applicant: %s", err)
+		}
+		if statusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, statusCode)
+		}
+		token, err := jwt.Parse(loginResponse.Result.Token, func(token *jwt.Token) (interface{}, error) {
+			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+				return nil, fmt.Errorf("Unexpected signing method: %v", token.Header["alg"])
+			}
+			kid, _ := token.Header["kid"].(string)
+			secret, ok := config.JWTKeys.VerificationKey(kid)
+			if !ok {
+				return nil, fmt.Errorf("Unknown key id: %q", kid)
+			}
+			return secret, nil
+		})
+		if err != nil {
+			t.Fatalf("couldn't parse token: %s", err)
+		}
+		claims, ok := token.Claims.(jwt.MapClaims)
+		if !ok || !token.Valid {
+			t.Fatalf("invalid token or claims")
+		}
+		if claims["email"] != validApplicantAccount.Email || claims["role"] != float64(0) {
+			t.Fatalf("expected applicant claims for %q, got %v", validApplicantAccount.Email, claims)
+		}
+	})
+
+	testCases := []struct {
+		desc   string
+		params ApplicantLoginParams
+		status int
+		error  string
+	}{
+		{
+			desc:   "missing email",
+			params: ApplicantLoginParams{Password: validApplicantAccount.Password},
+			status: http.StatusBadRequest,
+			error:  "Email is required",
+		},
+		{
+			desc:   "missing password",
+			params: ApplicantLoginParams{Email: validApplicantAccount.Email},
+			status: http.StatusBadRequest,
+			error:  "Password is required",
+		},
+		{
+			desc:   "invalid password",
+			params: ApplicantLoginParams{Email: validApplicantAccount.Email, Password: "a-wrong-password"},
+			status: http.StatusUnauthorized,
+			error:  "The email or password is incorrect. Try again.",
+		},
+		{
+			desc:   "employer account",
+			params: ApplicantLoginParams{Email: validEmployerAccount.Email, Password: validEmployerAccount.Password},
+			status: http.StatusUnauthorized,
+			error:  "The email or password is incorrect. Try again.",
+		},
+	}
+	for _, tC := range testCases {
+		t.Run("Login failure "+tC.desc, func(t *testing.T) {
+			statusCode, loginResponse, err := applicantLogin(ts.URL, client, &tC.params)
+			if err != nil {
+				t.Fatalf("couldn't login applicant: %s", err)
+			}
+			if statusCode != tC.status {
+				t.Fatalf("expected status %d, got %d", tC.status, statusCode)
+			}
+			if loginResponse.Error != tC.error {
+				t.Fatalf("expected error %q, got %q", tC.error, loginResponse.Error)
+			}
+		})
+	}
+}
@@ -0,0 +1,292 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gruyaume/lesvieux/internal/db"
+)
+
+const maxCoverMessageLength = 5000
+
+type CreateApplicationParams struct {
+	CoverMessage string `json:"cover_message"`
+}
+
+type CreateApplicationResponse struct {
+	ID int64 `json:"id"`
+}
+
+// ApplicationResponse is how an application is shown to the applicant who sent it
+type ApplicationResponse struct {
+	ID           int64  `json:"id"`
+	JobPostID    int64  `json:"job_post_id"`
+	JobPostTitle string `json:"job_post_title"`
+	EmployerName string `json:"employer_name"`
+	CoverMessage string `json:"cover_message"`
+	Status       string `json:"status"`
+	CreatedAt    string `json:"created_at"`
+	UpdatedAt    string `json:"updated_at"`
+}
+
+type ApplicationStatusChangeResponse struct {
+	FromStatus string `json:"from_status"`
+	ToStatus   string `json:"to_status"`
+	ChangedAt  string `json:"changed_at"`
+}
+
+func newApplicationResponse(application db.Application, jobPostTitle string, employerName string) ApplicationResponse {
+	return ApplicationResponse{
+		ID:           application.ID,
+		JobPostID:    application.JobPostID,
+		JobPostTitle: jobPostTitle,
+		EmployerName: employerName,
+		CoverMessage: application.CoverMessage,
+		Status:       application.Status,
+		CreatedAt:    application.CreatedAt,
+		UpdatedAt:    application.UpdatedAt,
+	}
+}
+
+// ApplyToJobPost receives the id of a published Job Post as a path parameter, and sends an application
+// to it from the logged in applicant. Applicants can apply once to each post.
+func ApplyToJobPost(env *HandlerConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := r.Context().Value(userIDKey).(int64)
+		id := r.PathValue("post_id")
+		idInt64, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "id must be an integer")
+			return
+		}
+		var params CreateApplicationParams
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid JSON format")
+			return
+		}
+		params.CoverMessage = strings.TrimSpace(params.CoverMessage)
+		if params.CoverMessage == "" {
+			writeError(w, http.StatusBadRequest, "cover_message is required")
+			return
+		}
+		if utf8.RuneCountInString(params.CoverMessage) > maxCoverMessageLength {
+			writeError(w, http.StatusBadRequest, "cover_message can't be longer than %d characters", maxCoverMessageLength)
+			return
+		}
+
+		_, err = env.DBQueries.GetPublishedJobPost(context.Background(), idInt64)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				writeError(w, http.StatusNotFound, "Job Post not found")
+				return
+			}
+			logError(r, "internal error", err)
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		_, err = env.DBQueries.GetApplicationForJobPost(context.Background(), db.GetApplicationForJobPostParams{
+			JobPostID:   idInt64,
+			ApplicantID: userID,
+		})
+		if err == nil {
+			writeError(w, http.StatusConflict, "Already applied to this Job Post")
+			return
+		}
+		if err != sql.ErrNoRows {
+			logError(r, "internal error", err)
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+
+		var application db.Application
+		err = env.DBQueries.ExecTx(context.Background(), func(q *db.Queries) error {
+			now := time.Now().UTC().Format(time.RFC3339)
+			var err error
+			application, err = q.CreateApplication(context.Background(), db.CreateApplicationParams{
+				JobPostID:    idInt64,
+				ApplicantID:  userID,
+				CoverMessage: params.CoverMessage,
+				Status:       ApplicationStatusReceived,
+				CreatedAt:    now,
+				UpdatedAt:    now,
+			})
+			if err != nil {
+				return err
+			}
+			actor := &jobPostActor{ID: userID, Role: ApplicantRole}
+			return recordApplicationStatusChange(context.Background(), q, application.ID, "", application.Status, now, actor)
+		})
+		if err != nil {
+			// An application to the same Job Post was created since the check above.
+			if db.IsUniqueViolation(err) {
+				writeError(w, http.StatusConflict, "Already applied to this Job Post")
+				return
+			}
+			logError(r, "couldn't create application", err)
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		env.Metrics.ApplicationsSubmitted.Inc()
+		w.WriteHeader(http.StatusCreated)
+		err = writeJSON(w, CreateApplicationResponse{ID: application.ID})
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+	}
+}
+
+// ListMyApplications returns a page of the applications sent by the logged in applicant,
+// newest first by default. They can be filtered by status.
+func ListMyApplications(env *HandlerConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := r.Context().Value(userIDKey).(int64)
+		page, err := parsePageRequest(r, "-created_at", "created_at")
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "%s", err)
+			return
+		}
+		status, err := parseApplicationStatusFilter(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "%s", err)
+			return
+		}
+		params := db.ListApplicantApplicationsNewestFirstParams{
+			ApplicantID: userID,
+			Status:      status,
+			CursorKey:   page.CursorKey(),
+			CursorID:    page.CursorID(),
+			Limit:       page.FetchLimit(),
+		}
+		var applications []db.GetApplicantApplicationRow
+		if page.Descending() {
+			rows, err := env.DBQueries.ListApplicantApplicationsNewestFirst(context.Background(), params)
+			if err != nil {
+				logError(r, "internal error", err)
+				writeError(w, http.StatusInternalServerError, "internal error")
+				return
+			}
+			for _, row := range rows {
+				applications = append(applications, db.GetApplicantApplicationRow(row))
+			}
+		} else {
+			rows, err := env.DBQueries.ListApplicantApplicationsOldestFirst(context.Background(), db.ListApplicantApplicationsOldestFirstParams(params))
+			if err != nil {
+				logError(r, "internal error", err)
+				writeError(w, http.StatusInternalServerError, "internal error")
+				return
+			}
+			for _, row := range rows {
+				applications = append(applications, db.GetApplicantApplicationRow(row))
+			}
+		}
+		applications, nextCursor := paginate(page, applications, func(a db.GetApplicantApplicationRow) (string, int64) {
+			return a.Application.CreatedAt, a.Application.ID
+		})
+		applicationsResponse := make([]ApplicationResponse, 0, len(applications))
+		for _, a := range applications {
+			applicationsResponse = append(applicationsResponse, newApplicationResponse(a.Application, a.JobPostTitle, a.EmployerName))
+		}
+		err = writeJSONPage(w, applicationsResponse, nextCursor)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+	}
+}
+
+// GetMyApplication receives an id as a path parameter, and returns the corresponding application
+// if it was sent by the logged in applicant
+func GetMyApplication(env *HandlerConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		application, ok := getMyApplicationFromPath(env, w, r)
+		if !ok {
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		err := writeJSON(w, newApplicationResponse(application.Application, application.JobPostTitle, application.EmployerName))
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+	}
+}
+
+// ListMyApplicationHistory receives an id as a path parameter, and returns the status changes
+// of the corresponding application if it was sent by the logged in applicant
+func ListMyApplicationHistory(env *HandlerConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		application, ok := getMyApplicationFromPath(env, w, r)
+		if !ok {
+			return
+		}
+		writeApplicationHistory(env, w, r, application.Application.ID)
+	}
+}
+
+// getMyApplicationFromPath reads the application_id path parameter and returns the matching application
+// of the logged in applicant. When ok is false, an error response has already been written.
+func getMyApplicationFromPath(env *HandlerConfig, w http.ResponseWriter, r *http.Request) (application db.GetApplicantApplicationRow, ok bool) {
+	userID := r.Context().Value(userIDKey).(int64)
+	id := r.PathValue("application_id")
+	idInt64, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "id must be an integer")
+		return application, false
+	}
+	application, err = env.DBQueries.GetApplicantApplication(context.Background(), db.GetApplicantApplicationParams{
+		ID:          idInt64,
+		ApplicantID: userID,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			writeError(w, http.StatusNotFound, "Application not found")
+			return application, false
+		}
+		logError(r, "internal error", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return application, false
+	}
+	return application, true
+}
+
+func writeApplicationHistory(env *HandlerConfig, w http.ResponseWriter, r *http.Request, applicationID int64) {
+	changes, err := env.DBQueries.ListApplicationStatusChanges(context.Background(), applicationID)
+	if err != nil {
+		logError(r, "internal error", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	changesResponse := make([]ApplicationStatusChangeResponse, 0, len(changes))
+	for _, change := range changes {
+		changesResponse = append(changesResponse, ApplicationStatusChangeResponse{
+			FromStatus: change.FromStatus,
+			ToStatus:   change.ToStatus,
+			ChangedAt:  change.ChangedAt,
+		})
+	}
+	err = writeJSON(w, changesResponse)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+}
+
+// parseApplicationStatusFilter reads the optional status query parameter of lists of applications.
+func parseApplicationStatusFilter(r *http.Request) (sql.NullString, error) {
+	status := r.URL.Query().Get("status")
+	if status == "" {
+		return sql.NullString{}, nil
+	}
+	if !isValidApplicationStatus(status) {
+		return sql.NullString{}, errors.New("Invalid status")
+	}
+	return sql.NullString{String: status, Valid: true}, nil
+}
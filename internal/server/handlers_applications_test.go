@@ -0,0 +1,348 @@
+package server_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+type CreateApplicationParams struct {
+	CoverMessage string `json:"cover_message"`
+}
+
+type CreateApplicationResponseResult struct {
+	ID int64 `json:"id"`
+}
+
+type CreateApplicationResponse struct {
+	Error  string                          `json:"error,omitempty"`
+	Result CreateApplicationResponseResult `json:"result"`
+}
+
+type ApplicationResponseResult struct {
+	ID           int64  `json:"id"`
+	JobPostID    int64  `json:"job_post_id"`
+	JobPostTitle string `json:"job_post_title"`
+	EmployerName string `json:"employer_name"`
+	CoverMessage string `json:"cover_message"`
+	Status       string `json:"status"`
+	Applicant    struct {
+		Name  string `json:"name"`
+		Email string `json:"email"`
+	} `json:"applicant"`
+}
+
+type GetApplicationResponse struct {
+	Error  string                    `json:"error,omitempty"`
+	Result ApplicationResponseResult `json:"result"`
+}
+
+type ListApplicationsResponse struct {
+	Error      string                      `json:"error,omitempty"`
+	Result     []ApplicationResponseResult `json:"result"`
+	NextCursor string                      `json:"next_cursor,omitempty"`
+}
+
+type ChangeApplicationStatusParams struct {
+	Status string `json:"status"`
+}
+
+type ChangeApplicationStatusResponse struct {
+	Error  string `json:"error,omitempty"`
+	Result struct {
+		ID     int64  `json:"id"`
+		Status string `json:"status"`
+	} `json:"result"`
+}
+
+type ApplicationStatusChangeResponseResult struct {
+	FromStatus string `json:"from_status"`
+	ToStatus   string `json:"to_status"`
+	ChangedAt  string `json:"changed_at"`
+}
+
+type ListApplicationHistoryResponse struct {
+	Error  string                                  `json:"error,omitempty"`
+	Result []ApplicationStatusChangeResponseResult `json:"result"`
+}
+
+func applyToJobPost(url string, client *http.Client, token string, postID string, data *CreateApplicationParams) (int, *CreateApplicationResponse, error) {
+	var response CreateApplicationResponse
+	statusCode, err := apiRequest("POST", url, client, token, "/posts/"+postID+"/applications", data, &response)
+	return statusCode, &response, err
+}
+
+func listMyApplications(url string, client *http.Client, token string) (int, *ListApplicationsResponse, error) {
+	var response ListApplicationsResponse
+	statusCode, err := apiRequest("GET", url, client, token, "/me/applications", nil, &response)
+	return statusCode, &response, err
+}
+
+func getMyApplication(url string, client *http.Client, token string, id string) (int, *GetApplicationResponse, error) {
+	var response GetApplicationResponse
+	statusCode, err := apiRequest("GET", url, client, token, "/me/applications/"+id, nil, &response)
+	return statusCode, &response, err
+}
+
+func listMyApplicationHistory(url string, client *http.Client, token string, id string) (int, *ListApplicationHistoryResponse, error) {
+	var response ListApplicationHistoryResponse
+	statusCode, err := apiRequest("GET", url, client, token, "/me/applications/"+id+"/history", nil, &response)
+	return statusCode, &response, err
+}
+
+func listMyJobPostApplications(url string, client *http.Client, token string, postID string, query string) (int, *ListApplicationsResponse, error) {
+	var response ListApplicationsResponse
+	statusCode, err := apiRequest("GET", url, client, token, "/me/posts/"+postID+"/applications?"+query, nil, &response)
+	return statusCode, &response, err
+}
+
+func getMyJobPostApplication(url string, client *http.Client, token string, postID string, id string) (int, *GetApplicationResponse, error) {
+	var response GetApplicationResponse
+	statusCode, err := apiRequest("GET", url, client, token, "/me/posts/"+postID+"/applications/"+id, nil, &response)
+	return statusCode, &response, err
+}
+
+func changeMyJobPostApplicationStatus(url string, client *http.Client, token string, postID string, id string, data *ChangeApplicationStatusParams) (int, *ChangeApplicationStatusResponse, error) {
+	var response ChangeApplicationStatusResponse
+	statusCode, err := apiRequest("POST", url, client, token, "/me/posts/"+postID+"/applications/"+id+"/status", data, &response)
+	return statusCode, &response, err
+}
+
+func TestApplicationsEndToEnd(t *testing.T) {
+	ts, _, err := setupServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ts.Close()
+	client := ts.Client()
+	var adminToken string
+	var employerToken string
+	var applicantToken string
+	ids := make([]string, 2)
+	t.Run("prepare admin account and token", prepareAdminAccount(ts.URL, client, &adminToken))
+	t.Run("prepare employer account and token", prepareEmployerAccount(ts.URL, client, &adminToken, &employerToken))
+	t.Run("prepare applicant account and token", prepareApplicantAccount(ts.URL, client, &applicantToken))
+	t.Run("prepare submitted job posts", prepareSubmittedJobPosts(ts.URL, client, &employerToken, ids))
+	t.Run("prepare published job post", func(t *testing.T) {
+		statusCode, _, err := moderateJobPost(ts.URL, client, adminToken, ids[0], "approve", &ModerateJobPostParams{})
+		if err != nil || statusCode != http.StatusOK {
+			t.Fatalf("couldn't approve job post: %v (status %d)", err, statusCode)
+		}
+	})
+	postID := ids[0]
+
+	applyCases := []struct {
+		desc   string
+		token  *string
+		postID string
+		params CreateApplicationParams
+		status int
+		error  string
+	}{
+		{
+			desc:   "missing cover message",
+			token:  &applicantToken,
+			postID: postID,
+			params: CreateApplicationParams{CoverMessage: "  "},
+			status: http.StatusBadRequest,
+			error:  "cover_message is required",
+		},
+		{
+			desc:   "post not published",
+			token:  &applicantToken,
+			postID: ids[1],
+			params: CreateApplicationParams{CoverMessage: "Hello"},
+			status: http.StatusNotFound,
+			error:  "Job Post not found",
+		},
+		{
+			desc:   "employer forbidden",
+			token:  &employerToken,
+			postID: postID,
+			params: CreateApplicationParams{CoverMessage: "Hello"},
+			status: http.StatusForbidden,
+			error:  "forbidden: applicant access required",
+		},
+		{
+			desc:   "success",
+			token:  &applicantToken,
+			postID: postID,
+			params: CreateApplicationParams{CoverMessage: "I have kept a garden for 40 years."},
+			status: http.StatusCreated,
+		},
+		{
+			desc:   "already applied",
+			token:  &applicantToken,
+			postID: postID,
+			params: CreateApplicationParams{CoverMessage: "Me again."},
+			status: http.StatusConflict,
+			error:  "Already applied to this Job Post",
+		},
+	}
+	var applicationID string
+	for _, tC := range applyCases {
+		t.Run("Apply - "+tC.desc, func(t *testing.T) {
+			statusCode, resp, err := applyToJobPost(ts.URL, client, *tC.token, tC.postID, &tC.params)
+			if err != nil {
+				t.Fatalf("couldn't apply to job post: %s", err)
+			}
+			if statusCode != tC.status {
+				t.Fatalf("expected status %d, got %d: %s", tC.status, statusCode, resp.Error)
+			}
+			if resp.Error != tC.error {
+				t.Fatalf("expected error %q, got %q", tC.error, resp.Error)
+			}
+			if statusCode == http.StatusCreated {
+				applicationID = fmt.Sprintf("%d", resp.Result.ID)
+			}
+		})
+	}
+
+	t.Run("List my applications - success", func(t *testing.T) {
+		statusCode, resp, err := listMyApplications(ts.URL, client, applicantToken)
+		if err != nil {
+			t.Fatalf("couldn't list applications: %s", err)
+		}
+		if statusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, statusCode)
+		}
+		if len(resp.Result) != 1 {
+			t.Fatalf("expected 1 application, got %v", resp.Result)
+		}
+		application := resp.Result[0]
+		if application.JobPostTitle != "Job 0" || application.EmployerName != validEmployer.Name || application.Status != "received" {
+			t.Fatalf("unexpected application: %+v", application)
+		}
+	})
+
+	t.Run("List job post applications - success", func(t *testing.T) {
+		statusCode, resp, err := listMyJobPostApplications(ts.URL, client, employerToken, postID, "")
+		if err != nil {
+			t.Fatalf("couldn't list applications: %s", err)
+		}
+		if statusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, statusCode)
+		}
+		if len(resp.Result) != 1 || resp.Result[0].Applicant.Name != validApplicantAccount.Name || resp.Result[0].Applicant.Email != validApplicantAccount.Email {
+			t.Fatalf("expected the application with the applicant's profile, got %+v", resp.Result)
+		}
+	})
+
+	t.Run("List job post applications - filtered by status", func(t *testing.T) {
+		statusCode, resp, err := listMyJobPostApplications(ts.URL, client, employerToken, postID, "status=offered")
+		if err != nil {
+			t.Fatalf("couldn't list applications: %s", err)
+		}
+		if statusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, statusCode)
+		}
+		if len(resp.Result) != 0 {
+			t.Fatalf("expected no application, got %+v", resp.Result)
+		}
+	})
+
+	t.Run("Get job post application - wrong post", func(t *testing.T) {
+		statusCode, _, err := getMyJobPostApplication(ts.URL, client, employerToken, ids[1], applicationID)
+		if err != nil {
+			t.Fatalf("couldn't get application: %s", err)
+		}
+		if statusCode != http.StatusNotFound {
+			t.Fatalf("expected status %d, got %d", http.StatusNotFound, statusCode)
+		}
+	})
+
+	statusCases := []struct {
+		desc   string
+		status string
+		code   int
+	}{
+		{
+			desc:   "invalid status",
+			status: "hired",
+			code:   http.StatusBadRequest,
+		},
+		{
+			desc:   "skipping a step",
+			status: "interview",
+			code:   http.StatusConflict,
+		},
+		{
+			desc:   "reviewing",
+			status: "reviewing",
+			code:   http.StatusOK,
+		},
+		{
+			desc:   "interview",
+			status: "interview",
+			code:   http.StatusOK,
+		},
+		{
+			desc:   "back to received",
+			status: "received",
+			code:   http.StatusConflict,
+		},
+	}
+	for _, tC := range statusCases {
+		t.Run("Change application status - "+tC.desc, func(t *testing.T) {
+			statusCode, resp, err := changeMyJobPostApplicationStatus(ts.URL, client, employerToken, postID, applicationID, &ChangeApplicationStatusParams{Status: tC.status})
+			if err != nil {
+				t.Fatalf("couldn't change application status: %s", err)
+			}
+			if statusCode != tC.code {
+				t.Fatalf("expected status %d, got %d: %s", tC.code, statusCode, resp.Error)
+			}
+		})
+	}
+
+	t.Run("Get my application - status updated", func(t *testing.T) {
+		statusCode, resp, err := getMyApplication(ts.URL, client, applicantToken, applicationID)
+		if err != nil {
+			t.Fatalf("couldn't get application: %s", err)
+		}
+		if statusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, statusCode)
+		}
+		if resp.Result.Status != "interview" {
+			t.Fatalf("expected status %q, got %q", "interview", resp.Result.Status)
+		}
+	})
+
+	t.Run("List my application history - success", func(t *testing.T) {
+		statusCode, resp, err := listMyApplicationHistory(ts.URL, client, applicantToken, applicationID)
+		if err != nil {
+			t.Fatalf("couldn't list application history: %s", err)
+		}
+		if statusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, statusCode)
+		}
+		var steps []string
+		for _, change := range resp.Result {
+			steps = append(steps, change.FromStatus+"->"+change.ToStatus)
+		}
+		expected := "[->received received->reviewing reviewing->interview]"
+		if fmt.Sprint(steps) != expected {
+			t.Fatalf("expected history %s, got %v", expected, steps)
+		}
+	})
+
+	t.Run("Get my application - other applicant not found", func(t *testing.T) {
+		var otherToken string
+		account := &CreateApplicantAccountParams{Email: "marcel@example.com", Password: "Applicant123!"}
+		statusCode, _, err := createApplicantAccount(ts.URL, client, account)
+		if err != nil || statusCode != http.StatusCreated {
+			t.Fatalf("couldn't create applicant account: %v (status %d)", err, statusCode)
+		}
+		statusCode, loginResp, err := applicantLogin(ts.URL, client, &ApplicantLoginParams{Email: account.Email, Password: account.Password})
+		if err != nil || statusCode != http.StatusOK {
+			t.Fatalf("couldn't login applicant: %v (status %d)", err, statusCode)
+		}
+		otherToken = loginResp.Result.Token
+		statusCode, _, err = getMyApplication(ts.URL, client, otherToken, applicationID)
+		if err != nil {
+			t.Fatalf("couldn't get application: %s", err)
+		}
+		if statusCode != http.StatusNotFound {
+			t.Fatalf("expected status %d, got %d", http.StatusNotFound, statusCode)
+		}
+	})
+}
@@ -0,0 +1,117 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gruyaume/lesvieux/internal/db"
+)
+
+type AuditEventResponse struct {
+	ID         int64           `json:"id"`
+	OccurredAt string          `json:"occurred_at"`
+	ActorID    *int64          `json:"actor_id,omitempty"`
+	ActorRole  *int64          `json:"actor_role,omitempty"`
+	Action     string          `json:"action"`
+	TargetType string          `json:"target_type"`
+	TargetID   *int64          `json:"target_id,omitempty"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+	IP         string          `json:"ip"`
+	UserAgent  string          `json:"user_agent"`
+}
+
+func newAuditEventResponse(event db.AuditEvent) AuditEventResponse {
+	response := AuditEventResponse{
+		ID:         event.ID,
+		OccurredAt: event.OccurredAt,
+		Action:     event.Action,
+		TargetType: event.TargetType,
+		IP:         event.Ip,
+		UserAgent:  event.UserAgent,
+	}
+	if event.ActorID.Valid {
+		response.ActorID = &event.ActorID.Int64
+	}
+	if event.ActorRole.Valid {
+		response.ActorRole = &event.ActorRole.Int64
+	}
+	if event.TargetID.Valid {
+		response.TargetID = &event.TargetID.Int64
+	}
+	if event.BeforeSummary.Valid {
+		response.Before = json.RawMessage(event.BeforeSummary.String)
+	}
+	if event.AfterSummary.Valid {
+		response.After = json.RawMessage(event.AfterSummary.String)
+	}
+	return response
+}
+
+// ListAuditEvents returns a page of the audit log, most recent events first by default.
+// Events can be filtered by actor_id, actor_role, action, target_type, target_id, occurred_after and occurred_before.
+func ListAuditEvents(env *HandlerConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		page, err := parsePageRequest(r, "-occurred_at", "occurred_at")
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "%s", err)
+			return
+		}
+		params, err := parseAuditEventFilters(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "%s", err)
+			return
+		}
+		params.CursorKey = page.CursorKey()
+		params.CursorID = page.CursorID()
+		params.Limit = page.FetchLimit()
+		var events []db.AuditEvent
+		if page.Descending() {
+			events, err = env.DBQueries.ListAuditEventsNewestFirst(context.Background(), params)
+		} else {
+			events, err = env.DBQueries.ListAuditEventsOldestFirst(context.Background(), db.ListAuditEventsOldestFirstParams(params))
+		}
+		if err != nil {
+			logError(r, "internal error", err)
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		events, nextCursor := paginate(page, events, func(e db.AuditEvent) (string, int64) { return e.OccurredAt, e.ID })
+		eventsResponse := make([]AuditEventResponse, 0, len(events))
+		for _, event := range events {
+			eventsResponse = append(eventsResponse, newAuditEventResponse(event))
+		}
+		err = writeJSONPage(w, eventsResponse, nextCursor)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+	}
+}
+
+// parseAuditEventFilters reads the query parameters narrowing down the audit log.
+func parseAuditEventFilters(r *http.Request) (db.ListAuditEventsNewestFirstParams, error) {
+	query := r.URL.Query()
+	filters := db.ListAuditEventsNewestFirstParams{
+		Action:     nullString(query.Get("action")),
+		TargetType: nullString(query.Get("target_type")),
+	}
+	var err error
+	if filters.ActorID, err = parseIntFilter(r, "actor_id"); err != nil {
+		return filters, err
+	}
+	if filters.ActorRole, err = parseIntFilter(r, "actor_role"); err != nil {
+		return filters, err
+	}
+	if filters.TargetID, err = parseIntFilter(r, "target_id"); err != nil {
+		return filters, err
+	}
+	if filters.OccurredAfter, err = parseTimeFilter(r, "occurred_after"); err != nil {
+		return filters, err
+	}
+	if filters.OccurredBefore, err = parseTimeFilter(r, "occurred_before"); err != nil {
+		return filters, err
+	}
+	return filters, nil
+}
@@ -0,0 +1,210 @@
+package server_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+type AuditEventResponseResult struct {
+	ID         int64           `json:"id"`
+	OccurredAt string          `json:"occurred_at"`
+	ActorID    *int64          `json:"actor_id"`
+	ActorRole  *int64          `json:"actor_role"`
+	Action     string          `json:"action"`
+	TargetType string          `json:"target_type"`
+	TargetID   *int64          `json:"target_id"`
+	Before     json.RawMessage `json:"before"`
+	After      json.RawMessage `json:"after"`
+	IP         string          `json:"ip"`
+	UserAgent  string          `json:"user_agent"`
+}
+
+type ListAuditEventsResponse struct {
+	Result     []AuditEventResponseResult `json:"result"`
+	NextCursor string                     `json:"next_cursor,omitempty"`
+	Error      string                     `json:"error,omitempty"`
+}
+
+func listAuditEvents(url string, client *http.Client, token string, query string) (int, *ListAuditEventsResponse, error) {
+	var response ListAuditEventsResponse
+	statusCode, err := apiRequest("GET", url, client, token, "/admin/audit_events"+query, nil, &response)
+	if err != nil {
+		return 0, nil, err
+	}
+	return statusCode, &response, nil
+}
+
+func TestAuditEventsEndToEnd(t *testing.T) {
+	ts, _, err := setupServer()
+	if err != nil {
+		t.Fatalf("couldn't create test server: %s", err)
+	}
+	defer ts.Close()
+	client := ts.Client()
+	var adminToken string
+	var employerToken string
+	t.Run("prepare admin account", prepareAdminAccount(ts.URL, client, &adminToken))
+	t.Run("prepare employer account", prepareEmployerAccount(ts.URL, client, &adminToken, &employerToken))
+
+	var employerID string
+	t.Run("change employer account password and delete employer", func(t *testing.T) {
+		statusCode, createResponse, err := createEmployer(ts.URL, client, adminToken, &CreateEmployerParams{Name: "otheremployer"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if statusCode != http.StatusCreated {
+			t.Fatalf("expected status %d, got %d", http.StatusCreated, statusCode)
+		}
+		employerID = fmt.Sprintf("%d", createResponse.Result.Id)
+		account := CreateEmployerAccountParams{Email: "someone@otheremployer.com", Password: "Otherpass123!"}
+		statusCode, accountResponse, err := createEmployerAccount(ts.URL, client, adminToken, employerID, &account)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if statusCode != http.StatusCreated {
+			t.Fatalf("expected status %d, got %d", http.StatusCreated, statusCode)
+		}
+		accountID := fmt.Sprintf("%d", accountResponse.Result.Id)
+		var changeResponse ChangeEmployerPasswordResponse
+		path := "/employers/" + employerID + "/accounts/" + accountID + "/change_password"
+		statusCode, err = apiRequest("POST", ts.URL, client, adminToken, path, &ChangeEmployerPasswordRequest{Password: "Newpassword123!"}, &changeResponse)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if statusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, statusCode)
+		}
+		statusCode, _, err = deleteEmployer(ts.URL, client, adminToken, employerID+"?cascade=true")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if statusCode != http.StatusAccepted {
+			t.Fatalf("expected status %d, got %d", http.StatusAccepted, statusCode)
+		}
+	})
+
+	t.Run("list audit events, most recent first", func(t *testing.T) {
+		statusCode, response, err := listAuditEvents(ts.URL, client, adminToken, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if statusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusOK, statusCode, response.Error)
+		}
+		expectedActions := []string{
+			"employer.delete",
+			"employer_account.change_password",
+			"employer_account.create",
+			"employer.create",
+			"employer_account.create",
+			"employer.create",
+			"admin_account.create",
+		}
+		if len(response.Result) != len(expectedActions) {
+			t.Fatalf("expected %d audit events, got %d: %+v", len(expectedActions), len(response.Result), response.Result)
+		}
+		for i, action := range expectedActions {
+			if response.Result[i].Action != action {
+				t.Fatalf("expected event %d to be %s, got %s", i, action, response.Result[i].Action)
+			}
+		}
+		deletion := response.Result[0]
+		if deletion.TargetType != "employer" || deletion.TargetID == nil || fmt.Sprintf("%d", *deletion.TargetID) != employerID {
+			t.Fatalf("expected the deletion of employer %s, got %+v", employerID, deletion)
+		}
+		if deletion.ActorID == nil || deletion.ActorRole == nil || *deletion.ActorRole != 1 {
+			t.Fatalf("expected the deletion to be attributed to the admin, got %+v", deletion)
+		}
+		if !strings.Contains(string(deletion.Before), `"name":"otheremployer"`) {
+			t.Fatalf("expected a summary of the deleted employer, got %s", deletion.Before)
+		}
+		if deletion.IP == "" || deletion.UserAgent == "" {
+			t.Fatalf("expected the IP address and user agent of the request, got %+v", deletion)
+		}
+		firstAccount := response.Result[len(response.Result)-1]
+		if firstAccount.ActorID != nil {
+			t.Fatalf("expected the first admin account to be created anonymously, got actor %d", *firstAccount.ActorID)
+		}
+		for _, event := range response.Result {
+			if strings.Contains(string(event.Before)+string(event.After), "password") {
+				t.Fatalf("audit event %s should not hold passwords: %s %s", event.Action, event.Before, event.After)
+			}
+		}
+	})
+
+	t.Run("filter audit events", func(t *testing.T) {
+		cases := []struct {
+			query    string
+			expected int
+		}{
+			{"?action=employer.create", 2},
+			{"?target_type=employer_account", 3},
+			{"?target_type=employer&target_id=" + employerID, 2},
+			{"?actor_role=1", 6},
+			{"?occurred_after=" + url.QueryEscape("2000-01-01T00:00:00Z"), 7},
+			{"?occurred_before=" + url.QueryEscape("2000-01-01T00:00:00Z"), 0},
+		}
+		for _, tc := range cases {
+			statusCode, response, err := listAuditEvents(ts.URL, client, adminToken, tc.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if statusCode != http.StatusOK {
+				t.Fatalf("%s: expected status %d, got %d: %s", tc.query, http.StatusOK, statusCode, response.Error)
+			}
+			if len(response.Result) != tc.expected {
+				t.Fatalf("%s: expected %d audit events, got %d", tc.query, tc.expected, len(response.Result))
+			}
+		}
+	})
+
+	t.Run("paginate audit events", func(t *testing.T) {
+		var actions []string
+		query := "?sort=occurred_at&limit=3"
+		for {
+			statusCode, response, err := listAuditEvents(ts.URL, client, adminToken, query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if statusCode != http.StatusOK {
+				t.Fatalf("expected status %d, got %d: %s", http.StatusOK, statusCode, response.Error)
+			}
+			for _, event := range response.Result {
+				actions = append(actions, event.Action)
+			}
+			if response.NextCursor == "" {
+				break
+			}
+			query = "?sort=occurred_at&limit=3&cursor=" + url.QueryEscape(response.NextCursor)
+		}
+		if len(actions) != 7 || actions[0] != "admin_account.create" || actions[6] != "employer.delete" {
+			t.Fatalf("expected the 7 audit events oldest first, got %v", actions)
+		}
+	})
+
+	t.Run("invalid filters are rejected", func(t *testing.T) {
+		for _, query := range []string{"?occurred_after=yesterday", "?actor_id=admin", "?sort=action"} {
+			statusCode, _, err := listAuditEvents(ts.URL, client, adminToken, query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if statusCode != http.StatusBadRequest {
+				t.Fatalf("%s: expected status %d, got %d", query, http.StatusBadRequest, statusCode)
+			}
+		}
+	})
+
+	t.Run("only admins can read the audit log", func(t *testing.T) {
+		statusCode, _, err := listAuditEvents(ts.URL, client, employerToken, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if statusCode != http.StatusForbidden {
+			t.Fatalf("expected status %d, got %d", http.StatusForbidden, statusCode)
+		}
+	})
+}
@@ -0,0 +1,109 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gruyaume/lesvieux/internal/backup"
+	"github.com/gruyaume/lesvieux/internal/db"
+)
+
+type BackupResponse struct {
+	Name       string `json:"name"`
+	Size       int64  `json:"size"`
+	Compressed bool   `json:"compressed"`
+	CreatedAt  string `json:"created_at"`
+}
+
+func newBackupResponse(info backup.Info) BackupResponse {
+	return BackupResponse{
+		Name:       info.Name,
+		Size:       info.Size,
+		Compressed: info.Compressed,
+		CreatedAt:  info.CreatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+// backupsAvailable writes an error and returns false when backups can't be taken:
+// they are only supported on SQLite, in the directory of the configuration file.
+func backupsAvailable(w http.ResponseWriter, env *HandlerConfig) bool {
+	if env.DBQueries.Driver() != db.SQLite {
+		writeError(w, http.StatusNotImplemented, "Backups are only supported on SQLite")
+		return false
+	}
+	if env.Backups.Dir == "" {
+		writeError(w, http.StatusServiceUnavailable, "Backups are disabled: set `backup.dir` in the configuration file")
+		return false
+	}
+	return true
+}
+
+// CreateBackup takes a snapshot of the database in the backup directory, while the server keeps running.
+// The oldest backups are removed so that the configured number is kept.
+func CreateBackup(env *HandlerConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !backupsAvailable(w, env) {
+			return
+		}
+		info, err := backup.CreateInDir(context.Background(), env.DBQueries.DB(), env.Backups.Dir, env.Backups.Keep, env.Backups.Compress)
+		if err != nil {
+			logError(r, "internal error", err)
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		err = recordAuditEvent(context.Background(), env.DBQueries, r, auditEvent{
+			Action:     AuditActionBackupCreate,
+			TargetType: AuditTargetBackup,
+			After:      map[string]any{"name": info.Name, "size": info.Size},
+		})
+		if err != nil {
+			logError(r, "couldn't record backup in the audit log", err)
+		}
+		w.WriteHeader(http.StatusCreated)
+		err = writeJSON(w, newBackupResponse(info))
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+	}
+}
+
+// ListBackups lists the backups of the backup directory, most recent first.
+func ListBackups(env *HandlerConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !backupsAvailable(w, env) {
+			return
+		}
+		backups, err := backup.List(env.Backups.Dir)
+		if err != nil {
+			logError(r, "internal error", err)
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		backupsResponse := make([]BackupResponse, 0, len(backups))
+		for _, info := range backups {
+			backupsResponse = append(backupsResponse, newBackupResponse(info))
+		}
+		err = writeJSON(w, backupsResponse)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+	}
+}
+
+// startScheduledBackups takes a backup in the backup directory every interval, keeping the configured number of them,
+// until ctx is done.
+func startScheduledBackups(ctx context.Context, workers *sync.WaitGroup, dbQueries *db.Queries, backups backup.Config) {
+	runEvery(ctx, workers, backups.Interval, false, func() {
+		info, err := backup.CreateInDir(context.Background(), dbQueries.DB(), backups.Dir, backups.Keep, backups.Compress)
+		if err != nil {
+			slog.Error("couldn't take scheduled backup", "error", err)
+			return
+		}
+		slog.Info("took scheduled backup", "name", info.Name, "bytes", info.Size)
+	})
+}
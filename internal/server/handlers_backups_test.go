@@ -0,0 +1,98 @@
+package server_test
+
+import (
+	"net/http"
+	"testing"
+)
+
+type BackupResponseResult struct {
+	Name       string `json:"name"`
+	Size       int64  `json:"size"`
+	Compressed bool   `json:"compressed"`
+	CreatedAt  string `json:"created_at"`
+}
+
+type CreateBackupResponse struct {
+	Error  string               `json:"error,omitempty"`
+	Result BackupResponseResult `json:"result"`
+}
+
+type ListBackupsResponse struct {
+	Error  string                 `json:"error,omitempty"`
+	Result []BackupResponseResult `json:"result"`
+}
+
+func TestBackupsEndToEnd(t *testing.T) {
+	ts, config, err := setupServer()
+	if err != nil {
+		t.Fatalf("couldn't create test server: %s", err)
+	}
+	defer ts.Close()
+	if testPostgresURL != "" {
+		t.Skip("backups are only supported on SQLite")
+	}
+	client := ts.Client()
+	var adminToken string
+	var employerToken string
+	t.Run("prepare admin account", prepareAdminAccount(ts.URL, client, &adminToken))
+	t.Run("prepare employer account", prepareEmployerAccount(ts.URL, client, &adminToken, &employerToken))
+
+	t.Run("backups are disabled without a directory", func(t *testing.T) {
+		var response CreateBackupResponse
+		statusCode, err := apiRequest("POST", ts.URL, client, adminToken, "/admin/backups", nil, &response)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if statusCode != http.StatusServiceUnavailable {
+			t.Fatalf("expected status %d, got %d", http.StatusServiceUnavailable, statusCode)
+		}
+	})
+
+	config.Backups.Dir = t.TempDir()
+	config.Backups.Keep = 2
+	config.Backups.Compress = true
+
+	t.Run("only admins can take backups", func(t *testing.T) {
+		var response CreateBackupResponse
+		statusCode, err := apiRequest("POST", ts.URL, client, employerToken, "/admin/backups", nil, &response)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if statusCode != http.StatusForbidden {
+			t.Fatalf("expected status %d, got %d", http.StatusForbidden, statusCode)
+		}
+	})
+
+	t.Run("take backups", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			var response CreateBackupResponse
+			statusCode, err := apiRequest("POST", ts.URL, client, adminToken, "/admin/backups", nil, &response)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if statusCode != http.StatusCreated {
+				t.Fatalf("expected status %d, got %d: %s", http.StatusCreated, statusCode, response.Error)
+			}
+			if !response.Result.Compressed || response.Result.Size == 0 {
+				t.Fatalf("expected a compressed backup, got %+v", response.Result)
+			}
+		}
+	})
+
+	t.Run("list backups", func(t *testing.T) {
+		var response ListBackupsResponse
+		statusCode, err := apiRequest("GET", ts.URL, client, adminToken, "/admin/backups", nil, &response)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if statusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusOK, statusCode, response.Error)
+		}
+		if len(response.Result) != 2 {
+			t.Fatalf("expected 2 backups to be kept, got %d", len(response.Result))
+		}
+		if response.Result[0].Name <= response.Result[1].Name {
+			t.Fatalf("expected the most recent backup first, got %s then %s", response.Result[0].Name, response.Result[1].Name)
+		}
+	})
+}
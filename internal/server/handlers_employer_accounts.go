@@ -4,7 +4,6 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
-	"log"
 	"net/http"
 	"strconv"
 
@@ -43,12 +42,33 @@ func ListEmployerAccounts(env *HandlerConfig) http.HandlerFunc {
 			writeError(w, http.StatusBadRequest, "Invalid id")
 			return
 		}
-		accounts, err := env.DBQueries.ListEmployerAccounts(context.Background(), employerIdInt)
+		page, err := parsePageRequest(r, "email", "-email")
 		if err != nil {
-			log.Println(err.Error())
+			writeError(w, http.StatusBadRequest, "%s", err)
+			return
+		}
+		var accounts []db.EmployerAccount
+		if page.Descending() {
+			accounts, err = env.DBQueries.ListEmployerAccountsDesc(context.Background(), db.ListEmployerAccountsDescParams{
+				EmployerID: employerIdInt,
+				CursorKey:  page.CursorKey(),
+				CursorID:   page.CursorID(),
+				Limit:      page.FetchLimit(),
+			})
+		} else {
+			accounts, err = env.DBQueries.ListEmployerAccounts(context.Background(), db.ListEmployerAccountsParams{
+				EmployerID: employerIdInt,
+				CursorKey:  page.CursorKey(),
+				CursorID:   page.CursorID(),
+				Limit:      page.FetchLimit(),
+			})
+		}
+		if err != nil {
+			logError(r, "internal error", err)
 			writeError(w, http.StatusInternalServerError, "internal error")
 			return
 		}
+		accounts, nextCursor := paginate(page, accounts, func(a db.EmployerAccount) (string, int64) { return a.Email, a.ID })
 		accountsResponse := make([]GetEmployerAccountResponse, 0, len(accounts))
 		for i := range accounts {
 			accountsResponse = append(accountsResponse, GetEmployerAccountResponse{
@@ -56,7 +76,7 @@ func ListEmployerAccounts(env *HandlerConfig) http.HandlerFunc {
 				Email: accounts[i].Email,
 			})
 		}
-		err = writeJSON(w, accountsResponse)
+		err = writeJSONPage(w, accountsResponse, nextCursor)
 		if err != nil {
 			writeError(w, http.StatusInternalServerError, "internal error")
 			return
@@ -91,7 +111,7 @@ func GetEmployerAccount(env *HandlerConfig) http.HandlerFunc {
 				writeError(w, http.StatusNotFound, "EmployerAccount not found")
 				return
 			}
-			log.Println(err.Error())
+			logError(r, "internal error", err)
 			writeError(w, http.StatusInternalServerError, "internal error")
 			return
 		}
@@ -110,7 +130,7 @@ func GetEmployerAccount(env *HandlerConfig) http.HandlerFunc {
 
 func GetMyEmployerAccount(env *HandlerConfig) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		claims, err := getClaimsFromAuthorizationHeader(r.Header.Get("Authorization"), env.JWTSecret)
+		claims, err := getClaimsFromAuthorizationHeader(r.Header.Get("Authorization"), env.JWTKeys)
 		if err != nil {
 			writeError(w, http.StatusUnauthorized, err.Error())
 			return
@@ -168,6 +188,16 @@ func CreateEmployerAccount(env *HandlerConfig) http.HandlerFunc {
 			return
 		}
 
+		_, err = env.DBQueries.GetEmployer(context.Background(), employerIdInt)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				writeError(w, http.StatusNotFound, "Employer not found")
+				return
+			}
+			logError(r, "internal error", err)
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
 		_, err = env.DBQueries.GetEmployerAccountByEmail(context.Background(), account.Email)
 		if err == nil {
 			writeError(w, http.StatusConflict, "Account already exists")
@@ -176,7 +206,7 @@ func CreateEmployerAccount(env *HandlerConfig) http.HandlerFunc {
 
 		passwordHash, err := GeneratePasswordHash(account.Password)
 		if err != nil {
-			log.Println("Failed to generate password hash: " + err.Error())
+			logError(r, "couldn't generate password hash", err)
 			writeError(w, http.StatusInternalServerError, "internal error")
 			return
 		}
@@ -185,9 +215,22 @@ func CreateEmployerAccount(env *HandlerConfig) http.HandlerFunc {
 			PasswordHash: passwordHash,
 			EmployerID:   employerIdInt,
 		}
-		newEmployerAccount, err := env.DBQueries.CreateEmployerAccount(context.Background(), newEmployerAccountParams)
+		var newEmployerAccount db.EmployerAccount
+		err = env.DBQueries.ExecTx(context.Background(), func(q *db.Queries) error {
+			var err error
+			newEmployerAccount, err = q.CreateEmployerAccount(context.Background(), newEmployerAccountParams)
+			if err != nil {
+				return err
+			}
+			return recordAuditEvent(context.Background(), q, r, auditEvent{
+				Action:     AuditActionEmployerAccountCreate,
+				TargetType: AuditTargetEmployerAccount,
+				TargetID:   newEmployerAccount.ID,
+				After:      map[string]any{"email": newEmployerAccount.Email, "employer_id": newEmployerAccount.EmployerID},
+			})
+		})
 		if err != nil {
-			log.Println("Failed to create account: " + err.Error())
+			logError(r, "couldn't create account", err)
 			writeError(w, http.StatusInternalServerError, "internal error")
 			return
 		}
@@ -221,13 +264,13 @@ func DeleteEmployerAccount(env *HandlerConfig) http.HandlerFunc {
 			EmployerID: employerIdInt,
 			ID:         userIdInt,
 		}
-		_, err = env.DBQueries.GetEmployerAccount(context.Background(), getAccountParams)
+		account, err := env.DBQueries.GetEmployerAccount(context.Background(), getAccountParams)
 		if err != nil {
 			if err == sql.ErrNoRows {
 				writeError(w, http.StatusNotFound, "EmployerAccount not found")
 				return
 			}
-			log.Println(err.Error())
+			logError(r, "internal error", err)
 			writeError(w, http.StatusInternalServerError, "internal error")
 			return
 		}
@@ -235,9 +278,25 @@ func DeleteEmployerAccount(env *HandlerConfig) http.HandlerFunc {
 			EmployerID: employerIdInt,
 			ID:         userIdInt,
 		}
-		err = env.DBQueries.DeleteEmployerAccount(context.Background(), deleteAccountParams)
+		err = env.DBQueries.ExecTx(context.Background(), func(q *db.Queries) error {
+			if err := q.DeleteEmployerAccount(context.Background(), deleteAccountParams); err != nil {
+				return err
+			}
+			if err := revokeAccountSessions(context.Background(), q, userIdInt, EmployerRole, 0); err != nil {
+				return err
+			}
+			if _, err := deleteSecondFactor(context.Background(), q, userIdInt, EmployerRole); err != nil {
+				return err
+			}
+			return recordAuditEvent(context.Background(), q, r, auditEvent{
+				Action:     AuditActionEmployerAccountDelete,
+				TargetType: AuditTargetEmployerAccount,
+				TargetID:   userIdInt,
+				Before:     map[string]any{"email": account.Email, "employer_id": account.EmployerID},
+			})
+		})
 		if err != nil {
-			log.Println(err.Error())
+			logError(r, "internal error", err)
 			writeError(w, http.StatusInternalServerError, "internal error")
 			return
 		}
@@ -280,7 +339,7 @@ func ChangeEmployerAccountPassword(env *HandlerConfig) http.HandlerFunc {
 				writeError(w, http.StatusNotFound, "EmployerAccount not found")
 				return
 			}
-			log.Println(err.Error())
+			logError(r, "internal error", err)
 			writeError(w, http.StatusInternalServerError, "internal error")
 			return
 		}
@@ -298,7 +357,7 @@ func ChangeEmployerAccountPassword(env *HandlerConfig) http.HandlerFunc {
 		}
 		passwordHash, err := GeneratePasswordHash(changeEmployerAccountPassword.Password)
 		if err != nil {
-			log.Println("Failed to generate password hash: " + err.Error())
+			logError(r, "couldn't generate password hash", err)
 			writeError(w, http.StatusInternalServerError, "internal error")
 			return
 		}
@@ -306,9 +365,22 @@ func ChangeEmployerAccountPassword(env *HandlerConfig) http.HandlerFunc {
 			ID:           UserIdInt64,
 			PasswordHash: passwordHash,
 		}
-		err = env.DBQueries.UpdateEmployerAccount(context.Background(), updateEmployerAccountParams)
+		err = env.DBQueries.ExecTx(context.Background(), func(q *db.Queries) error {
+			if err := q.UpdateEmployerAccount(context.Background(), updateEmployerAccountParams); err != nil {
+				return err
+			}
+			if err := revokeAccountSessions(context.Background(), q, UserIdInt64, EmployerRole, 0); err != nil {
+				return err
+			}
+			return recordAuditEvent(context.Background(), q, r, auditEvent{
+				Action:     AuditActionEmployerAccountChangePassword,
+				TargetType: AuditTargetEmployerAccount,
+				TargetID:   UserIdInt64,
+				After:      map[string]any{"other_sessions_revoked": true},
+			})
+		})
 		if err != nil {
-			log.Println(err.Error())
+			logError(r, "internal error", err)
 			writeError(w, http.StatusInternalServerError, "internal error")
 			return
 		}
@@ -326,7 +398,8 @@ func ChangeEmployerAccountPassword(env *HandlerConfig) http.HandlerFunc {
 
 func ChangeMyEmployerAccountPassword(env *HandlerConfig) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		claims, err := getClaimsFromAuthorizationHeader(r.Header.Get("Authorization"), env.JWTSecret)
+		sessionID := r.Context().Value(sessionIDKey).(int64)
+		claims, err := getClaimsFromAuthorizationHeader(r.Header.Get("Authorization"), env.JWTKeys)
 		if err != nil {
 			writeError(w, http.StatusUnauthorized, err.Error())
 			return
@@ -361,7 +434,7 @@ func ChangeMyEmployerAccountPassword(env *HandlerConfig) http.HandlerFunc {
 		}
 		passwordHash, err := GeneratePasswordHash(changeEmployerAccountPassword.Password)
 		if err != nil {
-			log.Println("Failed to generate password hash: " + err.Error())
+			logError(r, "couldn't generate password hash", err)
 			writeError(w, http.StatusInternalServerError, "internal error")
 			return
 		}
@@ -369,9 +442,22 @@ func ChangeMyEmployerAccountPassword(env *HandlerConfig) http.HandlerFunc {
 			ID:           idInt,
 			PasswordHash: passwordHash,
 		}
-		err = env.DBQueries.UpdateEmployerAccount(context.Background(), updateEmployerAccountParams)
+		err = env.DBQueries.ExecTx(context.Background(), func(q *db.Queries) error {
+			if err := q.UpdateEmployerAccount(context.Background(), updateEmployerAccountParams); err != nil {
+				return err
+			}
+			if err := revokeAccountSessions(context.Background(), q, idInt, EmployerRole, sessionID); err != nil {
+				return err
+			}
+			return recordAuditEvent(context.Background(), q, r, auditEvent{
+				Action:     AuditActionEmployerAccountChangePassword,
+				TargetType: AuditTargetEmployerAccount,
+				TargetID:   idInt,
+				After:      map[string]any{"other_sessions_revoked": true},
+			})
+		})
 		if err != nil {
-			log.Println(err.Error())
+			logError(r, "internal error", err)
 			writeError(w, http.StatusInternalServerError, "internal error")
 			return
 		}
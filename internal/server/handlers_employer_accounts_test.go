@@ -184,18 +184,29 @@ func TestUsersHandlersCreateEmployerAccount(t *testing.T) {
 	client := ts.Client()
 	var adminToken string
 	t.Run("prepare admin accounts and tokens", prepareAdminAccount(ts.URL, client, &adminToken))
+	t.Run("prepare employer", func(t *testing.T) {
+		statusCode, _, err := createEmployer(ts.URL, client, adminToken, &validEmployer)
+		if err != nil {
+			t.Fatalf("couldn't create employer: %s", err)
+		}
+		if statusCode != http.StatusCreated {
+			t.Fatalf("expected status %d, got %d", http.StatusCreated, statusCode)
+		}
+	})
 
 	testCases := []struct {
 		desc             string
 		auth             string
+		employerID       string
 		data             CreateEmployerAccountParams
 		expectedResponse CreateEmployerAccountResponse
 		status           int
 	}{
 		{
-			desc: "Admin create user - success",
-			data: CreateEmployerAccountParams{Email: "testuser@guillaume.com", Password: "Password1!"},
-			auth: adminToken,
+			desc:       "Admin create user - success",
+			employerID: "1",
+			data:       CreateEmployerAccountParams{Email: "testuser@guillaume.com", Password: "Password1!"},
+			auth:       adminToken,
 			expectedResponse: CreateEmployerAccountResponse{
 				Result: CreateEmployerAccountResponseResult{
 					Id: 1,
@@ -203,10 +214,20 @@ func TestUsersHandlersCreateEmployerAccount(t *testing.T) {
 			},
 			status: http.StatusCreated,
 		},
+		{
+			desc:       "Admin create user - employer not found",
+			employerID: "2",
+			data:       CreateEmployerAccountParams{Email: "otheruser@guillaume.com", Password: "Password1!"},
+			auth:       adminToken,
+			expectedResponse: CreateEmployerAccountResponse{
+				Error: "Employer not found",
+			},
+			status: http.StatusNotFound,
+		},
 	}
 	for _, tC := range testCases {
 		t.Run(tC.desc, func(t *testing.T) {
-			statusCode, resp, err := createEmployerAccount(ts.URL, client, tC.auth, "1", &tC.data)
+			statusCode, resp, err := createEmployerAccount(ts.URL, client, tC.auth, tC.employerID, &tC.data)
 			if err != nil {
 				t.Fatalf("couldn't create account: %s", err)
 			}
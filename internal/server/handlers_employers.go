@@ -4,7 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
-	"log"
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -19,6 +19,21 @@ type CreateEmployerResponse struct {
 	ID int64 `json:"id"`
 }
 
+// DeleteEmployerResponse reports what is removed along with an employer.
+type DeleteEmployerResponse struct {
+	ID               int64 `json:"id"`
+	EmployerAccounts int64 `json:"employer_accounts"`
+	JobPosts         int64 `json:"job_posts"`
+	Applications     int64 `json:"applications"`
+}
+
+var (
+	// errEmployerHasDependents rolls back the deletion of an employer that has accounts or job posts, without cascade.
+	errEmployerHasDependents = errors.New("employer has accounts or job posts")
+	// errEmployerDeletionDryRun rolls back the deletion of an employer once what it would remove is counted.
+	errEmployerDeletionDryRun = errors.New("dry run")
+)
+
 type GetEmployerResponse struct {
 	ID   int64  `json:"id"`
 	Name string `json:"name"`
@@ -26,12 +41,31 @@ type GetEmployerResponse struct {
 
 func ListEmployers(env *HandlerConfig) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		employers, err := env.DBQueries.ListEmployers(context.Background())
+		page, err := parsePageRequest(r, "name", "-name")
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "%s", err)
+			return
+		}
+		var employers []db.Employer
+		if page.Descending() {
+			employers, err = env.DBQueries.ListEmployersDesc(context.Background(), db.ListEmployersDescParams{
+				CursorKey: page.CursorKey(),
+				CursorID:  page.CursorID(),
+				Limit:     page.FetchLimit(),
+			})
+		} else {
+			employers, err = env.DBQueries.ListEmployers(context.Background(), db.ListEmployersParams{
+				CursorKey: page.CursorKey(),
+				CursorID:  page.CursorID(),
+				Limit:     page.FetchLimit(),
+			})
+		}
 		if err != nil {
-			log.Println(err.Error())
+			logError(r, "internal error", err)
 			writeError(w, http.StatusInternalServerError, "internal error")
 			return
 		}
+		employers, nextCursor := paginate(page, employers, func(e db.Employer) (string, int64) { return e.Name, e.ID })
 		employersResponse := make([]GetEmployerResponse, 0, len(employers))
 		for i := range employers {
 			employersResponse = append(employersResponse, GetEmployerResponse{
@@ -39,7 +73,7 @@ func ListEmployers(env *HandlerConfig) http.HandlerFunc {
 				Name: employers[i].Name,
 			})
 		}
-		err = writeJSON(w, employersResponse)
+		err = writeJSONPage(w, employersResponse, nextCursor)
 		if err != nil {
 			writeError(w, http.StatusInternalServerError, "internal error")
 			return
@@ -64,7 +98,7 @@ func GetEmployer(env *HandlerConfig) http.HandlerFunc {
 				writeError(w, http.StatusNotFound, "Employer not found")
 				return
 			}
-			log.Println(err.Error())
+			logError(r, "internal error", err)
 			writeError(w, http.StatusInternalServerError, "internal error")
 			return
 		}
@@ -94,9 +128,22 @@ func CreateEmployer(env *HandlerConfig) http.HandlerFunc {
 			return
 		}
 
-		newEmployer, err := env.DBQueries.CreateEmployer(context.Background(), employer.Name)
+		var newEmployer db.Employer
+		err := env.DBQueries.ExecTx(context.Background(), func(q *db.Queries) error {
+			var err error
+			newEmployer, err = q.CreateEmployer(context.Background(), employer.Name)
+			if err != nil {
+				return err
+			}
+			return recordAuditEvent(context.Background(), q, r, auditEvent{
+				Action:     AuditActionEmployerCreate,
+				TargetType: AuditTargetEmployer,
+				TargetID:   newEmployer.ID,
+				After:      map[string]any{"name": newEmployer.Name},
+			})
+		})
 		if err != nil {
-			log.Println("Failed to create employer: " + err.Error())
+			logError(r, "couldn't create employer", err)
 			writeError(w, http.StatusInternalServerError, "internal error")
 			return
 		}
@@ -110,8 +157,9 @@ func CreateEmployer(env *HandlerConfig) http.HandlerFunc {
 	}
 }
 
-// DeleteEmployer handler receives an id as a path parameter,
-// deletes the corresponding Employer, and returns a http.StatusNoContent on success
+// DeleteEmployer deletes an employer along with its accounts, its job posts and their applications.
+// An employer that has accounts or job posts is only deleted with cascade=true; with dry_run=true, nothing is deleted.
+// Either way, the response reports what is or would be removed.
 func DeleteEmployer(env *HandlerConfig) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		id := r.PathValue("employer_id")
@@ -120,25 +168,89 @@ func DeleteEmployer(env *HandlerConfig) http.HandlerFunc {
 			writeError(w, http.StatusBadRequest, "Invalid id")
 			return
 		}
-		_, err = env.DBQueries.GetEmployer(context.Background(), idInt)
+		cascade, err := parseBoolFilter(r, "cascade")
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "%s", err)
+			return
+		}
+		dryRun, err := parseBoolFilter(r, "dry_run")
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "%s", err)
+			return
+		}
+		employer, err := env.DBQueries.GetEmployer(context.Background(), idInt)
 		if err != nil {
 			if err == sql.ErrNoRows {
 				writeError(w, http.StatusNotFound, "Employer not found")
 				return
 			}
-			log.Println(err.Error())
+			logError(r, "internal error", err)
 			writeError(w, http.StatusInternalServerError, "internal error")
 			return
 		}
 
-		err = env.DBQueries.DeleteEmployer(context.Background(), idInt)
-		if err != nil {
-			log.Println(err.Error())
+		response := DeleteEmployerResponse{ID: idInt}
+		err = env.DBQueries.ExecTx(context.Background(), func(q *db.Queries) error {
+			response, err = getEmployerDependents(context.Background(), q, idInt)
+			if err != nil {
+				return err
+			}
+			if dryRun.Bool {
+				return errEmployerDeletionDryRun
+			}
+			if !cascade.Bool && (response.EmployerAccounts > 0 || response.JobPosts > 0) {
+				return errEmployerHasDependents
+			}
+			err := q.DeleteEmployerSessions(context.Background(), db.DeleteEmployerSessionsParams{
+				Role:       EmployerRole,
+				EmployerID: idInt,
+			})
+			if err != nil {
+				return err
+			}
+			err = q.DeleteEmployerTOTPFactors(context.Background(), db.DeleteEmployerTOTPFactorsParams{
+				Role:       EmployerRole,
+				EmployerID: idInt,
+			})
+			if err != nil {
+				return err
+			}
+			err = q.DeleteEmployerRecoveryCodes(context.Background(), db.DeleteEmployerRecoveryCodesParams{
+				Role:       EmployerRole,
+				EmployerID: idInt,
+			})
+			if err != nil {
+				return err
+			}
+			if err := q.DeleteEmployer(context.Background(), idInt); err != nil {
+				return err
+			}
+			return recordAuditEvent(context.Background(), q, r, auditEvent{
+				Action:     AuditActionEmployerDelete,
+				TargetType: AuditTargetEmployer,
+				TargetID:   idInt,
+				Before: map[string]any{
+					"name":              employer.Name,
+					"employer_accounts": response.EmployerAccounts,
+					"job_posts":         response.JobPosts,
+					"applications":      response.Applications,
+				},
+			})
+		})
+		switch err {
+		case nil:
+			w.WriteHeader(http.StatusAccepted)
+		case errEmployerDeletionDryRun:
+			w.WriteHeader(http.StatusOK)
+		case errEmployerHasDependents:
+			writeError(w, http.StatusConflict, "Employer has %d accounts and %d job posts with %d applications: delete with cascade=true to remove them too",
+				response.EmployerAccounts, response.JobPosts, response.Applications)
+			return
+		default:
+			logError(r, "internal error", err)
 			writeError(w, http.StatusInternalServerError, "internal error")
 			return
 		}
-		w.WriteHeader(http.StatusAccepted)
-		response := map[string]any{"id": idInt}
 		err = writeJSON(w, response)
 		if err != nil {
 			writeError(w, http.StatusInternalServerError, "internal error")
@@ -146,3 +258,19 @@ func DeleteEmployer(env *HandlerConfig) http.HandlerFunc {
 		}
 	}
 }
+
+// getEmployerDependents counts what is removed along with an employer.
+func getEmployerDependents(ctx context.Context, q *db.Queries, employerID int64) (DeleteEmployerResponse, error) {
+	response := DeleteEmployerResponse{ID: employerID}
+	var err error
+	if response.EmployerAccounts, err = q.NumEmployerAccountsOfEmployer(ctx, employerID); err != nil {
+		return response, err
+	}
+	if response.JobPosts, err = q.NumEmployerJobPosts(ctx, employerID); err != nil {
+		return response, err
+	}
+	if response.Applications, err = q.NumEmployerApplications(ctx, employerID); err != nil {
+		return response, err
+	}
+	return response, nil
+}
@@ -4,15 +4,15 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
-	"log"
 	"net/http"
 	"time"
 
 	"github.com/golang-jwt/jwt"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/gruyaume/lesvieux/internal/jwtkeys"
 )
 
-const ClaimValidity = 1 * time.Hour
+// ClaimValidity is how long an access token is valid. Clients get a new one with their refresh token.
+const ClaimValidity = 15 * time.Minute
 
 type EmployerLoginParams struct {
 	Email    string `json:"email"`
@@ -20,27 +20,38 @@ type EmployerLoginParams struct {
 }
 
 type EmployerLoginResponse struct {
-	Token string `json:"token"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
 }
 
 type jwtLesVieuxClaims struct {
 	ID    int64  `json:"id"`
 	Email string `json:"email"`
 	Role  int64  `json:"role"`
+	// SessionID is the id of the session the token was issued for, which must not be revoked for the token to be accepted
+	SessionID int64 `json:"sid"`
 	jwt.StandardClaims
 }
 
+// signJWT signs the claims with the active key, whose id is set as the kid header of the token
+func signJWT(claims jwt.Claims, jwtKeys *jwtkeys.Keyring) (string, error) {
+	key := jwtKeys.SigningKey()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.Secret)
+}
+
 // Helper function to generate a JWT
-func generateJWT(id int64, email string, jwtSecret []byte, role int64) (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwtLesVieuxClaims{
-		ID:    id,
-		Email: email,
-		Role:  role,
+func generateJWT(id int64, email string, sessionID int64, jwtKeys *jwtkeys.Keyring, role int64) (string, error) {
+	tokenString, err := signJWT(jwtLesVieuxClaims{
+		ID:        id,
+		Email:     email,
+		Role:      role,
+		SessionID: sessionID,
 		StandardClaims: jwt.StandardClaims{
 			ExpiresAt: time.Now().Add(ClaimValidity).Unix(),
 		},
-	})
-	tokenString, err := token.SignedString(jwtSecret)
+	}, jwtKeys)
 	if err != nil {
 		return "", err
 	}
@@ -62,28 +73,33 @@ func EmployersLogin(env *HandlerConfig) http.HandlerFunc {
 			writeError(w, http.StatusBadRequest, "Password is required")
 			return
 		}
+		if !env.logins.admit(w, r, EmployerRole, loginRequest.Email) {
+			return
+		}
 		account, err := env.DBQueries.GetEmployerAccountByEmail(context.Background(), loginRequest.Email)
-		if err != nil {
-			if err == sql.ErrNoRows {
-				writeError(w, http.StatusUnauthorized, "The email or password is incorrect. Try again.")
-				return
-			}
-			log.Println(err.Error())
+		if err != nil && err != sql.ErrNoRows {
+			logError(r, "internal error", err)
 			writeError(w, http.StatusInternalServerError, "internal error")
 			return
 		}
-		if err := bcrypt.CompareHashAndPassword([]byte(account.PasswordHash), []byte(loginRequest.Password)); err != nil {
-			writeError(w, http.StatusUnauthorized, "The email or password is incorrect. Try again.")
+		// Unknown emails go through a password check too, not to be told apart by response times.
+		if !checkPassword(account.PasswordHash, loginRequest.Password) {
+			env.logins.fail(w, r, EmployerRole, loginRequest.Email, "The email or password is incorrect. Try again.")
+			return
+		}
+		if requireSecondFactor(w, r, env, account.ID, account.Email, EmployerRole) {
 			return
 		}
-		jwt, err := generateJWT(account.ID, account.Email, env.JWTSecret, EmployerRole)
+		accessToken, refreshToken, err := startSession(context.Background(), env.DBQueries, env.JWTKeys, account.ID, account.Email, EmployerRole)
 		if err != nil {
-			log.Println(err.Error())
+			logError(r, "internal error", err)
 			writeError(w, http.StatusInternalServerError, "internal error")
 			return
 		}
+		env.logins.succeed(r, EmployerRole, loginRequest.Email)
 		loginResponse := EmployerLoginResponse{
-			Token: jwt,
+			Token:        accessToken,
+			RefreshToken: refreshToken,
 		}
 		w.WriteHeader(http.StatusOK)
 		err = writeJSON(w, loginResponse)
@@ -16,7 +16,8 @@ type EmployerLoginParams struct {
 }
 
 type EmployerLoginResponseResult struct {
-	Token string `json:"token"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
 }
 
 type EmployerLoginResponse struct {
@@ -103,7 +104,12 @@ func TestEmployerLoginEndToEnd(t *testing.T) {
 			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 				return nil, fmt.Errorf("Unexpected signing method: %v", token.Header["alg"])
 			}
-			return []byte(config.JWTSecret), nil
+			kid, _ := token.Header["kid"].(string)
+			secret, ok := config.JWTKeys.VerificationKey(kid)
+			if !ok {
+				return nil, fmt.Errorf("Unknown key id: %q", kid)
+			}
+			return secret, nil
 		})
 		if err != nil {
 			t.Fatalf("couldn't parse token: %s", err)
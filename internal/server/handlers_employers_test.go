@@ -31,7 +31,10 @@ type CreateEmployerResponse struct {
 }
 
 type DeleteEmployerResponseResult struct {
-	Id int `json:"id"`
+	Id               int `json:"id"`
+	EmployerAccounts int `json:"employer_accounts"`
+	JobPosts         int `json:"job_posts"`
+	Applications     int `json:"applications"`
 }
 
 type DeleteEmployerResponse struct {
@@ -39,6 +42,12 @@ type DeleteEmployerResponse struct {
 	Result DeleteEmployerResponseResult `json:"result"`
 }
 
+type ListEmployersResponse struct {
+	Result     []GetEmployerResponseResult `json:"result"`
+	NextCursor string                      `json:"next_cursor,omitempty"`
+	Error      string                      `json:"error,omitempty"`
+}
+
 func createEmployer(url string, client *http.Client, token string, data *CreateEmployerParams) (int, *CreateEmployerResponse, error) {
 	body, err := json.Marshal(data)
 	if err != nil {
@@ -79,6 +88,24 @@ func getEmployer(url string, client *http.Client, token string, id string) (int,
 	return res.StatusCode, &getResponse, nil
 }
 
+func listEmployers(url string, client *http.Client, token string, query string) (int, *ListEmployersResponse, error) {
+	req, err := http.NewRequest("GET", url+"/api/v1/employers?"+query, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer res.Body.Close()
+	var listResponse ListEmployersResponse
+	if err := json.NewDecoder(res.Body).Decode(&listResponse); err != nil {
+		return 0, nil, err
+	}
+	return res.StatusCode, &listResponse, nil
+}
+
 func deleteEmployer(url string, client *http.Client, token string, id string) (int, *DeleteEmployerResponse, error) {
 	req, err := http.NewRequest("DELETE", url+"/api/v1/employers/"+id, nil)
 	if err != nil {
@@ -267,3 +294,210 @@ func TestHandlersDeleteEmployers(t *testing.T) {
 		})
 	}
 }
+
+func TestHandlersDeleteEmployerWithDependents(t *testing.T) {
+	ts, _, err := setupServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ts.Close()
+	client := ts.Client()
+	var adminToken string
+	var employerToken string
+	t.Run("prepare admin account", prepareAdminAccount(ts.URL, client, &adminToken))
+	t.Run("prepare employer account", prepareEmployerAccount(ts.URL, client, &adminToken, &employerToken))
+	t.Run("prepare job post", func(t *testing.T) {
+		statusCode, _, err := createMyJobPost(ts.URL, client, employerToken, &CreateJobPostParams{Title: "Gardener", Content: "Tend the garden"})
+		if err != nil {
+			t.Fatalf("couldn't create job post: %s", err)
+		}
+		if statusCode != http.StatusCreated {
+			t.Fatalf("expected status %d, got %d", http.StatusCreated, statusCode)
+		}
+	})
+
+	dependents := DeleteEmployerResponseResult{Id: 1, EmployerAccounts: 1, JobPosts: 1}
+	testCases := []struct {
+		desc             string
+		query            string
+		expectedResponse DeleteEmployerResponse
+		status           int
+	}{
+		{
+			desc:             "Invalid cascade",
+			query:            "?cascade=maybe",
+			expectedResponse: DeleteEmployerResponse{Error: "cascade must be true or false"},
+			status:           http.StatusBadRequest,
+		},
+		{
+			desc:             "Delete is restricted without cascade",
+			expectedResponse: DeleteEmployerResponse{Error: "Employer has 1 accounts and 1 job posts with 0 applications: delete with cascade=true to remove them too"},
+			status:           http.StatusConflict,
+		},
+		{
+			desc:             "Dry run reports what would be removed",
+			query:            "?dry_run=true&cascade=true",
+			expectedResponse: DeleteEmployerResponse{Result: dependents},
+			status:           http.StatusOK,
+		},
+		{
+			desc:             "Delete with cascade",
+			query:            "?cascade=true",
+			expectedResponse: DeleteEmployerResponse{Result: dependents},
+			status:           http.StatusAccepted,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			var resp DeleteEmployerResponse
+			statusCode, err := apiRequest("DELETE", ts.URL, client, adminToken, "/employers/1"+tC.query, nil, &resp)
+			if err != nil {
+				t.Fatalf("couldn't delete employer: %s", err)
+			}
+			if statusCode != tC.status {
+				t.Fatalf("expected status %d, got %d", tC.status, statusCode)
+			}
+			if resp.Error != tC.expectedResponse.Error {
+				t.Fatalf("expected error %q, got %q", tC.expectedResponse.Error, resp.Error)
+			}
+			if resp.Result != tC.expectedResponse.Result {
+				t.Fatalf("expected result %v, got %v", tC.expectedResponse.Result, resp.Result)
+			}
+		})
+	}
+
+	t.Run("Employer, accounts and job posts are removed", func(t *testing.T) {
+		statusCode, _, err := getEmployer(ts.URL, client, adminToken, "1")
+		if err != nil {
+			t.Fatalf("couldn't get employer: %s", err)
+		}
+		if statusCode != http.StatusNotFound {
+			t.Fatalf("expected status %d, got %d", http.StatusNotFound, statusCode)
+		}
+		statusCode, _, err = employerLogin(ts.URL, client, &EmployerLoginParams{
+			Email:    validEmployerAccount.Email,
+			Password: validEmployerAccount.Password,
+		})
+		if err != nil {
+			t.Fatalf("couldn't login employer: %s", err)
+		}
+		if statusCode != http.StatusUnauthorized {
+			t.Fatalf("expected status %d, got %d", http.StatusUnauthorized, statusCode)
+		}
+		var jobPostResponse map[string]any
+		statusCode, err = apiRequest("GET", ts.URL, client, adminToken, "/moderation/posts/1", nil, &jobPostResponse)
+		if err != nil {
+			t.Fatalf("couldn't get job post: %s", err)
+		}
+		if statusCode != http.StatusNotFound {
+			t.Fatalf("expected status %d, got %d", http.StatusNotFound, statusCode)
+		}
+	})
+}
+
+func TestHandlersListEmployers(t *testing.T) {
+	ts, _, err := setupServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ts.Close()
+	client := ts.Client()
+	var adminToken string
+	t.Run("prepare admin account and token", prepareAdminAccount(ts.URL, client, &adminToken))
+	t.Run("prepare employers", func(t *testing.T) {
+		for _, name := range []string{"charlie", "alpha", "bravo"} {
+			statusCode, _, err := createEmployer(ts.URL, client, adminToken, &CreateEmployerParams{Name: name})
+			if err != nil || statusCode != http.StatusCreated {
+				t.Fatalf("couldn't create employer: %v (status %d)", err, statusCode)
+			}
+		}
+	})
+
+	var nextCursor string
+	t.Run("List employers - first page", func(t *testing.T) {
+		statusCode, resp, err := listEmployers(ts.URL, client, adminToken, "limit=2")
+		if err != nil {
+			t.Fatalf("couldn't list employers: %s", err)
+		}
+		if statusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, statusCode)
+		}
+		if len(resp.Result) != 2 || resp.Result[0].Name != "alpha" || resp.Result[1].Name != "bravo" {
+			t.Fatalf("expected [alpha bravo], got %v", resp.Result)
+		}
+		if resp.NextCursor == "" {
+			t.Fatalf("expected a next cursor")
+		}
+		nextCursor = resp.NextCursor
+	})
+
+	t.Run("List employers - last page", func(t *testing.T) {
+		statusCode, resp, err := listEmployers(ts.URL, client, adminToken, "limit=2&cursor="+nextCursor)
+		if err != nil {
+			t.Fatalf("couldn't list employers: %s", err)
+		}
+		if statusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, statusCode)
+		}
+		if len(resp.Result) != 1 || resp.Result[0].Name != "charlie" {
+			t.Fatalf("expected [charlie], got %v", resp.Result)
+		}
+		if resp.NextCursor != "" {
+			t.Fatalf("expected no next cursor, got %q", resp.NextCursor)
+		}
+	})
+
+	t.Run("List employers - descending", func(t *testing.T) {
+		statusCode, resp, err := listEmployers(ts.URL, client, adminToken, "sort=-name")
+		if err != nil {
+			t.Fatalf("couldn't list employers: %s", err)
+		}
+		if statusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, statusCode)
+		}
+		if len(resp.Result) != 3 || resp.Result[0].Name != "charlie" || resp.Result[2].Name != "alpha" {
+			t.Fatalf("expected [charlie bravo alpha], got %v", resp.Result)
+		}
+	})
+
+	testCases := []struct {
+		desc  string
+		query string
+		error string
+	}{
+		{
+			desc:  "Invalid limit",
+			query: "limit=0",
+			error: "limit must be an integer between 1 and 100",
+		},
+		{
+			desc:  "Invalid sort",
+			query: "sort=id",
+			error: "Invalid sort: must be one of name, -name",
+		},
+		{
+			desc:  "Invalid cursor",
+			query: "cursor=abc",
+			error: "Invalid cursor",
+		},
+		{
+			desc:  "Cursor from another sort",
+			query: "sort=-name&cursor=" + nextCursor,
+			error: "cursor doesn't match sort",
+		},
+	}
+	for _, tC := range testCases {
+		t.Run("List employers - "+tC.desc, func(t *testing.T) {
+			statusCode, resp, err := listEmployers(ts.URL, client, adminToken, tC.query)
+			if err != nil {
+				t.Fatalf("couldn't list employers: %s", err)
+			}
+			if statusCode != http.StatusBadRequest {
+				t.Fatalf("expected status %d, got %d", http.StatusBadRequest, statusCode)
+			}
+			if resp.Error != tC.error {
+				t.Fatalf("expected error %q, got %q", tC.error, resp.Error)
+			}
+		})
+	}
+}
@@ -2,12 +2,18 @@
 package server_test
 
 import (
+	"encoding/json"
 	"fmt"
+	"log"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gruyaume/lesvieux/internal/db"
+	"github.com/gruyaume/lesvieux/internal/jwtkeys"
 	"github.com/gruyaume/lesvieux/internal/server"
 )
 
@@ -25,13 +31,104 @@ var validEmployerAccount = CreateEmployerAccountParams{
 	Password: "Employerpass123!",
 }
 
+var validJobPost = CreateJobPostParams{
+	Title:   "Gardener",
+	Content: "Tend the garden of a retirement home, two mornings a week.",
+}
+
+var validApplicantAccount = CreateApplicantAccountParams{
+	Email:    "jeanne@example.com",
+	Password: "Applicant123!",
+	ApplicantProfile: ApplicantProfile{
+		Name: "Jeanne",
+	},
+}
+
+// apiRequest sends data as JSON to an /api/v1 path, authenticated with token if it isn't empty,
+// and decodes the response body into response.
+func apiRequest(method string, url string, client *http.Client, token string, path string, data any, response any) (int, error) {
+	var body string
+	if data != nil {
+		b, err := json.Marshal(data)
+		if err != nil {
+			return 0, err
+		}
+		body = string(b)
+	}
+	req, err := http.NewRequest(method, url+"/api/v1"+path, strings.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	if err := json.NewDecoder(res.Body).Decode(response); err != nil {
+		return 0, err
+	}
+	return res.StatusCode, nil
+}
+
+// testPostgresURL is the URL of a PostgreSQL database to run the tests against, instead of an in-memory SQLite database.
+var testPostgresURL = os.Getenv("LESVIEUX_TEST_POSTGRES_URL")
+
+// setupDatabase returns an empty database for a test.
+// On PostgreSQL, each test gets a schema of its own, which is dropped once the tests are done.
+func setupDatabase() (*db.Queries, error) {
+	if testPostgresURL == "" {
+		return db.Initialize(":memory:")
+	}
+	database, err := db.OpenDatabase(db.Postgres, testPostgresURL)
+	if err != nil {
+		return nil, err
+	}
+	defer database.Close()
+	schema := fmt.Sprintf("lesvieux_test_%d", time.Now().UnixNano())
+	if _, err := database.Exec("CREATE SCHEMA " + schema); err != nil {
+		return nil, err
+	}
+	testPostgresSchemas = append(testPostgresSchemas, schema)
+	separator := "?"
+	if strings.Contains(testPostgresURL, "?") {
+		separator = "&"
+	}
+	return db.InitializeDatabase(db.Postgres, testPostgresURL+separator+"search_path="+schema)
+}
+
+var testPostgresSchemas []string
+
+func TestMain(m *testing.M) {
+	code := m.Run()
+	if len(testPostgresSchemas) > 0 {
+		database, err := db.OpenDatabase(db.Postgres, testPostgresURL)
+		if err == nil {
+			for _, schema := range testPostgresSchemas {
+				if _, err := database.Exec("DROP SCHEMA " + schema + " CASCADE"); err != nil {
+					log.Printf("couldn't drop test schema %s: %s", schema, err)
+				}
+			}
+			database.Close()
+		}
+	}
+	os.Exit(code)
+}
+
 func setupServer() (*httptest.Server, *server.HandlerConfig, error) {
-	dbQueries, err := db.Initialize(":memory:")
+	dbQueries, err := setupDatabase()
+	if err != nil {
+		return nil, nil, err
+	}
+	keys, err := jwtkeys.Generate()
 	if err != nil {
 		return nil, nil, err
 	}
 	config := &server.HandlerConfig{
 		DBQueries: dbQueries,
+		JWTKeys:   jwtkeys.NewKeyring(keys),
 	}
 	ts := httptest.NewTLSServer(server.NewLesVieuxRouter(config))
 	return ts, config, nil
@@ -98,3 +195,47 @@ func prepareEmployerAccount(url string, client *http.Client, adminToken *string,
 
 	}
 }
+
+func prepareApplicantAccount(url string, client *http.Client, applicantToken *string) func(*testing.T) {
+	return func(t *testing.T) {
+		statusCode, _, err := createApplicantAccount(url, client, &validApplicantAccount)
+		if err != nil {
+			t.Fatalf("couldn't create applicant account: %s", err)
+		}
+		if statusCode != http.StatusCreated {
+			t.Fatalf("creating an applicant account should succeed without auth. status code received: %d", statusCode)
+		}
+		loginParams := ApplicantLoginParams{
+			Email:    validApplicantAccount.Email,
+			Password: validApplicantAccount.Password,
+		}
+		statusCode, loginResponse, err := applicantLogin(url, client, &loginParams)
+		if err != nil {
+			t.Fatalf("couldn't login applicant: %s", err)
+		}
+		if statusCode != http.StatusOK {
+			t.Fatalf("the applicant login request should have succeeded. status code received: %d", statusCode)
+		}
+		*applicantToken = loginResponse.Result.Token
+	}
+}
+
+// preparePublishedJobPost creates a job post with the given employer account and has it approved by the admin
+func preparePublishedJobPost(url string, client *http.Client, adminToken string, employerToken string, data *UpdateJobPostParams) (string, error) {
+	statusCode, resp, err := createMyJobPost(url, client, employerToken, &validJobPost)
+	if err != nil || statusCode != http.StatusCreated {
+		return "", fmt.Errorf("couldn't create job post: %v (status %d)", err, statusCode)
+	}
+	id := fmt.Sprintf("%d", resp.Result.ID)
+	statusCode, _, err = updateMyJobPost(url, client, employerToken, id, data)
+	if err != nil || statusCode != http.StatusOK {
+		return "", fmt.Errorf("couldn't submit job post: %v (status %d)", err, statusCode)
+	}
+	if data.Status == "pending_review" {
+		statusCode, _, err = moderateJobPost(url, client, adminToken, id, "approve", &ModerateJobPostParams{})
+		if err != nil || statusCode != http.StatusOK {
+			return "", fmt.Errorf("couldn't approve job post: %v (status %d)", err, statusCode)
+		}
+	}
+	return id, nil
+}
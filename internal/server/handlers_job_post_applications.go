@@ -0,0 +1,239 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gruyaume/lesvieux/internal/db"
+)
+
+type ApplicantResponse struct {
+	ID          int64  `json:"id"`
+	Email       string `json:"email"`
+	Name        string `json:"name"`
+	PhoneNumber string `json:"phone_number"`
+	City        string `json:"city"`
+	PostalCode  string `json:"postal_code"`
+}
+
+// JobPostApplicationResponse is how an application is shown to the employer of the Job Post
+type JobPostApplicationResponse struct {
+	ID           int64             `json:"id"`
+	JobPostID    int64             `json:"job_post_id"`
+	CoverMessage string            `json:"cover_message"`
+	Status       string            `json:"status"`
+	CreatedAt    string            `json:"created_at"`
+	UpdatedAt    string            `json:"updated_at"`
+	Applicant    ApplicantResponse `json:"applicant"`
+}
+
+type ChangeApplicationStatusParams struct {
+	Status string `json:"status"`
+}
+
+type ChangeApplicationStatusResponse struct {
+	ID     int64  `json:"id"`
+	Status string `json:"status"`
+}
+
+func newJobPostApplicationResponse(application db.GetJobPostApplicationRow) JobPostApplicationResponse {
+	return JobPostApplicationResponse{
+		ID:           application.Application.ID,
+		JobPostID:    application.Application.JobPostID,
+		CoverMessage: application.Application.CoverMessage,
+		Status:       application.Application.Status,
+		CreatedAt:    application.Application.CreatedAt,
+		UpdatedAt:    application.Application.UpdatedAt,
+		Applicant: ApplicantResponse{
+			ID:          application.Application.ApplicantID,
+			Email:       application.ApplicantEmail,
+			Name:        application.ApplicantName,
+			PhoneNumber: application.ApplicantPhoneNumber,
+			City:        application.ApplicantCity,
+			PostalCode:  application.ApplicantPostalCode,
+		},
+	}
+}
+
+// ListMyJobPostApplications receives the id of a Job Post owned by the employer of the logged in account
+// as a path parameter, and returns a page of the applications to it, oldest first by default.
+// They can be filtered by status.
+func ListMyJobPostApplications(env *HandlerConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		jobPost, ok := getMyJobPostFromPath(env, w, r)
+		if !ok {
+			return
+		}
+		page, err := parsePageRequest(r, "created_at", "-created_at")
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "%s", err)
+			return
+		}
+		status, err := parseApplicationStatusFilter(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "%s", err)
+			return
+		}
+		params := db.ListJobPostApplicationsOldestFirstParams{
+			JobPostID: jobPost.ID,
+			Status:    status,
+			CursorKey: page.CursorKey(),
+			CursorID:  page.CursorID(),
+			Limit:     page.FetchLimit(),
+		}
+		var applications []db.GetJobPostApplicationRow
+		if page.Descending() {
+			rows, err := env.DBQueries.ListJobPostApplicationsNewestFirst(context.Background(), db.ListJobPostApplicationsNewestFirstParams(params))
+			if err != nil {
+				logError(r, "internal error", err)
+				writeError(w, http.StatusInternalServerError, "internal error")
+				return
+			}
+			for _, row := range rows {
+				applications = append(applications, db.GetJobPostApplicationRow(row))
+			}
+		} else {
+			rows, err := env.DBQueries.ListJobPostApplicationsOldestFirst(context.Background(), params)
+			if err != nil {
+				logError(r, "internal error", err)
+				writeError(w, http.StatusInternalServerError, "internal error")
+				return
+			}
+			for _, row := range rows {
+				applications = append(applications, db.GetJobPostApplicationRow(row))
+			}
+		}
+		applications, nextCursor := paginate(page, applications, func(a db.GetJobPostApplicationRow) (string, int64) {
+			return a.Application.CreatedAt, a.Application.ID
+		})
+		applicationsResponse := make([]JobPostApplicationResponse, 0, len(applications))
+		for _, a := range applications {
+			applicationsResponse = append(applicationsResponse, newJobPostApplicationResponse(a))
+		}
+		err = writeJSONPage(w, applicationsResponse, nextCursor)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+	}
+}
+
+// GetMyJobPostApplication receives the ids of a Job Post owned by the employer of the logged in account
+// and of an application to it as path parameters, and returns the application along with the applicant's profile
+func GetMyJobPostApplication(env *HandlerConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		application, ok := getMyJobPostApplicationFromPath(env, w, r)
+		if !ok {
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		err := writeJSON(w, newJobPostApplicationResponse(application))
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+	}
+}
+
+// ChangeMyJobPostApplicationStatus moves an application to a Job Post owned by the employer of the logged in account
+// to the next step of the hiring process: received, reviewing, interview then offered. Applications can be declined until an offer is made.
+func ChangeMyJobPostApplicationStatus(env *HandlerConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := r.Context().Value(userIDKey).(int64)
+		var params ChangeApplicationStatusParams
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid JSON format")
+			return
+		}
+		if params.Status == "" {
+			writeError(w, http.StatusBadRequest, "Status is required")
+			return
+		}
+		if !isValidApplicationStatus(params.Status) {
+			writeError(w, http.StatusBadRequest, "Invalid status")
+			return
+		}
+		application, ok := getMyJobPostApplicationFromPath(env, w, r)
+		if !ok {
+			return
+		}
+		if err := validateApplicationStatusTransition(application.Application.Status, params.Status); err != nil {
+			writeError(w, http.StatusConflict, "Invalid status change: %s", err)
+			return
+		}
+		err := env.DBQueries.ExecTx(context.Background(), func(q *db.Queries) error {
+			actor := &jobPostActor{ID: userID, Role: EmployerRole}
+			if err := transitionApplicationStatus(context.Background(), q, application.Application, params.Status, actor); err != nil {
+				return err
+			}
+			return recordAuditEvent(context.Background(), q, r, auditEvent{
+				Action:     AuditActionApplicationChangeStatus,
+				TargetType: AuditTargetApplication,
+				TargetID:   application.Application.ID,
+				Before:     map[string]any{"status": application.Application.Status},
+				After:      map[string]any{"status": params.Status},
+			})
+		})
+		if err != nil {
+			if errors.Is(err, errApplicationStatusConflict) {
+				writeError(w, http.StatusConflict, "Application status was changed by someone else. Try again.")
+				return
+			}
+			logError(r, "internal error", err)
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		err = writeJSON(w, ChangeApplicationStatusResponse{ID: application.Application.ID, Status: params.Status})
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+	}
+}
+
+// ListMyJobPostApplicationHistory returns the status changes of an application
+// to a Job Post owned by the employer of the logged in account
+func ListMyJobPostApplicationHistory(env *HandlerConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		application, ok := getMyJobPostApplicationFromPath(env, w, r)
+		if !ok {
+			return
+		}
+		writeApplicationHistory(env, w, r, application.Application.ID)
+	}
+}
+
+// getMyJobPostApplicationFromPath reads the post_id and application_id path parameters and returns the matching
+// application, if the post is owned by the employer of the logged in account.
+// When ok is false, an error response has already been written.
+func getMyJobPostApplicationFromPath(env *HandlerConfig, w http.ResponseWriter, r *http.Request) (application db.GetJobPostApplicationRow, ok bool) {
+	jobPost, ok := getMyJobPostFromPath(env, w, r)
+	if !ok {
+		return application, false
+	}
+	id := r.PathValue("application_id")
+	idInt64, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "id must be an integer")
+		return application, false
+	}
+	application, err = env.DBQueries.GetJobPostApplication(context.Background(), db.GetJobPostApplicationParams{
+		ID:        idInt64,
+		JobPostID: jobPost.ID,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			writeError(w, http.StatusNotFound, "Application not found")
+			return application, false
+		}
+		logError(r, "internal error", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return application, false
+	}
+	return application, true
+}
@@ -3,9 +3,11 @@ package server
 import (
 	"context"
 	"database/sql"
-	"log"
+	"errors"
 	"net/http"
 	"strconv"
+
+	"github.com/gruyaume/lesvieux/internal/db"
 )
 
 type CreateJobPostResponse struct {
@@ -13,9 +15,11 @@ type CreateJobPostResponse struct {
 }
 
 type UpdateJobPostParams struct {
-	Title   string `json:"title"`
-	Content string `json:"content"`
-	Status  string `json:"status"`
+	Title     string `json:"title"`
+	Content   string `json:"content"`
+	Status    string `json:"status"`
+	ExpiresAt string `json:"expires_at"`
+	JobPostDetails
 }
 
 type UpdateJobPostResponse struct {
@@ -23,28 +27,188 @@ type UpdateJobPostResponse struct {
 }
 
 type GetJobPostResponse struct {
-	ID         int64  `json:"id"`
-	Title      string `json:"title"`
-	Content    string `json:"content"`
-	Status     string `json:"status"`
-	CreatedAt  string `json:"created_at"`
-	EmployerID int64  `json:"employer_id"`
+	ID          int64  `json:"id"`
+	Title       string `json:"title"`
+	Content     string `json:"content"`
+	Status      string `json:"status"`
+	CreatedAt   string `json:"created_at"`
+	PublishedAt string `json:"published_at,omitempty"`
+	ExpiresAt   string `json:"expires_at,omitempty"`
+	EmployerID  int64  `json:"employer_id"`
+	JobPostDetails
+
+	RejectionReason string `json:"rejection_reason,omitempty"`
+}
+
+// PublicJobPostResponse is how a published Job Post is shown to visitors
+type PublicJobPostResponse struct {
+	ID           int64  `json:"id"`
+	Title        string `json:"title"`
+	Content      string `json:"content"`
+	PublishedAt  string `json:"published_at"`
+	ExpiresAt    string `json:"expires_at,omitempty"`
+	EmployerID   int64  `json:"employer_id"`
+	EmployerName string `json:"employer_name"`
+	JobPostDetails
+}
+
+type JobPostStatusChangeResponse struct {
+	FromStatus string `json:"from_status"`
+	ToStatus   string `json:"to_status"`
+	ChangedAt  string `json:"changed_at"`
+	ActorID    *int64 `json:"actor_id,omitempty"`
+	ActorRole  *int64 `json:"actor_role,omitempty"`
+	Reason     string `json:"reason,omitempty"`
+}
+
+func newGetJobPostResponse(jobPost db.JobPost) GetJobPostResponse {
+	return GetJobPostResponse{
+		ID:          jobPost.ID,
+		Title:       jobPost.Title,
+		Content:     jobPost.Content,
+		Status:      jobPost.Status,
+		CreatedAt:   jobPost.CreatedAt,
+		PublishedAt: jobPost.PublishedAt.String,
+		ExpiresAt:   jobPost.ExpiresAt.String,
+		EmployerID:  jobPost.EmployerID,
+
+		JobPostDetails: newJobPostDetails(jobPost),
+	}
 }
 
+func newPublicJobPostResponse(jobPost db.JobPost, employerName string) PublicJobPostResponse {
+	return PublicJobPostResponse{
+		ID:             jobPost.ID,
+		Title:          jobPost.Title,
+		Content:        jobPost.Content,
+		PublishedAt:    jobPost.PublishedAt.String,
+		ExpiresAt:      jobPost.ExpiresAt.String,
+		EmployerID:     jobPost.EmployerID,
+		EmployerName:   employerName,
+		JobPostDetails: newJobPostDetails(jobPost),
+	}
+}
+
+func newJobPostStatusChangeResponse(change db.JobPostStatusChange) JobPostStatusChangeResponse {
+	response := JobPostStatusChangeResponse{
+		FromStatus: change.FromStatus,
+		ToStatus:   change.ToStatus,
+		ChangedAt:  change.ChangedAt,
+		Reason:     change.Reason.String,
+	}
+	if change.ActorID.Valid {
+		response.ActorID = &change.ActorID.Int64
+	}
+	if change.ActorRole.Valid {
+		response.ActorRole = &change.ActorRole.Int64
+	}
+	return response
+}
+
+// ListJobPosts returns a page of the published job posts, most recently published first by default.
+// They can be filtered by employer_id, published_after and published_before, and on their details,
+// see parseJobPostDetailFilters.
 func ListJobPosts(env *HandlerConfig) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		jobPosts, err := env.DBQueries.ListJobPosts(context.Background())
+		page, err := parsePageRequest(r, "-published_at", "published_at")
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "%s", err)
+			return
+		}
+		params := db.ListPublishedJobPostsNewestFirstParams{
+			CursorKey: sql.NullString{String: page.CursorKey(), Valid: true},
+			CursorID:  page.CursorID(),
+			Limit:     page.FetchLimit(),
+		}
+		if params.EmployerID, err = parseIntFilter(r, "employer_id"); err != nil {
+			writeError(w, http.StatusBadRequest, "%s", err)
+			return
+		}
+		if params.PublishedAfter, err = parseTimeFilter(r, "published_after"); err != nil {
+			writeError(w, http.StatusBadRequest, "%s", err)
+			return
+		}
+		if params.PublishedBefore, err = parseTimeFilter(r, "published_before"); err != nil {
+			writeError(w, http.StatusBadRequest, "%s", err)
+			return
+		}
+		details, err := parseJobPostDetailFilters(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "%s", err)
+			return
+		}
+		params.EmploymentType = details.EmploymentType
+		params.WorkMode = details.WorkMode
+		params.PhysicalDemands = details.PhysicalDemands
+		params.City = details.City
+		params.PostalCode = details.PostalCode
+		params.MaxWeeklyHours = details.MaxWeeklyHours
+		params.SalaryCurrency = details.SalaryCurrency
+		params.MinSalary = details.MinSalary
+		params.FlexibleSchedule = details.FlexibleSchedule
+		params.AccessibleWorkplace = details.AccessibleWorkplace
+		var jobPosts []db.GetPublishedJobPostRow
+		if page.Descending() {
+			rows, err := env.DBQueries.ListPublishedJobPostsNewestFirst(context.Background(), params)
+			if err != nil {
+				logError(r, "internal error", err)
+				writeError(w, http.StatusInternalServerError, "internal error")
+				return
+			}
+			for _, row := range rows {
+				jobPosts = append(jobPosts, db.GetPublishedJobPostRow(row))
+			}
+		} else {
+			rows, err := env.DBQueries.ListPublishedJobPostsOldestFirst(context.Background(), db.ListPublishedJobPostsOldestFirstParams(params))
+			if err != nil {
+				logError(r, "internal error", err)
+				writeError(w, http.StatusInternalServerError, "internal error")
+				return
+			}
+			for _, row := range rows {
+				jobPosts = append(jobPosts, db.GetPublishedJobPostRow(row))
+			}
+		}
+		jobPosts, nextCursor := paginate(page, jobPosts, func(p db.GetPublishedJobPostRow) (string, int64) {
+			return p.JobPost.PublishedAt.String, p.JobPost.ID
+		})
+		jobPostsResponse := make([]PublicJobPostResponse, 0, len(jobPosts))
+		for _, jobPost := range jobPosts {
+			jobPostsResponse = append(jobPostsResponse, newPublicJobPostResponse(jobPost.JobPost, jobPost.EmployerName))
+		}
+
+		err = writeJSONPage(w, jobPostsResponse, nextCursor)
 		if err != nil {
-			log.Println(err)
 			writeError(w, http.StatusInternalServerError, "internal error")
 			return
 		}
-		ids := make([]int64, 0, len(jobPosts))
-		for _, post := range jobPosts {
-			ids = append(ids, post.ID)
+	}
+}
+
+// GetPublicJobPost receives an id as a path parameter, and returns the corresponding Job Post
+// if it is published. Job Posts in any other status are reported as not found.
+func GetPublicJobPost(env *HandlerConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("post_id")
+		idInt64, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "id must be an integer")
+			return
+		}
+
+		jobPost, err := env.DBQueries.GetPublishedJobPost(context.Background(), idInt64)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				writeError(w, http.StatusNotFound, "Job Post not found")
+				return
+			}
+			logError(r, "internal error", err)
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
 		}
 
-		err = writeJSON(w, ids)
+		w.WriteHeader(http.StatusOK)
+		err = writeJSON(w, newPublicJobPostResponse(jobPost.JobPost, jobPost.EmployerName))
 		if err != nil {
 			writeError(w, http.StatusInternalServerError, "internal error")
 			return
@@ -67,19 +231,12 @@ func GetJobPost(env *HandlerConfig) http.HandlerFunc {
 				writeError(w, http.StatusNotFound, "Job Post not found")
 				return
 			}
-			log.Println(err)
+			logError(r, "internal error", err)
 			writeError(w, http.StatusInternalServerError, "internal error")
 			return
 		}
 
-		jobPostResponse := GetJobPostResponse{
-			ID:         jobPost.ID,
-			Title:      jobPost.Title,
-			Content:    jobPost.Content,
-			Status:     jobPost.Status,
-			CreatedAt:  jobPost.CreatedAt,
-			EmployerID: jobPost.EmployerID,
-		}
+		jobPostResponse := newGetJobPostResponse(jobPost)
 
 		w.WriteHeader(http.StatusOK)
 		err = writeJSON(w, jobPostResponse)
@@ -98,12 +255,94 @@ func DeleteJobPost(env *HandlerConfig) http.HandlerFunc {
 			writeError(w, http.StatusBadRequest, "id must be an integer")
 			return
 		}
-		err = env.DBQueries.DeleteJobPost(context.Background(), idInt64)
+		err = env.DBQueries.ExecTx(context.Background(), func(q *db.Queries) error {
+			jobPost, err := q.GetJobPost(context.Background(), idInt64)
+			if err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					return nil
+				}
+				return err
+			}
+			if err := q.DeleteJobPost(context.Background(), idInt64); err != nil {
+				return err
+			}
+			return recordAuditEvent(context.Background(), q, r, auditEvent{
+				Action:     AuditActionJobPostDelete,
+				TargetType: AuditTargetJobPost,
+				TargetID:   idInt64,
+				Before:     jobPostAuditSummary(jobPost),
+			})
+		})
 		if err != nil {
-			log.Println(err)
+			logError(r, "internal error", err)
 			writeError(w, http.StatusInternalServerError, "internal error")
 			return
 		}
 		w.WriteHeader(http.StatusAccepted)
 	}
 }
+
+// getJobPostFromPath reads the post_id path parameter and returns the matching Job Post.
+// When ok is false, an error response has already been written.
+func getJobPostFromPath(env *HandlerConfig, w http.ResponseWriter, r *http.Request) (jobPost db.JobPost, ok bool) {
+	id := r.PathValue("post_id")
+	idInt64, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "id must be an integer")
+		return db.JobPost{}, false
+	}
+	jobPost, err = env.DBQueries.GetJobPost(context.Background(), idInt64)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			writeError(w, http.StatusNotFound, "Job Post not found")
+			return db.JobPost{}, false
+		}
+		logError(r, "internal error", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return db.JobPost{}, false
+	}
+	return jobPost, true
+}
+
+// parseJobPostFilters reads the status, employer_id, created_after and created_before query parameters
+// used to narrow down lists of job posts.
+func parseJobPostFilters(r *http.Request) (db.ListJobPostsNewestFirstParams, error) {
+	var filters db.ListJobPostsNewestFirstParams
+	if status := r.URL.Query().Get("status"); status != "" {
+		if !isValidJobPostStatus(status) {
+			return filters, errors.New("Invalid status")
+		}
+		filters.Status = sql.NullString{String: status, Valid: true}
+	}
+	var err error
+	if filters.EmployerID, err = parseIntFilter(r, "employer_id"); err != nil {
+		return filters, err
+	}
+	if filters.CreatedAfter, err = parseTimeFilter(r, "created_after"); err != nil {
+		return filters, err
+	}
+	if filters.CreatedBefore, err = parseTimeFilter(r, "created_before"); err != nil {
+		return filters, err
+	}
+	return filters, nil
+}
+
+// listJobPostsPage returns a page of the job posts matching filters, sorted on their creation date.
+// The page is trimmed to its limit, and returned along with the cursor of the next page.
+func listJobPostsPage(queries *db.Queries, page pageRequest, filters db.ListJobPostsNewestFirstParams) ([]db.JobPost, string, error) {
+	filters.CursorKey = page.CursorKey()
+	filters.CursorID = page.CursorID()
+	filters.Limit = page.FetchLimit()
+	var jobPosts []db.JobPost
+	var err error
+	if page.Descending() {
+		jobPosts, err = queries.ListJobPostsNewestFirst(context.Background(), filters)
+	} else {
+		jobPosts, err = queries.ListJobPostsOldestFirst(context.Background(), db.ListJobPostsOldestFirstParams(filters))
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	jobPosts, nextCursor := paginate(page, jobPosts, func(p db.JobPost) (string, int64) { return p.CreatedAt, p.ID })
+	return jobPosts, nextCursor, nil
+}
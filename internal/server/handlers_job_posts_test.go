@@ -1,9 +1,32 @@
 package server_test
 
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+type JobPostDetails struct {
+	EmploymentType      string `json:"employment_type,omitempty"`
+	WeeklyHours         *int64 `json:"weekly_hours,omitempty"`
+	WorkMode            string `json:"work_mode,omitempty"`
+	City                string `json:"city,omitempty"`
+	PostalCode          string `json:"postal_code,omitempty"`
+	SalaryMin           *int64 `json:"salary_min,omitempty"`
+	SalaryMax           *int64 `json:"salary_max,omitempty"`
+	SalaryCurrency      string `json:"salary_currency,omitempty"`
+	SalaryPeriod        string `json:"salary_period,omitempty"`
+	PhysicalDemands     string `json:"physical_demands,omitempty"`
+	FlexibleSchedule    bool   `json:"flexible_schedule"`
+	AccessibleWorkplace bool   `json:"accessible_workplace"`
+}
+
 type UpdateJobPostParams struct {
 	Title   string `json:"title"`
 	Content string `json:"content"`
 	Status  string `json:"status"`
+	JobPostDetails
 }
 
 type UpdateJobPostResponseResult struct {
@@ -15,17 +38,36 @@ type UpdateJobPostResponse struct {
 	Result UpdateJobPostResponseResult `json:"result"`
 }
 
-type ListJobPostsResponseResult []int
+type PublicJobPostResponseResult struct {
+	ID           int64  `json:"id"`
+	Title        string `json:"title"`
+	Content      string `json:"content"`
+	PublishedAt  string `json:"published_at"`
+	EmployerID   int64  `json:"employer_id"`
+	EmployerName string `json:"employer_name"`
+	JobPostDetails
+}
+
+type PublicJobPostResponse struct {
+	Error  string                      `json:"error,omitempty"`
+	Result PublicJobPostResponseResult `json:"result"`
+}
+
+type ListJobPostsResponseResult []PublicJobPostResponseResult
 
 type ListJobPostsResponse struct {
-	Error  string                     `json:"error,omitempty"`
-	Result ListJobPostsResponseResult `json:"result"`
+	Error      string                     `json:"error,omitempty"`
+	Result     ListJobPostsResponseResult `json:"result"`
+	NextCursor string                     `json:"next_cursor,omitempty"`
 }
 
 type GetJobPostResponseResult struct {
 	Title   string `json:"title,omitempty"`
 	Content string `json:"content,omitempty"`
 	Status  string `json:"status,omitempty"`
+	JobPostDetails
+
+	RejectionReason string `json:"rejection_reason,omitempty"`
 }
 
 type GetJobPostResponse struct {
@@ -41,3 +83,368 @@ type DeleteJobPostResponse struct {
 	Error  string                      `json:"error,omitempty"`
 	Result DeleteJobPostResponseResult `json:"result"`
 }
+
+func listJobPosts(url string, client *http.Client, query string) (int, *ListJobPostsResponse, error) {
+	req, err := http.NewRequest("GET", url+"/api/v1/posts?"+query, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer res.Body.Close()
+	var listResponse ListJobPostsResponse
+	if err := json.NewDecoder(res.Body).Decode(&listResponse); err != nil {
+		return 0, nil, err
+	}
+	return res.StatusCode, &listResponse, nil
+}
+
+func getJobPost(url string, client *http.Client, id string) (int, *PublicJobPostResponse, error) {
+	req, err := http.NewRequest("GET", url+"/api/v1/posts/"+id, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer res.Body.Close()
+	var getResponse PublicJobPostResponse
+	if err := json.NewDecoder(res.Body).Decode(&getResponse); err != nil {
+		return 0, nil, err
+	}
+	return res.StatusCode, &getResponse, nil
+}
+
+func TestPublicJobPostsEndToEnd(t *testing.T) {
+	ts, _, err := setupServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ts.Close()
+	client := ts.Client()
+	var adminToken string
+	var employerToken string
+	ids := make([]string, 3)
+	t.Run("prepare admin account and token", prepareAdminAccount(ts.URL, client, &adminToken))
+	t.Run("prepare employer account and token", prepareEmployerAccount(ts.URL, client, &adminToken, &employerToken))
+	t.Run("prepare submitted job posts", prepareSubmittedJobPosts(ts.URL, client, &employerToken, ids))
+	t.Run("prepare published job posts", func(t *testing.T) {
+		for _, id := range []string{ids[0], ids[2]} {
+			statusCode, _, err := moderateJobPost(ts.URL, client, adminToken, id, "approve", &ModerateJobPostParams{})
+			if err != nil || statusCode != http.StatusOK {
+				t.Fatalf("couldn't approve job post: %v (status %d)", err, statusCode)
+			}
+		}
+	})
+
+	t.Run("List job posts - published only, with employer name", func(t *testing.T) {
+		statusCode, resp, err := listJobPosts(ts.URL, client, "")
+		if err != nil {
+			t.Fatalf("couldn't list job posts: %s", err)
+		}
+		if statusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, statusCode)
+		}
+		if len(resp.Result) != 2 {
+			t.Fatalf("expected 2 job posts, got %v", resp.Result)
+		}
+		jobPost := resp.Result[1]
+		if jobPost.Title != "Job 0" || jobPost.Content != "Some content" || jobPost.EmployerName != validEmployer.Name || jobPost.PublishedAt == "" {
+			t.Fatalf("unexpected job post: %+v", jobPost)
+		}
+	})
+
+	t.Run("List job posts - paginated", func(t *testing.T) {
+		statusCode, resp, err := listJobPosts(ts.URL, client, "limit=1")
+		if err != nil {
+			t.Fatalf("couldn't list job posts: %s", err)
+		}
+		if statusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, statusCode)
+		}
+		if len(resp.Result) != 1 || resp.Result[0].Title != "Job 2" || resp.NextCursor == "" {
+			t.Fatalf("expected the most recent job post and a next cursor, got %v", resp)
+		}
+		statusCode, resp, err = listJobPosts(ts.URL, client, "limit=1&cursor="+resp.NextCursor)
+		if err != nil {
+			t.Fatalf("couldn't list job posts: %s", err)
+		}
+		if statusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, statusCode)
+		}
+		if len(resp.Result) != 1 || resp.Result[0].Title != "Job 0" || resp.NextCursor != "" {
+			t.Fatalf("expected the oldest job post and no next cursor, got %v", resp)
+		}
+	})
+
+	filterCases := []struct {
+		desc   string
+		query  string
+		status int
+		count  int
+	}{
+		{
+			desc:   "Oldest first",
+			query:  "sort=published_at",
+			status: http.StatusOK,
+			count:  2,
+		},
+		{
+			desc:   "By employer",
+			query:  "employer_id=999",
+			status: http.StatusOK,
+			count:  0,
+		},
+		{
+			desc:   "Published after",
+			query:  "published_after=2999-01-01T00:00:00Z",
+			status: http.StatusOK,
+			count:  0,
+		},
+		{
+			desc:   "Published before",
+			query:  "published_before=2999-01-01T00:00:00%2B02:00",
+			status: http.StatusOK,
+			count:  2,
+		},
+		{
+			desc:   "Invalid date",
+			query:  "published_after=yesterday",
+			status: http.StatusBadRequest,
+		},
+	}
+	for _, tC := range filterCases {
+		t.Run("List job posts - "+tC.desc, func(t *testing.T) {
+			statusCode, resp, err := listJobPosts(ts.URL, client, tC.query)
+			if err != nil {
+				t.Fatalf("couldn't list job posts: %s", err)
+			}
+			if statusCode != tC.status {
+				t.Fatalf("expected status %d, got %d", tC.status, statusCode)
+			}
+			if len(resp.Result) != tC.count {
+				t.Fatalf("expected %d job posts, got %v", tC.count, resp.Result)
+			}
+		})
+	}
+
+	testCases := []struct {
+		desc   string
+		id     string
+		status int
+		error  string
+	}{
+		{
+			desc:   "Published job post",
+			id:     ids[0],
+			status: http.StatusOK,
+		},
+		{
+			desc:   "Job post pending review is not public",
+			id:     ids[1],
+			status: http.StatusNotFound,
+			error:  "Job Post not found",
+		},
+		{
+			desc:   "Job post doesn't exist",
+			id:     "999",
+			status: http.StatusNotFound,
+			error:  "Job Post not found",
+		},
+		{
+			desc:   "Invalid id",
+			id:     "abc",
+			status: http.StatusBadRequest,
+			error:  "id must be an integer",
+		},
+	}
+	for _, tC := range testCases {
+		t.Run("Get job post - "+tC.desc, func(t *testing.T) {
+			statusCode, resp, err := getJobPost(ts.URL, client, tC.id)
+			if err != nil {
+				t.Fatalf("couldn't get job post: %s", err)
+			}
+			if statusCode != tC.status {
+				t.Fatalf("expected status %d, got %d", tC.status, statusCode)
+			}
+			if resp.Error != tC.error {
+				t.Fatalf("expected error %q, got %q", tC.error, resp.Error)
+			}
+			if tC.status == http.StatusOK && resp.Result.EmployerName != validEmployer.Name {
+				t.Fatalf("expected employer name %q, got %q", validEmployer.Name, resp.Result.EmployerName)
+			}
+		})
+	}
+}
+
+func int64Ptr(i int64) *int64 {
+	return &i
+}
+
+func TestPublicJobPostsDetailFilters(t *testing.T) {
+	ts, _, err := setupServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ts.Close()
+	client := ts.Client()
+	var adminToken string
+	var employerToken string
+	t.Run("prepare admin account and token", prepareAdminAccount(ts.URL, client, &adminToken))
+	t.Run("prepare employer account and token", prepareEmployerAccount(ts.URL, client, &adminToken, &employerToken))
+	details := []JobPostDetails{
+		{
+			EmploymentType:   "part_time",
+			WeeklyHours:      int64Ptr(15),
+			WorkMode:         "remote",
+			SalaryMin:        int64Ptr(20),
+			SalaryMax:        int64Ptr(25),
+			SalaryCurrency:   "eur",
+			SalaryPeriod:     "hour",
+			PhysicalDemands:  "low",
+			FlexibleSchedule: true,
+		},
+		{
+			EmploymentType:      "full_time",
+			WeeklyHours:         int64Ptr(35),
+			WorkMode:            "on_site",
+			City:                "Lyon",
+			PostalCode:          "69001",
+			SalaryMin:           int64Ptr(2000),
+			SalaryCurrency:      "EUR",
+			SalaryPeriod:        "month",
+			PhysicalDemands:     "moderate",
+			AccessibleWorkplace: true,
+		},
+	}
+	t.Run("prepare published job posts with details", func(t *testing.T) {
+		for i, d := range details {
+			statusCode, resp, err := createMyJobPost(ts.URL, client, employerToken, &validJobPost)
+			if err != nil || statusCode != http.StatusCreated {
+				t.Fatalf("couldn't create job post: %v (status %d)", err, statusCode)
+			}
+			id := fmt.Sprintf("%d", resp.Result.ID)
+			data := &UpdateJobPostParams{Title: fmt.Sprintf("Job %d", i), Content: "Some content", Status: "pending_review", JobPostDetails: d}
+			statusCode, updateResp, err := updateMyJobPost(ts.URL, client, employerToken, id, data)
+			if err != nil || statusCode != http.StatusOK {
+				t.Fatalf("couldn't submit job post: %v (status %d: %s)", err, statusCode, updateResp.Error)
+			}
+			statusCode, _, err = moderateJobPost(ts.URL, client, adminToken, id, "approve", &ModerateJobPostParams{})
+			if err != nil || statusCode != http.StatusOK {
+				t.Fatalf("couldn't approve job post: %v (status %d)", err, statusCode)
+			}
+		}
+	})
+
+	t.Run("List job posts - with details", func(t *testing.T) {
+		statusCode, resp, err := listJobPosts(ts.URL, client, "sort=published_at")
+		if err != nil {
+			t.Fatalf("couldn't list job posts: %s", err)
+		}
+		if statusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, statusCode)
+		}
+		if len(resp.Result) != 2 {
+			t.Fatalf("expected 2 job posts, got %v", resp.Result)
+		}
+		got := resp.Result[0].JobPostDetails
+		if got.WorkMode != "remote" || *got.WeeklyHours != 15 || *got.SalaryMax != 25 || got.SalaryCurrency != "EUR" || !got.FlexibleSchedule || got.AccessibleWorkplace {
+			t.Fatalf("unexpected job post details: %+v", got)
+		}
+	})
+
+	filterCases := []struct {
+		desc   string
+		query  string
+		status int
+		titles []string
+	}{
+		{
+			desc:   "Employment type",
+			query:  "employment_type=part_time",
+			status: http.StatusOK,
+			titles: []string{"Job 0"},
+		},
+		{
+			desc:   "Work mode",
+			query:  "work_mode=on_site",
+			status: http.StatusOK,
+			titles: []string{"Job 1"},
+		},
+		{
+			desc:   "City, ignoring case",
+			query:  "city=lyon",
+			status: http.StatusOK,
+			titles: []string{"Job 1"},
+		},
+		{
+			desc:   "Max weekly hours",
+			query:  "max_weekly_hours=20",
+			status: http.StatusOK,
+			titles: []string{"Job 0"},
+		},
+		{
+			desc:   "Min salary reached by the top of the range",
+			query:  "min_salary=22&salary_currency=EUR",
+			status: http.StatusOK,
+			titles: []string{"Job 0", "Job 1"},
+		},
+		{
+			desc:   "Min salary above every range",
+			query:  "min_salary=3000",
+			status: http.StatusOK,
+			titles: []string{},
+		},
+		{
+			desc:   "Flexible schedule",
+			query:  "flexible_schedule=true",
+			status: http.StatusOK,
+			titles: []string{"Job 0"},
+		},
+		{
+			desc:   "Accessible workplace and low physical demands",
+			query:  "accessible_workplace=true&physical_demands=low",
+			status: http.StatusOK,
+			titles: []string{},
+		},
+		{
+			desc:   "Invalid work mode",
+			query:  "work_mode=moon",
+			status: http.StatusBadRequest,
+		},
+		{
+			desc:   "Invalid boolean",
+			query:  "flexible_schedule=sometimes",
+			status: http.StatusBadRequest,
+		},
+		{
+			desc:   "Invalid hours",
+			query:  "max_weekly_hours=many",
+			status: http.StatusBadRequest,
+		},
+	}
+	for _, tC := range filterCases {
+		t.Run("List job posts - "+tC.desc, func(t *testing.T) {
+			statusCode, resp, err := listJobPosts(ts.URL, client, "sort=published_at&"+tC.query)
+			if err != nil {
+				t.Fatalf("couldn't list job posts: %s", err)
+			}
+			if statusCode != tC.status {
+				t.Fatalf("expected status %d, got %d", tC.status, statusCode)
+			}
+			if tC.status != http.StatusOK {
+				return
+			}
+			titles := make([]string, 0, len(resp.Result))
+			for _, jobPost := range resp.Result {
+				titles = append(titles, jobPost.Title)
+			}
+			if fmt.Sprint(titles) != fmt.Sprint(tC.titles) {
+				t.Fatalf("expected job posts %v, got %v", tC.titles, titles)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gruyaume/lesvieux/internal/db"
+)
+
+type LoginLockoutResponse struct {
+	Role           int64  `json:"role"`
+	Email          string `json:"email"`
+	FailedAttempts int64  `json:"failed_attempts"`
+	LastFailedAt   string `json:"last_failed_at"`
+	LockedUntil    string `json:"locked_until"`
+}
+
+type UnlockLoginParams struct {
+	Role  *int64 `json:"role"`
+	Email string `json:"email"`
+}
+
+// ListLoginLockouts returns the emails that are locked out of logging in, those locked out the longest first.
+func ListLoginLockouts(env *HandlerConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		now := sql.NullString{String: time.Now().UTC().Format(time.RFC3339), Valid: true}
+		lockouts, err := env.DBQueries.ListLockedLogins(context.Background(), now)
+		if err != nil {
+			logError(r, "internal error", err)
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		lockoutsResponse := make([]LoginLockoutResponse, 0, len(lockouts))
+		for _, lockout := range lockouts {
+			lockoutsResponse = append(lockoutsResponse, LoginLockoutResponse{
+				Role:           lockout.Role,
+				Email:          lockout.Email,
+				FailedAttempts: lockout.FailedAttempts,
+				LastFailedAt:   lockout.LastFailedAt,
+				LockedUntil:    lockout.LockedUntil.String,
+			})
+		}
+		err = writeJSON(w, lockoutsResponse)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+	}
+}
+
+// UnlockLogin lifts the lockout of an email and clears its failed logins, for its account to log in right away.
+func UnlockLogin(env *HandlerConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var params UnlockLoginParams
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid JSON format")
+			return
+		}
+		if params.Role == nil {
+			writeError(w, http.StatusBadRequest, "Role is required")
+			return
+		}
+		role := *params.Role
+		if role != AdminRole && role != EmployerRole && role != ApplicantRole {
+			writeError(w, http.StatusBadRequest, "Invalid role")
+			return
+		}
+		if params.Email == "" {
+			writeError(w, http.StatusBadRequest, "Email is required")
+			return
+		}
+		email := loginEmail(params.Email)
+		failure, err := env.DBQueries.GetLoginFailure(context.Background(), db.GetLoginFailureParams{Role: role, Email: email})
+		if err != nil {
+			if err == sql.ErrNoRows {
+				writeError(w, http.StatusNotFound, "Login lockout not found")
+				return
+			}
+			logError(r, "internal error", err)
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		err = env.DBQueries.ExecTx(context.Background(), func(q *db.Queries) error {
+			_, err := q.DeleteLoginFailure(context.Background(), db.DeleteLoginFailureParams{Role: role, Email: email})
+			if err != nil {
+				return err
+			}
+			return recordAuditEvent(context.Background(), q, r, auditEvent{
+				Action:     AuditActionLoginLockoutUnlock,
+				TargetType: AuditTargetLoginLockout,
+				Before: map[string]any{
+					"role":            role,
+					"email":           email,
+					"failed_attempts": failure.FailedAttempts,
+					"locked_until":    failure.LockedUntil.String,
+				},
+			})
+		})
+		if err != nil {
+			logError(r, "internal error", err)
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		env.logins.forget(role, email)
+		response := map[string]any{"role": role, "email": email}
+		err = writeJSON(w, response)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+	}
+}
@@ -0,0 +1,334 @@
+package server_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gruyaume/lesvieux/internal/jwtkeys"
+	"github.com/gruyaume/lesvieux/internal/server"
+)
+
+type LoginAttemptResponse struct {
+	Error string `json:"error,omitempty"`
+	// RetryAfter is the Retry-After header of refused logins.
+	RetryAfter string `json:"-"`
+}
+
+type LoginLockoutResponseResult struct {
+	Role           int64  `json:"role"`
+	Email          string `json:"email"`
+	FailedAttempts int64  `json:"failed_attempts"`
+	LastFailedAt   string `json:"last_failed_at"`
+	LockedUntil    string `json:"locked_until"`
+}
+
+type ListLoginLockoutsResponse struct {
+	Result []LoginLockoutResponseResult `json:"result"`
+	Error  string                       `json:"error,omitempty"`
+}
+
+type UnlockLoginParams struct {
+	Role  int64  `json:"role"`
+	Email string `json:"email"`
+}
+
+type UnlockLoginResponse struct {
+	Error string `json:"error,omitempty"`
+}
+
+// setupServerWithLoginLimits returns a test server enforcing limits, which trusts the X-Forwarded-For header
+// of local clients for tests to log in from several addresses.
+func setupServerWithLoginLimits(limits server.LoginLimits) (*httptest.Server, error) {
+	dbQueries, err := setupDatabase()
+	if err != nil {
+		return nil, err
+	}
+	keys, err := jwtkeys.Generate()
+	if err != nil {
+		return nil, err
+	}
+	config := &server.HandlerConfig{
+		DBQueries:      dbQueries,
+		JWTKeys:        jwtkeys.NewKeyring(keys),
+		TrustedProxies: []netip.Prefix{netip.MustParsePrefix("127.0.0.0/8"), netip.MustParsePrefix("::1/128")},
+		LoginLimits:    limits,
+	}
+	return httptest.NewTLSServer(server.NewLesVieuxRouter(config)), nil
+}
+
+// loginFrom logs in at the login path of a role, as the client at ip.
+func loginFrom(url string, client *http.Client, path string, ip string, data *ApplicantLoginParams) (int, *LoginAttemptResponse, error) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return 0, nil, err
+	}
+	req, err := http.NewRequest("POST", url+"/api/v1"+path, strings.NewReader(string(body)))
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("X-Forwarded-For", ip)
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer res.Body.Close()
+	var response LoginAttemptResponse
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return 0, nil, err
+	}
+	response.RetryAfter = res.Header.Get("Retry-After")
+	return res.StatusCode, &response, nil
+}
+
+func listLoginLockouts(url string, client *http.Client, token string) (int, *ListLoginLockoutsResponse, error) {
+	var response ListLoginLockoutsResponse
+	statusCode, err := apiRequest("GET", url, client, token, "/admin/login_lockouts", nil, &response)
+	if err != nil {
+		return 0, nil, err
+	}
+	return statusCode, &response, nil
+}
+
+func unlockLogin(url string, client *http.Client, token string, data *UnlockLoginParams) (int, *UnlockLoginResponse, error) {
+	var response UnlockLoginResponse
+	statusCode, err := apiRequest("POST", url, client, token, "/admin/login_lockouts/unlock", data, &response)
+	if err != nil {
+		return 0, nil, err
+	}
+	return statusCode, &response, nil
+}
+
+func TestLoginRateLimits(t *testing.T) {
+	generous := server.RateLimit{Burst: 100, Interval: time.Second}
+	strict := server.RateLimit{Burst: 3, Interval: time.Hour}
+	type attempt struct {
+		Path           string
+		IP             string
+		Email          string
+		ExpectedStatus int
+	}
+	cases := []struct {
+		Name     string
+		Limits   server.LoginLimits
+		Attempts []attempt
+	}{
+		{
+			Name:   "per IP",
+			Limits: server.LoginLimits{PerIP: strict, PerAccount: generous, LockoutThreshold: 100, LockoutDuration: time.Minute, MaxLockoutDuration: time.Hour},
+			Attempts: []attempt{
+				{"/applicants/login", "203.0.113.1", "a@example.com", http.StatusUnauthorized},
+				{"/employers/login", "203.0.113.1", "b@example.com", http.StatusUnauthorized},
+				{"/admin/login", "203.0.113.1", "c@example.com", http.StatusUnauthorized},
+				{"/applicants/login", "203.0.113.1", "d@example.com", http.StatusTooManyRequests},
+				{"/applicants/login", "203.0.113.2", "d@example.com", http.StatusUnauthorized},
+			},
+		},
+		{
+			Name:   "per account",
+			Limits: server.LoginLimits{PerIP: generous, PerAccount: strict, LockoutThreshold: 100, LockoutDuration: time.Minute, MaxLockoutDuration: time.Hour},
+			Attempts: []attempt{
+				{"/applicants/login", "203.0.113.1", "a@example.com", http.StatusUnauthorized},
+				{"/applicants/login", "203.0.113.2", "a@example.com", http.StatusUnauthorized},
+				{"/applicants/login", "203.0.113.3", "A@Example.com", http.StatusUnauthorized},
+				{"/applicants/login", "203.0.113.4", "a@example.com", http.StatusTooManyRequests},
+				{"/employers/login", "203.0.113.4", "a@example.com", http.StatusUnauthorized},
+				{"/applicants/login", "203.0.113.4", "b@example.com", http.StatusUnauthorized},
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			ts, err := setupServerWithLoginLimits(c.Limits)
+			if err != nil {
+				t.Fatalf("couldn't create test server: %s", err)
+			}
+			defer ts.Close()
+			client := ts.Client()
+			for i, a := range c.Attempts {
+				statusCode, response, err := loginFrom(ts.URL, client, a.Path, a.IP, &ApplicantLoginParams{Email: a.Email, Password: "wrong password"})
+				if err != nil {
+					t.Fatalf("couldn't log in: %s", err)
+				}
+				if statusCode != a.ExpectedStatus {
+					t.Fatalf("attempt %d: expected status %d, got %d", i, a.ExpectedStatus, statusCode)
+				}
+				if statusCode == http.StatusTooManyRequests && response.RetryAfter == "" {
+					t.Fatalf("attempt %d: expected a Retry-After header", i)
+				}
+			}
+		})
+	}
+}
+
+func TestLoginLockoutEndToEnd(t *testing.T) {
+	ts, err := setupServerWithLoginLimits(server.LoginLimits{
+		PerIP:              server.RateLimit{Burst: 100, Interval: time.Second},
+		PerAccount:         server.RateLimit{Burst: 100, Interval: time.Second},
+		LockoutThreshold:   3,
+		LockoutDuration:    time.Minute,
+		MaxLockoutDuration: time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("couldn't create test server: %s", err)
+	}
+	defer ts.Close()
+	client := ts.Client()
+	var adminToken string
+	t.Run("prepare admin account", prepareAdminAccount(ts.URL, client, &adminToken))
+	t.Run("prepare applicant account", func(t *testing.T) {
+		statusCode, _, err := createApplicantAccount(ts.URL, client, &validApplicantAccount)
+		if err != nil || statusCode != http.StatusCreated {
+			t.Fatalf("couldn't create applicant account: %d %v", statusCode, err)
+		}
+	})
+
+	unknownEmail := "nobody@example.com"
+	t.Run("Known and unknown emails are locked out alike", func(t *testing.T) {
+		for _, email := range []string{validApplicantAccount.Email, unknownEmail} {
+			for i := 0; i < 3; i++ {
+				statusCode, response, err := loginFrom(ts.URL, client, "/applicants/login", "203.0.113.1", &ApplicantLoginParams{Email: email, Password: "wrong password"})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if statusCode != http.StatusUnauthorized || response.Error != "The email or password is incorrect. Try again." {
+					t.Fatalf("expected the failed login of %s to be refused with 401, got %d %q", email, statusCode, response.Error)
+				}
+			}
+			statusCode, response, err := loginFrom(ts.URL, client, "/applicants/login", "203.0.113.2", &ApplicantLoginParams{Email: email, Password: validApplicantAccount.Password})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if statusCode != http.StatusTooManyRequests || response.Error != "Too many failed login attempts. Try again later." {
+				t.Fatalf("expected %s to be locked out, got %d %q", email, statusCode, response.Error)
+			}
+			if response.RetryAfter == "" || response.RetryAfter == "0" {
+				t.Fatalf("expected a Retry-After header, got %q", response.RetryAfter)
+			}
+		}
+	})
+
+	t.Run("Lockouts are only lifted for their role", func(t *testing.T) {
+		statusCode, _, err := loginFrom(ts.URL, client, "/employers/login", "203.0.113.1", &ApplicantLoginParams{Email: unknownEmail, Password: "wrong password"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if statusCode != http.StatusUnauthorized {
+			t.Fatalf("expected the employer login not to be locked out, got %d", statusCode)
+		}
+	})
+
+	t.Run("List lockouts", func(t *testing.T) {
+		statusCode, response, err := listLoginLockouts(ts.URL, client, adminToken)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if statusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, statusCode)
+		}
+		if len(response.Result) != 2 {
+			t.Fatalf("expected 2 lockouts, got %+v", response.Result)
+		}
+		for _, lockout := range response.Result {
+			if lockout.Role != 0 || lockout.FailedAttempts != 3 || lockout.LockedUntil == "" {
+				t.Fatalf("unexpected lockout %+v", lockout)
+			}
+		}
+	})
+
+	t.Run("List lockouts requires admin", func(t *testing.T) {
+		statusCode, _, err := listLoginLockouts(ts.URL, client, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if statusCode != http.StatusUnauthorized {
+			t.Fatalf("expected status %d, got %d", http.StatusUnauthorized, statusCode)
+		}
+	})
+
+	t.Run("Unlock", func(t *testing.T) {
+		statusCode, response, err := unlockLogin(ts.URL, client, adminToken, &UnlockLoginParams{Role: 0, Email: strings.ToUpper(validApplicantAccount.Email)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if statusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusOK, statusCode, response.Error)
+		}
+		statusCode, _, err = loginFrom(ts.URL, client, "/applicants/login", "203.0.113.2", &ApplicantLoginParams{Email: validApplicantAccount.Email, Password: validApplicantAccount.Password})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if statusCode != http.StatusOK {
+			t.Fatalf("expected the unlocked login to succeed, got %d", statusCode)
+		}
+		statusCode, response, err = unlockLogin(ts.URL, client, adminToken, &UnlockLoginParams{Role: 0, Email: validApplicantAccount.Email})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if statusCode != http.StatusNotFound {
+			t.Fatalf("expected status %d, got %d", http.StatusNotFound, statusCode)
+		}
+		statusCode, response, err = unlockLogin(ts.URL, client, adminToken, &UnlockLoginParams{Role: 7, Email: validApplicantAccount.Email})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if statusCode != http.StatusBadRequest || response.Error != "Invalid role" {
+			t.Fatalf("expected status %d, got %d %q", http.StatusBadRequest, statusCode, response.Error)
+		}
+	})
+
+	t.Run("Unlock is audited", func(t *testing.T) {
+		statusCode, response, err := listAuditEvents(ts.URL, client, adminToken, "?action=login_lockout.unlock")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if statusCode != http.StatusOK || len(response.Result) != 1 {
+			t.Fatalf("expected 1 unlock in the audit log, got %d %+v", statusCode, response.Result)
+		}
+		if response.Result[0].TargetType != "login_lockout" || !strings.Contains(string(response.Result[0].Before), validApplicantAccount.Email) {
+			t.Fatalf("unexpected audit event %+v", response.Result[0])
+		}
+	})
+
+	t.Run("Metrics", func(t *testing.T) {
+		body, err := getMetrics(ts.URL, client)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := []string{
+			`login_lockouts_total{role="applicant"} 2`,
+			`logins_throttled_total{reason="lockout",role="applicant"} 2`,
+			`logins_total{result="failed",role="applicant"} 6`,
+		}
+		for _, line := range expected {
+			if !strings.Contains(body, line) {
+				t.Errorf("expected %q in the metrics", line)
+			}
+		}
+	})
+}
+
+func TestPartialLoginLimitsDefaultTheOtherFields(t *testing.T) {
+	ts, err := setupServerWithLoginLimits(server.LoginLimits{LockoutThreshold: 2})
+	if err != nil {
+		t.Fatalf("couldn't create test server: %s", err)
+	}
+	defer ts.Close()
+	client := ts.Client()
+	for i, expectedStatus := range []int{http.StatusUnauthorized, http.StatusUnauthorized, http.StatusTooManyRequests} {
+		statusCode, response, err := loginFrom(ts.URL, client, "/applicants/login", "203.0.113.1", &ApplicantLoginParams{Email: "a@example.com", Password: "wrong password"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if statusCode != expectedStatus {
+			t.Fatalf("attempt %d: expected status %d, got %d", i, expectedStatus, statusCode)
+		}
+		if statusCode == http.StatusTooManyRequests && response.Error != "Too many failed login attempts. Try again later." {
+			t.Fatalf("expected a lockout after the threshold, got %q", response.Error)
+		}
+	}
+}
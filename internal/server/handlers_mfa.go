@@ -0,0 +1,492 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gruyaume/lesvieux/internal/db"
+	"github.com/gruyaume/lesvieux/internal/totp"
+)
+
+type MFAStatusResponse struct {
+	Enabled bool `json:"enabled"`
+	// Required is set when the role of the account must have a second factor to log in.
+	Required               bool  `json:"required"`
+	RecoveryCodesRemaining int64 `json:"recovery_codes_remaining"`
+}
+
+type EnrollTOTPResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+type MFACodeParams struct {
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+type ConfirmTOTPResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+	// Token and RefreshToken start the session of a login that had to enroll a second factor.
+	Token        string `json:"token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type VerifyMFAParams struct {
+	MFAToken     string `json:"mfa_token"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+// GetMyMFAStatus tells whether the logged in account has a second factor, and how many recovery codes it has left.
+func GetMyMFAStatus(env *HandlerConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		accountID := r.Context().Value(userIDKey).(int64)
+		role := r.Context().Value(roleKey).(int64)
+		enabled, err := hasSecondFactor(context.Background(), env.DBQueries, accountID, role)
+		if err != nil {
+			logError(r, "internal error", err)
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		response := MFAStatusResponse{Enabled: enabled, Required: env.mfaRequired(role)}
+		if enabled {
+			response.RecoveryCodesRemaining, err = env.DBQueries.CountRecoveryCodes(context.Background(), db.CountRecoveryCodesParams{AccountID: accountID, Role: role})
+			if err != nil {
+				logError(r, "internal error", err)
+				writeError(w, http.StatusInternalServerError, "internal error")
+				return
+			}
+		}
+		err = writeJSON(w, response)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+	}
+}
+
+// EnrollTOTP generates the secret of a TOTP factor for the logged in account, along with its otpauth:// URI,
+// which authenticator apps read from a QR code. The factor is only enabled once ConfirmTOTP gets a code of it.
+// Enrolling again before that replaces the secret.
+func EnrollTOTP(env *HandlerConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		accountID := r.Context().Value(userIDKey).(int64)
+		role := r.Context().Value(roleKey).(int64)
+		email := r.Context().Value(emailKey).(string)
+		secret, err := totp.GenerateSecret()
+		if err != nil {
+			logError(r, "internal error", err)
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		err = env.DBQueries.ExecTx(context.Background(), func(q *db.Queries) error {
+			_, err := q.CreateTOTPFactor(context.Background(), db.CreateTOTPFactorParams{
+				AccountID: accountID,
+				Role:      role,
+				Secret:    secret,
+				CreatedAt: time.Now().UTC().Format(time.RFC3339),
+			})
+			if err != nil {
+				return err
+			}
+			event := ownSecondFactorAuditEvent(role, accountID, AuditActionAdminAccountEnrollMFA, AuditActionEmployerAccountEnrollMFA)
+			event.After = map[string]any{"method": "totp"}
+			return recordAuditEvent(context.Background(), q, r, event)
+		})
+		if err != nil {
+			// The factor isn't replaced once it is confirmed.
+			if err == sql.ErrNoRows {
+				writeError(w, http.StatusConflict, "A second factor is already enabled: disable it first")
+				return
+			}
+			logError(r, "internal error", err)
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		response := EnrollTOTPResponse{
+			Secret:          secret,
+			ProvisioningURI: totp.ProvisioningURI(env.mfaIssuer(), email, secret),
+		}
+		err = writeJSON(w, response)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+	}
+}
+
+// ConfirmTOTP enables the pending TOTP factor of the logged in account with a first code of it, and hands out
+// its recovery codes. When the request comes from a login that had to enroll a second factor, it also starts
+// the session of the login.
+func ConfirmTOTP(env *HandlerConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		accountID := r.Context().Value(userIDKey).(int64)
+		role := r.Context().Value(roleKey).(int64)
+		email := r.Context().Value(emailKey).(string)
+		var params MFACodeParams
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid JSON format")
+			return
+		}
+		if params.Code == "" {
+			writeError(w, http.StatusBadRequest, "Code is required")
+			return
+		}
+		factor, err := env.DBQueries.GetTOTPFactor(context.Background(), db.GetTOTPFactorParams{AccountID: accountID, Role: role})
+		if err != nil {
+			if err == sql.ErrNoRows {
+				writeError(w, http.StatusNotFound, "No second factor is being enrolled")
+				return
+			}
+			logError(r, "internal error", err)
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		if factor.ConfirmedAt.Valid {
+			writeError(w, http.StatusConflict, "The second factor is already enabled")
+			return
+		}
+		step, ok := totp.Validate(factor.Secret, params.Code, time.Now())
+		if !ok {
+			writeError(w, http.StatusUnauthorized, "The code is incorrect. Try again.")
+			return
+		}
+		var recoveryCodes []string
+		err = env.DBQueries.ExecTx(context.Background(), func(q *db.Queries) error {
+			confirmed, err := q.ConfirmTOTPFactor(context.Background(), db.ConfirmTOTPFactorParams{
+				ConfirmedAt: sql.NullString{String: time.Now().UTC().Format(time.RFC3339), Valid: true},
+				Step:        step,
+				AccountID:   accountID,
+				Role:        role,
+			})
+			if err != nil {
+				return err
+			}
+			if confirmed == 0 {
+				return sql.ErrNoRows
+			}
+			recoveryCodes, err = replaceRecoveryCodes(context.Background(), q, accountID, role)
+			if err != nil {
+				return err
+			}
+			event := ownSecondFactorAuditEvent(role, accountID, AuditActionAdminAccountEnableMFA, AuditActionEmployerAccountEnableMFA)
+			event.After = map[string]any{"method": "totp", "recovery_codes": len(recoveryCodes)}
+			return recordAuditEvent(context.Background(), q, r, event)
+		})
+		if err != nil {
+			if err == sql.ErrNoRows {
+				writeError(w, http.StatusConflict, "The second factor is already enabled")
+				return
+			}
+			logError(r, "internal error", err)
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		response := ConfirmTOTPResponse{RecoveryCodes: recoveryCodes}
+		if enrolling, _ := r.Context().Value(mfaEnrollmentKey).(bool); enrolling {
+			response.Token, response.RefreshToken, err = startSession(context.Background(), env.DBQueries, env.JWTKeys, accountID, email, role)
+			if err != nil {
+				logError(r, "internal error", err)
+				writeError(w, http.StatusInternalServerError, "internal error")
+				return
+			}
+			env.logins.succeed(r, role, email)
+		}
+		w.WriteHeader(http.StatusOK)
+		err = writeJSON(w, response)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+	}
+}
+
+// DisableTOTP removes the second factor of the logged in account, given a code of it or one of its recovery codes.
+// Accounts whose role requires a second factor can't remove theirs.
+func DisableTOTP(env *HandlerConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		accountID := r.Context().Value(userIDKey).(int64)
+		role := r.Context().Value(roleKey).(int64)
+		if env.mfaRequired(role) {
+			writeError(w, http.StatusForbidden, "A second factor is required for this account")
+			return
+		}
+		if !checkOwnSecondFactor(w, r, env, accountID, role) {
+			return
+		}
+		err := env.DBQueries.ExecTx(context.Background(), func(q *db.Queries) error {
+			if _, err := deleteSecondFactor(context.Background(), q, accountID, role); err != nil {
+				return err
+			}
+			event := ownSecondFactorAuditEvent(role, accountID, AuditActionAdminAccountDisableMFA, AuditActionEmployerAccountDisableMFA)
+			event.Before = map[string]any{"method": "totp"}
+			return recordAuditEvent(context.Background(), q, r, event)
+		})
+		if err != nil {
+			logError(r, "internal error", err)
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		err = writeJSON(w, map[string]any{"id": accountID})
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+	}
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of the logged in account, given a code of its second factor.
+func RegenerateRecoveryCodes(env *HandlerConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		accountID := r.Context().Value(userIDKey).(int64)
+		role := r.Context().Value(roleKey).(int64)
+		if !checkOwnSecondFactor(w, r, env, accountID, role) {
+			return
+		}
+		var recoveryCodes []string
+		err := env.DBQueries.ExecTx(context.Background(), func(q *db.Queries) error {
+			var err error
+			recoveryCodes, err = replaceRecoveryCodes(context.Background(), q, accountID, role)
+			if err != nil {
+				return err
+			}
+			event := ownSecondFactorAuditEvent(role, accountID, AuditActionAdminAccountRegenerateRecoveryCodes, AuditActionEmployerAccountRegenerateRecoveryCodes)
+			event.After = map[string]any{"recovery_codes": len(recoveryCodes)}
+			return recordAuditEvent(context.Background(), q, r, event)
+		})
+		if err != nil {
+			logError(r, "internal error", err)
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		err = writeJSON(w, RecoveryCodesResponse{RecoveryCodes: recoveryCodes})
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+	}
+}
+
+// ownSecondFactorAuditEvent is the audit event of an account changing its own second factor, whose action is
+// adminAction or employerAction depending on the role of the account.
+func ownSecondFactorAuditEvent(role int64, accountID int64, adminAction string, employerAction string) auditEvent {
+	if role == EmployerRole {
+		return auditEvent{Action: employerAction, TargetType: AuditTargetEmployerAccount, TargetID: accountID}
+	}
+	return auditEvent{Action: adminAction, TargetType: AuditTargetAdminAccount, TargetID: accountID}
+}
+
+// checkOwnSecondFactor checks the code or recovery code of the request body against the confirmed second factor
+// of the logged in account. Requests authenticated by an enrollment challenge are refused, since the account has
+// no second factor yet. Wrong codes count as failed logins of the account, as in VerifyMFA, so a stolen access
+// token can't be used to guess them. Otherwise, it writes the error response and returns false.
+func checkOwnSecondFactor(w http.ResponseWriter, r *http.Request, env *HandlerConfig, accountID int64, role int64) bool {
+	if enrolling, _ := r.Context().Value(mfaEnrollmentKey).(bool); enrolling {
+		writeError(w, http.StatusForbidden, "forbidden: log in first")
+		return false
+	}
+	var params MFACodeParams
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid JSON format")
+		return false
+	}
+	if params.Code == "" && params.RecoveryCode == "" {
+		writeError(w, http.StatusBadRequest, "Code or recovery_code is required")
+		return false
+	}
+	factor, err := env.DBQueries.GetTOTPFactor(context.Background(), db.GetTOTPFactorParams{AccountID: accountID, Role: role})
+	if err != nil && err != sql.ErrNoRows {
+		logError(r, "internal error", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return false
+	}
+	if err == sql.ErrNoRows || !factor.ConfirmedAt.Valid {
+		writeError(w, http.StatusNotFound, "No second factor is enabled")
+		return false
+	}
+	email := r.Context().Value(emailKey).(string)
+	if !env.logins.admit(w, r, role, email) {
+		return false
+	}
+	ok, err := checkSecondFactor(context.Background(), env.DBQueries, factor, params.Code, params.RecoveryCode)
+	if err != nil {
+		logError(r, "internal error", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return false
+	}
+	if !ok {
+		env.logins.fail(w, r, role, email, "The code is incorrect. Try again.")
+		return false
+	}
+	env.logins.succeed(r, role, email)
+	return true
+}
+
+// VerifyMFA completes a login whose password was right with a code of the second factor of the account,
+// or one of its recovery codes, and starts its session. Wrong codes count as failed logins.
+func VerifyMFA(env *HandlerConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var params VerifyMFAParams
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid JSON format")
+			return
+		}
+		if params.MFAToken == "" {
+			writeError(w, http.StatusBadRequest, "mfa_token is required")
+			return
+		}
+		if params.Code == "" && params.RecoveryCode == "" {
+			writeError(w, http.StatusBadRequest, "Code or recovery_code is required")
+			return
+		}
+		challenge, err := parseMFAChallenge(params.MFAToken, mfaVerifyAudience, env.JWTKeys)
+		if err != nil {
+			writeError(w, http.StatusUnauthorized, "Invalid or expired mfa_token: log in again")
+			return
+		}
+		if challenge.Role == AdminRole && !checkAdminClientCert(w, r) {
+			return
+		}
+		if !env.logins.admit(w, r, challenge.Role, challenge.Email) {
+			return
+		}
+		factor, err := env.DBQueries.GetTOTPFactor(context.Background(), db.GetTOTPFactorParams{AccountID: challenge.ID, Role: challenge.Role})
+		if err != nil && err != sql.ErrNoRows {
+			logError(r, "internal error", err)
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		// The second factor was reset since the password was checked.
+		if err == sql.ErrNoRows || !factor.ConfirmedAt.Valid {
+			writeError(w, http.StatusUnauthorized, "Invalid or expired mfa_token: log in again")
+			return
+		}
+		ok, err := checkSecondFactor(context.Background(), env.DBQueries, factor, params.Code, params.RecoveryCode)
+		if err != nil {
+			logError(r, "internal error", err)
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		if !ok {
+			env.logins.fail(w, r, challenge.Role, challenge.Email, "The code is incorrect. Try again.")
+			return
+		}
+		accessToken, refreshToken, err := startSession(context.Background(), env.DBQueries, env.JWTKeys, challenge.ID, challenge.Email, challenge.Role)
+		if err != nil {
+			logError(r, "internal error", err)
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		env.logins.succeed(r, challenge.Role, challenge.Email)
+		w.WriteHeader(http.StatusOK)
+		err = writeJSON(w, LoginResponse{Token: accessToken, RefreshToken: refreshToken})
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+	}
+}
+
+// ResetAdminAccountMFA removes the second factor of an admin account, for an admin who lost theirs to enroll again.
+func ResetAdminAccountMFA(env *HandlerConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		idInt, err := strconv.ParseInt(r.PathValue("account_id"), 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid id")
+			return
+		}
+		account, err := env.DBQueries.GetAdminAccount(context.Background(), idInt)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				writeError(w, http.StatusNotFound, "Admin Account not found")
+				return
+			}
+			logError(r, "internal error", err)
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		resetSecondFactor(w, r, env, idInt, AdminRole, auditEvent{
+			Action:     AuditActionAdminAccountResetMFA,
+			TargetType: AuditTargetAdminAccount,
+			TargetID:   idInt,
+			Before:     map[string]any{"email": account.Email},
+		})
+	}
+}
+
+// ResetEmployerAccountMFA removes the second factor of an employer account, for its owner to enroll again.
+func ResetEmployerAccountMFA(env *HandlerConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		employerIdInt, err := strconv.ParseInt(r.PathValue("employer_id"), 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid employer id")
+			return
+		}
+		userIdInt, err := strconv.ParseInt(r.PathValue("account_id"), 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid user id")
+			return
+		}
+		account, err := env.DBQueries.GetEmployerAccount(context.Background(), db.GetEmployerAccountParams{
+			EmployerID: employerIdInt,
+			ID:         userIdInt,
+		})
+		if err != nil {
+			if err == sql.ErrNoRows {
+				writeError(w, http.StatusNotFound, "EmployerAccount not found")
+				return
+			}
+			logError(r, "internal error", err)
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		resetSecondFactor(w, r, env, userIdInt, EmployerRole, auditEvent{
+			Action:     AuditActionEmployerAccountResetMFA,
+			TargetType: AuditTargetEmployerAccount,
+			TargetID:   userIdInt,
+			Before:     map[string]any{"email": account.Email, "employer_id": account.EmployerID},
+		})
+	}
+}
+
+// resetSecondFactor removes the second factor of an account along with its recovery codes, records event in the
+// audit log, and writes the response.
+func resetSecondFactor(w http.ResponseWriter, r *http.Request, env *HandlerConfig, accountID int64, role int64, event auditEvent) {
+	err := env.DBQueries.ExecTx(context.Background(), func(q *db.Queries) error {
+		deleted, err := deleteSecondFactor(context.Background(), q, accountID, role)
+		if err != nil {
+			return err
+		}
+		if !deleted {
+			return sql.ErrNoRows
+		}
+		return recordAuditEvent(context.Background(), q, r, event)
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			writeError(w, http.StatusNotFound, "Second factor not found")
+			return
+		}
+		logError(r, "internal error", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+	err = writeJSON(w, map[string]any{"id": accountID})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+}
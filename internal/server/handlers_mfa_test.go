@@ -0,0 +1,569 @@
+package server_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gruyaume/lesvieux/internal/jwtkeys"
+	"github.com/gruyaume/lesvieux/internal/server"
+	"github.com/gruyaume/lesvieux/internal/totp"
+)
+
+type MFALoginResponseResult struct {
+	Token                 string `json:"token"`
+	RefreshToken          string `json:"refresh_token"`
+	MFARequired           bool   `json:"mfa_required"`
+	MFAEnrollmentRequired bool   `json:"mfa_enrollment_required"`
+	MFAToken              string `json:"mfa_token"`
+}
+
+type MFALoginResponse struct {
+	Result MFALoginResponseResult `json:"result"`
+	Error  string                 `json:"error,omitempty"`
+}
+
+type MFAStatusResponse struct {
+	Result struct {
+		Enabled                bool  `json:"enabled"`
+		Required               bool  `json:"required"`
+		RecoveryCodesRemaining int64 `json:"recovery_codes_remaining"`
+	} `json:"result"`
+	Error string `json:"error,omitempty"`
+}
+
+type EnrollTOTPResponse struct {
+	Result struct {
+		Secret          string `json:"secret"`
+		ProvisioningURI string `json:"provisioning_uri"`
+	} `json:"result"`
+	Error string `json:"error,omitempty"`
+}
+
+type MFACodeParams struct {
+	Code         string `json:"code,omitempty"`
+	RecoveryCode string `json:"recovery_code,omitempty"`
+}
+
+type ConfirmTOTPResponse struct {
+	Result struct {
+		RecoveryCodes []string `json:"recovery_codes"`
+		Token         string   `json:"token"`
+		RefreshToken  string   `json:"refresh_token"`
+	} `json:"result"`
+	Error string `json:"error,omitempty"`
+}
+
+type RecoveryCodesResponse struct {
+	Result struct {
+		RecoveryCodes []string `json:"recovery_codes"`
+	} `json:"result"`
+	Error string `json:"error,omitempty"`
+}
+
+type VerifyMFAParams struct {
+	MFAToken     string `json:"mfa_token"`
+	Code         string `json:"code,omitempty"`
+	RecoveryCode string `json:"recovery_code,omitempty"`
+}
+
+type MFAResponse struct {
+	Error string `json:"error,omitempty"`
+}
+
+func mfaLogin(url string, client *http.Client, path string, email string, password string) (int, *MFALoginResponse, error) {
+	var response MFALoginResponse
+	statusCode, err := apiRequest("POST", url, client, "", path, &AdminLoginParams{Email: email, Password: password}, &response)
+	if err != nil {
+		return 0, nil, err
+	}
+	return statusCode, &response, nil
+}
+
+// enrollTOTP enrolls a TOTP factor with token, confirms it with the code of the current time step,
+// and returns its secret and the step of the code along with the response of the confirmation.
+func enrollTOTP(t *testing.T, url string, client *http.Client, token string) (string, int64, *ConfirmTOTPResponse) {
+	t.Helper()
+	var enrollResponse EnrollTOTPResponse
+	statusCode, err := apiRequest("POST", url, client, token, "/auth/mfa/totp", nil, &enrollResponse)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if statusCode != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusCreated, statusCode, enrollResponse.Error)
+	}
+	secret := enrollResponse.Result.Secret
+	step := totp.Step(time.Now())
+	code, err := totp.Code(secret, step)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var confirmResponse ConfirmTOTPResponse
+	statusCode, err = apiRequest("POST", url, client, token, "/auth/mfa/totp/confirm", &MFACodeParams{Code: code}, &confirmResponse)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if statusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, statusCode, confirmResponse.Error)
+	}
+	return secret, step, &confirmResponse
+}
+
+func TestMFAEndToEnd(t *testing.T) {
+	// Codes are checked along with the logins, within their rate limits.
+	generous := server.RateLimit{Burst: 100, Interval: time.Second}
+	ts, err := setupServerWithLoginLimits(server.LoginLimits{
+		PerIP:              generous,
+		PerAccount:         generous,
+		LockoutThreshold:   5,
+		LockoutDuration:    time.Minute,
+		MaxLockoutDuration: time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("couldn't create test server: %s", err)
+	}
+	defer ts.Close()
+	client := ts.Client()
+	var adminToken string
+	t.Run("prepare admin account", prepareAdminAccount(ts.URL, client, &adminToken))
+
+	t.Run("Second factor is disabled by default", func(t *testing.T) {
+		var response MFAStatusResponse
+		statusCode, err := apiRequest("GET", ts.URL, client, adminToken, "/auth/mfa", nil, &response)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if statusCode != http.StatusOK || response.Result.Enabled || response.Result.Required {
+			t.Fatalf("expected the second factor to be disabled, got %d %+v", statusCode, response.Result)
+		}
+	})
+
+	var secret string
+	var step int64
+	var recoveryCodes []string
+	t.Run("Enroll", func(t *testing.T) {
+		var enrollResponse EnrollTOTPResponse
+		statusCode, err := apiRequest("POST", ts.URL, client, adminToken, "/auth/mfa/totp", nil, &enrollResponse)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if statusCode != http.StatusCreated {
+			t.Fatalf("expected status %d, got %d", http.StatusCreated, statusCode)
+		}
+		uri := enrollResponse.Result.ProvisioningURI
+		if !strings.HasPrefix(uri, "otpauth://totp/LesVieux:testadmin?") || !strings.Contains(uri, "secret="+enrollResponse.Result.Secret) {
+			t.Fatalf("unexpected provisioning URI %q", uri)
+		}
+		var response ConfirmTOTPResponse
+		statusCode, err = apiRequest("POST", ts.URL, client, adminToken, "/auth/mfa/totp/confirm", &MFACodeParams{Code: "000000"}, &response)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if statusCode != http.StatusUnauthorized {
+			t.Fatalf("expected a wrong code to be refused, got %d", statusCode)
+		}
+
+		var confirmResponse *ConfirmTOTPResponse
+		secret, step, confirmResponse = enrollTOTP(t, ts.URL, client, adminToken)
+		recoveryCodes = confirmResponse.Result.RecoveryCodes
+		if len(recoveryCodes) != 10 {
+			t.Fatalf("expected 10 recovery codes, got %v", recoveryCodes)
+		}
+		if confirmResponse.Result.Token != "" {
+			t.Fatal("expected no session to be started by enrolling while logged in")
+		}
+
+		var enrollAgain EnrollTOTPResponse
+		statusCode, err = apiRequest("POST", ts.URL, client, adminToken, "/auth/mfa/totp", nil, &enrollAgain)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if statusCode != http.StatusConflict {
+			t.Fatalf("expected the enabled factor not to be replaced, got %d", statusCode)
+		}
+	})
+
+	var mfaToken string
+	t.Run("Login requires a code", func(t *testing.T) {
+		statusCode, response, err := mfaLogin(ts.URL, client, "/admin/login", adminUser.Email, adminUser.Password)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if statusCode != http.StatusOK || !response.Result.MFARequired || response.Result.Token != "" || response.Result.MFAToken == "" {
+			t.Fatalf("expected an MFA challenge, got %d %+v", statusCode, response.Result)
+		}
+		mfaToken = response.Result.MFAToken
+
+		var statusResponse MFAStatusResponse
+		statusCode, err = apiRequest("GET", ts.URL, client, mfaToken, "/admin/accounts", nil, &statusResponse)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if statusCode != http.StatusUnauthorized {
+			t.Fatalf("expected the challenge not to be usable as an access token, got %d", statusCode)
+		}
+	})
+
+	t.Run("Verify", func(t *testing.T) {
+		var response MFALoginResponse
+		statusCode, err := apiRequest("POST", ts.URL, client, "", "/auth/mfa/verify", &VerifyMFAParams{MFAToken: mfaToken, Code: "000000"}, &response)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if statusCode != http.StatusUnauthorized || response.Error != "The code is incorrect. Try again." {
+			t.Fatalf("expected a wrong code to be refused, got %d %q", statusCode, response.Error)
+		}
+		// The code of the enrollment can't be used again, the next one is accepted too.
+		used, err := totp.Code(secret, step)
+		if err != nil {
+			t.Fatal(err)
+		}
+		statusCode, err = apiRequest("POST", ts.URL, client, "", "/auth/mfa/verify", &VerifyMFAParams{MFAToken: mfaToken, Code: used}, &response)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if statusCode != http.StatusUnauthorized {
+			t.Fatalf("expected a used code to be refused, got %d", statusCode)
+		}
+		next, err := totp.Code(secret, step+1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		statusCode, err = apiRequest("POST", ts.URL, client, "", "/auth/mfa/verify", &VerifyMFAParams{MFAToken: mfaToken, Code: next}, &response)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if statusCode != http.StatusOK || response.Result.Token == "" || response.Result.RefreshToken == "" {
+			t.Fatalf("expected the login to succeed, got %d %+v", statusCode, response)
+		}
+		statusCode, err = apiRequest("POST", ts.URL, client, "", "/auth/mfa/verify", &VerifyMFAParams{MFAToken: mfaToken, Code: next}, &response)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if statusCode != http.StatusUnauthorized {
+			t.Fatalf("expected a replayed code to be refused, got %d", statusCode)
+		}
+	})
+
+	t.Run("Recovery codes are used once", func(t *testing.T) {
+		for i, expected := range []int{http.StatusOK, http.StatusUnauthorized} {
+			statusCode, loginResponse, err := mfaLogin(ts.URL, client, "/admin/login", adminUser.Email, adminUser.Password)
+			if err != nil || statusCode != http.StatusOK {
+				t.Fatalf("couldn't log in: %d %v", statusCode, err)
+			}
+			var response MFALoginResponse
+			params := VerifyMFAParams{MFAToken: loginResponse.Result.MFAToken, RecoveryCode: strings.ToUpper(recoveryCodes[0])}
+			statusCode, err = apiRequest("POST", ts.URL, client, "", "/auth/mfa/verify", &params, &response)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if statusCode != expected {
+				t.Fatalf("attempt %d: expected status %d, got %d", i, expected, statusCode)
+			}
+		}
+		var response MFAStatusResponse
+		statusCode, err := apiRequest("GET", ts.URL, client, adminToken, "/auth/mfa", nil, &response)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if statusCode != http.StatusOK || !response.Result.Enabled || response.Result.RecoveryCodesRemaining != 9 {
+			t.Fatalf("expected 9 recovery codes left, got %d %+v", statusCode, response.Result)
+		}
+	})
+
+	t.Run("Regenerate recovery codes", func(t *testing.T) {
+		var response RecoveryCodesResponse
+		statusCode, err := apiRequest("POST", ts.URL, client, adminToken, "/auth/mfa/recovery_codes", &MFACodeParams{RecoveryCode: recoveryCodes[1]}, &response)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if statusCode != http.StatusOK || len(response.Result.RecoveryCodes) != 10 || slices.Contains(response.Result.RecoveryCodes, recoveryCodes[2]) {
+			t.Fatalf("expected 10 new recovery codes, got %d %+v", statusCode, response.Result)
+		}
+		statusCode, err = apiRequest("POST", ts.URL, client, adminToken, "/auth/mfa/recovery_codes", &MFACodeParams{RecoveryCode: recoveryCodes[2]}, &response)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if statusCode != http.StatusUnauthorized {
+			t.Fatalf("expected the previous recovery codes to be refused, got %d", statusCode)
+		}
+		recoveryCodes = response.Result.RecoveryCodes
+	})
+
+	t.Run("Disable", func(t *testing.T) {
+		var response MFAResponse
+		statusCode, err := apiRequest("DELETE", ts.URL, client, adminToken, "/auth/mfa/totp", &MFACodeParams{RecoveryCode: "wrong-code"}, &response)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if statusCode != http.StatusUnauthorized {
+			t.Fatalf("expected a wrong recovery code to be refused, got %d", statusCode)
+		}
+		statusCode, err = apiRequest("DELETE", ts.URL, client, adminToken, "/auth/mfa/totp", &MFACodeParams{RecoveryCode: recoveryCodes[1]}, &response)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if statusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusOK, statusCode, response.Error)
+		}
+		statusCode, loginResponse, err := mfaLogin(ts.URL, client, "/admin/login", adminUser.Email, adminUser.Password)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if statusCode != http.StatusOK || loginResponse.Result.MFARequired || loginResponse.Result.Token == "" {
+			t.Fatalf("expected the login to succeed with the password alone, got %d %+v", statusCode, loginResponse.Result)
+		}
+	})
+
+	t.Run("Second factor changes are audited", func(t *testing.T) {
+		cases := []struct {
+			Action   string
+			Expected int
+		}{
+			{"admin_account.enroll_mfa", 2},
+			{"admin_account.enable_mfa", 1},
+			{"admin_account.regenerate_recovery_codes", 1},
+			{"admin_account.disable_mfa", 1},
+		}
+		for _, c := range cases {
+			statusCode, response, err := listAuditEvents(ts.URL, client, adminToken, "?action="+c.Action)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if statusCode != http.StatusOK || len(response.Result) != c.Expected {
+				t.Fatalf("expected %d %s in the audit log, got %d %+v", c.Expected, c.Action, statusCode, response.Result)
+			}
+			for _, event := range response.Result {
+				if event.ActorRole == nil || *event.ActorRole != 1 || event.TargetType != "admin_account" || event.TargetID == nil || *event.TargetID != *event.ActorID {
+					t.Fatalf("unexpected audit event %+v", event)
+				}
+				if strings.Contains(string(event.After), recoveryCodes[0]) || strings.Contains(string(event.After), secret) {
+					t.Fatalf("expected the audit event to hold no secret, got %s", event.After)
+				}
+			}
+		}
+	})
+
+	t.Run("Applicants have no second factor", func(t *testing.T) {
+		var applicantToken string
+		prepareApplicantAccount(ts.URL, client, &applicantToken)(t)
+		var response EnrollTOTPResponse
+		statusCode, err := apiRequest("POST", ts.URL, client, applicantToken, "/auth/mfa/totp", nil, &response)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if statusCode != http.StatusForbidden {
+			t.Fatalf("expected status %d, got %d", http.StatusForbidden, statusCode)
+		}
+	})
+}
+
+func TestOwnSecondFactorLockout(t *testing.T) {
+	generous := server.RateLimit{Burst: 100, Interval: time.Second}
+	ts, err := setupServerWithLoginLimits(server.LoginLimits{
+		PerIP:              generous,
+		PerAccount:         generous,
+		LockoutThreshold:   3,
+		LockoutDuration:    time.Minute,
+		MaxLockoutDuration: time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("couldn't create test server: %s", err)
+	}
+	defer ts.Close()
+	client := ts.Client()
+	var adminToken string
+	t.Run("prepare admin account", prepareAdminAccount(ts.URL, client, &adminToken))
+	var recoveryCodes []string
+	t.Run("enroll", func(t *testing.T) {
+		_, _, confirmResponse := enrollTOTP(t, ts.URL, client, adminToken)
+		recoveryCodes = confirmResponse.Result.RecoveryCodes
+	})
+
+	t.Run("Wrong codes lock the account out", func(t *testing.T) {
+		attempts := []struct {
+			method string
+			path   string
+		}{
+			{"DELETE", "/auth/mfa/totp"},
+			{"POST", "/auth/mfa/recovery_codes"},
+			{"DELETE", "/auth/mfa/totp"},
+		}
+		for i, attempt := range attempts {
+			var response MFAResponse
+			statusCode, err := apiRequest(attempt.method, ts.URL, client, adminToken, attempt.path, &MFACodeParams{Code: "000000"}, &response)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if statusCode != http.StatusUnauthorized {
+				t.Fatalf("attempt %d: expected a wrong code to be refused with 401, got %d", i, statusCode)
+			}
+		}
+		var response MFAResponse
+		statusCode, err := apiRequest("DELETE", ts.URL, client, adminToken, "/auth/mfa/totp", &MFACodeParams{RecoveryCode: recoveryCodes[0]}, &response)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if statusCode != http.StatusTooManyRequests {
+			t.Fatalf("expected the locked out account to be refused with 429, got %d", statusCode)
+		}
+		statusCode, _, err = mfaLogin(ts.URL, client, "/admin/login", adminUser.Email, adminUser.Password)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if statusCode != http.StatusTooManyRequests {
+			t.Fatalf("expected the login of the locked out account to be refused with 429, got %d", statusCode)
+		}
+	})
+}
+
+func TestMFAResetByAdmin(t *testing.T) {
+	ts, _, err := setupServer()
+	if err != nil {
+		t.Fatalf("couldn't create test server: %s", err)
+	}
+	defer ts.Close()
+	client := ts.Client()
+	var adminToken, employerToken string
+	t.Run("prepare admin account", prepareAdminAccount(ts.URL, client, &adminToken))
+	t.Run("prepare employer account", prepareEmployerAccount(ts.URL, client, &adminToken, &employerToken))
+
+	t.Run("Reset", func(t *testing.T) {
+		enrollTOTP(t, ts.URL, client, employerToken)
+		statusCode, loginResponse, err := mfaLogin(ts.URL, client, "/employers/login", validEmployerAccount.Email, validEmployerAccount.Password)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if statusCode != http.StatusOK || !loginResponse.Result.MFARequired {
+			t.Fatalf("expected an MFA challenge, got %d %+v", statusCode, loginResponse.Result)
+		}
+		mfaToken := loginResponse.Result.MFAToken
+
+		var response MFAResponse
+		statusCode, err = apiRequest("DELETE", ts.URL, client, employerToken, "/employers/1/accounts/1/mfa", nil, &response)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if statusCode != http.StatusForbidden {
+			t.Fatalf("expected employers not to reset second factors, got %d", statusCode)
+		}
+		statusCode, err = apiRequest("DELETE", ts.URL, client, adminToken, "/employers/1/accounts/1/mfa", nil, &response)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if statusCode != http.StatusAccepted {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusAccepted, statusCode, response.Error)
+		}
+		statusCode, err = apiRequest("DELETE", ts.URL, client, adminToken, "/employers/1/accounts/1/mfa", nil, &response)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if statusCode != http.StatusNotFound {
+			t.Fatalf("expected status %d, got %d", http.StatusNotFound, statusCode)
+		}
+
+		var verifyResponse MFALoginResponse
+		statusCode, err = apiRequest("POST", ts.URL, client, "", "/auth/mfa/verify", &VerifyMFAParams{MFAToken: mfaToken, Code: "000000"}, &verifyResponse)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if statusCode != http.StatusUnauthorized || !strings.Contains(verifyResponse.Error, "log in again") {
+			t.Fatalf("expected the challenge of the reset factor to be refused, got %d %q", statusCode, verifyResponse.Error)
+		}
+		statusCode, loginResponse, err = mfaLogin(ts.URL, client, "/employers/login", validEmployerAccount.Email, validEmployerAccount.Password)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if statusCode != http.StatusOK || loginResponse.Result.Token == "" {
+			t.Fatalf("expected the login to succeed with the password alone, got %d %+v", statusCode, loginResponse.Result)
+		}
+	})
+
+	t.Run("Reset is audited", func(t *testing.T) {
+		statusCode, response, err := listAuditEvents(ts.URL, client, adminToken, "?action=employer_account.reset_mfa")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if statusCode != http.StatusOK || len(response.Result) != 1 {
+			t.Fatalf("expected 1 reset in the audit log, got %d %+v", statusCode, response.Result)
+		}
+		if !strings.Contains(string(response.Result[0].Before), validEmployerAccount.Email) {
+			t.Fatalf("unexpected audit event %+v", response.Result[0])
+		}
+	})
+}
+
+func TestMFARequiredRole(t *testing.T) {
+	dbQueries, err := setupDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, err := jwtkeys.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &server.HandlerConfig{
+		DBQueries:        dbQueries,
+		JWTKeys:          jwtkeys.NewKeyring(keys),
+		MFAIssuer:        "LesVieux Test",
+		MFARequiredRoles: []int64{server.AdminRole},
+	}
+	ts := httptest.NewTLSServer(server.NewLesVieuxRouter(config))
+	defer ts.Close()
+	client := ts.Client()
+	statusCode, _, err := createAdminAccount(ts.URL, client, "", &adminUser)
+	if err != nil || statusCode != http.StatusCreated {
+		t.Fatalf("couldn't create admin account: %d %v", statusCode, err)
+	}
+
+	statusCode, loginResponse, err := mfaLogin(ts.URL, client, "/admin/login", adminUser.Email, adminUser.Password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if statusCode != http.StatusOK || !loginResponse.Result.MFAEnrollmentRequired || loginResponse.Result.Token != "" {
+		t.Fatalf("expected the login to require an enrollment, got %d %+v", statusCode, loginResponse.Result)
+	}
+	enrollToken := loginResponse.Result.MFAToken
+
+	var response MFAResponse
+	statusCode, err = apiRequest("GET", ts.URL, client, enrollToken, "/admin/accounts", nil, &response)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if statusCode != http.StatusUnauthorized {
+		t.Fatalf("expected the enrollment challenge not to be usable as an access token, got %d", statusCode)
+	}
+	var verifyResponse MFALoginResponse
+	statusCode, err = apiRequest("POST", ts.URL, client, "", "/auth/mfa/verify", &VerifyMFAParams{MFAToken: enrollToken, Code: "000000"}, &verifyResponse)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if statusCode != http.StatusUnauthorized {
+		t.Fatalf("expected the enrollment challenge not to be usable to verify a code, got %d", statusCode)
+	}
+
+	_, _, confirmResponse := enrollTOTP(t, ts.URL, client, enrollToken)
+	accessToken := confirmResponse.Result.Token
+	if accessToken == "" || confirmResponse.Result.RefreshToken == "" || len(confirmResponse.Result.RecoveryCodes) != 10 {
+		t.Fatalf("expected the enrollment to start a session, got %+v", confirmResponse.Result)
+	}
+
+	var statusResponse MFAStatusResponse
+	statusCode, err = apiRequest("GET", ts.URL, client, accessToken, "/auth/mfa", nil, &statusResponse)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if statusCode != http.StatusOK || !statusResponse.Result.Enabled || !statusResponse.Result.Required {
+		t.Fatalf("expected a required and enabled second factor, got %d %+v", statusCode, statusResponse.Result)
+	}
+	statusCode, err = apiRequest("DELETE", ts.URL, client, accessToken, "/auth/mfa/totp", &MFACodeParams{RecoveryCode: confirmResponse.Result.RecoveryCodes[0]}, &response)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if statusCode != http.StatusForbidden {
+		t.Fatalf("expected the required second factor not to be disabled, got %d", statusCode)
+	}
+}
@@ -0,0 +1,219 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gruyaume/lesvieux/internal/db"
+)
+
+type ModerateJobPostParams struct {
+	Reason string `json:"reason"`
+}
+
+type ModerateJobPostResponse struct {
+	ID     int64  `json:"id"`
+	Status string `json:"status"`
+}
+
+type BulkApproveJobPostsParams struct {
+	IDs []int64 `json:"ids"`
+}
+
+type BulkApproveJobPostsFailure struct {
+	ID    int64  `json:"id"`
+	Error string `json:"error"`
+}
+
+type BulkApproveJobPostsResponse struct {
+	Approved []int64                      `json:"approved"`
+	Failed   []BulkApproveJobPostsFailure `json:"failed"`
+}
+
+// ListPendingJobPosts returns a page of the job posts awaiting review, oldest first by default.
+// Other statuses can be listed with the status filter, and posts can also be filtered by
+// employer_id, created_after and created_before.
+func ListPendingJobPosts(env *HandlerConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		page, err := parsePageRequest(r, "created_at", "-created_at")
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "%s", err)
+			return
+		}
+		filters, err := parseJobPostFilters(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "%s", err)
+			return
+		}
+		if !filters.Status.Valid {
+			filters.Status = sql.NullString{String: JobPostStatusPendingReview, Valid: true}
+		}
+		jobPosts, nextCursor, err := listJobPostsPage(env.DBQueries, page, filters)
+		if err != nil {
+			logError(r, "internal error", err)
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		jobPostsResponse := make([]GetJobPostResponse, 0, len(jobPosts))
+		for _, jobPost := range jobPosts {
+			jobPostsResponse = append(jobPostsResponse, newGetJobPostResponse(jobPost))
+		}
+		err = writeJSONPage(w, jobPostsResponse, nextCursor)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+	}
+}
+
+// ListJobPostHistory receives an id as a path parameter, and returns every status change
+// of the corresponding Job Post along with the account that made it
+func ListJobPostHistory(env *HandlerConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		jobPost, ok := getJobPostFromPath(env, w, r)
+		if !ok {
+			return
+		}
+		changes, err := env.DBQueries.ListJobPostStatusChanges(context.Background(), jobPost.ID)
+		if err != nil {
+			logError(r, "internal error", err)
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		changesResponse := make([]JobPostStatusChangeResponse, 0, len(changes))
+		for _, change := range changes {
+			changesResponse = append(changesResponse, newJobPostStatusChangeResponse(change))
+		}
+		err = writeJSON(w, changesResponse)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+	}
+}
+
+// ApproveJobPost publishes a Job Post that is awaiting review
+func ApproveJobPost(env *HandlerConfig) http.HandlerFunc {
+	return moderateJobPost(env, JobPostStatusPendingReview, JobPostStatusPublished, false)
+}
+
+// RejectJobPost sends a Job Post that is awaiting review back to its employer with a reason
+func RejectJobPost(env *HandlerConfig) http.HandlerFunc {
+	return moderateJobPost(env, JobPostStatusPendingReview, JobPostStatusRejected, true)
+}
+
+// TakedownJobPost removes a published Job Post from the public board with a reason
+func TakedownJobPost(env *HandlerConfig) http.HandlerFunc {
+	return moderateJobPost(env, JobPostStatusPublished, JobPostStatusRejected, true)
+}
+
+// moderateJobPost returns a handler moving the Job Post in the path from one status to another on behalf of the logged in admin.
+func moderateJobPost(env *HandlerConfig, from string, to string, reasonRequired bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := r.Context().Value(userIDKey).(int64)
+		var params ModerateJobPostParams
+		if reasonRequired {
+			if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+				writeError(w, http.StatusBadRequest, "Invalid JSON format")
+				return
+			}
+			if params.Reason == "" {
+				writeError(w, http.StatusBadRequest, "Reason is required")
+				return
+			}
+		}
+		jobPost, ok := getJobPostFromPath(env, w, r)
+		if !ok {
+			return
+		}
+		if jobPost.Status != from {
+			writeError(w, http.StatusConflict, "Job Post is %s, expected %s", jobPost.Status, from)
+			return
+		}
+		actor := &jobPostActor{ID: userID, Role: AdminRole}
+		if err := validateJobPostStatusTransition(jobPost.Status, to, actor); err != nil {
+			writeError(w, http.StatusConflict, "Invalid status change: %s", err)
+			return
+		}
+		err := env.DBQueries.ExecTx(context.Background(), func(q *db.Queries) error {
+			if err := transitionJobPostStatus(context.Background(), q, jobPost, to, actor, params.Reason); err != nil {
+				return err
+			}
+			return recordAuditEvent(context.Background(), q, r, jobPostStatusAuditEvent(jobPost, to, params.Reason))
+		})
+		if err != nil {
+			if errors.Is(err, errJobPostStatusConflict) {
+				writeError(w, http.StatusConflict, "Job Post status was changed by someone else. Try again.")
+				return
+			}
+			logError(r, "internal error", err)
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		err = writeJSON(w, ModerateJobPostResponse{ID: jobPost.ID, Status: to})
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+	}
+}
+
+// BulkApproveJobPosts publishes every Job Post in the given list that is awaiting review.
+// Each post is approved on its own, and the ones that couldn't be approved are reported with the reason.
+func BulkApproveJobPosts(env *HandlerConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := r.Context().Value(userIDKey).(int64)
+		var params BulkApproveJobPostsParams
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid JSON format")
+			return
+		}
+		if len(params.IDs) == 0 {
+			writeError(w, http.StatusBadRequest, "ids is required")
+			return
+		}
+		actor := &jobPostActor{ID: userID, Role: AdminRole}
+		response := BulkApproveJobPostsResponse{
+			Approved: make([]int64, 0, len(params.IDs)),
+			Failed:   make([]BulkApproveJobPostsFailure, 0),
+		}
+		for _, id := range params.IDs {
+			err := env.DBQueries.ExecTx(context.Background(), func(q *db.Queries) error {
+				jobPost, err := q.GetJobPost(context.Background(), id)
+				if err != nil {
+					return err
+				}
+				if err := validateJobPostStatusTransition(jobPost.Status, JobPostStatusPublished, actor); err != nil {
+					return err
+				}
+				if err := transitionJobPostStatus(context.Background(), q, jobPost, JobPostStatusPublished, actor, ""); err != nil {
+					return err
+				}
+				return recordAuditEvent(context.Background(), q, r, jobPostStatusAuditEvent(jobPost, JobPostStatusPublished, ""))
+			})
+			var transitionErr *jobPostTransitionError
+			switch {
+			case err == nil:
+				response.Approved = append(response.Approved, id)
+			case errors.Is(err, sql.ErrNoRows):
+				response.Failed = append(response.Failed, BulkApproveJobPostsFailure{ID: id, Error: "Job Post not found"})
+			case errors.Is(err, errJobPostStatusConflict), errors.As(err, &transitionErr):
+				response.Failed = append(response.Failed, BulkApproveJobPostsFailure{ID: id, Error: err.Error()})
+			default:
+				logError(r, "couldn't approve job post", fmt.Errorf("job post %d: %w", id, err))
+				response.Failed = append(response.Failed, BulkApproveJobPostsFailure{ID: id, Error: "internal error"})
+			}
+		}
+		w.WriteHeader(http.StatusOK)
+		err := writeJSON(w, response)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+	}
+}
@@ -0,0 +1,333 @@
+package server_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type ModerateJobPostParams struct {
+	Reason string `json:"reason"`
+}
+
+type ModerateJobPostResponseResult struct {
+	ID     int64  `json:"id"`
+	Status string `json:"status"`
+}
+
+type ModerateJobPostResponse struct {
+	Error  string                        `json:"error,omitempty"`
+	Result ModerateJobPostResponseResult `json:"result"`
+}
+
+type ListPendingJobPostsResponse struct {
+	Error  string                     `json:"error,omitempty"`
+	Result []GetJobPostResponseResult `json:"result"`
+}
+
+type BulkApproveJobPostsParams struct {
+	IDs []int64 `json:"ids"`
+}
+
+type BulkApproveJobPostsFailure struct {
+	ID    int64  `json:"id"`
+	Error string `json:"error"`
+}
+
+type BulkApproveJobPostsResponseResult struct {
+	Approved []int64                      `json:"approved"`
+	Failed   []BulkApproveJobPostsFailure `json:"failed"`
+}
+
+type BulkApproveJobPostsResponse struct {
+	Error  string                            `json:"error,omitempty"`
+	Result BulkApproveJobPostsResponseResult `json:"result"`
+}
+
+func listPendingJobPosts(url string, client *http.Client, token string) (int, *ListPendingJobPostsResponse, error) {
+	req, err := http.NewRequest("GET", url+"/api/v1/moderation/posts", nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer res.Body.Close()
+	var listResponse ListPendingJobPostsResponse
+	if err := json.NewDecoder(res.Body).Decode(&listResponse); err != nil {
+		return 0, nil, err
+	}
+	return res.StatusCode, &listResponse, nil
+}
+
+// moderateJobPost calls one of the approve, reject or takedown moderation endpoints
+func moderateJobPost(url string, client *http.Client, token string, id string, action string, data *ModerateJobPostParams) (int, *ModerateJobPostResponse, error) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return 0, nil, err
+	}
+	req, err := http.NewRequest("POST", url+"/api/v1/moderation/posts/"+id+"/"+action, strings.NewReader(string(body)))
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer res.Body.Close()
+	var moderateResponse ModerateJobPostResponse
+	if err := json.NewDecoder(res.Body).Decode(&moderateResponse); err != nil {
+		return 0, nil, err
+	}
+	return res.StatusCode, &moderateResponse, nil
+}
+
+func bulkApproveJobPosts(url string, client *http.Client, token string, data *BulkApproveJobPostsParams) (int, *BulkApproveJobPostsResponse, error) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return 0, nil, err
+	}
+	req, err := http.NewRequest("POST", url+"/api/v1/moderation/posts/approve", strings.NewReader(string(body)))
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer res.Body.Close()
+	var bulkResponse BulkApproveJobPostsResponse
+	if err := json.NewDecoder(res.Body).Decode(&bulkResponse); err != nil {
+		return 0, nil, err
+	}
+	return res.StatusCode, &bulkResponse, nil
+}
+
+func listJobPostHistory(url string, client *http.Client, token string, id string) (int, *ListJobPostHistoryResponse, error) {
+	req, err := http.NewRequest("GET", url+"/api/v1/moderation/posts/"+id+"/history", nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer res.Body.Close()
+	var historyResponse ListJobPostHistoryResponse
+	if err := json.NewDecoder(res.Body).Decode(&historyResponse); err != nil {
+		return 0, nil, err
+	}
+	return res.StatusCode, &historyResponse, nil
+}
+
+// prepareSubmittedJobPosts creates job posts with the given employer account and submits them for review
+func prepareSubmittedJobPosts(url string, client *http.Client, employerToken *string, ids []string) func(*testing.T) {
+	return func(t *testing.T) {
+		for i := range ids {
+			statusCode, resp, err := createMyJobPost(url, client, *employerToken, &validJobPost)
+			if err != nil || statusCode != http.StatusCreated {
+				t.Fatalf("couldn't create job post: %v (status %d)", err, statusCode)
+			}
+			ids[i] = fmt.Sprintf("%d", resp.Result.ID)
+			data := &UpdateJobPostParams{
+				Title:   fmt.Sprintf("Job %d", i),
+				Content: "Some content",
+				Status:  "pending_review",
+			}
+			statusCode, _, err = updateMyJobPost(url, client, *employerToken, ids[i], data)
+			if err != nil || statusCode != http.StatusOK {
+				t.Fatalf("couldn't submit job post: %v (status %d)", err, statusCode)
+			}
+		}
+	}
+}
+
+func TestModerationEndToEnd(t *testing.T) {
+	ts, _, err := setupServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ts.Close()
+	client := ts.Client()
+	var adminToken string
+	var employerToken string
+	ids := make([]string, 3)
+	t.Run("prepare admin account and token", prepareAdminAccount(ts.URL, client, &adminToken))
+	t.Run("prepare employer account and token", prepareEmployerAccount(ts.URL, client, &adminToken, &employerToken))
+	t.Run("prepare submitted job posts", prepareSubmittedJobPosts(ts.URL, client, &employerToken, ids))
+
+	t.Run("List pending job posts - employer forbidden", func(t *testing.T) {
+		statusCode, _, err := listPendingJobPosts(ts.URL, client, employerToken)
+		if err != nil {
+			t.Fatalf("couldn't list pending job posts: %s", err)
+		}
+		if statusCode != http.StatusForbidden {
+			t.Fatalf("expected status %d, got %d", http.StatusForbidden, statusCode)
+		}
+	})
+
+	t.Run("List pending job posts - oldest first", func(t *testing.T) {
+		statusCode, resp, err := listPendingJobPosts(ts.URL, client, adminToken)
+		if err != nil {
+			t.Fatalf("couldn't list pending job posts: %s", err)
+		}
+		if statusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, statusCode)
+		}
+		if len(resp.Result) != 3 || resp.Result[0].Title != "Job 0" {
+			t.Fatalf("expected the 3 submitted job posts oldest first, got %v", resp.Result)
+		}
+	})
+
+	testCases := []struct {
+		desc   string
+		id     string
+		action string
+		data   ModerateJobPostParams
+		status int
+		error  string
+	}{
+		{
+			desc:   "Reject - reason required",
+			id:     ids[0],
+			action: "reject",
+			status: http.StatusBadRequest,
+			error:  "Reason is required",
+		},
+		{
+			desc:   "Reject - success",
+			id:     ids[0],
+			action: "reject",
+			data:   ModerateJobPostParams{Reason: "Salary is missing"},
+			status: http.StatusOK,
+		},
+		{
+			desc:   "Approve - rejected post can't be approved",
+			id:     ids[0],
+			action: "approve",
+			status: http.StatusConflict,
+			error:  "Job Post is rejected, expected pending_review",
+		},
+		{
+			desc:   "Approve - success",
+			id:     ids[1],
+			action: "approve",
+			status: http.StatusOK,
+		},
+		{
+			desc:   "Approve - not found",
+			id:     "999",
+			action: "approve",
+			status: http.StatusNotFound,
+			error:  "Job Post not found",
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			statusCode, resp, err := moderateJobPost(ts.URL, client, adminToken, tC.id, tC.action, &tC.data)
+			if err != nil {
+				t.Fatalf("couldn't moderate job post: %s", err)
+			}
+			if statusCode != tC.status {
+				t.Fatalf("expected status %d, got %d", tC.status, statusCode)
+			}
+			if resp.Error != tC.error {
+				t.Fatalf("expected error %q, got %q", tC.error, resp.Error)
+			}
+		})
+	}
+
+	t.Run("Rejection reason is shown to the employer", func(t *testing.T) {
+		statusCode, resp, err := getMyJobPost(ts.URL, client, employerToken, ids[0])
+		if err != nil {
+			t.Fatalf("couldn't get job post: %s", err)
+		}
+		if statusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, statusCode)
+		}
+		if resp.Result.Status != "rejected" || resp.Result.RejectionReason != "Salary is missing" {
+			t.Fatalf("expected rejected post with reason, got %+v", resp.Result)
+		}
+	})
+
+	t.Run("Bulk approve", func(t *testing.T) {
+		data := &BulkApproveJobPostsParams{IDs: []int64{3, 2, 999}}
+		statusCode, resp, err := bulkApproveJobPosts(ts.URL, client, adminToken, data)
+		if err != nil {
+			t.Fatalf("couldn't bulk approve job posts: %s", err)
+		}
+		if statusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, statusCode)
+		}
+		if len(resp.Result.Approved) != 1 || resp.Result.Approved[0] != 3 {
+			t.Fatalf("expected job post 3 to be approved, got %v", resp.Result.Approved)
+		}
+		expectedFailures := []BulkApproveJobPostsFailure{
+			{ID: 2, Error: `status can't change from "published" to "published"`},
+			{ID: 999, Error: "Job Post not found"},
+		}
+		if len(resp.Result.Failed) != len(expectedFailures) {
+			t.Fatalf("expected failures %v, got %v", expectedFailures, resp.Result.Failed)
+		}
+		for i := range expectedFailures {
+			if resp.Result.Failed[i] != expectedFailures[i] {
+				t.Fatalf("expected failures %v, got %v", expectedFailures, resp.Result.Failed)
+			}
+		}
+	})
+
+	t.Run("Takedown - success", func(t *testing.T) {
+		data := &ModerateJobPostParams{Reason: "Reported as a scam"}
+		statusCode, resp, err := moderateJobPost(ts.URL, client, adminToken, ids[1], "takedown", data)
+		if err != nil {
+			t.Fatalf("couldn't take down job post: %s", err)
+		}
+		if statusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusOK, statusCode, resp.Error)
+		}
+		if resp.Result.Status != "rejected" {
+			t.Fatalf("expected status rejected, got %q", resp.Result.Status)
+		}
+	})
+
+	t.Run("Only approved posts are public", func(t *testing.T) {
+		statusCode, resp, err := listJobPosts(ts.URL, client, "")
+		if err != nil {
+			t.Fatalf("couldn't list job posts: %s", err)
+		}
+		if statusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, statusCode)
+		}
+		if len(resp.Result) != 1 || resp.Result[0].ID != 3 {
+			t.Fatalf("expected public job post 3, got %v", resp.Result)
+		}
+	})
+
+	t.Run("History tells who approved and took down a post", func(t *testing.T) {
+		statusCode, resp, err := listJobPostHistory(ts.URL, client, adminToken, ids[1])
+		if err != nil {
+			t.Fatalf("couldn't list job post history: %s", err)
+		}
+		if statusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, statusCode)
+		}
+		if len(resp.Result) != 4 {
+			t.Fatalf("expected 4 status changes, got %v", resp.Result)
+		}
+		approval := resp.Result[2]
+		if approval.ToStatus != "published" || approval.ActorRole != 1 || approval.ActorID != 1 || approval.ChangedAt == "" {
+			t.Fatalf("expected approval by admin account 1, got %+v", approval)
+		}
+		takedown := resp.Result[3]
+		if takedown.ToStatus != "rejected" || takedown.Reason != "Reported as a scam" {
+			t.Fatalf("expected takedown with reason, got %+v", takedown)
+		}
+	})
+}
@@ -0,0 +1,350 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gruyaume/lesvieux/internal/db"
+)
+
+type CreateJobPostParams struct {
+	Title   string `json:"title"`
+	Content string `json:"content"`
+	Status  string `json:"status"`
+	JobPostDetails
+}
+
+// ListMyJobPosts returns a page of the ids of the job posts owned by the employer of the logged in account,
+// newest first by default. They can be filtered by status, created_after and created_before.
+func ListMyJobPosts(env *HandlerConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		employerID := r.Context().Value(employerIDKey).(int64)
+		page, err := parsePageRequest(r, "-created_at", "created_at")
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "%s", err)
+			return
+		}
+		filters, err := parseJobPostFilters(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "%s", err)
+			return
+		}
+		filters.EmployerID = sql.NullInt64{Int64: employerID, Valid: true}
+		jobPosts, nextCursor, err := listJobPostsPage(env.DBQueries, page, filters)
+		if err != nil {
+			logError(r, "internal error", err)
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		ids := make([]int64, 0, len(jobPosts))
+		for _, post := range jobPosts {
+			ids = append(ids, post.ID)
+		}
+
+		err = writeJSONPage(w, ids, nextCursor)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+	}
+}
+
+// GetMyJobPost receives an id as a path parameter, and
+// returns the corresponding Job Post if it is owned by the employer of the logged in account
+func GetMyJobPost(env *HandlerConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		jobPost, ok := getMyJobPostFromPath(env, w, r)
+		if !ok {
+			return
+		}
+
+		jobPostResponse := newGetJobPostResponse(jobPost)
+		if jobPost.Status == JobPostStatusRejected {
+			change, err := env.DBQueries.GetLatestJobPostStatusChange(context.Background(), jobPost.ID)
+			if err != nil {
+				logError(r, "internal error", err)
+				writeError(w, http.StatusInternalServerError, "internal error")
+				return
+			}
+			jobPostResponse.RejectionReason = change.Reason.String
+		}
+
+		w.WriteHeader(http.StatusOK)
+		err := writeJSON(w, jobPostResponse)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+	}
+}
+
+// CreateMyJobPost creates a new draft Job Post for the employer of the logged in account,
+// and returns the id of the created row
+func CreateMyJobPost(env *HandlerConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := r.Context().Value(userIDKey).(int64)
+		employerID := r.Context().Value(employerIDKey).(int64)
+		var jobPost CreateJobPostParams
+		if err := json.NewDecoder(r.Body).Decode(&jobPost); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid JSON format")
+			return
+		}
+		if jobPost.Status == "" {
+			jobPost.Status = JobPostStatusDraft
+		}
+		if !isValidJobPostStatus(jobPost.Status) {
+			writeError(w, http.StatusBadRequest, "Invalid status")
+			return
+		}
+		if jobPost.Status != JobPostStatusDraft {
+			writeError(w, http.StatusBadRequest, "Job Posts must be created as draft")
+			return
+		}
+		if jobPost.Title == "" {
+			writeError(w, http.StatusBadRequest, "Title is required")
+			return
+		}
+		if jobPost.Content == "" {
+			writeError(w, http.StatusBadRequest, "Content is required")
+			return
+		}
+		if err := jobPost.JobPostDetails.validate(); err != nil {
+			writeError(w, http.StatusBadRequest, "%s", err)
+			return
+		}
+
+		var newJobPost db.JobPost
+		err := env.DBQueries.ExecTx(context.Background(), func(q *db.Queries) error {
+			var err error
+			newJobPost, err = q.CreateJobPost(context.Background(), db.CreateJobPostParams{
+				Title:               jobPost.Title,
+				Content:             jobPost.Content,
+				CreatedAt:           time.Now().UTC().Format(time.RFC3339),
+				Status:              jobPost.Status,
+				EmployerID:          employerID,
+				EmploymentType:      nullString(jobPost.EmploymentType),
+				WeeklyHours:         nullInt64(jobPost.WeeklyHours),
+				WorkMode:            nullString(jobPost.WorkMode),
+				City:                nullString(jobPost.City),
+				PostalCode:          nullString(jobPost.PostalCode),
+				SalaryMin:           nullInt64(jobPost.SalaryMin),
+				SalaryMax:           nullInt64(jobPost.SalaryMax),
+				SalaryCurrency:      nullString(jobPost.SalaryCurrency),
+				SalaryPeriod:        nullString(jobPost.SalaryPeriod),
+				PhysicalDemands:     nullString(jobPost.PhysicalDemands),
+				FlexibleSchedule:    jobPost.FlexibleSchedule,
+				AccessibleWorkplace: jobPost.AccessibleWorkplace,
+			})
+			if err != nil {
+				return err
+			}
+			actor := &jobPostActor{ID: userID, Role: EmployerRole}
+			err = recordJobPostStatusChange(context.Background(), q, newJobPost.ID, "", newJobPost.Status, newJobPost.CreatedAt, actor, "")
+			if err != nil {
+				return err
+			}
+			return recordAuditEvent(context.Background(), q, r, auditEvent{
+				Action:     AuditActionJobPostCreate,
+				TargetType: AuditTargetJobPost,
+				TargetID:   newJobPost.ID,
+				After:      jobPostAuditSummary(newJobPost),
+			})
+		})
+		if err != nil {
+			logError(r, "couldn't create job post", err)
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		response := CreateJobPostResponse{ID: newJobPost.ID}
+		err = writeJSON(w, response)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+	}
+}
+
+// UpdateMyJobPost receives an id as a path parameter, and updates the corresponding Job Post
+// if it is owned by the employer of the logged in account.
+// The title, content, expiry date and details can only be changed while the post is a draft or was rejected.
+// A different status moves the post through its publication workflow.
+func UpdateMyJobPost(env *HandlerConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := r.Context().Value(userIDKey).(int64)
+		jobPost, ok := getMyJobPostFromPath(env, w, r)
+		if !ok {
+			return
+		}
+		var updateJobPost UpdateJobPostParams
+		if err := json.NewDecoder(r.Body).Decode(&updateJobPost); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid JSON format")
+			return
+		}
+		if updateJobPost.Title == "" {
+			writeError(w, http.StatusBadRequest, "Title is required")
+			return
+		}
+		if updateJobPost.Content == "" {
+			writeError(w, http.StatusBadRequest, "Content is required")
+			return
+		}
+		if updateJobPost.Status == "" {
+			updateJobPost.Status = jobPost.Status
+		}
+		if !isValidJobPostStatus(updateJobPost.Status) {
+			writeError(w, http.StatusBadRequest, "Invalid status")
+			return
+		}
+		var expiresAt sql.NullString
+		if updateJobPost.ExpiresAt != "" {
+			expiry, err := time.Parse(time.RFC3339, updateJobPost.ExpiresAt)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "expires_at must be an RFC 3339 timestamp")
+				return
+			}
+			expiresAt = sql.NullString{String: expiry.UTC().Format(time.RFC3339), Valid: true}
+		}
+		if err := updateJobPost.JobPostDetails.validate(); err != nil {
+			writeError(w, http.StatusBadRequest, "%s", err)
+			return
+		}
+
+		params := newUpdateJobPostParams(jobPost.ID, updateJobPost.Title, updateJobPost.Content, expiresAt, updateJobPost.JobPostDetails)
+		edited := params != newUpdateJobPostParams(jobPost.ID, jobPost.Title, jobPost.Content, jobPost.ExpiresAt, newJobPostDetails(jobPost))
+		if edited && !isEditableJobPostStatus(jobPost.Status) {
+			writeError(w, http.StatusConflict, "Job Post can't be edited while %s", jobPost.Status)
+			return
+		}
+		actor := &jobPostActor{ID: userID, Role: EmployerRole}
+		statusChanged := updateJobPost.Status != jobPost.Status
+		if statusChanged {
+			if err := validateJobPostStatusTransition(jobPost.Status, updateJobPost.Status, actor); err != nil {
+				writeError(w, http.StatusConflict, "Invalid status change: %s", err)
+				return
+			}
+		}
+
+		err := env.DBQueries.ExecTx(context.Background(), func(q *db.Queries) error {
+			if edited {
+				err := q.UpdateJobPost(context.Background(), params)
+				if err != nil {
+					return err
+				}
+				err = recordAuditEvent(context.Background(), q, r, auditEvent{
+					Action:     AuditActionJobPostUpdate,
+					TargetType: AuditTargetJobPost,
+					TargetID:   jobPost.ID,
+					Before:     map[string]any{"title": jobPost.Title},
+					After:      map[string]any{"title": updateJobPost.Title},
+				})
+				if err != nil {
+					return err
+				}
+			}
+			if statusChanged {
+				if err := transitionJobPostStatus(context.Background(), q, jobPost, updateJobPost.Status, actor, ""); err != nil {
+					return err
+				}
+				return recordAuditEvent(context.Background(), q, r, jobPostStatusAuditEvent(jobPost, updateJobPost.Status, ""))
+			}
+			return nil
+		})
+		if err != nil {
+			if errors.Is(err, errJobPostStatusConflict) {
+				writeError(w, http.StatusConflict, "Job Post status was changed by someone else. Try again.")
+				return
+			}
+			logError(r, "internal error", err)
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		response := UpdateJobPostResponse{ID: jobPost.ID}
+		err = writeJSON(w, response)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+	}
+}
+
+// ListMyJobPostHistory receives an id as a path parameter, and returns the status changes
+// of the corresponding Job Post if it is owned by the employer of the logged in account
+func ListMyJobPostHistory(env *HandlerConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		jobPost, ok := getMyJobPostFromPath(env, w, r)
+		if !ok {
+			return
+		}
+		changes, err := env.DBQueries.ListJobPostStatusChanges(context.Background(), jobPost.ID)
+		if err != nil {
+			logError(r, "internal error", err)
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		changesResponse := make([]JobPostStatusChangeResponse, 0, len(changes))
+		for _, change := range changes {
+			changesResponse = append(changesResponse, newJobPostStatusChangeResponse(change))
+		}
+		err = writeJSON(w, changesResponse)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+	}
+}
+
+// DeleteMyJobPost handler receives an id as a path parameter,
+// deletes the corresponding Job Post if it is owned by the employer of the logged in account
+func DeleteMyJobPost(env *HandlerConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		jobPost, ok := getMyJobPostFromPath(env, w, r)
+		if !ok {
+			return
+		}
+		err := env.DBQueries.ExecTx(context.Background(), func(q *db.Queries) error {
+			if err := q.DeleteJobPost(context.Background(), jobPost.ID); err != nil {
+				return err
+			}
+			return recordAuditEvent(context.Background(), q, r, auditEvent{
+				Action:     AuditActionJobPostDelete,
+				TargetType: AuditTargetJobPost,
+				TargetID:   jobPost.ID,
+				Before:     jobPostAuditSummary(jobPost),
+			})
+		})
+		if err != nil {
+			logError(r, "internal error", err)
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		response := map[string]any{"id": jobPost.ID}
+		err = writeJSON(w, response)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+	}
+}
+
+// getMyJobPostFromPath reads the post_id path parameter and returns the matching Job Post.
+// Posts owned by other employers are reported as not found so their existence isn't leaked.
+// When ok is false, an error response has already been written.
+func getMyJobPostFromPath(env *HandlerConfig, w http.ResponseWriter, r *http.Request) (jobPost db.JobPost, ok bool) {
+	employerID := r.Context().Value(employerIDKey).(int64)
+	jobPost, ok = getJobPostFromPath(env, w, r)
+	if !ok {
+		return db.JobPost{}, false
+	}
+	if jobPost.EmployerID != employerID {
+		writeError(w, http.StatusNotFound, "Job Post not found")
+		return db.JobPost{}, false
+	}
+	return jobPost, true
+}
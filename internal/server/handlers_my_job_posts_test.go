@@ -0,0 +1,556 @@
+package server_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gruyaume/lesvieux/internal/db"
+)
+
+type CreateJobPostParams struct {
+	Title   string `json:"title"`
+	Content string `json:"content"`
+	Status  string `json:"status"`
+}
+
+type CreateJobPostResponseResult struct {
+	ID int64 `json:"id"`
+}
+
+type CreateJobPostResponse struct {
+	Error  string                      `json:"error,omitempty"`
+	Result CreateJobPostResponseResult `json:"result"`
+}
+
+type ListMyJobPostsResponse struct {
+	Error  string  `json:"error,omitempty"`
+	Result []int64 `json:"result"`
+}
+
+type JobPostStatusChangeResponseResult struct {
+	FromStatus string `json:"from_status"`
+	ToStatus   string `json:"to_status"`
+	ChangedAt  string `json:"changed_at"`
+	ActorID    int64  `json:"actor_id"`
+	ActorRole  int64  `json:"actor_role"`
+	Reason     string `json:"reason"`
+}
+
+type ListJobPostHistoryResponse struct {
+	Error  string                              `json:"error,omitempty"`
+	Result []JobPostStatusChangeResponseResult `json:"result"`
+}
+
+func createMyJobPost(url string, client *http.Client, token string, data *CreateJobPostParams) (int, *CreateJobPostResponse, error) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return 0, nil, err
+	}
+	req, err := http.NewRequest("POST", url+"/api/v1/me/posts", strings.NewReader(string(body)))
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer res.Body.Close()
+	var createResponse CreateJobPostResponse
+	if err := json.NewDecoder(res.Body).Decode(&createResponse); err != nil {
+		return 0, nil, err
+	}
+	return res.StatusCode, &createResponse, nil
+}
+
+func listMyJobPosts(url string, client *http.Client, token string) (int, *ListMyJobPostsResponse, error) {
+	req, err := http.NewRequest("GET", url+"/api/v1/me/posts", nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer res.Body.Close()
+	var listResponse ListMyJobPostsResponse
+	if err := json.NewDecoder(res.Body).Decode(&listResponse); err != nil {
+		return 0, nil, err
+	}
+	return res.StatusCode, &listResponse, nil
+}
+
+func getMyJobPost(url string, client *http.Client, token string, id string) (int, *GetJobPostResponse, error) {
+	req, err := http.NewRequest("GET", url+"/api/v1/me/posts/"+id, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer res.Body.Close()
+	var getResponse GetJobPostResponse
+	if err := json.NewDecoder(res.Body).Decode(&getResponse); err != nil {
+		return 0, nil, err
+	}
+	return res.StatusCode, &getResponse, nil
+}
+
+func updateMyJobPost(url string, client *http.Client, token string, id string, data *UpdateJobPostParams) (int, *UpdateJobPostResponse, error) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return 0, nil, err
+	}
+	req, err := http.NewRequest("PUT", url+"/api/v1/me/posts/"+id, strings.NewReader(string(body)))
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer res.Body.Close()
+	var updateResponse UpdateJobPostResponse
+	if err := json.NewDecoder(res.Body).Decode(&updateResponse); err != nil {
+		return 0, nil, err
+	}
+	return res.StatusCode, &updateResponse, nil
+}
+
+func deleteMyJobPost(url string, client *http.Client, token string, id string) (int, *DeleteJobPostResponse, error) {
+	req, err := http.NewRequest("DELETE", url+"/api/v1/me/posts/"+id, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer res.Body.Close()
+	var deleteResponse DeleteJobPostResponse
+	if err := json.NewDecoder(res.Body).Decode(&deleteResponse); err != nil {
+		return 0, nil, err
+	}
+	return res.StatusCode, &deleteResponse, nil
+}
+
+func listMyJobPostHistory(url string, client *http.Client, token string, id string) (int, *ListJobPostHistoryResponse, error) {
+	req, err := http.NewRequest("GET", url+"/api/v1/me/posts/"+id+"/history", nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer res.Body.Close()
+	var historyResponse ListJobPostHistoryResponse
+	if err := json.NewDecoder(res.Body).Decode(&historyResponse); err != nil {
+		return 0, nil, err
+	}
+	return res.StatusCode, &historyResponse, nil
+}
+
+func TestMyJobPostsEndToEnd(t *testing.T) {
+	ts, _, err := setupServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ts.Close()
+	client := ts.Client()
+	var adminToken string
+	var employerToken string
+	t.Run("prepare admin account and token", prepareAdminAccount(ts.URL, client, &adminToken))
+	t.Run("prepare employer account and token", prepareEmployerAccount(ts.URL, client, &adminToken, &employerToken))
+
+	var postID string
+	t.Run("Create job post - success", func(t *testing.T) {
+		statusCode, resp, err := createMyJobPost(ts.URL, client, employerToken, &CreateJobPostParams{Title: validJobPost.Title, Content: validJobPost.Content, Status: "draft"})
+		if err != nil {
+			t.Fatalf("couldn't create job post: %s", err)
+		}
+		if statusCode != http.StatusCreated {
+			t.Fatalf("expected status %d, got %d", http.StatusCreated, statusCode)
+		}
+		if resp.Error != "" {
+			t.Fatalf("expected no error, got %q", resp.Error)
+		}
+		postID = fmt.Sprintf("%d", resp.Result.ID)
+	})
+
+	t.Run("Create job post - invalid status", func(t *testing.T) {
+		statusCode, resp, err := createMyJobPost(ts.URL, client, employerToken, &CreateJobPostParams{Status: "whatever"})
+		if err != nil {
+			t.Fatalf("couldn't create job post: %s", err)
+		}
+		if statusCode != http.StatusBadRequest {
+			t.Fatalf("expected status %d, got %d", http.StatusBadRequest, statusCode)
+		}
+		if resp.Error != "Invalid status" {
+			t.Fatalf("expected error %q, got %q", "Invalid status", resp.Error)
+		}
+	})
+
+	t.Run("Create job post - title and content required", func(t *testing.T) {
+		cases := []struct {
+			data          CreateJobPostParams
+			expectedError string
+		}{
+			{CreateJobPostParams{Content: validJobPost.Content}, "Title is required"},
+			{CreateJobPostParams{Title: validJobPost.Title}, "Content is required"},
+		}
+		for _, c := range cases {
+			statusCode, resp, err := createMyJobPost(ts.URL, client, employerToken, &c.data)
+			if err != nil {
+				t.Fatalf("couldn't create job post: %s", err)
+			}
+			if statusCode != http.StatusBadRequest || resp.Error != c.expectedError {
+				t.Fatalf("expected status %d and error %q, got %d %q", http.StatusBadRequest, c.expectedError, statusCode, resp.Error)
+			}
+		}
+	})
+
+	t.Run("Create job post - admin forbidden", func(t *testing.T) {
+		statusCode, _, err := createMyJobPost(ts.URL, client, adminToken, &CreateJobPostParams{Title: validJobPost.Title, Content: validJobPost.Content, Status: "draft"})
+		if err != nil {
+			t.Fatalf("couldn't create job post: %s", err)
+		}
+		if statusCode != http.StatusForbidden {
+			t.Fatalf("expected status %d, got %d", http.StatusForbidden, statusCode)
+		}
+	})
+
+	t.Run("Update job post - success", func(t *testing.T) {
+		data := &UpdateJobPostParams{Title: "Gardener", Content: "Take care of our garden", Status: "draft"}
+		statusCode, resp, err := updateMyJobPost(ts.URL, client, employerToken, postID, data)
+		if err != nil {
+			t.Fatalf("couldn't update job post: %s", err)
+		}
+		if statusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusOK, statusCode, resp.Error)
+		}
+	})
+
+	t.Run("Update job post - missing title", func(t *testing.T) {
+		data := &UpdateJobPostParams{Content: "Take care of our garden"}
+		statusCode, resp, err := updateMyJobPost(ts.URL, client, employerToken, postID, data)
+		if err != nil {
+			t.Fatalf("couldn't update job post: %s", err)
+		}
+		if statusCode != http.StatusBadRequest {
+			t.Fatalf("expected status %d, got %d", http.StatusBadRequest, statusCode)
+		}
+		if resp.Error != "Title is required" {
+			t.Fatalf("expected error %q, got %q", "Title is required", resp.Error)
+		}
+	})
+
+	detailCases := []struct {
+		desc    string
+		details JobPostDetails
+		error   string
+	}{
+		{
+			desc:    "invalid employment type",
+			details: JobPostDetails{EmploymentType: "seasonal"},
+			error:   "employment_type must be one of part_time, full_time",
+		},
+		{
+			desc:    "too many weekly hours",
+			details: JobPostDetails{WeeklyHours: int64Ptr(90)},
+			error:   "weekly_hours must be between 1 and 80",
+		},
+		{
+			desc:    "inverted salary range",
+			details: JobPostDetails{SalaryMin: int64Ptr(30), SalaryMax: int64Ptr(20), SalaryCurrency: "EUR", SalaryPeriod: "hour"},
+			error:   "salary_max can't be lower than salary_min",
+		},
+		{
+			desc:    "salary without currency",
+			details: JobPostDetails{SalaryMin: int64Ptr(20), SalaryPeriod: "hour"},
+			error:   "salary_currency and salary_period are required with a salary",
+		},
+		{
+			desc:    "invalid currency",
+			details: JobPostDetails{SalaryMin: int64Ptr(20), SalaryCurrency: "EURO", SalaryPeriod: "hour"},
+			error:   "salary_currency must be a 3 letter currency code",
+		},
+	}
+	for _, tC := range detailCases {
+		t.Run("Update job post - "+tC.desc, func(t *testing.T) {
+			data := &UpdateJobPostParams{Title: "Gardener", Content: "Take care of our garden", JobPostDetails: tC.details}
+			statusCode, resp, err := updateMyJobPost(ts.URL, client, employerToken, postID, data)
+			if err != nil {
+				t.Fatalf("couldn't update job post: %s", err)
+			}
+			if statusCode != http.StatusBadRequest {
+				t.Fatalf("expected status %d, got %d", http.StatusBadRequest, statusCode)
+			}
+			if resp.Error != tC.error {
+				t.Fatalf("expected error %q, got %q", tC.error, resp.Error)
+			}
+		})
+	}
+
+	t.Run("Get job post - success", func(t *testing.T) {
+		statusCode, resp, err := getMyJobPost(ts.URL, client, employerToken, postID)
+		if err != nil {
+			t.Fatalf("couldn't get job post: %s", err)
+		}
+		if statusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, statusCode)
+		}
+		expected := GetJobPostResponseResult{Title: "Gardener", Content: "Take care of our garden", Status: "draft"}
+		if resp.Result != expected {
+			t.Fatalf("expected result %v, got %v", expected, resp.Result)
+		}
+	})
+
+	t.Run("List job posts - success", func(t *testing.T) {
+		statusCode, resp, err := listMyJobPosts(ts.URL, client, employerToken)
+		if err != nil {
+			t.Fatalf("couldn't list job posts: %s", err)
+		}
+		if statusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, statusCode)
+		}
+		if len(resp.Result) != 1 || fmt.Sprintf("%d", resp.Result[0]) != postID {
+			t.Fatalf("expected result [%s], got %v", postID, resp.Result)
+		}
+	})
+
+	var otherEmployerToken string
+	t.Run("prepare other employer account and token", func(t *testing.T) {
+		statusCode, employerResp, err := createEmployer(ts.URL, client, adminToken, &CreateEmployerParams{Name: "other employer"})
+		if err != nil || statusCode != http.StatusCreated {
+			t.Fatalf("couldn't create employer: %v (status %d)", err, statusCode)
+		}
+		account := &CreateEmployerAccountParams{Email: "someone@other.com", Password: "Otherpass123!"}
+		statusCode, _, err = createEmployerAccount(ts.URL, client, adminToken, fmt.Sprintf("%d", employerResp.Result.Id), account)
+		if err != nil || statusCode != http.StatusCreated {
+			t.Fatalf("couldn't create employer account: %v (status %d)", err, statusCode)
+		}
+		statusCode, loginResp, err := employerLogin(ts.URL, client, &EmployerLoginParams{Email: account.Email, Password: account.Password})
+		if err != nil || statusCode != http.StatusOK {
+			t.Fatalf("couldn't login employer account: %v (status %d)", err, statusCode)
+		}
+		otherEmployerToken = loginResp.Result.Token
+	})
+
+	t.Run("Get job post - other employer not found", func(t *testing.T) {
+		statusCode, resp, err := getMyJobPost(ts.URL, client, otherEmployerToken, postID)
+		if err != nil {
+			t.Fatalf("couldn't get job post: %s", err)
+		}
+		if statusCode != http.StatusNotFound {
+			t.Fatalf("expected status %d, got %d", http.StatusNotFound, statusCode)
+		}
+		if resp.Error != "Job Post not found" {
+			t.Fatalf("expected error %q, got %q", "Job Post not found", resp.Error)
+		}
+	})
+
+	t.Run("Delete job post - other employer not found", func(t *testing.T) {
+		statusCode, _, err := deleteMyJobPost(ts.URL, client, otherEmployerToken, postID)
+		if err != nil {
+			t.Fatalf("couldn't delete job post: %s", err)
+		}
+		if statusCode != http.StatusNotFound {
+			t.Fatalf("expected status %d, got %d", http.StatusNotFound, statusCode)
+		}
+	})
+
+	t.Run("Delete job post - success", func(t *testing.T) {
+		statusCode, resp, err := deleteMyJobPost(ts.URL, client, employerToken, postID)
+		if err != nil {
+			t.Fatalf("couldn't delete job post: %s", err)
+		}
+		if statusCode != http.StatusAccepted {
+			t.Fatalf("expected status %d, got %d", http.StatusAccepted, statusCode)
+		}
+		if fmt.Sprintf("%d", resp.Result.ID) != postID {
+			t.Fatalf("expected deleted id %s, got %d", postID, resp.Result.ID)
+		}
+	})
+
+	t.Run("Get job post - deleted", func(t *testing.T) {
+		statusCode, _, err := getMyJobPost(ts.URL, client, employerToken, postID)
+		if err != nil {
+			t.Fatalf("couldn't get job post: %s", err)
+		}
+		if statusCode != http.StatusNotFound {
+			t.Fatalf("expected status %d, got %d", http.StatusNotFound, statusCode)
+		}
+	})
+}
+
+func TestMyJobPostsStatusWorkflow(t *testing.T) {
+	ts, config, err := setupServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ts.Close()
+	client := ts.Client()
+	var adminToken string
+	var employerToken string
+	t.Run("prepare admin account and token", prepareAdminAccount(ts.URL, client, &adminToken))
+	t.Run("prepare employer account and token", prepareEmployerAccount(ts.URL, client, &adminToken, &employerToken))
+
+	var postID string
+	t.Run("Create job post", func(t *testing.T) {
+		statusCode, resp, err := createMyJobPost(ts.URL, client, employerToken, &validJobPost)
+		if err != nil {
+			t.Fatalf("couldn't create job post: %s", err)
+		}
+		if statusCode != http.StatusCreated {
+			t.Fatalf("expected status %d, got %d", http.StatusCreated, statusCode)
+		}
+		postID = fmt.Sprintf("%d", resp.Result.ID)
+	})
+
+	t.Run("Create job post - can't start published", func(t *testing.T) {
+		statusCode, resp, err := createMyJobPost(ts.URL, client, employerToken, &CreateJobPostParams{Status: "published"})
+		if err != nil {
+			t.Fatalf("couldn't create job post: %s", err)
+		}
+		if statusCode != http.StatusBadRequest {
+			t.Fatalf("expected status %d, got %d", http.StatusBadRequest, statusCode)
+		}
+		if resp.Error != "Job Posts must be created as draft" {
+			t.Fatalf("expected error %q, got %q", "Job Posts must be created as draft", resp.Error)
+		}
+	})
+
+	testCases := []struct {
+		desc   string
+		data   UpdateJobPostParams
+		status int
+		error  string
+	}{
+		{
+			desc:   "Draft can't be published directly",
+			data:   UpdateJobPostParams{Title: "Gardener", Content: "Garden work", Status: "published"},
+			status: http.StatusConflict,
+			error:  `Invalid status change: status can't change from "draft" to "published"`,
+		},
+		{
+			desc:   "Draft can be submitted for review",
+			data:   UpdateJobPostParams{Title: "Gardener", Content: "Garden work", Status: "pending_review"},
+			status: http.StatusOK,
+		},
+		{
+			desc:   "Post under review can't be edited",
+			data:   UpdateJobPostParams{Title: "Gardener", Content: "Other garden work", Status: "pending_review"},
+			status: http.StatusConflict,
+			error:  "Job Post can't be edited while pending_review",
+		},
+		{
+			desc:   "Post under review can't be approved by its employer",
+			data:   UpdateJobPostParams{Title: "Gardener", Content: "Garden work", Status: "published"},
+			status: http.StatusConflict,
+			error:  `Invalid status change: status can't change from "pending_review" to "published"`,
+		},
+		{
+			desc:   "Unknown status is rejected",
+			data:   UpdateJobPostParams{Title: "Gardener", Content: "Garden work", Status: "archived"},
+			status: http.StatusBadRequest,
+			error:  "Invalid status",
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			statusCode, resp, err := updateMyJobPost(ts.URL, client, employerToken, postID, &tC.data)
+			if err != nil {
+				t.Fatalf("couldn't update job post: %s", err)
+			}
+			if statusCode != tC.status {
+				t.Fatalf("expected status %d, got %d", tC.status, statusCode)
+			}
+			if resp.Error != tC.error {
+				t.Fatalf("expected error %q, got %q", tC.error, resp.Error)
+			}
+		})
+	}
+
+	t.Run("Unpublished post isn't listed publicly", func(t *testing.T) {
+		statusCode, resp, err := listJobPosts(ts.URL, client, "")
+		if err != nil {
+			t.Fatalf("couldn't list job posts: %s", err)
+		}
+		if statusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, statusCode)
+		}
+		if len(resp.Result) != 0 {
+			t.Fatalf("expected no public job posts, got %v", resp.Result)
+		}
+	})
+
+	t.Run("Published post is listed publicly", func(t *testing.T) {
+		var id int64
+		fmt.Sscanf(postID, "%d", &id)
+		_, err := config.DBQueries.UpdateJobPostStatus(context.Background(), db.UpdateJobPostStatusParams{
+			Status:      "published",
+			PublishedAt: sql.NullString{String: time.Now().UTC().Format(time.RFC3339), Valid: true},
+			ID:          id,
+			FromStatus:  "pending_review",
+		})
+		if err != nil {
+			t.Fatalf("couldn't publish job post: %s", err)
+		}
+		statusCode, resp, err := listJobPosts(ts.URL, client, "")
+		if err != nil {
+			t.Fatalf("couldn't list job posts: %s", err)
+		}
+		if statusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, statusCode)
+		}
+		if len(resp.Result) != 1 || resp.Result[0].ID != id {
+			t.Fatalf("expected public job posts [%d], got %v", id, resp.Result)
+		}
+	})
+
+	t.Run("Published post can be closed by its employer", func(t *testing.T) {
+		data := &UpdateJobPostParams{Title: "Gardener", Content: "Garden work", Status: "closed"}
+		statusCode, resp, err := updateMyJobPost(ts.URL, client, employerToken, postID, data)
+		if err != nil {
+			t.Fatalf("couldn't update job post: %s", err)
+		}
+		if statusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusOK, statusCode, resp.Error)
+		}
+	})
+
+	t.Run("History records every transition", func(t *testing.T) {
+		statusCode, resp, err := listMyJobPostHistory(ts.URL, client, employerToken, postID)
+		if err != nil {
+			t.Fatalf("couldn't list job post history: %s", err)
+		}
+		if statusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, statusCode)
+		}
+		expected := [][2]string{{"", "draft"}, {"draft", "pending_review"}, {"published", "closed"}}
+		if len(resp.Result) != len(expected) {
+			t.Fatalf("expected %d status changes, got %v", len(expected), resp.Result)
+		}
+		for i, change := range resp.Result {
+			if change.FromStatus != expected[i][0] || change.ToStatus != expected[i][1] {
+				t.Fatalf("expected change %v, got %s -> %s", expected[i], change.FromStatus, change.ToStatus)
+			}
+			if change.ActorRole != 2 || change.ActorID != 1 || change.ChangedAt == "" {
+				t.Fatalf("expected change by employer account 1 with a timestamp, got %+v", change)
+			}
+		}
+	})
+}
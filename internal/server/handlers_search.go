@@ -0,0 +1,187 @@
+package server
+
+import (
+	"context"
+	"html"
+	"net/http"
+	"strings"
+	"unicode"
+
+	"github.com/gruyaume/lesvieux/internal/db"
+)
+
+// Markers placed by the database around the matched terms of highlights and snippets.
+// They are swapped for <mark> tags once the rest of the text is escaped.
+const (
+	searchMatchStart = "\x02"
+	searchMatchEnd   = "\x03"
+)
+
+type SearchJobPostResponse struct {
+	ID             int64  `json:"id"`
+	Title          string `json:"title"`
+	TitleHighlight string `json:"title_highlight"`
+	Snippet        string `json:"snippet"`
+	PublishedAt    string `json:"published_at"`
+	ExpiresAt      string `json:"expires_at,omitempty"`
+	EmployerID     int64  `json:"employer_id"`
+	EmployerName   string `json:"employer_name"`
+	JobPostDetails
+}
+
+// SearchJobPosts returns a page of the published job posts matching the q query parameter, best matches first.
+// Words match the title, content and employer name of the posts. A word ending with * matches any word
+// starting with it, and words between double quotes must appear next to each other.
+// Results can be narrowed down with the same detail filters as ListJobPosts.
+// Matched terms are wrapped in <mark> tags in the title_highlight and snippet fields, which are otherwise HTML escaped.
+func SearchJobPosts(env *HandlerConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !env.DBQueries.FullTextSearch() {
+			writeError(w, http.StatusServiceUnavailable, "Search is not available on this server")
+			return
+		}
+		terms := parseSearchTerms(r.URL.Query().Get("q"))
+		query := buildFullTextQuery(terms)
+		if env.DBQueries.Driver() == db.Postgres {
+			query = buildTextSearchQuery(terms)
+		}
+		if query == "" {
+			writeError(w, http.StatusBadRequest, "q is required")
+			return
+		}
+		page, err := parsePageRequest(r, "relevance")
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "%s", err)
+			return
+		}
+		details, err := parseJobPostDetailFilters(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "%s", err)
+			return
+		}
+		offset := page.CursorID()
+		rows, err := env.DBQueries.SearchPublishedJobPosts(context.Background(), db.SearchPublishedJobPostsParams{
+			Query:               query,
+			EmploymentType:      details.EmploymentType,
+			WorkMode:            details.WorkMode,
+			PhysicalDemands:     details.PhysicalDemands,
+			City:                details.City,
+			PostalCode:          details.PostalCode,
+			MaxWeeklyHours:      details.MaxWeeklyHours,
+			SalaryCurrency:      details.SalaryCurrency,
+			MinSalary:           details.MinSalary,
+			FlexibleSchedule:    details.FlexibleSchedule,
+			AccessibleWorkplace: details.AccessibleWorkplace,
+			Limit:               page.FetchLimit(),
+			Offset:              offset,
+		})
+		if err != nil {
+			logError(r, "internal error", err)
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		// Results are ranked rather than sorted on a column, so the cursor holds the offset of the next page
+		rows, nextCursor := paginate(page, rows, func(db.SearchPublishedJobPostsRow) (string, int64) {
+			return "", offset + page.Limit
+		})
+		jobPostsResponse := make([]SearchJobPostResponse, 0, len(rows))
+		for _, row := range rows {
+			jobPostsResponse = append(jobPostsResponse, SearchJobPostResponse{
+				ID:             row.JobPost.ID,
+				Title:          strings.NewReplacer(searchMatchStart, "", searchMatchEnd, "").Replace(row.TitleHighlight),
+				TitleHighlight: highlightSearchMatches(row.TitleHighlight),
+				Snippet:        highlightSearchMatches(row.ContentSnippet),
+				PublishedAt:    row.JobPost.PublishedAt.String,
+				ExpiresAt:      row.JobPost.ExpiresAt.String,
+				EmployerID:     row.JobPost.EmployerID,
+				EmployerName:   row.EmployerName,
+				JobPostDetails: newJobPostDetails(row.JobPost),
+			})
+		}
+		err = writeJSONPage(w, jobPostsResponse, nextCursor)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+	}
+}
+
+// highlightSearchMatches escapes text for HTML, and wraps the terms marked by the database in <mark> tags.
+func highlightSearchMatches(text string) string {
+	return strings.NewReplacer(searchMatchStart, "<mark>", searchMatchEnd, "</mark>").Replace(html.EscapeString(text))
+}
+
+// searchTerm is a word or a "quoted phrase" of a search. A prefix term matches any word starting with it.
+type searchTerm struct {
+	text   string
+	prefix bool
+}
+
+// parseSearchTerms splits a search typed by a visitor into words and "quoted phrases".
+// A trailing * on a word is kept as a prefix search.
+func parseSearchTerms(q string) []searchTerm {
+	var terms []searchTerm
+	addTerm := func(term string, prefix bool) {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			return
+		}
+		terms = append(terms, searchTerm{text: term, prefix: prefix})
+	}
+	for {
+		q = strings.TrimLeftFunc(q, unicode.IsSpace)
+		if q == "" {
+			break
+		}
+		if strings.HasPrefix(q, `"`) {
+			end := strings.Index(q[1:], `"`)
+			if end == -1 {
+				addTerm(q[1:], false)
+				break
+			}
+			addTerm(q[1:end+1], false)
+			q = q[end+2:]
+			continue
+		}
+		end := strings.IndexFunc(q, func(r rune) bool { return unicode.IsSpace(r) || r == '"' })
+		if end == -1 {
+			end = len(q)
+		}
+		word := q[:end]
+		q = q[end:]
+		addTerm(strings.TrimRight(word, "*"), strings.HasSuffix(word, "*"))
+	}
+	return terms
+}
+
+// buildFullTextQuery turns search terms into an SQLite FTS5 query.
+// Every term is quoted so that FTS5 operators and column filters can't be used. All terms must match.
+func buildFullTextQuery(terms []searchTerm) string {
+	quoted := make([]string, 0, len(terms))
+	for _, term := range terms {
+		text := `"` + strings.ReplaceAll(term.text, `"`, `""`) + `"`
+		if term.prefix {
+			text += "*"
+		}
+		quoted = append(quoted, text)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// buildTextSearchQuery turns search terms into a PostgreSQL tsquery.
+// Every word is quoted so that tsquery operators can't be used, and the words of a phrase must follow each other.
+// All terms must match.
+func buildTextSearchQuery(terms []searchTerm) string {
+	quoted := make([]string, 0, len(terms))
+	for _, term := range terms {
+		words := strings.Fields(term.text)
+		for i, word := range words {
+			words[i] = "'" + strings.NewReplacer(`\`, `\\`, "'", "''").Replace(word) + "'"
+		}
+		if term.prefix {
+			words[len(words)-1] += ":*"
+		}
+		quoted = append(quoted, "("+strings.Join(words, " <-> ")+")")
+	}
+	return strings.Join(quoted, " & ")
+}
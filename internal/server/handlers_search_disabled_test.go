@@ -0,0 +1,37 @@
+//go:build !(sqlite_fts5 || fts5)
+
+package server_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestSearchJobPostsUnavailable(t *testing.T) {
+	ts, config, err := setupServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ts.Close()
+	if config.DBQueries.FullTextSearch() {
+		t.Skip("search is always available on PostgreSQL")
+	}
+	res, err := ts.Client().Get(ts.URL + "/api/v1/posts/search?q=garden")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	var errorResponse struct {
+		Error string `json:"error"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&errorResponse); err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected status %d, got %d", http.StatusServiceUnavailable, res.StatusCode)
+	}
+	if errorResponse.Error != "Search is not available on this server" {
+		t.Fatalf("unexpected error %q", errorResponse.Error)
+	}
+}
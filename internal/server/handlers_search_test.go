@@ -0,0 +1,184 @@
+//go:build sqlite_fts5 || fts5
+
+package server_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+type SearchJobPostResponseResult struct {
+	ID             int64  `json:"id"`
+	Title          string `json:"title"`
+	TitleHighlight string `json:"title_highlight"`
+	Snippet        string `json:"snippet"`
+	EmployerName   string `json:"employer_name"`
+}
+
+type SearchJobPostsResponse struct {
+	Error      string                        `json:"error,omitempty"`
+	Result     []SearchJobPostResponseResult `json:"result"`
+	NextCursor string                        `json:"next_cursor,omitempty"`
+}
+
+func searchJobPosts(url string, client *http.Client, query string) (int, *SearchJobPostsResponse, error) {
+	req, err := http.NewRequest("GET", url+"/api/v1/posts/search?"+query, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer res.Body.Close()
+	var searchResponse SearchJobPostsResponse
+	if err := json.NewDecoder(res.Body).Decode(&searchResponse); err != nil {
+		return 0, nil, err
+	}
+	return res.StatusCode, &searchResponse, nil
+}
+
+func TestSearchJobPosts(t *testing.T) {
+	ts, _, err := setupServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ts.Close()
+	client := ts.Client()
+	var adminToken string
+	var employerToken string
+	t.Run("prepare admin account and token", prepareAdminAccount(ts.URL, client, &adminToken))
+	t.Run("prepare employer account and token", prepareEmployerAccount(ts.URL, client, &adminToken, &employerToken))
+	t.Run("prepare job posts", func(t *testing.T) {
+		posts := []UpdateJobPostParams{
+			{Title: "Gardener", Content: "Tend the community garden <i>two</i> mornings a week.", Status: "pending_review"},
+			{Title: "Library assistant", Content: "Help visitors at the library. Some gardening of the courtyard plants.", Status: "pending_review", JobPostDetails: JobPostDetails{WorkMode: "remote"}},
+			{Title: "Gardening mentor", Content: "Draft post that isn't published.", Status: "draft"},
+		}
+		for i := range posts {
+			if _, err := preparePublishedJobPost(ts.URL, client, adminToken, employerToken, &posts[i]); err != nil {
+				t.Fatal(err)
+			}
+		}
+	})
+
+	testCases := []struct {
+		desc   string
+		q      string
+		titles []string
+	}{
+		{
+			desc:   "Title matches rank first",
+			q:      "garden*",
+			titles: []string{"Gardener", "Library assistant"},
+		},
+		{
+			desc:   "Word",
+			q:      "library",
+			titles: []string{"Library assistant"},
+		},
+		{
+			desc:   "Phrase",
+			q:      `"community garden"`,
+			titles: []string{"Gardener"},
+		},
+		{
+			desc:   "Phrase words out of order",
+			q:      `"garden community"`,
+			titles: []string{},
+		},
+		{
+			desc:   "Employer name",
+			q:      validEmployer.Name,
+			titles: []string{"Gardener", "Library assistant"},
+		},
+		{
+			desc:   "Operators are searched as words",
+			q:      "title:gardener OR library",
+			titles: []string{},
+		},
+		{
+			desc:   "Unbalanced quote",
+			q:      `"courtyard plants`,
+			titles: []string{"Library assistant"},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run("Search - "+tC.desc, func(t *testing.T) {
+			statusCode, resp, err := searchJobPosts(ts.URL, client, "q="+url.QueryEscape(tC.q))
+			if err != nil {
+				t.Fatalf("couldn't search job posts: %s", err)
+			}
+			if statusCode != http.StatusOK {
+				t.Fatalf("expected status %d, got %d: %s", http.StatusOK, statusCode, resp.Error)
+			}
+			titles := make([]string, 0, len(resp.Result))
+			for _, result := range resp.Result {
+				titles = append(titles, result.Title)
+			}
+			if fmt.Sprint(titles) != fmt.Sprint(tC.titles) {
+				t.Fatalf("expected %v, got %v", tC.titles, titles)
+			}
+		})
+	}
+
+	t.Run("Search - filtered on details", func(t *testing.T) {
+		statusCode, resp, err := searchJobPosts(ts.URL, client, "q=garden*&work_mode=remote")
+		if err != nil {
+			t.Fatalf("couldn't search job posts: %s", err)
+		}
+		if statusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusOK, statusCode, resp.Error)
+		}
+		if len(resp.Result) != 1 || resp.Result[0].Title != "Library assistant" {
+			t.Fatalf("expected the remote job post only, got %v", resp.Result)
+		}
+	})
+
+	t.Run("Search - matches are highlighted and content is escaped", func(t *testing.T) {
+		statusCode, resp, err := searchJobPosts(ts.URL, client, "q=two")
+		if err != nil {
+			t.Fatalf("couldn't search job posts: %s", err)
+		}
+		if statusCode != http.StatusOK || len(resp.Result) != 1 {
+			t.Fatalf("expected 1 result, got %d: %v", statusCode, resp)
+		}
+		expected := "Tend the community garden &lt;i&gt;<mark>two</mark>&lt;/i&gt; mornings a week."
+		if resp.Result[0].Snippet != expected {
+			t.Fatalf("expected snippet %q, got %q", expected, resp.Result[0].Snippet)
+		}
+		if resp.Result[0].EmployerName != validEmployer.Name {
+			t.Fatalf("expected employer name %q, got %q", validEmployer.Name, resp.Result[0].EmployerName)
+		}
+	})
+
+	t.Run("Search - paginated", func(t *testing.T) {
+		statusCode, resp, err := searchJobPosts(ts.URL, client, "q=garden*&limit=1")
+		if err != nil {
+			t.Fatalf("couldn't search job posts: %s", err)
+		}
+		if statusCode != http.StatusOK || len(resp.Result) != 1 || resp.Result[0].TitleHighlight != "<mark>Gardener</mark>" || resp.NextCursor == "" {
+			t.Fatalf("expected the first result and a next cursor, got %d: %v", statusCode, resp)
+		}
+		statusCode, resp, err = searchJobPosts(ts.URL, client, "q=garden*&limit=1&cursor="+resp.NextCursor)
+		if err != nil {
+			t.Fatalf("couldn't search job posts: %s", err)
+		}
+		if statusCode != http.StatusOK || len(resp.Result) != 1 || resp.Result[0].Title != "Library assistant" || resp.NextCursor != "" {
+			t.Fatalf("expected the last result and no next cursor, got %d: %v", statusCode, resp)
+		}
+	})
+
+	t.Run("Search - query is required", func(t *testing.T) {
+		statusCode, resp, err := searchJobPosts(ts.URL, client, "q=+")
+		if err != nil {
+			t.Fatalf("couldn't search job posts: %s", err)
+		}
+		if statusCode != http.StatusBadRequest || resp.Error != "q is required" {
+			t.Fatalf("expected status %d and error %q, got %d and %q", http.StatusBadRequest, "q is required", statusCode, resp.Error)
+		}
+	})
+}
@@ -0,0 +1,259 @@
+package server_test
+
+import (
+	"net/http"
+	"testing"
+)
+
+type RefreshSessionParams struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type RefreshSessionResponseResult struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+type RefreshSessionResponse struct {
+	Result RefreshSessionResponseResult `json:"result"`
+	Error  string                       `json:"error,omitempty"`
+}
+
+type LogoutResponse struct {
+	Result map[string]any `json:"result"`
+	Error  string         `json:"error,omitempty"`
+}
+
+func refreshSession(url string, client *http.Client, data *RefreshSessionParams) (int, *RefreshSessionResponse, error) {
+	var response RefreshSessionResponse
+	statusCode, err := apiRequest("POST", url, client, "", "/auth/refresh", data, &response)
+	return statusCode, &response, err
+}
+
+func logout(url string, client *http.Client, token string) (int, *LogoutResponse, error) {
+	var response LogoutResponse
+	statusCode, err := apiRequest("POST", url, client, token, "/auth/logout", nil, &response)
+	return statusCode, &response, err
+}
+
+func logoutAllSessions(url string, client *http.Client, token string) (int, *LogoutResponse, error) {
+	var response LogoutResponse
+	statusCode, err := apiRequest("POST", url, client, token, "/auth/logout_all", nil, &response)
+	return statusCode, &response, err
+}
+
+// loginApplicantSession logs the valid applicant in, starting a new session.
+func loginApplicantSession(t *testing.T, url string, client *http.Client) ApplicantLoginResponseResult {
+	t.Helper()
+	statusCode, loginResponse, err := applicantLogin(url, client, &ApplicantLoginParams{
+		Email:    validApplicantAccount.Email,
+		Password: validApplicantAccount.Password,
+	})
+	if err != nil {
+		t.Fatalf("couldn't login applicant: %s", err)
+	}
+	if statusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, statusCode)
+	}
+	if loginResponse.Result.RefreshToken == "" {
+		t.Fatalf("expected refresh token, got empty string")
+	}
+	return loginResponse.Result
+}
+
+func expectApplicantTokenStatus(t *testing.T, url string, client *http.Client, token string, status int) {
+	t.Helper()
+	statusCode, _, err := getMyApplicantAccount(url, client, token)
+	if err != nil {
+		t.Fatalf("couldn't get applicant account: %s", err)
+	}
+	if statusCode != status {
+		t.Fatalf("expected status %d, got %d", status, statusCode)
+	}
+}
+
+func TestRefreshSession(t *testing.T) {
+	ts, _, err := setupServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ts.Close()
+	client := ts.Client()
+	var token string
+	t.Run("prepare applicant account", prepareApplicantAccount(ts.URL, client, &token))
+	session := loginApplicantSession(t, ts.URL, client)
+
+	var refreshed RefreshSessionResponseResult
+	t.Run("Refresh success", func(t *testing.T) {
+		statusCode, response, err := refreshSession(ts.URL, client, &RefreshSessionParams{RefreshToken: session.RefreshToken})
+		if err != nil {
+			t.Fatalf("couldn't refresh session: %s", err)
+		}
+		if statusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusOK, statusCode, response.Error)
+		}
+		if response.Result.Token == "" || response.Result.RefreshToken == "" {
+			t.Fatalf("expected tokens, got %v", response.Result)
+		}
+		if response.Result.RefreshToken == session.RefreshToken {
+			t.Fatalf("expected a new refresh token")
+		}
+		refreshed = response.Result
+		expectApplicantTokenStatus(t, ts.URL, client, refreshed.Token, http.StatusOK)
+	})
+
+	testCases := []struct {
+		desc   string
+		params RefreshSessionParams
+		status int
+		error  string
+	}{
+		{"Refresh token can only be used once", RefreshSessionParams{RefreshToken: session.RefreshToken}, http.StatusUnauthorized, "Invalid refresh token"},
+		{"Unknown refresh token", RefreshSessionParams{RefreshToken: "not-a-refresh-token"}, http.StatusUnauthorized, "Invalid refresh token"},
+		{"Missing refresh token", RefreshSessionParams{}, http.StatusBadRequest, "refresh_token is required"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			statusCode, response, err := refreshSession(ts.URL, client, &tc.params)
+			if err != nil {
+				t.Fatalf("couldn't refresh session: %s", err)
+			}
+			if statusCode != tc.status {
+				t.Fatalf("expected status %d, got %d", tc.status, statusCode)
+			}
+			if response.Error != tc.error {
+				t.Fatalf("expected error %q, got %q", tc.error, response.Error)
+			}
+		})
+	}
+
+	t.Run("Refreshed token can be refreshed again", func(t *testing.T) {
+		statusCode, _, err := refreshSession(ts.URL, client, &RefreshSessionParams{RefreshToken: refreshed.RefreshToken})
+		if err != nil {
+			t.Fatalf("couldn't refresh session: %s", err)
+		}
+		if statusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, statusCode)
+		}
+	})
+}
+
+func TestLogout(t *testing.T) {
+	ts, _, err := setupServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ts.Close()
+	client := ts.Client()
+	var token string
+	t.Run("prepare applicant account", prepareApplicantAccount(ts.URL, client, &token))
+	first := loginApplicantSession(t, ts.URL, client)
+	second := loginApplicantSession(t, ts.URL, client)
+
+	t.Run("Logout ends the current session only", func(t *testing.T) {
+		statusCode, response, err := logout(ts.URL, client, first.Token)
+		if err != nil {
+			t.Fatalf("couldn't logout: %s", err)
+		}
+		if statusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusOK, statusCode, response.Error)
+		}
+		expectApplicantTokenStatus(t, ts.URL, client, first.Token, http.StatusUnauthorized)
+		expectApplicantTokenStatus(t, ts.URL, client, second.Token, http.StatusOK)
+		expectApplicantTokenStatus(t, ts.URL, client, token, http.StatusOK)
+		statusCode, _, err = refreshSession(ts.URL, client, &RefreshSessionParams{RefreshToken: first.RefreshToken})
+		if err != nil {
+			t.Fatalf("couldn't refresh session: %s", err)
+		}
+		if statusCode != http.StatusUnauthorized {
+			t.Fatalf("expected status %d, got %d", http.StatusUnauthorized, statusCode)
+		}
+	})
+
+	t.Run("Logout requires auth", func(t *testing.T) {
+		statusCode, _, err := logout(ts.URL, client, "")
+		if err != nil {
+			t.Fatalf("couldn't logout: %s", err)
+		}
+		if statusCode != http.StatusUnauthorized {
+			t.Fatalf("expected status %d, got %d", http.StatusUnauthorized, statusCode)
+		}
+	})
+
+	t.Run("Logout all ends every session of the account", func(t *testing.T) {
+		statusCode, response, err := logoutAllSessions(ts.URL, client, second.Token)
+		if err != nil {
+			t.Fatalf("couldn't logout: %s", err)
+		}
+		if statusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusOK, statusCode, response.Error)
+		}
+		expectApplicantTokenStatus(t, ts.URL, client, second.Token, http.StatusUnauthorized)
+		expectApplicantTokenStatus(t, ts.URL, client, token, http.StatusUnauthorized)
+	})
+}
+
+func TestSessionsRevokedOnPasswordChange(t *testing.T) {
+	ts, _, err := setupServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ts.Close()
+	client := ts.Client()
+	var token string
+	t.Run("prepare applicant account", prepareApplicantAccount(ts.URL, client, &token))
+	other := loginApplicantSession(t, ts.URL, client)
+
+	statusCode, _, err := changeMyApplicantAccountPassword(ts.URL, client, token, &ChangeApplicantPasswordParams{Password: "NewApplicant123!"})
+	if err != nil {
+		t.Fatalf("couldn't change password: %s", err)
+	}
+	if statusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, statusCode)
+	}
+	expectApplicantTokenStatus(t, ts.URL, client, token, http.StatusOK)
+	expectApplicantTokenStatus(t, ts.URL, client, other.Token, http.StatusUnauthorized)
+	statusCode, _, err = refreshSession(ts.URL, client, &RefreshSessionParams{RefreshToken: other.RefreshToken})
+	if err != nil {
+		t.Fatalf("couldn't refresh session: %s", err)
+	}
+	if statusCode != http.StatusUnauthorized {
+		t.Fatalf("expected status %d, got %d", http.StatusUnauthorized, statusCode)
+	}
+}
+
+func TestSessionsRevokedOnAccountDeletion(t *testing.T) {
+	ts, _, err := setupServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ts.Close()
+	client := ts.Client()
+	var adminToken string
+	var employerToken string
+	t.Run("prepare admin account", prepareAdminAccount(ts.URL, client, &adminToken))
+	t.Run("prepare employer account", prepareEmployerAccount(ts.URL, client, &adminToken, &employerToken))
+
+	statusCode, _, err := getMyEmployerAccount(ts.URL, client, employerToken)
+	if err != nil {
+		t.Fatalf("couldn't get employer account: %s", err)
+	}
+	if statusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, statusCode)
+	}
+	var response map[string]any
+	statusCode, err = apiRequest("DELETE", ts.URL, client, adminToken, "/employers/1/accounts/1", nil, &response)
+	if err != nil {
+		t.Fatalf("couldn't delete employer account: %s", err)
+	}
+	if statusCode != http.StatusAccepted {
+		t.Fatalf("expected status %d, got %d", http.StatusAccepted, statusCode)
+	}
+	statusCode, _, err = getMyEmployerAccount(ts.URL, client, employerToken)
+	if err != nil {
+		t.Fatalf("couldn't get employer account: %s", err)
+	}
+	if statusCode != http.StatusUnauthorized {
+		t.Fatalf("expected status %d, got %d", http.StatusUnauthorized, statusCode)
+	}
+}
@@ -2,7 +2,6 @@ package server
 
 import (
 	"context"
-	"log"
 	"net/http"
 
 	"github.com/gruyaume/lesvieux/version"
@@ -17,7 +16,7 @@ func GetStatus(env *HandlerConfig) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		numEmployerAccounts, err := env.DBQueries.NumAdminAccounts(context.Background())
 		if err != nil {
-			log.Println("couldn't retrieve admin accounts: " + err.Error())
+			logError(r, "couldn't retrieve admin accounts", err)
 			writeError(w, http.StatusInternalServerError, "internal error")
 			return
 		}
@@ -33,3 +32,28 @@ func GetStatus(env *HandlerConfig) http.HandlerFunc {
 		}
 	}
 }
+
+type GetReadinessResponse struct {
+	Ready bool `json:"ready"`
+}
+
+// GetReadiness reports whether the server is ready to handle requests. It fails once the server is shutting down,
+// so that load balancers stop sending it requests, and while the database can't be reached.
+func GetReadiness(env *HandlerConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if env.shuttingDown.Load() {
+			writeError(w, http.StatusServiceUnavailable, "shutting down")
+			return
+		}
+		if err := env.DBQueries.DB().PingContext(r.Context()); err != nil {
+			logError(r, "couldn't reach the database", err)
+			writeError(w, http.StatusServiceUnavailable, "database unavailable")
+			return
+		}
+		err := writeJSON(w, GetReadinessResponse{Ready: true})
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+	}
+}
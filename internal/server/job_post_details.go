@@ -0,0 +1,243 @@
+package server
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gruyaume/lesvieux/internal/db"
+)
+
+const (
+	EmploymentTypePartTime = "part_time"
+	EmploymentTypeFullTime = "full_time"
+
+	WorkModeRemote = "remote"
+	WorkModeHybrid = "hybrid"
+	WorkModeOnSite = "on_site"
+
+	SalaryPeriodHour  = "hour"
+	SalaryPeriodMonth = "month"
+	SalaryPeriodYear  = "year"
+
+	PhysicalDemandsLow      = "low"
+	PhysicalDemandsModerate = "moderate"
+	PhysicalDemandsHigh     = "high"
+)
+
+const maxJobPostWeeklyHours = 80
+
+var (
+	employmentTypes = []string{EmploymentTypePartTime, EmploymentTypeFullTime}
+	workModes       = []string{WorkModeRemote, WorkModeHybrid, WorkModeOnSite}
+	salaryPeriods   = []string{SalaryPeriodHour, SalaryPeriodMonth, SalaryPeriodYear}
+	physicalDemands = []string{PhysicalDemandsLow, PhysicalDemandsModerate, PhysicalDemandsHigh}
+)
+
+// JobPostDetails are the structured criteria of a Job Post that seniors can filter on.
+// They are all optional so that drafts can be saved before they are complete.
+type JobPostDetails struct {
+	EmploymentType      string `json:"employment_type,omitempty"`
+	WeeklyHours         *int64 `json:"weekly_hours,omitempty"`
+	WorkMode            string `json:"work_mode,omitempty"`
+	City                string `json:"city,omitempty"`
+	PostalCode          string `json:"postal_code,omitempty"`
+	SalaryMin           *int64 `json:"salary_min,omitempty"`
+	SalaryMax           *int64 `json:"salary_max,omitempty"`
+	SalaryCurrency      string `json:"salary_currency,omitempty"`
+	SalaryPeriod        string `json:"salary_period,omitempty"`
+	PhysicalDemands     string `json:"physical_demands,omitempty"`
+	FlexibleSchedule    bool   `json:"flexible_schedule"`
+	AccessibleWorkplace bool   `json:"accessible_workplace"`
+}
+
+func newJobPostDetails(jobPost db.JobPost) JobPostDetails {
+	details := JobPostDetails{
+		EmploymentType:      jobPost.EmploymentType.String,
+		WorkMode:            jobPost.WorkMode.String,
+		City:                jobPost.City.String,
+		PostalCode:          jobPost.PostalCode.String,
+		SalaryCurrency:      jobPost.SalaryCurrency.String,
+		SalaryPeriod:        jobPost.SalaryPeriod.String,
+		PhysicalDemands:     jobPost.PhysicalDemands.String,
+		FlexibleSchedule:    jobPost.FlexibleSchedule,
+		AccessibleWorkplace: jobPost.AccessibleWorkplace,
+	}
+	if jobPost.WeeklyHours.Valid {
+		details.WeeklyHours = &jobPost.WeeklyHours.Int64
+	}
+	if jobPost.SalaryMin.Valid {
+		details.SalaryMin = &jobPost.SalaryMin.Int64
+	}
+	if jobPost.SalaryMax.Valid {
+		details.SalaryMax = &jobPost.SalaryMax.Int64
+	}
+	return details
+}
+
+// validate normalizes the free form details, and returns an error describing the first invalid one.
+func (d *JobPostDetails) validate() error {
+	d.City = strings.TrimSpace(d.City)
+	d.PostalCode = strings.ToUpper(strings.TrimSpace(d.PostalCode))
+	d.SalaryCurrency = strings.ToUpper(strings.TrimSpace(d.SalaryCurrency))
+	if err := validateOneOf("employment_type", d.EmploymentType, employmentTypes); err != nil {
+		return err
+	}
+	if err := validateOneOf("work_mode", d.WorkMode, workModes); err != nil {
+		return err
+	}
+	if err := validateOneOf("salary_period", d.SalaryPeriod, salaryPeriods); err != nil {
+		return err
+	}
+	if err := validateOneOf("physical_demands", d.PhysicalDemands, physicalDemands); err != nil {
+		return err
+	}
+	if d.WeeklyHours != nil && (*d.WeeklyHours < 1 || *d.WeeklyHours > maxJobPostWeeklyHours) {
+		return fmt.Errorf("weekly_hours must be between 1 and %d", maxJobPostWeeklyHours)
+	}
+	if d.SalaryMin != nil && *d.SalaryMin < 0 {
+		return fmt.Errorf("salary_min can't be negative")
+	}
+	if d.SalaryMax != nil && *d.SalaryMax < 0 {
+		return fmt.Errorf("salary_max can't be negative")
+	}
+	if d.SalaryMin != nil && d.SalaryMax != nil && *d.SalaryMax < *d.SalaryMin {
+		return fmt.Errorf("salary_max can't be lower than salary_min")
+	}
+	hasSalary := d.SalaryMin != nil || d.SalaryMax != nil
+	if hasSalary && (d.SalaryCurrency == "" || d.SalaryPeriod == "") {
+		return fmt.Errorf("salary_currency and salary_period are required with a salary")
+	}
+	if !hasSalary && (d.SalaryCurrency != "" || d.SalaryPeriod != "") {
+		return fmt.Errorf("salary_min or salary_max is required with salary_currency and salary_period")
+	}
+	if d.SalaryCurrency != "" && !isCurrencyCode(d.SalaryCurrency) {
+		return fmt.Errorf("salary_currency must be a 3 letter currency code")
+	}
+	return nil
+}
+
+func validateOneOf(name string, value string, allowed []string) error {
+	if value == "" {
+		return nil
+	}
+	for _, a := range allowed {
+		if value == a {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s must be one of %s", name, strings.Join(allowed, ", "))
+}
+
+func isCurrencyCode(s string) bool {
+	if len(s) != 3 {
+		return false
+	}
+	for _, c := range s {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+func nullInt64(i *int64) sql.NullInt64 {
+	if i == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: *i, Valid: true}
+}
+
+// newUpdateJobPostParams returns the parameters to write the editable fields of a Job Post.
+func newUpdateJobPostParams(id int64, title string, content string, expiresAt sql.NullString, details JobPostDetails) db.UpdateJobPostParams {
+	return db.UpdateJobPostParams{
+		Title:               title,
+		Content:             content,
+		ExpiresAt:           expiresAt,
+		EmploymentType:      nullString(details.EmploymentType),
+		WeeklyHours:         nullInt64(details.WeeklyHours),
+		WorkMode:            nullString(details.WorkMode),
+		City:                nullString(details.City),
+		PostalCode:          nullString(details.PostalCode),
+		SalaryMin:           nullInt64(details.SalaryMin),
+		SalaryMax:           nullInt64(details.SalaryMax),
+		SalaryCurrency:      nullString(details.SalaryCurrency),
+		SalaryPeriod:        nullString(details.SalaryPeriod),
+		PhysicalDemands:     nullString(details.PhysicalDemands),
+		FlexibleSchedule:    details.FlexibleSchedule,
+		AccessibleWorkplace: details.AccessibleWorkplace,
+		ID:                  id,
+	}
+}
+
+// jobPostDetailFilters are the query parameters narrowing down the public lists of job posts
+// on their structured details. Unset filters match every post.
+type jobPostDetailFilters struct {
+	EmploymentType      sql.NullString
+	WorkMode            sql.NullString
+	PhysicalDemands     sql.NullString
+	City                sql.NullString
+	PostalCode          sql.NullString
+	MaxWeeklyHours      sql.NullInt64
+	SalaryCurrency      sql.NullString
+	MinSalary           sql.NullInt64
+	FlexibleSchedule    sql.NullBool
+	AccessibleWorkplace sql.NullBool
+}
+
+// parseJobPostDetailFilters reads the employment_type, work_mode, physical_demands, city, postal_code,
+// max_weekly_hours, salary_currency, min_salary, flexible_schedule and accessible_workplace query parameters.
+// min_salary matches the posts whose salary range reaches it.
+func parseJobPostDetailFilters(r *http.Request) (jobPostDetailFilters, error) {
+	query := r.URL.Query()
+	filters := jobPostDetailFilters{
+		EmploymentType:  nullString(query.Get("employment_type")),
+		WorkMode:        nullString(query.Get("work_mode")),
+		PhysicalDemands: nullString(query.Get("physical_demands")),
+		City:            nullString(strings.TrimSpace(query.Get("city"))),
+		PostalCode:      nullString(strings.ToUpper(strings.TrimSpace(query.Get("postal_code")))),
+		SalaryCurrency:  nullString(strings.ToUpper(query.Get("salary_currency"))),
+	}
+	if err := validateOneOf("employment_type", filters.EmploymentType.String, employmentTypes); err != nil {
+		return filters, err
+	}
+	if err := validateOneOf("work_mode", filters.WorkMode.String, workModes); err != nil {
+		return filters, err
+	}
+	if err := validateOneOf("physical_demands", filters.PhysicalDemands.String, physicalDemands); err != nil {
+		return filters, err
+	}
+	var err error
+	if filters.MaxWeeklyHours, err = parseIntFilter(r, "max_weekly_hours"); err != nil {
+		return filters, err
+	}
+	if filters.MinSalary, err = parseIntFilter(r, "min_salary"); err != nil {
+		return filters, err
+	}
+	if filters.FlexibleSchedule, err = parseBoolFilter(r, "flexible_schedule"); err != nil {
+		return filters, err
+	}
+	if filters.AccessibleWorkplace, err = parseBoolFilter(r, "accessible_workplace"); err != nil {
+		return filters, err
+	}
+	return filters, nil
+}
+
+// parseBoolFilter reads an optional true or false query parameter.
+func parseBoolFilter(r *http.Request, name string) (sql.NullBool, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return sql.NullBool{}, nil
+	}
+	valueBool, err := strconv.ParseBool(value)
+	if err != nil {
+		return sql.NullBool{}, fmt.Errorf("%s must be true or false", name)
+	}
+	return sql.NullBool{Bool: valueBool, Valid: true}, nil
+}
@@ -0,0 +1,184 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/gruyaume/lesvieux/internal/db"
+)
+
+const (
+	JobPostStatusDraft         = "draft"
+	JobPostStatusPendingReview = "pending_review"
+	JobPostStatusPublished     = "published"
+	JobPostStatusRejected      = "rejected"
+	JobPostStatusClosed        = "closed"
+	JobPostStatusExpired       = "expired"
+)
+
+// errJobPostStatusConflict is returned when a job post changed status since it was read.
+var errJobPostStatusConflict = errors.New("job post status was changed by someone else")
+
+// jobPostExpiryInterval is how often published job posts are checked for expiry.
+const jobPostExpiryInterval = 5 * time.Minute
+
+// jobPostActor identifies the account changing the status of a job post, or of an application to it.
+// A nil *jobPostActor stands for the server itself, for example when expiring posts.
+type jobPostActor struct {
+	ID   int64
+	Role int64
+}
+
+type jobPostStatusTransition struct {
+	From string
+	To   string
+}
+
+// employerJobPostTransitions are the status changes an employer can make on its own posts.
+var employerJobPostTransitions = []jobPostStatusTransition{
+	{JobPostStatusDraft, JobPostStatusPendingReview},
+	{JobPostStatusPendingReview, JobPostStatusDraft},
+	{JobPostStatusRejected, JobPostStatusDraft},
+	{JobPostStatusRejected, JobPostStatusPendingReview},
+	{JobPostStatusPublished, JobPostStatusClosed},
+}
+
+// adminJobPostTransitions are the status changes an admin can make on any post.
+// Moving a published post to rejected takes it down from the public board.
+var adminJobPostTransitions = []jobPostStatusTransition{
+	{JobPostStatusPendingReview, JobPostStatusPublished},
+	{JobPostStatusPendingReview, JobPostStatusRejected},
+	{JobPostStatusPublished, JobPostStatusRejected},
+	{JobPostStatusPublished, JobPostStatusClosed},
+}
+
+// systemJobPostTransitions are the status changes made by the server without any account involved.
+var systemJobPostTransitions = []jobPostStatusTransition{
+	{JobPostStatusPublished, JobPostStatusExpired},
+}
+
+func isValidJobPostStatus(status string) bool {
+	switch status {
+	case JobPostStatusDraft, JobPostStatusPendingReview, JobPostStatusPublished,
+		JobPostStatusRejected, JobPostStatusClosed, JobPostStatusExpired:
+		return true
+	}
+	return false
+}
+
+// isEditableJobPostStatus reports whether the title and content of a post in the given status can be changed.
+// Posts that are under review or were already published must go back to draft before being edited.
+func isEditableJobPostStatus(status string) bool {
+	return status == JobPostStatusDraft || status == JobPostStatusRejected
+}
+
+// jobPostTransitionError is returned when a status change isn't allowed by the publication workflow.
+type jobPostTransitionError struct {
+	From string
+	To   string
+}
+
+func (e *jobPostTransitionError) Error() string {
+	if !isValidJobPostStatus(e.To) {
+		return fmt.Sprintf("invalid status %q", e.To)
+	}
+	return fmt.Sprintf("status can't change from %q to %q", e.From, e.To)
+}
+
+// validateJobPostStatusTransition returns an error if the actor isn't allowed to move a post from one status to another.
+func validateJobPostStatusTransition(from string, to string, actor *jobPostActor) error {
+	if !isValidJobPostStatus(to) {
+		return &jobPostTransitionError{From: from, To: to}
+	}
+	var allowed []jobPostStatusTransition
+	switch {
+	case actor == nil:
+		allowed = systemJobPostTransitions
+	case actor.Role == EmployerRole:
+		allowed = employerJobPostTransitions
+	case actor.Role == AdminRole:
+		allowed = adminJobPostTransitions
+	}
+	for _, t := range allowed {
+		if t.From == from && t.To == to {
+			return nil
+		}
+	}
+	return &jobPostTransitionError{From: from, To: to}
+}
+
+// transitionJobPostStatus applies a status change to a job post, and records when it happened,
+// who made it and why. The transition must already be validated, and queries should be bound to a transaction.
+func transitionJobPostStatus(ctx context.Context, queries *db.Queries, jobPost db.JobPost, to string, actor *jobPostActor, reason string) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+	publishedAt := jobPost.PublishedAt
+	if to == JobPostStatusPublished {
+		publishedAt = sql.NullString{String: now, Valid: true}
+	}
+	updated, err := queries.UpdateJobPostStatus(ctx, db.UpdateJobPostStatusParams{
+		Status:      to,
+		PublishedAt: publishedAt,
+		ID:          jobPost.ID,
+		FromStatus:  jobPost.Status,
+	})
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return errJobPostStatusConflict
+	}
+	return recordJobPostStatusChange(ctx, queries, jobPost.ID, jobPost.Status, to, now, actor, reason)
+}
+
+func recordJobPostStatusChange(ctx context.Context, queries *db.Queries, jobPostID int64, from string, to string, changedAt string, actor *jobPostActor, reason string) error {
+	params := db.CreateJobPostStatusChangeParams{
+		JobPostID:  jobPostID,
+		FromStatus: from,
+		ToStatus:   to,
+		ChangedAt:  changedAt,
+	}
+	if actor != nil {
+		params.ActorID = sql.NullInt64{Int64: actor.ID, Valid: true}
+		params.ActorRole = sql.NullInt64{Int64: actor.Role, Valid: true}
+	}
+	if reason != "" {
+		params.Reason = sql.NullString{String: reason, Valid: true}
+	}
+	_, err := queries.CreateJobPostStatusChange(ctx, params)
+	return err
+}
+
+// expireJobPosts moves every published job post whose expiry date has passed to the expired status.
+// A job post that fails to expire is logged and skipped, for it not to hold back the others.
+func expireJobPosts(dbQueries *db.Queries) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+	jobPosts, err := dbQueries.ListExpiredJobPosts(context.Background(), sql.NullString{String: now, Valid: true})
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, jobPost := range jobPosts {
+		err := dbQueries.ExecTx(context.Background(), func(q *db.Queries) error {
+			return transitionJobPostStatus(context.Background(), q, jobPost, JobPostStatusExpired, nil, "")
+		})
+		if err != nil {
+			slog.Warn("couldn't expire job post", "job_post_id", jobPost.ID, "error", err)
+			errs = append(errs, fmt.Errorf("couldn't expire job post %d: %w", jobPost.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// startJobPostExpiry periodically expires job posts in the background, until ctx is done.
+func startJobPostExpiry(ctx context.Context, workers *sync.WaitGroup, dbQueries *db.Queries, interval time.Duration) {
+	runEvery(ctx, workers, interval, true, func() {
+		if err := expireJobPosts(dbQueries); err != nil {
+			slog.Error("couldn't expire job posts", "error", err)
+		}
+	})
+}
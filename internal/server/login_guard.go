@@ -0,0 +1,235 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gruyaume/lesvieux/internal/db"
+	"github.com/gruyaume/lesvieux/internal/metrics"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// LoginLimits protect the login endpoints against password guessing.
+type LoginLimits struct {
+	// PerIP limits the login attempts of each client address, and PerAccount those with each email of each role.
+	PerIP      RateLimit
+	PerAccount RateLimit
+	// LockoutThreshold is the number of failed logins in a row after which an email is locked out
+	// for LockoutDuration. The lockout doubles with each further failure, up to MaxLockoutDuration.
+	LockoutThreshold   int
+	LockoutDuration    time.Duration
+	MaxLockoutDuration time.Duration
+}
+
+// DefaultLoginLimits apply to the fields of the LoginLimits of the HandlerConfig that are left zero.
+var DefaultLoginLimits = LoginLimits{
+	PerIP:              RateLimit{Burst: 20, Interval: 6 * time.Second},
+	PerAccount:         RateLimit{Burst: 10, Interval: 30 * time.Second},
+	LockoutThreshold:   5,
+	LockoutDuration:    time.Minute,
+	MaxLockoutDuration: time.Hour,
+}
+
+// withDefaults returns the limits with each field that isn't positive set to that of DefaultLoginLimits,
+// and MaxLockoutDuration raised to LockoutDuration when it is shorter.
+func (l LoginLimits) withDefaults() LoginLimits {
+	l.PerIP = l.PerIP.withDefaults(DefaultLoginLimits.PerIP)
+	l.PerAccount = l.PerAccount.withDefaults(DefaultLoginLimits.PerAccount)
+	if l.LockoutThreshold <= 0 {
+		l.LockoutThreshold = DefaultLoginLimits.LockoutThreshold
+	}
+	if l.LockoutDuration <= 0 {
+		l.LockoutDuration = DefaultLoginLimits.LockoutDuration
+	}
+	if l.MaxLockoutDuration <= 0 {
+		l.MaxLockoutDuration = DefaultLoginLimits.MaxLockoutDuration
+	}
+	l.MaxLockoutDuration = max(l.MaxLockoutDuration, l.LockoutDuration)
+	return l
+}
+
+// loginFailureRetention is how long failed logins are remembered once their email isn't locked out anymore.
+const loginFailureRetention = 24 * time.Hour
+
+const loginFailureCleanupInterval = 1 * time.Hour
+
+// dummyPasswordHash is checked against the passwords of logins with unknown emails, for them to take as long
+// as logins with known emails: response times would otherwise reveal which emails have an account.
+var dummyPasswordHash = sync.OnceValue(func() []byte {
+	hash, err := bcrypt.GenerateFromPassword([]byte("not the password of any account"), bcrypt.DefaultCost)
+	if err != nil {
+		panic(err)
+	}
+	return hash
+})
+
+// checkPassword reports whether password matches hash. An empty hash, of an account that doesn't exist,
+// matches no password but takes as long to check.
+func checkPassword(hash string, password string) bool {
+	if hash == "" {
+		bcrypt.CompareHashAndPassword(dummyPasswordHash(), []byte(password))
+		return false
+	}
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// loginGuard rate limits the login attempts and locks out the emails that fail to log in too many times in a row.
+// Failures are counted by email whether or not an account has it, so that lockouts don't reveal which emails exist.
+type loginGuard struct {
+	limits     LoginLimits
+	perIP      *rateLimiter
+	perAccount *rateLimiter
+	dbQueries  *db.Queries
+	metrics    *metrics.PrometheusMetrics
+}
+
+func newLoginGuard(limits LoginLimits, dbQueries *db.Queries, m *metrics.PrometheusMetrics) *loginGuard {
+	limits = limits.withDefaults()
+	return &loginGuard{
+		limits:     limits,
+		perIP:      newRateLimiter(limits.PerIP),
+		perAccount: newRateLimiter(limits.PerAccount),
+		dbQueries:  dbQueries,
+		metrics:    m,
+	}
+}
+
+// roleLabel returns the label of a role in metrics.
+func roleLabel(role int64) string {
+	switch role {
+	case AdminRole:
+		return metrics.RoleAdmin
+	case EmployerRole:
+		return metrics.RoleEmployer
+	default:
+		return metrics.RoleApplicant
+	}
+}
+
+// loginEmail normalizes the email of a login, for its variants not to get rate limits and lockouts of their own.
+func loginEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// accountKey returns the key of the rate limit of an email of a role.
+func accountKey(role int64, email string) string {
+	return strconv.FormatInt(role, 10) + ":" + email
+}
+
+// admit checks that a login may go on to check its password: the client and the email must be within their
+// rate limits, and the email must not be locked out. Otherwise, admit writes the error response and returns false.
+func (g *loginGuard) admit(w http.ResponseWriter, r *http.Request, role int64, email string) bool {
+	now := time.Now()
+	if ok, retryAfter := g.perIP.allow(clientIP(r), now); !ok {
+		g.metrics.RecordLoginThrottled(roleLabel(role), metrics.ThrottledByIP)
+		writeTooManyLogins(w, retryAfter, "Too many login attempts. Try again later.")
+		return false
+	}
+	email = loginEmail(email)
+	if ok, retryAfter := g.perAccount.allow(accountKey(role, email), now); !ok {
+		g.metrics.RecordLoginThrottled(roleLabel(role), metrics.ThrottledByAccount)
+		writeTooManyLogins(w, retryAfter, "Too many login attempts. Try again later.")
+		return false
+	}
+	failure, err := g.dbQueries.GetLoginFailure(context.Background(), db.GetLoginFailureParams{Role: role, Email: email})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return true
+		}
+		logError(r, "internal error", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return false
+	}
+	if !failure.LockedUntil.Valid {
+		return true
+	}
+	lockedUntil, err := time.Parse(time.RFC3339, failure.LockedUntil.String)
+	if err != nil || !lockedUntil.After(now) {
+		return true
+	}
+	g.metrics.RecordLoginThrottled(roleLabel(role), metrics.ThrottledByLockout)
+	writeTooManyLogins(w, lockedUntil.Sub(now), "Too many failed login attempts. Try again later.")
+	return false
+}
+
+// fail records a failed login and writes the 401 response with message.
+// The email is locked out once it fails LockoutThreshold times in a row.
+func (g *loginGuard) fail(w http.ResponseWriter, r *http.Request, role int64, email string, message string) {
+	g.metrics.RecordLogin(roleLabel(role), metrics.LoginFailed)
+	now := time.Now().UTC()
+	failure, err := g.dbQueries.RecordLoginFailure(context.Background(), db.RecordLoginFailureParams{
+		Role:         role,
+		Email:        loginEmail(email),
+		LastFailedAt: now.Format(time.RFC3339),
+	})
+	if err != nil {
+		logError(r, "couldn't record failed login", err)
+	} else if failure.FailedAttempts >= int64(g.limits.LockoutThreshold) {
+		lockout := g.lockoutDuration(failure.FailedAttempts)
+		err := g.dbQueries.LockLogin(context.Background(), db.LockLoginParams{
+			LockedUntil: sql.NullString{String: now.Add(lockout).Format(time.RFC3339), Valid: true},
+			Role:        role,
+			Email:       failure.Email,
+		})
+		if err != nil {
+			logError(r, "couldn't lock out login", err)
+		} else {
+			g.metrics.RecordLoginLockout(roleLabel(role))
+			slog.Warn("login locked out", "role", roleLabel(role), "failed_attempts", failure.FailedAttempts, "duration", lockout, "client_ip", clientIP(r))
+		}
+	}
+	writeError(w, http.StatusUnauthorized, message)
+}
+
+// succeed records a successful login, which clears the failures of the email.
+func (g *loginGuard) succeed(r *http.Request, role int64, email string) {
+	g.metrics.RecordLogin(roleLabel(role), metrics.LoginSucceeded)
+	_, err := g.dbQueries.DeleteLoginFailure(context.Background(), db.DeleteLoginFailureParams{Role: role, Email: loginEmail(email)})
+	if err != nil {
+		logError(r, "couldn't clear failed logins", err)
+	}
+}
+
+// forget lifts the rate limit of an email, once an admin unlocks it.
+func (g *loginGuard) forget(role int64, email string) {
+	g.perAccount.reset(accountKey(role, loginEmail(email)))
+}
+
+// lockoutDuration returns how long an email is locked out after failedAttempts failures in a row.
+func (g *loginGuard) lockoutDuration(failedAttempts int64) time.Duration {
+	doublings := float64(failedAttempts - int64(g.limits.LockoutThreshold))
+	lockout := float64(g.limits.LockoutDuration) * math.Pow(2, doublings)
+	if lockout >= float64(g.limits.MaxLockoutDuration) {
+		return g.limits.MaxLockoutDuration
+	}
+	return time.Duration(lockout)
+}
+
+// writeTooManyLogins writes the 429 response of a refused login, telling the client when to try again.
+func writeTooManyLogins(w http.ResponseWriter, retryAfter time.Duration, message string) {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	w.Header().Set("Retry-After", strconv.Itoa(max(seconds, 1)))
+	writeError(w, http.StatusTooManyRequests, message)
+}
+
+// startLoginFailureCleanup deletes the failed logins of the emails that are neither locked out
+// nor failed to log in recently, at every interval until ctx is done.
+func startLoginFailureCleanup(ctx context.Context, workers *sync.WaitGroup, dbQueries *db.Queries, interval time.Duration) {
+	runEvery(ctx, workers, interval, true, func() {
+		now := time.Now().UTC()
+		_, err := dbQueries.DeleteStaleLoginFailures(context.Background(), db.DeleteStaleLoginFailuresParams{
+			FailedBefore: now.Add(-loginFailureRetention).Format(time.RFC3339),
+			Now:          sql.NullString{String: now.Format(time.RFC3339), Valid: true},
+		})
+		if err != nil {
+			slog.Error("couldn't delete stale failed logins", "error", err)
+		}
+	})
+}
@@ -0,0 +1,214 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/gruyaume/lesvieux/internal/db"
+	"github.com/gruyaume/lesvieux/internal/jwtkeys"
+	"github.com/gruyaume/lesvieux/internal/totp"
+)
+
+// MFAChallengeValidity is how long a login has to go through its second step once its password is checked.
+const MFAChallengeValidity = 5 * time.Minute
+
+// recoveryCodeCount is the number of recovery codes handed out when a second factor is enrolled.
+const recoveryCodeCount = 10
+
+// The audiences of the challenge tokens handed out by logins whose password is right: mfaVerifyAudience to send
+// a code of the second factor to /auth/mfa/verify, mfaEnrollAudience to enroll a second factor first, when the role
+// of the account requires one. Access tokens have no audience, so that challenges can't be used as access tokens.
+const (
+	mfaVerifyAudience = "mfa_verify"
+	mfaEnrollAudience = "mfa_enroll"
+)
+
+type jwtMFAChallengeClaims struct {
+	ID    int64  `json:"id"`
+	Email string `json:"email"`
+	Role  int64  `json:"role"`
+	jwt.StandardClaims
+}
+
+type MFAChallengeResponse struct {
+	// MFARequired is set when the login must be completed by sending a code with MFAToken to /auth/mfa/verify.
+	MFARequired bool `json:"mfa_required,omitempty"`
+	// MFAEnrollmentRequired is set when the account must enroll a second factor with MFAToken to log in.
+	MFAEnrollmentRequired bool   `json:"mfa_enrollment_required,omitempty"`
+	MFAToken              string `json:"mfa_token"`
+}
+
+// roleNames are the names of the roles a second factor can be required of in the configuration.
+var roleNames = map[string]int64{"admin": AdminRole, "employer": EmployerRole}
+
+// mfaRequired reports whether the accounts of role must have a second factor to log in.
+func (env *HandlerConfig) mfaRequired(role int64) bool {
+	return slices.Contains(env.MFARequiredRoles, role)
+}
+
+// mfaIssuer returns the name accounts are listed under in authenticator apps.
+func (env *HandlerConfig) mfaIssuer() string {
+	if env.MFAIssuer == "" {
+		return "LesVieux"
+	}
+	return env.MFAIssuer
+}
+
+func newMFAChallenge(jwtKeys *jwtkeys.Keyring, accountID int64, email string, role int64, audience string) (string, error) {
+	return signJWT(jwtMFAChallengeClaims{
+		ID:    accountID,
+		Email: email,
+		Role:  role,
+		StandardClaims: jwt.StandardClaims{
+			Audience:  audience,
+			ExpiresAt: time.Now().Add(MFAChallengeValidity).Unix(),
+		},
+	}, jwtKeys)
+}
+
+// parseMFAChallenge returns the claims of a challenge token handed out for audience.
+func parseMFAChallenge(token string, audience string, jwtKeys *jwtkeys.Keyring) (*jwtMFAChallengeClaims, error) {
+	claims := jwtMFAChallengeClaims{}
+	parsed, err := jwt.ParseWithClaims(token, &claims, jwtKeyFunc(jwtKeys))
+	if err != nil {
+		return nil, err
+	}
+	if !parsed.Valid || !claims.VerifyAudience(audience, true) {
+		return nil, errors.New("invalid token")
+	}
+	return &claims, nil
+}
+
+// requireSecondFactor ends the first step of a login whose password is right, when the account has a second
+// factor or its role requires one: it writes the challenge of the next step, and returns true.
+// It returns false when the login can start a session right away.
+func requireSecondFactor(w http.ResponseWriter, r *http.Request, env *HandlerConfig, accountID int64, email string, role int64) bool {
+	enrolled, err := hasSecondFactor(context.Background(), env.DBQueries, accountID, role)
+	if err != nil {
+		logError(r, "internal error", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return true
+	}
+	var response MFAChallengeResponse
+	audience := mfaVerifyAudience
+	switch {
+	case enrolled:
+		response.MFARequired = true
+	case env.mfaRequired(role):
+		response.MFAEnrollmentRequired = true
+		audience = mfaEnrollAudience
+	default:
+		return false
+	}
+	response.MFAToken, err = newMFAChallenge(env.JWTKeys, accountID, email, role, audience)
+	if err != nil {
+		logError(r, "internal error", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return true
+	}
+	err = writeJSON(w, response)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal error")
+	}
+	return true
+}
+
+// hasSecondFactor reports whether an account has a confirmed second factor.
+func hasSecondFactor(ctx context.Context, queries *db.Queries, accountID int64, role int64) (bool, error) {
+	factor, err := queries.GetTOTPFactor(ctx, db.GetTOTPFactorParams{AccountID: accountID, Role: role})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, err
+	}
+	return factor.ConfirmedAt.Valid, nil
+}
+
+// checkSecondFactor reports whether code is a code of the TOTP factor of an account that wasn't used yet,
+// or else whether recoveryCode is one of its recovery codes. Either is used up by a successful check.
+func checkSecondFactor(ctx context.Context, queries *db.Queries, factor db.TotpFactor, code string, recoveryCode string) (bool, error) {
+	if code != "" {
+		step, ok := totp.Validate(factor.Secret, code, time.Now())
+		if !ok {
+			return false, nil
+		}
+		used, err := queries.UseTOTPStep(ctx, db.UseTOTPStepParams{Step: step, AccountID: factor.AccountID, Role: factor.Role})
+		return used == 1, err
+	}
+	if recoveryCode != "" {
+		used, err := queries.UseRecoveryCode(ctx, db.UseRecoveryCodeParams{
+			AccountID: factor.AccountID,
+			Role:      factor.Role,
+			CodeHash:  hashRecoveryCode(recoveryCode),
+		})
+		return used == 1, err
+	}
+	return false, nil
+}
+
+// newRecoveryCodes returns recoveryCodeCount random recovery codes, such as 7k2qm-x9fjd.
+func newRecoveryCodes() ([]string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		b := make([]byte, 7)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		code := strings.ToLower(base32.StdEncoding.EncodeToString(b))[:10]
+		codes = append(codes, code[:5]+"-"+code[5:])
+	}
+	return codes, nil
+}
+
+// hashRecoveryCode returns the hash recovery codes are stored as. Codes are random enough for a fast hash.
+// Case, spaces and dashes don't matter.
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	hash := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(hash[:])
+}
+
+// replaceRecoveryCodes generates the recovery codes of an account, which replace its previous ones.
+func replaceRecoveryCodes(ctx context.Context, queries *db.Queries, accountID int64, role int64) ([]string, error) {
+	codes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	err = queries.DeleteAccountRecoveryCodes(ctx, db.DeleteAccountRecoveryCodesParams{AccountID: accountID, Role: role})
+	if err != nil {
+		return nil, err
+	}
+	for _, code := range codes {
+		err := queries.CreateRecoveryCode(ctx, db.CreateRecoveryCodeParams{
+			AccountID: accountID,
+			Role:      role,
+			CodeHash:  hashRecoveryCode(code),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return codes, nil
+}
+
+// deleteSecondFactor removes the second factor of an account and its recovery codes, and reports whether it had one,
+// confirmed or not.
+func deleteSecondFactor(ctx context.Context, queries *db.Queries, accountID int64, role int64) (bool, error) {
+	deleted, err := queries.DeleteAccountTOTPFactor(ctx, db.DeleteAccountTOTPFactorParams{AccountID: accountID, Role: role})
+	if err != nil {
+		return false, err
+	}
+	err = queries.DeleteAccountRecoveryCodes(ctx, db.DeleteAccountRecoveryCodesParams{AccountID: accountID, Role: role})
+	return deleted > 0, err
+}
@@ -1,28 +1,38 @@
 package server
 
 import (
+	"log/slog"
 	"net/http"
 )
 
 type middleware func(http.Handler) http.Handler
 
 // The loggingMiddlewareContext type helps the logging middleware receive and pass along information through the middleware chain.
+// It is stored in the context of each request, so that handlers and the middlewares they are wrapped in can reach
+// the logger of the request and report the account it is authenticated as.
 type loggingMiddlewareContext struct {
-	responseStatusCode int
+	requestID     string
+	logger        *slog.Logger
+	authenticated bool
+	userID        int64
+	role          int64
 }
 
 // The statusRecorder struct wraps the http.ResponseWriter struct, and extracts the status
-// code of the response writer for the middleware to read
+// code of the response writer for the middleware to read, along with the size of the body
+// and the error message written by writeError, if any.
 type statusRecorder struct {
 	http.ResponseWriter
-	statusCode int
+	statusCode   int
+	bytes        int
+	errorMessage string
 }
 
 // newResponseWriter returns a new ResponseWriterCloner struct
 // it returns http.StatusOK by default because the http.ResponseWriter defaults to that header
 // if the WriteHeader() function is never called.
 func newResponseWriter(w http.ResponseWriter) *statusRecorder {
-	return &statusRecorder{w, http.StatusOK}
+	return &statusRecorder{ResponseWriter: w, statusCode: http.StatusOK}
 }
 
 // WriteHeader overrides the ResponseWriter method to duplicate the status code into the wrapper struct
@@ -31,6 +41,13 @@ func (rwc *statusRecorder) WriteHeader(code int) {
 	rwc.ResponseWriter.WriteHeader(code)
 }
 
+// Write overrides the ResponseWriter method to count the bytes of the body
+func (rwc *statusRecorder) Write(b []byte) (int, error) {
+	n, err := rwc.ResponseWriter.Write(b)
+	rwc.bytes += n
+	return n, err
+}
+
 // createMiddlewareStack chains the given middleware functions to wrap the api.
 // Each middleware functions calls next.ServeHTTP in order to resume the chain of execution.
 // The order the middleware functions are given to createMiddlewareStack matters.
@@ -2,14 +2,15 @@ package server
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
-	"log"
 	"net/http"
 	"strings"
 
 	"github.com/golang-jwt/jwt"
 	"github.com/gruyaume/lesvieux/internal/db"
+	"github.com/gruyaume/lesvieux/internal/jwtkeys"
 )
 
 const (
@@ -20,14 +21,25 @@ const (
 
 type contextKey string
 
-const userIDKey = contextKey("userID")
+const (
+	userIDKey     = contextKey("userID")
+	employerIDKey = contextKey("employerID")
+	sessionIDKey  = contextKey("sessionID")
+	roleKey       = contextKey("role")
+	emailKey      = contextKey("email")
+	// mfaEnrollmentKey is set when the request is authenticated by the enrollment challenge of a login,
+	// rather than by an access token.
+	mfaEnrollmentKey = contextKey("mfaEnrollment")
+)
 
 // The adminOnly middleware checks if the user has admin role before allowing access to the handler.
-func adminOnly(jwtSecret []byte, handler func(http.ResponseWriter, *http.Request)) func(http.ResponseWriter, *http.Request) {
+func adminOnly(jwtKeys *jwtkeys.Keyring, db *db.Queries, handler func(http.ResponseWriter, *http.Request)) func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
-		claims, err := getClaimsFromAuthorizationHeader(r.Header.Get("Authorization"), jwtSecret)
-		if err != nil {
-			writeError(w, http.StatusUnauthorized, "auth failed: %s", err)
+		if !checkAdminClientCert(w, r) {
+			return
+		}
+		claims, ok := authenticate(w, r, jwtKeys, db)
+		if !ok {
 			return
 		}
 
@@ -37,43 +49,148 @@ func adminOnly(jwtSecret []byte, handler func(http.ResponseWriter, *http.Request
 		}
 
 		// Set the user ID in the request context for further handlers
-		ctxWithUserID := context.WithValue(r.Context(), userIDKey, claims.ID)
-		r = r.WithContext(ctxWithUserID)
+		r = r.WithContext(withSession(r.Context(), claims))
 
 		handler(w, r)
 	}
 }
 
-func Me(jwtSecret []byte, handler func(http.ResponseWriter, *http.Request)) func(http.ResponseWriter, *http.Request) {
+// The employerOnly middleware checks if the user has employer role before allowing access to the handler.
+// The employer the account belongs to is looked up and set in the request context alongside the user ID.
+func employerOnly(jwtKeys *jwtkeys.Keyring, db *db.Queries, handler func(http.ResponseWriter, *http.Request)) func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
-		claims, err := getClaimsFromAuthorizationHeader(r.Header.Get("Authorization"), jwtSecret)
+		claims, ok := authenticate(w, r, jwtKeys, db)
+		if !ok {
+			return
+		}
+
+		if claims.Role != EmployerRole {
+			writeError(w, http.StatusForbidden, "forbidden: employer access required")
+			return
+		}
+
+		account, err := db.GetEmployerAccountByEmail(context.Background(), claims.Email)
 		if err != nil {
-			writeError(w, http.StatusUnauthorized, "auth failed: %s", err)
+			if err == sql.ErrNoRows {
+				writeError(w, http.StatusUnauthorized, "auth failed: account not found")
+				return
+			}
+			logError(r, "couldn't retrieve employer account", err)
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+
+		ctx := withSession(r.Context(), claims)
+		ctx = context.WithValue(ctx, userIDKey, account.ID)
+		ctx = context.WithValue(ctx, employerIDKey, account.EmployerID)
+		r = r.WithContext(ctx)
+
+		handler(w, r)
+	}
+}
+
+// The applicantOnly middleware checks if the user has applicant role before allowing access to the handler.
+// Tokens of deleted accounts are rejected.
+func applicantOnly(jwtKeys *jwtkeys.Keyring, db *db.Queries, handler func(http.ResponseWriter, *http.Request)) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := authenticate(w, r, jwtKeys, db)
+		if !ok {
+			return
+		}
+
+		if claims.Role != ApplicantRole {
+			writeError(w, http.StatusForbidden, "forbidden: applicant access required")
+			return
+		}
+
+		account, err := db.GetApplicantAccount(context.Background(), claims.ID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				writeError(w, http.StatusUnauthorized, "auth failed: account not found")
+				return
+			}
+			logError(r, "couldn't retrieve applicant account", err)
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+
+		ctx := withSession(r.Context(), claims)
+		ctx = context.WithValue(ctx, userIDKey, account.ID)
+		r = r.WithContext(ctx)
+
+		handler(w, r)
+	}
+}
+
+// The Me middleware lets any logged in user, whatever their role, access the handler.
+func Me(jwtKeys *jwtkeys.Keyring, db *db.Queries, handler func(http.ResponseWriter, *http.Request)) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := authenticate(w, r, jwtKeys, db)
+		if !ok {
 			return
 		}
 
 		// Set the user ID in the request context for further handlers
-		ctxWithUserID := context.WithValue(r.Context(), userIDKey, claims.ID)
-		r = r.WithContext(ctxWithUserID)
+		r = r.WithContext(withSession(r.Context(), claims))
 
 		handler(w, r)
 	}
 }
 
+// The secondFactorOwner middleware lets admins and employers manage their second factor. They are authenticated by
+// an access token, or by the enrollment challenge of a login when their role requires a second factor to log in.
+func secondFactorOwner(jwtKeys *jwtkeys.Keyring, db *db.Queries, handler func(http.ResponseWriter, *http.Request)) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if challenge, err := parseMFAChallenge(token, mfaEnrollAudience, jwtKeys); err == nil {
+			if challenge.Role == AdminRole && !checkAdminClientCert(w, r) {
+				return
+			}
+			ctx := r.Context()
+			setRequestPrincipal(ctx, challenge.ID, challenge.Role)
+			ctx = context.WithValue(ctx, userIDKey, challenge.ID)
+			ctx = context.WithValue(ctx, roleKey, challenge.Role)
+			ctx = context.WithValue(ctx, emailKey, challenge.Email)
+			ctx = context.WithValue(ctx, mfaEnrollmentKey, true)
+			handler(w, r.WithContext(ctx))
+			return
+		}
+
+		claims, ok := authenticate(w, r, jwtKeys, db)
+		if !ok {
+			return
+		}
+
+		if claims.Role != AdminRole && claims.Role != EmployerRole {
+			writeError(w, http.StatusForbidden, "forbidden: admin or employer access required")
+			return
+		}
+		if claims.Role == AdminRole && !checkAdminClientCert(w, r) {
+			return
+		}
+
+		ctx := withSession(r.Context(), claims)
+		ctx = context.WithValue(ctx, emailKey, claims.Email)
+		handler(w, r.WithContext(ctx))
+	}
+}
+
 // The adminOrFirstUser middleware checks if the user has admin role or if the user is the first user before allowing access to the handler.
-func adminOrFirstUser(jwtSecret []byte, db *db.Queries, handler func(http.ResponseWriter, *http.Request)) func(http.ResponseWriter, *http.Request) {
+func adminOrFirstUser(jwtKeys *jwtkeys.Keyring, db *db.Queries, handler func(http.ResponseWriter, *http.Request)) func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
+		if !checkAdminClientCert(w, r) {
+			return
+		}
 		numUsers, err := db.NumEmployerAccounts(context.Background())
 		if err != nil {
-			log.Println("couldn't retrieve accounts: " + err.Error())
+			logError(r, "couldn't retrieve accounts", err)
 			writeError(w, http.StatusInternalServerError, "internal error")
 			return
 		}
 
 		if numUsers > 0 {
-			claims, err := getClaimsFromAuthorizationHeader(r.Header.Get("Authorization"), jwtSecret)
-			if err != nil {
-				writeError(w, http.StatusUnauthorized, "auth failed: %s", err)
+			claims, ok := authenticate(w, r, jwtKeys, db)
+			if !ok {
 				return
 			}
 
@@ -82,15 +199,44 @@ func adminOrFirstUser(jwtSecret []byte, db *db.Queries, handler func(http.Respon
 				return
 			}
 
-			ctxWithUserID := context.WithValue(r.Context(), userIDKey, claims.ID)
-			r = r.WithContext(ctxWithUserID)
+			r = r.WithContext(withSession(r.Context(), claims))
 		}
 
 		handler(w, r)
 	}
 }
 
-func getClaimsFromAuthorizationHeader(header string, jwtSecret []byte) (*jwtLesVieuxClaims, error) {
+// authenticate verifies the token of the Authorization header, and that the session it was issued for
+// hasn't been revoked by a logout, a password change or the deletion of the account.
+// When ok is false, an error response has already been written.
+func authenticate(w http.ResponseWriter, r *http.Request, jwtKeys *jwtkeys.Keyring, db *db.Queries) (claims *jwtLesVieuxClaims, ok bool) {
+	claims, err := getClaimsFromAuthorizationHeader(r.Header.Get("Authorization"), jwtKeys)
+	if err != nil {
+		writeError(w, http.StatusUnauthorized, "auth failed: %s", err)
+		return nil, false
+	}
+	err = checkSession(context.Background(), db, claims)
+	if err != nil {
+		if errors.Is(err, errSessionRevoked) {
+			writeError(w, http.StatusUnauthorized, "auth failed: %s", err)
+			return nil, false
+		}
+		logError(r, "couldn't retrieve session", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return nil, false
+	}
+	return claims, true
+}
+
+// withSession sets the user ID, role and session ID of the claims in the context.
+func withSession(ctx context.Context, claims *jwtLesVieuxClaims) context.Context {
+	setRequestPrincipal(ctx, claims.ID, claims.Role)
+	ctx = context.WithValue(ctx, userIDKey, claims.ID)
+	ctx = context.WithValue(ctx, roleKey, claims.Role)
+	return context.WithValue(ctx, sessionIDKey, claims.SessionID)
+}
+
+func getClaimsFromAuthorizationHeader(header string, jwtKeys *jwtkeys.Keyring) (*jwtLesVieuxClaims, error) {
 	if header == "" {
 		return nil, fmt.Errorf("authorization header not found")
 	}
@@ -98,26 +244,39 @@ func getClaimsFromAuthorizationHeader(header string, jwtSecret []byte) (*jwtLesV
 	if len(bearerToken) != 2 || bearerToken[0] != "Bearer" {
 		return nil, fmt.Errorf("authorization header couldn't be processed. The expected format is 'Bearer <token>'")
 	}
-	claims, err := getClaimsFromJWT(bearerToken[1], jwtSecret)
+	claims, err := getClaimsFromJWT(bearerToken[1], jwtKeys)
 	if err != nil {
 		return nil, fmt.Errorf("token is not valid: %s", err)
 	}
 	return claims, nil
 }
 
-func getClaimsFromJWT(bearerToken string, jwtSecret []byte) (*jwtLesVieuxClaims, error) {
+func getClaimsFromJWT(bearerToken string, jwtKeys *jwtkeys.Keyring) (*jwtLesVieuxClaims, error) {
 	claims := jwtLesVieuxClaims{}
-	token, err := jwt.ParseWithClaims(bearerToken, &claims, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return jwtSecret, nil
-	})
+	token, err := jwt.ParseWithClaims(bearerToken, &claims, jwtKeyFunc(jwtKeys))
 	if err != nil {
 		return nil, err
 	}
-	if !token.Valid {
+	if !token.Valid || claims.Audience != "" {
 		return nil, errors.New("invalid token")
 	}
 	return &claims, nil
 }
+
+// jwtKeyFunc returns the key that verifies a token, the key of the keyring named by its kid header.
+func jwtKeyFunc(jwtKeys *jwtkeys.Keyring) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		kid, ok := token.Header["kid"].(string)
+		if !ok {
+			return nil, errors.New("token has no key id")
+		}
+		secret, ok := jwtKeys.VerificationKey(kid)
+		if !ok {
+			return nil, fmt.Errorf("unknown key id %q", kid)
+		}
+		return secret, nil
+	}
+}
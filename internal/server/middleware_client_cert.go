@@ -0,0 +1,35 @@
+package server
+
+import (
+	"context"
+	"net/http"
+)
+
+const adminClientCertContextKey = contextKey("adminClientCert")
+
+// The AdminClientCert middleware marks requests as requiring a client certificate to reach the admin API,
+// when required is set. Certificates are verified against the client authorities by the TLS handshake:
+// the admin middlewares and the admin login then check that one was given, with checkAdminClientCert.
+func adminClientCertMiddleware(required bool) middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if required {
+				r = r.WithContext(context.WithValue(r.Context(), adminClientCertContextKey, true))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// checkAdminClientCert reports whether a request can reach the admin API: either client certificates aren't
+// required, or the request came with a verified one. When it returns false, an error response has been written.
+func checkAdminClientCert(w http.ResponseWriter, r *http.Request) bool {
+	if required, _ := r.Context().Value(adminClientCertContextKey).(bool); !required {
+		return true
+	}
+	if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 {
+		return true
+	}
+	writeError(w, http.StatusForbidden, "forbidden: client certificate required")
+	return false
+}
@@ -1,16 +1,121 @@
 package server
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
 	"net/http"
+	"strings"
+	"time"
 )
 
-// The Logging middleware captures any http request coming through and the response status code, and logs it.
-func loggingMiddleware(ctx *loggingMiddlewareContext) middleware {
+// requestIDHeader carries the ID of a request. The ID sent by a client or a proxy in front of the server is kept
+// when it is valid, otherwise one is generated. It is sent back on every response.
+const requestIDHeader = "X-Request-ID"
+
+const loggingContextKey = contextKey("logging")
+
+// The Logging middleware gives every request an ID and a logger carrying it, and logs each request once it is handled:
+// its method, route, status code, latency, the size of the response, the address of the client and the account
+// it was authenticated as.
+// route names the route a request matched.
+func loggingMiddleware(logger *slog.Logger, route func(*http.Request) string) middleware {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			requestID := r.Header.Get(requestIDHeader)
+			if !isValidRequestID(requestID) {
+				requestID = newRequestID()
+			}
+			w.Header().Set(requestIDHeader, requestID)
+			ctx := &loggingMiddlewareContext{
+				requestID: requestID,
+				logger:    logger.With("request_id", requestID),
+			}
 			clonedWriter := newResponseWriter(w)
-			next.ServeHTTP(clonedWriter, r)
-			ctx.responseStatusCode = clonedWriter.statusCode
+			next.ServeHTTP(clonedWriter, r.WithContext(context.WithValue(r.Context(), loggingContextKey, ctx)))
+
+			attrs := []any{
+				"method", r.Method,
+				"route", route(r),
+				"status", clonedWriter.statusCode,
+				"latency_ms", float64(time.Since(start).Microseconds()) / 1000,
+				"bytes", clonedWriter.bytes,
+				"client_ip", clientIP(r),
+				"scheme", requestScheme(r),
+			}
+			if ctx.authenticated {
+				attrs = append(attrs, "user_id", ctx.userID, "role", ctx.role)
+			}
+			if clonedWriter.errorMessage != "" {
+				attrs = append(attrs, "error", clonedWriter.errorMessage)
+			}
+			level := slog.LevelInfo
+			if clonedWriter.statusCode >= http.StatusInternalServerError {
+				level = slog.LevelError
+			}
+			ctx.logger.Log(r.Context(), level, "request", attrs...)
 		})
 	}
 }
+
+// routeOf returns a function naming the route of router a request matches by its pattern, without the method,
+// prefixed with the path router is served under: /api/v1/employers/{employer_id}.
+// Requests that match no route are named "unmatched", so that unknown paths don't each get a name of their own.
+func routeOf(prefix string, router *http.ServeMux) func(*http.Request) string {
+	return func(r *http.Request) string {
+		_, pattern := router.Handler(r)
+		if pattern == "" {
+			return "unmatched"
+		}
+		if _, path, ok := strings.Cut(pattern, " "); ok {
+			pattern = path
+		}
+		return prefix + pattern
+	}
+}
+
+// requestLogger returns the logger of a request, which adds the ID of the request to every record.
+func requestLogger(r *http.Request) *slog.Logger {
+	if ctx, ok := r.Context().Value(loggingContextKey).(*loggingMiddlewareContext); ok {
+		return ctx.logger
+	}
+	return slog.Default()
+}
+
+// logError logs an error that occurred while handling a request, along with the ID of the request.
+func logError(r *http.Request, msg string, err error) {
+	requestLogger(r).Error(msg, "error", err)
+}
+
+// setRequestPrincipal records the account a request is authenticated as, for the logging middleware to log.
+func setRequestPrincipal(ctx context.Context, userID int64, role int64) {
+	if lc, ok := ctx.Value(loggingContextKey).(*loggingMiddlewareContext); ok {
+		lc.authenticated = true
+		lc.userID = userID
+		lc.role = role
+	}
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+// isValidRequestID reports whether a request ID received from a client can be used as is:
+// it must be short and only hold characters that are safe to write in logs and headers.
+func isValidRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for _, c := range id {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.ContainsRune("-_.:", c)) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,158 @@
+package server_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gruyaume/lesvieux/internal/jwtkeys"
+	"github.com/gruyaume/lesvieux/internal/server"
+)
+
+// logRecorder collects the JSON records of a logger, which the logging middleware writes once responses are sent.
+type logRecorder struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (l *logRecorder) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.buf.Write(p)
+}
+
+// requestRecord waits for the record of the request with the given ID.
+func (l *logRecorder) requestRecord(t *testing.T, requestID string) map[string]any {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		l.mu.Lock()
+		lines := bytes.Split(l.buf.Bytes(), []byte("\n"))
+		l.mu.Unlock()
+		for _, line := range lines {
+			var record map[string]any
+			if json.Unmarshal(line, &record) != nil {
+				continue
+			}
+			if record["msg"] == "request" && record["request_id"] == requestID {
+				return record
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("no request logged with ID %s", requestID)
+	return nil
+}
+
+func setupServerWithLogs() (*httptest.Server, *logRecorder, error) {
+	dbQueries, err := setupDatabase()
+	if err != nil {
+		return nil, nil, err
+	}
+	keys, err := jwtkeys.Generate()
+	if err != nil {
+		return nil, nil, err
+	}
+	logs := &logRecorder{}
+	config := &server.HandlerConfig{
+		DBQueries: dbQueries,
+		JWTKeys:   jwtkeys.NewKeyring(keys),
+		Logger:    slog.New(slog.NewJSONHandler(logs, nil)),
+	}
+	return httptest.NewTLSServer(server.NewLesVieuxRouter(config)), logs, nil
+}
+
+func sendWithRequestID(client *http.Client, url string, token string, requestID string) (*http.Response, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if requestID != "" {
+		req.Header.Set("X-Request-ID", requestID)
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	res.Body.Close()
+	return res, nil
+}
+
+func TestRequestLogging(t *testing.T) {
+	ts, logs, err := setupServerWithLogs()
+	if err != nil {
+		t.Fatalf("couldn't create test server: %s", err)
+	}
+	defer ts.Close()
+	client := ts.Client()
+	var adminToken string
+	t.Run("prepare admin account", prepareAdminAccount(ts.URL, client, &adminToken))
+
+	t.Run("request IDs are generated", func(t *testing.T) {
+		res, err := sendWithRequestID(client, ts.URL+"/api/v1/employers", adminToken, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		requestID := res.Header.Get("X-Request-ID")
+		if !regexp.MustCompile(`^[0-9a-f]{32}$`).MatchString(requestID) {
+			t.Fatalf("expected a generated request ID, got %q", requestID)
+		}
+		record := logs.requestRecord(t, requestID)
+		if record["method"] != "GET" || record["route"] != "/api/v1/employers" || record["status"] != float64(http.StatusOK) {
+			t.Fatalf("unexpected request record: %v", record)
+		}
+		if record["user_id"] != float64(1) || record["role"] != float64(1) {
+			t.Fatalf("expected the request to be logged with the admin account, got %v", record)
+		}
+		if record["bytes"].(float64) == 0 {
+			t.Fatalf("expected the size of the response to be logged, got %v", record)
+		}
+		if _, ok := record["latency_ms"]; !ok {
+			t.Fatalf("expected the latency to be logged, got %v", record)
+		}
+	})
+
+	t.Run("request IDs are propagated", func(t *testing.T) {
+		res, err := sendWithRequestID(client, ts.URL+"/api/v1/employers/42", adminToken, "proxy-request-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Header.Get("X-Request-ID") != "proxy-request-1" {
+			t.Fatalf("expected the request ID to be sent back, got %q", res.Header.Get("X-Request-ID"))
+		}
+		record := logs.requestRecord(t, "proxy-request-1")
+		if record["route"] != "/api/v1/employers/{employer_id}" || record["status"] != float64(http.StatusNotFound) {
+			t.Fatalf("unexpected request record: %v", record)
+		}
+		if record["error"] != "Employer not found" {
+			t.Fatalf("expected the error to be logged with the request, got %v", record)
+		}
+	})
+
+	t.Run("invalid request IDs are replaced", func(t *testing.T) {
+		res, err := sendWithRequestID(client, ts.URL+"/api/v1/nowhere", "", "bad id\twith spaces")
+		if err != nil {
+			t.Fatal(err)
+		}
+		requestID := res.Header.Get("X-Request-ID")
+		if requestID == "" || requestID == "bad id\twith spaces" {
+			t.Fatalf("expected a generated request ID, got %q", requestID)
+		}
+		record := logs.requestRecord(t, requestID)
+		if record["route"] != "unmatched" {
+			t.Fatalf("expected unknown paths not to be logged as routes, got %v", record)
+		}
+		if _, ok := record["user_id"]; ok {
+			t.Fatalf("expected an anonymous request, got %v", record)
+		}
+	})
+}
@@ -4,17 +4,20 @@ import (
 	"net/http"
 
 	"github.com/gruyaume/lesvieux/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 // The Metrics middleware captures any request relevant to a metric and records it for prometheus.
-func metricsMiddleware(metrics *metrics.PrometheusMetrics) middleware {
+// Requests are labeled with the route they match, as named by route.
+func metricsMiddleware(metrics *metrics.PrometheusMetrics, route func(*http.Request) string) middleware {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			labels := prometheus.Labels{"route": route(r)}
 			base := promhttp.InstrumentHandlerCounter(
-				&metrics.RequestsTotal,
+				metrics.RequestsTotal.MustCurryWith(labels),
 				promhttp.InstrumentHandlerDuration(
-					&metrics.RequestsDuration,
+					metrics.RequestsDuration.MustCurryWith(labels),
 					next,
 				),
 			)
@@ -0,0 +1,83 @@
+package server_test
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func getMetrics(url string, client *http.Client) (string, error) {
+	res, err := client.Get(url + "/metrics")
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
+
+func TestMetricsEndToEnd(t *testing.T) {
+	ts, _, err := setupServer()
+	if err != nil {
+		t.Fatalf("couldn't create test server: %s", err)
+	}
+	defer ts.Close()
+	client := ts.Client()
+	var adminToken string
+	var employerToken string
+	var applicantToken string
+	t.Run("prepare admin account", prepareAdminAccount(ts.URL, client, &adminToken))
+	t.Run("prepare employer account", prepareEmployerAccount(ts.URL, client, &adminToken, &employerToken))
+	t.Run("prepare applicant account", prepareApplicantAccount(ts.URL, client, &applicantToken))
+
+	t.Run("failed login and application", func(t *testing.T) {
+		statusCode, _, err := adminLogin(ts.URL, client, &AdminLoginParams{Email: adminUser.Email, Password: "wrong password"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if statusCode != http.StatusUnauthorized {
+			t.Fatalf("expected status %d, got %d", http.StatusUnauthorized, statusCode)
+		}
+		id, err := preparePublishedJobPost(ts.URL, client, adminToken, employerToken, &UpdateJobPostParams{Title: "Gardener", Content: "Tend the garden.", Status: "pending_review"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		statusCode, _, err = applyToJobPost(ts.URL, client, applicantToken, id, &CreateApplicationParams{CoverMessage: "I love gardens."})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if statusCode != http.StatusCreated {
+			t.Fatalf("expected status %d, got %d", http.StatusCreated, statusCode)
+		}
+	})
+
+	t.Run("metrics are reported", func(t *testing.T) {
+		body, err := getMetrics(ts.URL, client)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := []string{
+			`logins_total{result="succeeded",role="admin"} 1`,
+			`logins_total{result="failed",role="admin"} 1`,
+			`logins_total{result="succeeded",role="employer"} 1`,
+			`logins_total{result="succeeded",role="applicant"} 1`,
+			`applications_submitted_total 1`,
+			`job_posts_by_status{status="published"} 1`,
+			`accounts_total{role="applicant"} 1`,
+			`http_requests_total{code="201",method="post",route="/api/v1/posts/{post_id}/applications"} 1`,
+			`http_request_duration_seconds_bucket{code="200",method="post",route="/api/v1/employers/login",le="0.001"}`,
+		}
+		for _, line := range expected {
+			if !strings.Contains(body, line) {
+				t.Errorf("expected %q in the metrics", line)
+			}
+		}
+		if strings.Contains(body, `route="/api/v1/posts/1/applications"`) {
+			t.Errorf("requests should be labeled with their route, not their path")
+		}
+	})
+}
@@ -0,0 +1,104 @@
+package server
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+const forwardedContextKey = contextKey("forwarded")
+
+// forwarded is where a request comes from: the IP address of the client and the scheme it was sent with.
+// Behind a trusted reverse proxy, they are read from the X-Forwarded-For and X-Forwarded-Proto headers.
+type forwarded struct {
+	clientIP string
+	scheme   string
+}
+
+// The Proxy middleware finds out where each request comes from. The forwarded headers are only read when the
+// request is sent by one of the trusted proxies, since anyone can send them.
+func proxyMiddleware(trustedProxies []netip.Prefix) middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			f := forwarded{clientIP: remoteIP(r), scheme: "http"}
+			if r.TLS != nil {
+				f.scheme = "https"
+			}
+			if addr, err := netip.ParseAddr(f.clientIP); err == nil && isTrusted(trustedProxies, addr) {
+				f.clientIP = forwardedFor(trustedProxies, addr, r.Header.Values("X-Forwarded-For")).String()
+				if proto := lastValue(r.Header.Values("X-Forwarded-Proto")); proto == "http" || proto == "https" {
+					f.scheme = proto
+				}
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), forwardedContextKey, f)))
+		})
+	}
+}
+
+// forwardedFor returns the address of the client in the X-Forwarded-For headers of a request sent by a trusted proxy.
+// Each proxy appends the address it received the request from, so addresses are read from the last one,
+// skipping the trusted proxies. The first address that isn't trusted is the client: the ones before it could
+// have been sent by the client itself.
+func forwardedFor(trustedProxies []netip.Prefix, remote netip.Addr, headers []string) netip.Addr {
+	client := remote
+	hops := strings.Split(strings.Join(headers, ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			break
+		}
+		client = addr.Unmap()
+		if !isTrusted(trustedProxies, client) {
+			break
+		}
+	}
+	return client
+}
+
+func isTrusted(trustedProxies []netip.Prefix, addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func lastValue(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	parts := strings.Split(values[len(values)-1], ",")
+	return strings.ToLower(strings.TrimSpace(parts[len(parts)-1]))
+}
+
+// clientIP returns the IP address of the client that sent the request, as found by the proxy middleware.
+func clientIP(r *http.Request) string {
+	if f, ok := r.Context().Value(forwardedContextKey).(forwarded); ok {
+		return f.clientIP
+	}
+	return remoteIP(r)
+}
+
+// requestScheme returns the scheme the client sent the request with, http or https.
+func requestScheme(r *http.Request) string {
+	if f, ok := r.Context().Value(forwardedContextKey).(forwarded); ok {
+		return f.scheme
+	}
+	if r.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
+
+// remoteIP returns the IP address the connection of the request comes from.
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,82 @@
+package server_test
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/gruyaume/lesvieux/internal/jwtkeys"
+	"github.com/gruyaume/lesvieux/internal/server"
+)
+
+func setupServerBehindProxies(trustedProxies []netip.Prefix) (*httptest.Server, *logRecorder, error) {
+	dbQueries, err := setupDatabase()
+	if err != nil {
+		return nil, nil, err
+	}
+	keys, err := jwtkeys.Generate()
+	if err != nil {
+		return nil, nil, err
+	}
+	logs := &logRecorder{}
+	config := &server.HandlerConfig{
+		DBQueries:      dbQueries,
+		JWTKeys:        jwtkeys.NewKeyring(keys),
+		Logger:         slog.New(slog.NewJSONHandler(logs, nil)),
+		TrustedProxies: trustedProxies,
+	}
+	return httptest.NewTLSServer(server.NewLesVieuxRouter(config)), logs, nil
+}
+
+func TestForwardedHeaders(t *testing.T) {
+	cases := []struct {
+		Name             string
+		TrustedProxies   []string
+		ForwardedFor     []string
+		ForwardedProto   string
+		ExpectedClientIP string
+		ExpectedScheme   string
+	}{
+		{"no trusted proxies", nil, []string{"203.0.113.7"}, "http", "127.0.0.1", "https"},
+		{"untrusted proxy", []string{"10.0.0.0/8"}, []string{"203.0.113.7"}, "http", "127.0.0.1", "https"},
+		{"trusted proxy", []string{"127.0.0.1/32"}, []string{"203.0.113.7"}, "http", "203.0.113.7", "http"},
+		{"chain of trusted proxies", []string{"127.0.0.0/8", "10.0.0.0/8"}, []string{"198.51.100.1, 203.0.113.7", "10.0.0.2"}, "https", "203.0.113.7", "https"},
+		{"only trusted proxies", []string{"127.0.0.0/8", "10.0.0.0/8"}, []string{"10.0.0.3, 10.0.0.2"}, "", "10.0.0.3", "https"},
+		{"invalid address", []string{"127.0.0.0/8"}, []string{"203.0.113.7, unknown"}, "ftp", "127.0.0.1", "https"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			var trustedProxies []netip.Prefix
+			for _, proxy := range tc.TrustedProxies {
+				trustedProxies = append(trustedProxies, netip.MustParsePrefix(proxy))
+			}
+			ts, logs, err := setupServerBehindProxies(trustedProxies)
+			if err != nil {
+				t.Fatalf("couldn't create test server: %s", err)
+			}
+			defer ts.Close()
+			req, err := http.NewRequest("GET", ts.URL+"/api/v1/status", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("X-Request-ID", "forwarded")
+			for _, forwardedFor := range tc.ForwardedFor {
+				req.Header.Add("X-Forwarded-For", forwardedFor)
+			}
+			if tc.ForwardedProto != "" {
+				req.Header.Set("X-Forwarded-Proto", tc.ForwardedProto)
+			}
+			res, err := ts.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res.Body.Close()
+			record := logs.requestRecord(t, "forwarded")
+			if record["client_ip"] != tc.ExpectedClientIP || record["scheme"] != tc.ExpectedScheme {
+				t.Fatalf("expected client %s over %s, got %v over %v", tc.ExpectedClientIP, tc.ExpectedScheme, record["client_ip"], record["scheme"])
+			}
+		})
+	}
+}
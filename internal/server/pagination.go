@@ -0,0 +1,166 @@
+package server
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 100
+)
+
+// lastCursorKey sorts after any key stored in the database.
+// Descending pages without a cursor start from it.
+const lastCursorKey = "\U0010FFFF"
+
+// pageCursor is the position of the last item of a page, in the sort order of that page.
+// It is handed to clients as an opaque string, see encodePageCursor.
+type pageCursor struct {
+	Sort string `json:"s"`
+	Key  string `json:"k"`
+	ID   int64  `json:"i"`
+}
+
+func encodePageCursor(c pageCursor) string {
+	b, err := json.Marshal(c)
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodePageCursor(s string) (pageCursor, error) {
+	var c pageCursor
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, err
+	}
+	err = json.Unmarshal(b, &c)
+	return c, err
+}
+
+// pageRequest holds the limit, sort and cursor query parameters of a list request.
+//
+// Sorts are the name of the field to sort on, prefixed with "-" for descending order.
+// Every sort is tie-broken on the id so that pages never skip or repeat items.
+type pageRequest struct {
+	Limit  int64
+	Sort   string
+	cursor *pageCursor
+}
+
+// parsePageRequest reads the pagination query parameters of r.
+// sorts lists the sort values the endpoint accepts, the first one being the default.
+func parsePageRequest(r *http.Request, sorts ...string) (pageRequest, error) {
+	query := r.URL.Query()
+	page := pageRequest{Limit: defaultPageLimit, Sort: query.Get("sort")}
+	if limit := query.Get("limit"); limit != "" {
+		limitInt, err := strconv.ParseInt(limit, 10, 64)
+		if err != nil || limitInt < 1 || limitInt > maxPageLimit {
+			return page, fmt.Errorf("limit must be an integer between 1 and %d", maxPageLimit)
+		}
+		page.Limit = limitInt
+	}
+	if cursor := query.Get("cursor"); cursor != "" {
+		c, err := decodePageCursor(cursor)
+		if err != nil {
+			return page, errors.New("Invalid cursor")
+		}
+		if page.Sort == "" {
+			page.Sort = c.Sort
+		}
+		if c.Sort != page.Sort {
+			return page, errors.New("cursor doesn't match sort")
+		}
+		page.cursor = &c
+	}
+	if page.Sort == "" {
+		page.Sort = sorts[0]
+	}
+	for _, sort := range sorts {
+		if page.Sort == sort {
+			return page, nil
+		}
+	}
+	return page, fmt.Errorf("Invalid sort: must be one of %s", strings.Join(sorts, ", "))
+}
+
+// Descending reports whether the page is sorted in descending order.
+func (p pageRequest) Descending() bool {
+	return strings.HasPrefix(p.Sort, "-")
+}
+
+// CursorKey and CursorID return the position the page starts after.
+// Without a cursor, that is before the first item in the sort order.
+func (p pageRequest) CursorKey() string {
+	if p.cursor != nil {
+		return p.cursor.Key
+	}
+	if p.Descending() {
+		return lastCursorKey
+	}
+	return ""
+}
+
+func (p pageRequest) CursorID() int64 {
+	if p.cursor != nil {
+		return p.cursor.ID
+	}
+	if p.Descending() {
+		return math.MaxInt64
+	}
+	return 0
+}
+
+// FetchLimit is the number of rows to query for the page.
+// The extra row tells whether there is a next page.
+func (p pageRequest) FetchLimit() int64 {
+	return p.Limit + 1
+}
+
+// paginate trims rows fetched with FetchLimit down to the page, and returns the cursor
+// of the next page, or an empty string on the last page. key returns the sort key and id of a row.
+func paginate[T any](page pageRequest, rows []T, key func(T) (string, int64)) ([]T, string) {
+	if int64(len(rows)) <= page.Limit {
+		return rows, ""
+	}
+	rows = rows[:page.Limit]
+	k, id := key(rows[len(rows)-1])
+	return rows, encodePageCursor(pageCursor{Sort: page.Sort, Key: k, ID: id})
+}
+
+// parseIntFilter reads an optional integer query parameter.
+func parseIntFilter(r *http.Request, name string) (sql.NullInt64, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return sql.NullInt64{}, nil
+	}
+	valueInt, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return sql.NullInt64{}, fmt.Errorf("%s must be an integer", name)
+	}
+	return sql.NullInt64{Int64: valueInt, Valid: true}, nil
+}
+
+// parseTimeFilter reads an optional RFC3339 query parameter, and converts it to UTC
+// so that it compares with the timestamps stored in the database.
+func parseTimeFilter(r *http.Request, name string) (sql.NullString, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return sql.NullString{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return sql.NullString{}, fmt.Errorf("%s must be an RFC3339 timestamp", name)
+	}
+	return sql.NullString{String: t.UTC().Format(time.RFC3339), Valid: true}, nil
+}
@@ -0,0 +1,88 @@
+package server
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimit lets Burst requests through at once, then one more every Interval.
+type RateLimit struct {
+	Burst    int
+	Interval time.Duration
+}
+
+// withDefaults returns the limit with each field that isn't positive set to that of defaults.
+func (l RateLimit) withDefaults(defaults RateLimit) RateLimit {
+	if l.Burst <= 0 {
+		l.Burst = defaults.Burst
+	}
+	if l.Interval <= 0 {
+		l.Interval = defaults.Interval
+	}
+	return l
+}
+
+// rateLimiter keeps a token bucket for each key, such as a client address.
+// Buckets are created on first use and dropped once they are full again, so that idle keys cost nothing.
+type rateLimiter struct {
+	limit RateLimit
+
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+	sweptAt time.Time
+}
+
+type tokenBucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+func newRateLimiter(limit RateLimit) *rateLimiter {
+	return &rateLimiter{limit: limit, buckets: map[string]*tokenBucket{}}
+}
+
+// refill returns the tokens of the bucket at now, as tokens are added every interval up to the burst.
+func (l *rateLimiter) refill(b *tokenBucket, now time.Time) float64 {
+	tokens := b.tokens + float64(now.Sub(b.updatedAt))/float64(l.limit.Interval)
+	return min(tokens, float64(l.limit.Burst))
+}
+
+// allow takes a token from the bucket of key. When the bucket is empty, it returns false and how long until
+// the next token.
+func (l *rateLimiter) allow(key string, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sweep(now)
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: float64(l.limit.Burst), updatedAt: now}
+		l.buckets[key] = b
+	}
+	b.tokens = l.refill(b, now)
+	b.updatedAt = now
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) * float64(l.limit.Interval))
+	}
+	b.tokens--
+	return true, 0
+}
+
+// reset refills the bucket of key.
+func (l *rateLimiter) reset(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.buckets, key)
+}
+
+// sweep drops the buckets that are full again, at most once a minute.
+func (l *rateLimiter) sweep(now time.Time) {
+	if now.Sub(l.sweptAt) < time.Minute {
+		return
+	}
+	l.sweptAt = now
+	for key, b := range l.buckets {
+		if l.refill(b, now) >= float64(l.limit.Burst) {
+			delete(l.buckets, key)
+		}
+	}
+}
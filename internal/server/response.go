@@ -3,16 +3,22 @@ package server
 import (
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 )
 
 // writeJSON is a helper function that writes a JSON response to the http.ResponseWriter
 func writeJSON(w http.ResponseWriter, v any) error {
+	return writeJSONPage(w, v, "")
+}
+
+// writeJSONPage writes a page of a list as a JSON response, along with the cursor of the next page if there is one
+func writeJSONPage(w http.ResponseWriter, v any, nextCursor string) error {
 	type response struct {
-		Result any `json:"result,omitempty"`
+		Result     any    `json:"result,omitempty"`
+		NextCursor string `json:"next_cursor,omitempty"`
 	}
-	resp := response{Result: v}
+	resp := response{Result: v, NextCursor: nextCursor}
 	respBytes, err := json.Marshal(&resp)
 	if err != nil {
 		return err
@@ -24,25 +30,30 @@ func writeJSON(w http.ResponseWriter, v any) error {
 	return nil
 }
 
-// writeError is a helper function that logs any error and writes it back as an http response
+// writeError is a helper function that writes an error back as an http response.
+// The error is logged by the logging middleware along with the request, or right away outside of it.
 func writeError(w http.ResponseWriter, status int, format string, args ...any) {
 	type errorResponse struct {
 		Error string `json:"error"`
 	}
 
 	errorMessage := fmt.Sprintf(format, args...)
-	log.Println(errorMessage)
+	if recorder, ok := w.(*statusRecorder); ok {
+		recorder.errorMessage = errorMessage
+	} else {
+		slog.Warn("request failed", "status", status, "error", errorMessage)
+	}
 
 	resp := errorResponse{Error: errorMessage}
 	respBytes, err := json.Marshal(&resp)
 	if err != nil {
-		log.Printf("Error marshalling error response: %v", err)
+		slog.Error("couldn't marshal error response", "error", err)
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 	w.WriteHeader(status)
 	_, err = w.Write(respBytes)
 	if err != nil {
-		log.Printf("Error writing error response: %v", err)
+		slog.Error("couldn't write error response", "error", err)
 	}
 }
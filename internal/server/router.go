@@ -1,61 +1,140 @@
 package server
 
 import (
+	"context"
+	"log/slog"
 	"net/http"
 
 	"github.com/gruyaume/lesvieux/internal/metrics"
 )
 
 func NewLesVieuxRouter(config *HandlerConfig) http.Handler {
+	if config.Metrics == nil {
+		config.Metrics = metrics.NewMetricsSubsystem(context.Background(), config.DBQueries)
+	}
+	config.logins = newLoginGuard(config.LoginLimits, config.DBQueries, config.Metrics)
 	apiV1Router := http.NewServeMux()
 
 	// No Auth
 	apiV1Router.HandleFunc("POST /employers/login", EmployersLogin(config))
 	apiV1Router.HandleFunc("POST /admin/login", AdminLogin(config))
+	apiV1Router.HandleFunc("POST /applicants/login", ApplicantsLogin(config))
+	apiV1Router.HandleFunc("POST /applicants/accounts", CreateApplicantAccount(config))
+	apiV1Router.HandleFunc("POST /auth/refresh", RefreshSession(config))
+	apiV1Router.HandleFunc("POST /auth/mfa/verify", VerifyMFA(config))
 	apiV1Router.HandleFunc("GET /status", GetStatus(config))
 	apiV1Router.HandleFunc("GET /posts", ListJobPosts(config))
+	apiV1Router.HandleFunc("GET /posts/search", SearchJobPosts(config))
+	apiV1Router.HandleFunc("GET /posts/{post_id}", GetPublicJobPost(config))
 
 	// Admin or First User
-	apiV1Router.HandleFunc("POST /admin/accounts", adminOrFirstUser(config.JWTSecret, config.DBQueries, CreateAdminAccount(config)))
+	apiV1Router.HandleFunc("POST /admin/accounts", adminOrFirstUser(config.JWTKeys, config.DBQueries, CreateAdminAccount(config)))
 
 	// Admin Only
-	apiV1Router.HandleFunc("GET /posts/{post_id}", adminOnly(config.JWTSecret, GetJobPost(config)))
-	apiV1Router.HandleFunc("POST /employers", adminOnly(config.JWTSecret, CreateEmployer(config)))
-	apiV1Router.HandleFunc("GET /employers", adminOnly(config.JWTSecret, ListEmployers(config)))
-	apiV1Router.HandleFunc("GET /employers/{employer_id}", adminOnly(config.JWTSecret, GetEmployer(config)))
-	apiV1Router.HandleFunc("DELETE /employers/{employer_id}", adminOnly(config.JWTSecret, DeleteEmployer(config)))
-	apiV1Router.HandleFunc("GET /employers/{employer_id}/accounts", adminOnly(config.JWTSecret, ListEmployerAccounts(config)))
-	apiV1Router.HandleFunc("POST /employers/{employer_id}/accounts", adminOnly(config.JWTSecret, CreateEmployerAccount(config)))
-	apiV1Router.HandleFunc("GET /employers/{employer_id}/accounts/{account_id}", adminOnly(config.JWTSecret, GetEmployerAccount(config)))
-	apiV1Router.HandleFunc("DELETE /employers/{employer_id}/accounts/{account_id}", adminOnly(config.JWTSecret, DeleteEmployerAccount(config)))
-	apiV1Router.HandleFunc("POST /employers/{employer_id}/accounts/{account_id}/change_password", adminOnly(config.JWTSecret, ChangeEmployerAccountPassword(config)))
-	apiV1Router.HandleFunc("GET /admin/accounts", adminOnly(config.JWTSecret, ListAdminAccounts(config)))
-	apiV1Router.HandleFunc("GET /admin/accounts/{account_id}", adminOnly(config.JWTSecret, GetAdminAccount(config)))
-	apiV1Router.HandleFunc("DELETE /admin/accounts/{account_id}", adminOnly(config.JWTSecret, DeleteAdminAccount(config)))
-	apiV1Router.HandleFunc("POST /admin/accounts/{account_id}/change_password", adminOnly(config.JWTSecret, ChangeAdminAccountPassword(config)))
-
-	// Admin (Me) Only
-	apiV1Router.HandleFunc("GET /employers/accounts/me", Me(config.JWTSecret, GetMyEmployerAccount(config)))
-	apiV1Router.HandleFunc("POST /employers/accounts/me/change_password", Me(config.JWTSecret, ChangeMyEmployerAccountPassword(config)))
-	apiV1Router.HandleFunc("GET /admin/accounts/me", Me(config.JWTSecret, GetMyAdminAccount(config)))
-	apiV1Router.HandleFunc("POST /admin/accounts/me/change_password", Me(config.JWTSecret, ChangeMyAdminAccountPassword(config)))
+	apiV1Router.HandleFunc("DELETE /posts/{post_id}", adminOnly(config.JWTKeys, config.DBQueries, DeleteJobPost(config)))
+	apiV1Router.HandleFunc("GET /moderation/posts", adminOnly(config.JWTKeys, config.DBQueries, ListPendingJobPosts(config)))
+	apiV1Router.HandleFunc("POST /moderation/posts/approve", adminOnly(config.JWTKeys, config.DBQueries, BulkApproveJobPosts(config)))
+	apiV1Router.HandleFunc("GET /moderation/posts/{post_id}", adminOnly(config.JWTKeys, config.DBQueries, GetJobPost(config)))
+	apiV1Router.HandleFunc("GET /moderation/posts/{post_id}/history", adminOnly(config.JWTKeys, config.DBQueries, ListJobPostHistory(config)))
+	apiV1Router.HandleFunc("POST /moderation/posts/{post_id}/approve", adminOnly(config.JWTKeys, config.DBQueries, ApproveJobPost(config)))
+	apiV1Router.HandleFunc("POST /moderation/posts/{post_id}/reject", adminOnly(config.JWTKeys, config.DBQueries, RejectJobPost(config)))
+	apiV1Router.HandleFunc("POST /moderation/posts/{post_id}/takedown", adminOnly(config.JWTKeys, config.DBQueries, TakedownJobPost(config)))
+	apiV1Router.HandleFunc("POST /employers", adminOnly(config.JWTKeys, config.DBQueries, CreateEmployer(config)))
+	apiV1Router.HandleFunc("GET /employers", adminOnly(config.JWTKeys, config.DBQueries, ListEmployers(config)))
+	apiV1Router.HandleFunc("GET /employers/{employer_id}", adminOnly(config.JWTKeys, config.DBQueries, GetEmployer(config)))
+	apiV1Router.HandleFunc("DELETE /employers/{employer_id}", adminOnly(config.JWTKeys, config.DBQueries, DeleteEmployer(config)))
+	apiV1Router.HandleFunc("GET /employers/{employer_id}/accounts", adminOnly(config.JWTKeys, config.DBQueries, ListEmployerAccounts(config)))
+	apiV1Router.HandleFunc("POST /employers/{employer_id}/accounts", adminOnly(config.JWTKeys, config.DBQueries, CreateEmployerAccount(config)))
+	apiV1Router.HandleFunc("GET /employers/{employer_id}/accounts/{account_id}", adminOnly(config.JWTKeys, config.DBQueries, GetEmployerAccount(config)))
+	apiV1Router.HandleFunc("DELETE /employers/{employer_id}/accounts/{account_id}", adminOnly(config.JWTKeys, config.DBQueries, DeleteEmployerAccount(config)))
+	apiV1Router.HandleFunc("POST /employers/{employer_id}/accounts/{account_id}/change_password", adminOnly(config.JWTKeys, config.DBQueries, ChangeEmployerAccountPassword(config)))
+	apiV1Router.HandleFunc("DELETE /employers/{employer_id}/accounts/{account_id}/mfa", adminOnly(config.JWTKeys, config.DBQueries, ResetEmployerAccountMFA(config)))
+	apiV1Router.HandleFunc("GET /admin/accounts", adminOnly(config.JWTKeys, config.DBQueries, ListAdminAccounts(config)))
+	apiV1Router.HandleFunc("GET /admin/accounts/{account_id}", adminOnly(config.JWTKeys, config.DBQueries, GetAdminAccount(config)))
+	apiV1Router.HandleFunc("DELETE /admin/accounts/{account_id}", adminOnly(config.JWTKeys, config.DBQueries, DeleteAdminAccount(config)))
+	apiV1Router.HandleFunc("POST /admin/accounts/{account_id}/change_password", adminOnly(config.JWTKeys, config.DBQueries, ChangeAdminAccountPassword(config)))
+	apiV1Router.HandleFunc("DELETE /admin/accounts/{account_id}/mfa", adminOnly(config.JWTKeys, config.DBQueries, ResetAdminAccountMFA(config)))
+	apiV1Router.HandleFunc("GET /admin/login_lockouts", adminOnly(config.JWTKeys, config.DBQueries, ListLoginLockouts(config)))
+	apiV1Router.HandleFunc("POST /admin/login_lockouts/unlock", adminOnly(config.JWTKeys, config.DBQueries, UnlockLogin(config)))
+	apiV1Router.HandleFunc("GET /admin/audit_events", adminOnly(config.JWTKeys, config.DBQueries, ListAuditEvents(config)))
+	apiV1Router.HandleFunc("GET /admin/backups", adminOnly(config.JWTKeys, config.DBQueries, ListBackups(config)))
+	apiV1Router.HandleFunc("POST /admin/backups", adminOnly(config.JWTKeys, config.DBQueries, CreateBackup(config)))
+
+	// Employer Only
+	apiV1Router.HandleFunc("GET /me/posts", employerOnly(config.JWTKeys, config.DBQueries, ListMyJobPosts(config)))
+	apiV1Router.HandleFunc("POST /me/posts", employerOnly(config.JWTKeys, config.DBQueries, CreateMyJobPost(config)))
+	apiV1Router.HandleFunc("GET /me/posts/{post_id}", employerOnly(config.JWTKeys, config.DBQueries, GetMyJobPost(config)))
+	apiV1Router.HandleFunc("PUT /me/posts/{post_id}", employerOnly(config.JWTKeys, config.DBQueries, UpdateMyJobPost(config)))
+	apiV1Router.HandleFunc("DELETE /me/posts/{post_id}", employerOnly(config.JWTKeys, config.DBQueries, DeleteMyJobPost(config)))
+	apiV1Router.HandleFunc("GET /me/posts/{post_id}/history", employerOnly(config.JWTKeys, config.DBQueries, ListMyJobPostHistory(config)))
+	apiV1Router.HandleFunc("GET /me/posts/{post_id}/applications", employerOnly(config.JWTKeys, config.DBQueries, ListMyJobPostApplications(config)))
+	apiV1Router.HandleFunc("GET /me/posts/{post_id}/applications/{application_id}", employerOnly(config.JWTKeys, config.DBQueries, GetMyJobPostApplication(config)))
+	apiV1Router.HandleFunc("GET /me/posts/{post_id}/applications/{application_id}/history", employerOnly(config.JWTKeys, config.DBQueries, ListMyJobPostApplicationHistory(config)))
+	apiV1Router.HandleFunc("POST /me/posts/{post_id}/applications/{application_id}/status", employerOnly(config.JWTKeys, config.DBQueries, ChangeMyJobPostApplicationStatus(config)))
+
+	// Applicant Only
+	apiV1Router.HandleFunc("GET /applicants/accounts/me", applicantOnly(config.JWTKeys, config.DBQueries, GetMyApplicantAccount(config)))
+	apiV1Router.HandleFunc("PUT /applicants/accounts/me", applicantOnly(config.JWTKeys, config.DBQueries, UpdateMyApplicantAccount(config)))
+	apiV1Router.HandleFunc("DELETE /applicants/accounts/me", applicantOnly(config.JWTKeys, config.DBQueries, DeleteMyApplicantAccount(config)))
+	apiV1Router.HandleFunc("POST /applicants/accounts/me/change_password", applicantOnly(config.JWTKeys, config.DBQueries, ChangeMyApplicantAccountPassword(config)))
+	apiV1Router.HandleFunc("POST /posts/{post_id}/applications", applicantOnly(config.JWTKeys, config.DBQueries, ApplyToJobPost(config)))
+	apiV1Router.HandleFunc("GET /me/applications", applicantOnly(config.JWTKeys, config.DBQueries, ListMyApplications(config)))
+	apiV1Router.HandleFunc("GET /me/applications/{application_id}", applicantOnly(config.JWTKeys, config.DBQueries, GetMyApplication(config)))
+	apiV1Router.HandleFunc("GET /me/applications/{application_id}/history", applicantOnly(config.JWTKeys, config.DBQueries, ListMyApplicationHistory(config)))
+
+	// Any Role
+	apiV1Router.HandleFunc("POST /auth/logout", Me(config.JWTKeys, config.DBQueries, Logout(config)))
+	apiV1Router.HandleFunc("POST /auth/logout_all", Me(config.JWTKeys, config.DBQueries, LogoutAllSessions(config)))
+
+	// Admin or Employer
+	// Logins that must enroll a second factor reach the enrollment with their challenge token
+	apiV1Router.HandleFunc("GET /auth/mfa", secondFactorOwner(config.JWTKeys, config.DBQueries, GetMyMFAStatus(config)))
+	apiV1Router.HandleFunc("POST /auth/mfa/totp", secondFactorOwner(config.JWTKeys, config.DBQueries, EnrollTOTP(config)))
+	apiV1Router.HandleFunc("POST /auth/mfa/totp/confirm", secondFactorOwner(config.JWTKeys, config.DBQueries, ConfirmTOTP(config)))
+	apiV1Router.HandleFunc("DELETE /auth/mfa/totp", secondFactorOwner(config.JWTKeys, config.DBQueries, DisableTOTP(config)))
+	apiV1Router.HandleFunc("POST /auth/mfa/recovery_codes", secondFactorOwner(config.JWTKeys, config.DBQueries, RegenerateRecoveryCodes(config)))
+
+	// Me Only
+	// Accounts of different roles can share an email, so each role only reaches its own account
+	apiV1Router.HandleFunc("GET /employers/accounts/me", employerOnly(config.JWTKeys, config.DBQueries, GetMyEmployerAccount(config)))
+	apiV1Router.HandleFunc("POST /employers/accounts/me/change_password", employerOnly(config.JWTKeys, config.DBQueries, ChangeMyEmployerAccountPassword(config)))
+	apiV1Router.HandleFunc("GET /admin/accounts/me", adminOnly(config.JWTKeys, config.DBQueries, GetMyAdminAccount(config)))
+	apiV1Router.HandleFunc("POST /admin/accounts/me/change_password", adminOnly(config.JWTKeys, config.DBQueries, ChangeMyAdminAccountPassword(config)))
 
 	frontendHandler := newFrontendFileServer()
 
 	router := http.NewServeMux()
-	router.HandleFunc("GET /status", GetStatus(config))
-	m := metrics.NewMetricsSubsystem(config.DBQueries)
-	router.Handle("/metrics", m.Handler)
-	ctx := loggingMiddlewareContext{}
+	router.HandleFunc("GET /ready", GetReadiness(config))
+	m := config.Metrics
+	if !config.SeparateMetricsListener {
+		router.HandleFunc("GET /status", GetStatus(config))
+		router.Handle("/metrics", m.Handler)
+	}
+	logger := config.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	apiRoute := routeOf("/api/v1", apiV1Router)
 	apiMiddlewareStack := createMiddlewareStack(
-		metricsMiddleware(m),
-		loggingMiddleware(&ctx),
+		metricsMiddleware(m, apiRoute),
+		loggingMiddleware(logger, apiRoute),
+		adminClientCertMiddleware(config.AdminClientCertRequired),
 	)
 	metricsMiddlewareStack := createMiddlewareStack(
-		metricsMiddleware(m),
+		metricsMiddleware(m, func(*http.Request) string { return "frontend" }),
 	)
 	router.Handle("/api/v1/", http.StripPrefix("/api/v1", apiMiddlewareStack(apiV1Router)))
 	router.Handle("/", metricsMiddlewareStack(frontendHandler))
 
+	return proxyMiddleware(config.TrustedProxies)(router)
+}
+
+// NewMetricsRouter returns the router of the listener serving /metrics, /status and /ready apart from the API,
+// for them not to be exposed publicly. It is used along with the SeparateMetricsListener option of the router.
+func NewMetricsRouter(config *HandlerConfig) http.Handler {
+	router := http.NewServeMux()
+	router.HandleFunc("GET /status", GetStatus(config))
+	router.HandleFunc("GET /ready", GetReadiness(config))
+	router.Handle("/metrics", config.Metrics.Handler)
 	return router
 }
@@ -1,55 +1,336 @@
 package server
 
 import (
-	"crypto/rand"
+	"context"
 	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
+	"log/slog"
+	"net"
 	"net/http"
+	"net/netip"
+	"os"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/gruyaume/lesvieux/internal/backup"
+	"github.com/gruyaume/lesvieux/internal/certs"
 	"github.com/gruyaume/lesvieux/internal/db"
+	"github.com/gruyaume/lesvieux/internal/jwtkeys"
+	"github.com/gruyaume/lesvieux/internal/metrics"
 )
 
+// jwtKeysReloadInterval is how often the JWT keys file is read again, so that rotated keys are picked up without a restart.
+const jwtKeysReloadInterval = 1 * time.Minute
+
+// certReloadInterval is how often the TLS certificate files are checked for changes, so that renewed certificates
+// are served without a restart.
+const certReloadInterval = 30 * time.Second
+
 type HandlerConfig struct {
 	DBQueries *db.Queries
-	JWTSecret []byte
+	JWTKeys   *jwtkeys.Keyring
+	Backups   backup.Config
+	// Logger logs the requests to the API. It defaults to the default logger of log/slog.
+	Logger *slog.Logger
+	// Metrics are the Prometheus metrics of the server. They are created along with the router when missing.
+	Metrics *metrics.PrometheusMetrics
+	// TrustedProxies are the networks of the reverse proxies whose forwarded headers are trusted.
+	TrustedProxies []netip.Prefix
+	// AdminClientCertRequired makes the admin API, including the admin login, require a client certificate
+	// verified by the TLS handshake. The server must then request client certificates.
+	AdminClientCertRequired bool
+	// SeparateMetricsListener leaves /metrics and /status out of the router, for the router of NewMetricsRouter
+	// to serve them on a listener that isn't exposed publicly.
+	SeparateMetricsListener bool
+	// LoginLimits rate limit the logins and lock out the emails failing to log in too many times in a row.
+	// DefaultLoginLimits apply to the fields that are left zero.
+	LoginLimits LoginLimits
+	// MFAIssuer is the name accounts are listed under in authenticator apps. It defaults to LesVieux.
+	MFAIssuer string
+	// MFARequiredRoles are the roles whose accounts must have a second factor to log in.
+	MFARequiredRoles []int64
+
+	// logins enforces the LoginLimits. It is created along with the router.
+	logins *loginGuard
+	// shuttingDown is set once the server starts shutting down, for readiness checks to fail.
+	shuttingDown atomic.Bool
 }
 
-func generateJWTSecret() ([]byte, error) {
-	bytes := make([]byte, 32)
-	if _, err := rand.Read(bytes); err != nil {
-		return bytes, fmt.Errorf("failed to generate JWT secret: %w", err)
+// loadJWTKeys returns a keyring holding the keys of the given file.
+// Without a file, a random key is generated: tokens are invalidated on restart and can't be shared between instances.
+func loadJWTKeys(keysFile string) (*jwtkeys.Keyring, error) {
+	if keysFile == "" {
+		slog.Warn("no JWT keys file configured, generating a temporary key: logins won't survive a restart")
+		f, err := jwtkeys.Generate()
+		if err != nil {
+			return nil, err
+		}
+		return jwtkeys.NewKeyring(f), nil
+	}
+	f, err := jwtkeys.ReadFile(keysFile)
+	if err != nil {
+		return nil, err
 	}
-	return bytes, nil
+	return jwtkeys.NewKeyring(f), nil
+}
+
+// startJWTKeysReload reads the keys file every interval and replaces the keys of the keyring, until ctx is done.
+// When the file can't be read, the previous keys are kept.
+func startJWTKeysReload(ctx context.Context, workers *sync.WaitGroup, keysFile string, jwtKeys *jwtkeys.Keyring, interval time.Duration) {
+	runEvery(ctx, workers, interval, false, func() {
+		f, err := jwtkeys.ReadFile(keysFile)
+		if err != nil {
+			slog.Error("couldn't reload JWT keys", "error", err)
+			return
+		}
+		jwtKeys.Set(f)
+	})
+}
+
+// startCertReload reads the certificate files again every interval when they changed, until ctx is done.
+// When they can't be loaded, the previous certificate is kept.
+func startCertReload(ctx context.Context, workers *sync.WaitGroup, reloader *certs.Reloader, interval time.Duration) {
+	runEvery(ctx, workers, interval, false, func() {
+		reloaded, err := reloader.Reload()
+		if err != nil {
+			slog.Error("couldn't reload TLS certificate", "error", err)
+			return
+		}
+		if reloaded {
+			slog.Info("reloaded TLS certificate")
+		}
+	})
 }
 
-func New(port int, cert []byte, key []byte, dbQueries *db.Queries) (*http.Server, error) {
-	jwtSecret, err := generateJWTSecret()
+// runEvery calls work in the background every interval, and right away first when immediately is set, until ctx is done.
+// workers is done once work is no longer running.
+func runEvery(ctx context.Context, workers *sync.WaitGroup, interval time.Duration, immediately bool, work func()) {
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		if immediately {
+			work()
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				work()
+			}
+		}
+	}()
+}
+
+// Options configure the server and its listeners.
+type Options struct {
+	Port int
+	// CertFile and KeyFile hold the TLS certificate of the server and its key, in PEM. They are read again when
+	// they change. They are ignored with PlainHTTP.
+	CertFile string
+	KeyFile  string
+	// TLSMinVersion and TLSCipherSuites restrict TLS connections, as the fields of tls.Config they are given to.
+	TLSMinVersion   uint16
+	TLSCipherSuites []uint16
+	// ClientCAFile holds the authorities client certificates are verified against, in PEM. When it is set,
+	// the admin API requires a verified client certificate.
+	ClientCAFile string
+	// PlainHTTP serves plain HTTP, for TLS to be terminated by a reverse proxy.
+	PlainHTTP   bool
+	JWTKeysFile string
+	Backups     backup.Config
+	// TrustedProxies are the networks of the reverse proxies whose forwarded headers are trusted.
+	TrustedProxies []netip.Prefix
+	// MetricsAddress is the address of a separate plain HTTP listener for /metrics and /status.
+	// When it is empty, they are served by the main listener.
+	MetricsAddress string
+	// MFAIssuer is the name accounts are listed under in authenticator apps.
+	MFAIssuer string
+	// MFARequiredRoles name the roles whose accounts must have a second factor to log in, as validated by the
+	// configuration: admin or employer.
+	MFARequiredRoles []string
+	// DrainPeriod is how long the server keeps serving once it starts shutting down, with /ready failing,
+	// for load balancers to stop sending it requests before its listeners are closed.
+	DrainPeriod time.Duration
+}
+
+// Server is the HTTP server of LesVieux, along with the workers running in the background:
+// job post expiry, session cleanup, scheduled backups and the reload of the JWT keys and of the TLS certificate.
+// With a metrics address, metricsServer serves /metrics and /status on a listener of its own.
+type Server struct {
+	*http.Server
+	metricsServer *http.Server
+	env           *HandlerConfig
+	drainPeriod   time.Duration
+	stopWorkers   context.CancelFunc
+	workers       sync.WaitGroup
+}
+
+func New(opts Options, dbQueries *db.Queries) (*Server, error) {
+	var tlsConfig *tls.Config
+	var reloader *certs.Reloader
+	if !opts.PlainHTTP {
+		var err error
+		reloader, err = certs.NewReloader(opts.CertFile, opts.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig = &tls.Config{
+			GetCertificate: reloader.GetCertificate,
+			MinVersion:     opts.TLSMinVersion,
+			CipherSuites:   opts.TLSCipherSuites,
+		}
+		if opts.ClientCAFile != "" {
+			clientCAs, err := os.ReadFile(opts.ClientCAFile)
+			if err != nil {
+				return nil, err
+			}
+			tlsConfig.ClientCAs = x509.NewCertPool()
+			if !tlsConfig.ClientCAs.AppendCertsFromPEM(clientCAs) {
+				return nil, errors.New("no PEM certificate in the client CA file")
+			}
+			tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+		}
+	}
+	var mfaRequiredRoles []int64
+	for _, name := range opts.MFARequiredRoles {
+		mfaRequiredRoles = append(mfaRequiredRoles, roleNames[name])
+	}
+	jwtKeys, err := loadJWTKeys(opts.JWTKeysFile)
 	if err != nil {
 		return nil, err
 	}
+	ctx, stopWorkers := context.WithCancel(context.Background())
 	env := &HandlerConfig{
-		DBQueries: dbQueries,
-		JWTSecret: jwtSecret,
+		DBQueries:               dbQueries,
+		JWTKeys:                 jwtKeys,
+		Backups:                 opts.Backups,
+		Logger:                  slog.Default(),
+		Metrics:                 metrics.NewMetricsSubsystem(ctx, dbQueries),
+		TrustedProxies:          opts.TrustedProxies,
+		AdminClientCertRequired: tlsConfig != nil && tlsConfig.ClientCAs != nil,
+		SeparateMetricsListener: opts.MetricsAddress != "",
+		MFAIssuer:               opts.MFAIssuer,
+		MFARequiredRoles:        mfaRequiredRoles,
+	}
+	s := &Server{
+		Server: &http.Server{
+			Addr: fmt.Sprintf(":%d", opts.Port),
+
+			ReadTimeout:    10 * time.Second,
+			WriteTimeout:   10 * time.Second,
+			Handler:        NewLesVieuxRouter(env),
+			MaxHeaderBytes: 1 << 20,
+			TLSConfig:      tlsConfig,
+		},
+		env:         env,
+		drainPeriod: opts.DrainPeriod,
+		stopWorkers: stopWorkers,
+	}
+	if opts.MetricsAddress != "" {
+		s.metricsServer = &http.Server{
+			Addr:           opts.MetricsAddress,
+			ReadTimeout:    10 * time.Second,
+			WriteTimeout:   10 * time.Second,
+			Handler:        NewMetricsRouter(env),
+			MaxHeaderBytes: 1 << 20,
+		}
+	}
+	if reloader != nil {
+		startCertReload(ctx, &s.workers, reloader, certReloadInterval)
+	}
+	if opts.JWTKeysFile != "" {
+		startJWTKeysReload(ctx, &s.workers, opts.JWTKeysFile, jwtKeys, jwtKeysReloadInterval)
 	}
-	router := NewLesVieuxRouter(env)
+	startJobPostExpiry(ctx, &s.workers, dbQueries, jobPostExpiryInterval)
+	startSessionCleanup(ctx, &s.workers, dbQueries, sessionCleanupInterval)
+	startLoginFailureCleanup(ctx, &s.workers, dbQueries, loginFailureCleanupInterval)
+	if opts.Backups.Dir != "" && opts.Backups.Interval > 0 {
+		startScheduledBackups(ctx, &s.workers, dbQueries, opts.Backups)
+	}
+	return s, nil
+}
 
-	serverCerts, err := tls.X509KeyPair(cert, key)
+// ListenAndServe listens on the addresses of the server and serves its listeners, as ServeListeners does.
+func (s *Server) ListenAndServe() error {
+	listener, err := net.Listen("tcp", s.Addr)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	var metricsListener net.Listener
+	if s.metricsServer != nil {
+		metricsListener, err = net.Listen("tcp", s.metricsServer.Addr)
+		if err != nil {
+			listener.Close()
+			return err
+		}
 	}
+	return s.ServeListeners(listener, metricsListener)
+}
 
-	s := &http.Server{
-		Addr: fmt.Sprintf(":%d", port),
+// ServeListeners serves the API on listener, with TLS unless the server serves plain HTTP, and /metrics and /status
+// on metricsListener when the server has a metrics address. It returns once one of them stops, with its error:
+// http.ErrServerClosed once the server is shut down.
+func (s *Server) ServeListeners(listener net.Listener, metricsListener net.Listener) error {
+	served := make(chan error, 2)
+	if s.metricsServer != nil {
+		go func() {
+			served <- s.metricsServer.Serve(metricsListener)
+		}()
+	}
+	go func() {
+		if s.TLSConfig == nil {
+			served <- s.Serve(listener)
+			return
+		}
+		served <- s.ServeTLS(listener, "", "")
+	}()
+	return <-served
+}
 
-		ReadTimeout:    10 * time.Second,
-		WriteTimeout:   10 * time.Second,
-		Handler:        router,
-		MaxHeaderBytes: 1 << 20,
-		TLSConfig: &tls.Config{
-			Certificates: []tls.Certificate{serverCerts},
-		},
+// URL returns the address of the API, for logs.
+func (s *Server) URL() string {
+	if s.TLSConfig == nil {
+		return "http://127.0.0.1" + s.Addr
 	}
+	return "https://127.0.0.1" + s.Addr
+}
 
-	return s, nil
+// Shutdown stops the server gracefully. Readiness checks fail from then on, and the server keeps serving for
+// the drain period, so that load balancers see it and stop sending requests. It then stops accepting connections
+// and waits for the requests in flight to be handled. The background workers are then stopped, letting the work
+// they are doing finish, and the metrics listener is closed last.
+// When ctx is done before, Shutdown returns its error without waiting any longer: the database can then be closed.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.env.shuttingDown.Store(true)
+	if s.drainPeriod > 0 {
+		slog.Info("draining before closing the listeners", "period", s.drainPeriod.String())
+		select {
+		case <-time.After(s.drainPeriod):
+		case <-ctx.Done():
+		}
+	}
+	err := s.Server.Shutdown(ctx)
+	s.stopWorkers()
+	stopped := make(chan struct{})
+	go func() {
+		s.workers.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		if err == nil {
+			err = ctx.Err()
+		}
+	}
+	if s.metricsServer != nil {
+		err = errors.Join(err, s.metricsServer.Shutdown(ctx))
+	}
+	return err
 }
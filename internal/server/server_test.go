@@ -1,32 +1,353 @@
 package server_test
 
 import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
-	"github.com/gruyaume/lesvieux/internal/db"
 	"github.com/gruyaume/lesvieux/internal/server"
 )
 
-func TestNewSuccess(t *testing.T) {
-	dbQueries, err := db.Initialize(":memory:")
+// newServer returns a server with the given options, serving the test certificate unless it serves plain HTTP.
+func newServer(t *testing.T, opts server.Options) *server.Server {
+	t.Helper()
+	dbQueries, err := setupDatabase()
 	if err != nil {
-		t.Errorf("Error occured: %s", err)
+		t.Fatalf("Error occured: %s", err)
 	}
 
-	certPath := filepath.Join("testdata", "cert.pem")
-	keyPath := filepath.Join("testdata", "key.pem")
-	cert, err := os.ReadFile(certPath)
+	opts.Port = 1234
+	if !opts.PlainHTTP && opts.CertFile == "" {
+		opts.CertFile = filepath.Join("testdata", "cert.pem")
+		opts.KeyFile = filepath.Join("testdata", "key.pem")
+	}
+	srv, err := server.New(opts, dbQueries)
 	if err != nil {
+		t.Fatalf("Error occured: %s", err)
+	}
+	return srv
+}
+
+func TestNewSuccess(t *testing.T) {
+	srv := newServer(t, server.Options{})
+	if err := srv.Shutdown(context.Background()); err != nil {
 		t.Errorf("Error occured: %s", err)
 	}
-	key, err := os.ReadFile(keyPath)
+}
+
+func TestGracefulShutdown(t *testing.T) {
+	srv := newServer(t, server.Options{})
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
 	if err != nil {
-		t.Errorf("Error occured: %s", err)
+		t.Fatal(err)
 	}
-	_, err = server.New(1234, []byte(cert), []byte(key), dbQueries)
+	served := make(chan error, 1)
+	go func() {
+		served <- srv.ServeListeners(listener, nil)
+	}()
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+
+	res, err := client.Get("https://" + listener.Addr().String() + "/ready")
 	if err != nil {
-		t.Errorf("Error occured: %s", err)
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected the server to be ready, got status %d", res.StatusCode)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		t.Fatalf("couldn't shut down: %s", err)
+	}
+	if err := <-served; !errors.Is(err, http.ErrServerClosed) {
+		t.Fatalf("expected the server to be closed, got %v", err)
+	}
+
+	recorder := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/ready", nil))
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected readiness to fail once shutting down, got status %d", recorder.Code)
+	}
+}
+
+func TestShutdownDrainPeriod(t *testing.T) {
+	srv := newServer(t, server.Options{DrainPeriod: 2 * time.Second})
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	served := make(chan error, 1)
+	go func() {
+		served <- srv.ServeListeners(listener, nil)
+	}()
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+	url := "https://" + listener.Addr().String() + "/ready"
+	if statusCode, _, err := get(client, url); err != nil || statusCode != http.StatusOK {
+		t.Fatalf("expected the server to be ready, got %d %v", statusCode, err)
+	}
+
+	shutdown := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		shutdown <- srv.Shutdown(ctx)
+	}()
+	deadline := time.Now().Add(time.Second)
+	for {
+		statusCode, _, err := get(client, url)
+		if err != nil {
+			t.Fatalf("expected the server to keep serving while draining: %s", err)
+		}
+		if statusCode == http.StatusServiceUnavailable {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected readiness to fail while draining, got status %d", statusCode)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := <-shutdown; err != nil {
+		t.Fatalf("couldn't shut down: %s", err)
+	}
+	if err := <-served; !errors.Is(err, http.ErrServerClosed) {
+		t.Fatalf("expected the server to be closed, got %v", err)
+	}
+	if _, _, err := get(client, url); err == nil {
+		t.Fatal("expected the listener to be closed once drained")
+	}
+}
+
+func get(client *http.Client, url string) (int, string, error) {
+	res, err := client.Get(url)
+	if err != nil {
+		return 0, "", err
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return 0, "", err
 	}
+	return res.StatusCode, string(body), nil
+}
+
+func TestPlainHTTPWithMetricsListener(t *testing.T) {
+	srv := newServer(t, server.Options{PlainHTTP: true, MetricsAddress: "127.0.0.1:0"})
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	metricsListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	served := make(chan error, 1)
+	go func() {
+		served <- srv.ServeListeners(listener, metricsListener)
+	}()
+	apiURL := "http://" + listener.Addr().String()
+	metricsURL := "http://" + metricsListener.Addr().String()
+
+	t.Run("the api is served over plain http", func(t *testing.T) {
+		statusCode, _, err := get(http.DefaultClient, apiURL+"/api/v1/status")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if statusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, statusCode)
+		}
+	})
+
+	t.Run("metrics and status are only served by the metrics listener", func(t *testing.T) {
+		_, body, err := get(http.DefaultClient, apiURL+"/metrics")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(body, "http_requests_total") {
+			t.Fatalf("expected the metrics not to be served by the main listener")
+		}
+		statusCode, body, err := get(http.DefaultClient, metricsURL+"/metrics")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if statusCode != http.StatusOK || !strings.Contains(body, `http_requests_total{code="200",method="get",route="/api/v1/status"} 1`) {
+			t.Fatalf("expected the metrics of the api, got status %d: %s", statusCode, body)
+		}
+		for _, path := range []string{"/status", "/ready"} {
+			statusCode, _, err := get(http.DefaultClient, metricsURL+path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if statusCode != http.StatusOK {
+				t.Fatalf("%s: expected status %d, got %d", path, http.StatusOK, statusCode)
+			}
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		t.Fatalf("couldn't shut down: %s", err)
+	}
+	if err := <-served; !errors.Is(err, http.ErrServerClosed) {
+		t.Fatalf("expected the server to be closed, got %v", err)
+	}
+	if _, _, err := get(http.DefaultClient, metricsURL+"/metrics"); err == nil {
+		t.Fatalf("expected the metrics listener to be closed")
+	}
+}
+
+// serveTLS serves srv on a local listener until the test ends, and returns its address.
+func serveTLS(t *testing.T, srv *server.Server) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go srv.ServeListeners(listener, nil)
+	t.Cleanup(func() {
+		if err := srv.Shutdown(context.Background()); err != nil {
+			t.Errorf("couldn't shut down: %s", err)
+		}
+	})
+	return "https://" + listener.Addr().String()
+}
+
+func TestTLSMinVersion(t *testing.T) {
+	srv := newServer(t, server.Options{TLSMinVersion: tls.VersionTLS13})
+	url := serveTLS(t, srv)
+
+	cases := []struct {
+		Name       string
+		MaxVersion uint16
+		Accepted   bool
+	}{
+		{"tls 1.2", tls.VersionTLS12, false},
+		{"tls 1.3", tls.VersionTLS13, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true, MaxVersion: tc.MaxVersion}}}
+			_, _, err := get(client, url+"/ready")
+			if tc.Accepted && err != nil {
+				t.Fatalf("expected the connection to be accepted: %s", err)
+			}
+			if !tc.Accepted && err == nil {
+				t.Fatalf("expected the connection to be refused")
+			}
+		})
+	}
+}
+
+// newClientCert returns a self-signed client certificate, and writes it to a file to be used as the client CA.
+func newClientCert(t *testing.T) (tls.Certificate, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "admin"},
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caFile := filepath.Join(t.TempDir(), "client_ca.pem")
+	if err := os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, caFile
+}
+
+func TestAdminClientCert(t *testing.T) {
+	clientCert, caFile := newClientCert(t)
+	srv := newServer(t, server.Options{ClientCAFile: caFile})
+	url := serveTLS(t, srv)
+	anonymous := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+	authenticated := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true, Certificates: []tls.Certificate{clientCert}}}}
+
+	t.Run("the admin api requires a client certificate", func(t *testing.T) {
+		statusCode, response, err := createAdminAccount(url, anonymous, "", &adminUser)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if statusCode != http.StatusForbidden || response.Error != "forbidden: client certificate required" {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusForbidden, statusCode, response.Error)
+		}
+		statusCode, _, err = adminLogin(url, anonymous, &AdminLoginParams{Email: adminUser.Email, Password: adminUser.Password})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if statusCode != http.StatusForbidden {
+			t.Fatalf("expected status %d, got %d", http.StatusForbidden, statusCode)
+		}
+	})
+
+	t.Run("the admin api is reached with a client certificate", func(t *testing.T) {
+		statusCode, _, err := createAdminAccount(url, authenticated, "", &adminUser)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if statusCode != http.StatusCreated {
+			t.Fatalf("expected status %d, got %d", http.StatusCreated, statusCode)
+		}
+		statusCode, loginResponse, err := adminLogin(url, authenticated, &AdminLoginParams{Email: adminUser.Email, Password: adminUser.Password})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if statusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, statusCode)
+		}
+		var response ListAuditEventsResponse
+		statusCode, err = apiRequest("GET", url, anonymous, loginResponse.Result.Token, "/admin/audit_events", nil, &response)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if statusCode != http.StatusForbidden {
+			t.Fatalf("expected tokens not to be enough without a client certificate, got status %d", statusCode)
+		}
+		statusCode, err = apiRequest("GET", url, authenticated, loginResponse.Result.Token, "/admin/audit_events", nil, &response)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if statusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, statusCode)
+		}
+	})
+
+	t.Run("other apis don't require a client certificate", func(t *testing.T) {
+		statusCode, _, err := get(anonymous, url+"/api/v1/posts")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if statusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, statusCode)
+		}
+	})
 }
@@ -0,0 +1,251 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gruyaume/lesvieux/internal/db"
+	"github.com/gruyaume/lesvieux/internal/jwtkeys"
+)
+
+// RefreshTokenValidity is how long a session lasts without being refreshed.
+// Each refresh hands out a new refresh token and extends the session by as much.
+const RefreshTokenValidity = 30 * 24 * time.Hour
+
+const sessionCleanupInterval = 1 * time.Hour
+
+// errSessionRevoked is returned when the session a token was issued for doesn't exist anymore.
+var errSessionRevoked = errors.New("session expired or revoked")
+
+type RefreshSessionParams struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type RefreshSessionResponse struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// newRefreshToken returns a random refresh token, and the hash of it stored in the database.
+func newRefreshToken() (token string, hash string, err error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", "", fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	token = base64.RawURLEncoding.EncodeToString(bytes)
+	return token, hashRefreshToken(token), nil
+}
+
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// startSession creates a session for an account that just logged in, and returns an access token and a refresh token for it.
+func startSession(ctx context.Context, queries *db.Queries, jwtKeys *jwtkeys.Keyring, accountID int64, email string, role int64) (accessToken string, refreshToken string, err error) {
+	refreshToken, refreshTokenHash, err := newRefreshToken()
+	if err != nil {
+		return "", "", err
+	}
+	now := time.Now().UTC()
+	session, err := queries.CreateSession(ctx, db.CreateSessionParams{
+		AccountID:        accountID,
+		Role:             role,
+		RefreshTokenHash: refreshTokenHash,
+		CreatedAt:        now.Format(time.RFC3339),
+		ExpiresAt:        now.Add(RefreshTokenValidity).Format(time.RFC3339),
+	})
+	if err != nil {
+		return "", "", err
+	}
+	accessToken, err = generateJWT(accountID, email, session.ID, jwtKeys, role)
+	if err != nil {
+		return "", "", err
+	}
+	return accessToken, refreshToken, nil
+}
+
+// checkSession returns errSessionRevoked if the session of the claims was ended by a logout, a password change,
+// the deletion of the account, or has expired.
+func checkSession(ctx context.Context, queries *db.Queries, claims *jwtLesVieuxClaims) error {
+	session, err := queries.GetSession(ctx, claims.SessionID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errSessionRevoked
+		}
+		return err
+	}
+	if session.AccountID != claims.ID || session.Role != claims.Role {
+		return errSessionRevoked
+	}
+	if session.ExpiresAt <= time.Now().UTC().Format(time.RFC3339) {
+		return errSessionRevoked
+	}
+	return nil
+}
+
+// revokeAccountSessions logs an account out of all its sessions but the one with the keep id, which can be 0.
+func revokeAccountSessions(ctx context.Context, queries *db.Queries, accountID int64, role int64, keep int64) error {
+	return queries.DeleteOtherAccountSessions(ctx, db.DeleteOtherAccountSessionsParams{
+		AccountID: accountID,
+		Role:      role,
+		KeepID:    keep,
+	})
+}
+
+// getSessionAccountEmail returns the email of the account a session belongs to.
+func getSessionAccountEmail(ctx context.Context, queries *db.Queries, session db.Session) (string, error) {
+	switch session.Role {
+	case AdminRole:
+		account, err := queries.GetAdminAccount(ctx, session.AccountID)
+		return account.Email, err
+	case EmployerRole:
+		account, err := queries.GetEmployerAccountByID(ctx, session.AccountID)
+		return account.Email, err
+	case ApplicantRole:
+		account, err := queries.GetApplicantAccount(ctx, session.AccountID)
+		return account.Email, err
+	}
+	return "", fmt.Errorf("unknown role %d", session.Role)
+}
+
+// RefreshSession exchanges a refresh token for a new access token and a new refresh token.
+// The refresh token can only be used once.
+func RefreshSession(env *HandlerConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var params RefreshSessionParams
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid JSON format")
+			return
+		}
+		if params.RefreshToken == "" {
+			writeError(w, http.StatusBadRequest, "refresh_token is required")
+			return
+		}
+		refreshTokenHash := hashRefreshToken(params.RefreshToken)
+		session, err := env.DBQueries.GetSessionByRefreshTokenHash(context.Background(), refreshTokenHash)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				writeError(w, http.StatusUnauthorized, "Invalid refresh token")
+				return
+			}
+			logError(r, "internal error", err)
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		now := time.Now().UTC()
+		if session.ExpiresAt <= now.Format(time.RFC3339) {
+			writeError(w, http.StatusUnauthorized, "Invalid refresh token")
+			return
+		}
+		email, err := getSessionAccountEmail(context.Background(), env.DBQueries, session)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				writeError(w, http.StatusUnauthorized, "Invalid refresh token")
+				return
+			}
+			logError(r, "internal error", err)
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		refreshToken, newRefreshTokenHash, err := newRefreshToken()
+		if err != nil {
+			logError(r, "internal error", err)
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		updated, err := env.DBQueries.RotateSessionRefreshToken(context.Background(), db.RotateSessionRefreshTokenParams{
+			NewRefreshTokenHash: newRefreshTokenHash,
+			ExpiresAt:           now.Add(RefreshTokenValidity).Format(time.RFC3339),
+			ID:                  session.ID,
+			RefreshTokenHash:    refreshTokenHash,
+		})
+		if err != nil {
+			logError(r, "internal error", err)
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		if updated == 0 {
+			writeError(w, http.StatusUnauthorized, "Invalid refresh token")
+			return
+		}
+		accessToken, err := generateJWT(session.AccountID, email, session.ID, env.JWTKeys, session.Role)
+		if err != nil {
+			logError(r, "internal error", err)
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		err = writeJSON(w, RefreshSessionResponse{Token: accessToken, RefreshToken: refreshToken})
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+	}
+}
+
+// Logout ends the session of the token used to authenticate the request.
+// Its access token and refresh token stop working.
+func Logout(env *HandlerConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		sessionID := r.Context().Value(sessionIDKey).(int64)
+		err := env.DBQueries.DeleteSession(context.Background(), sessionID)
+		if err != nil {
+			logError(r, "internal error", err)
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		err = writeJSON(w, map[string]any{"id": sessionID})
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+	}
+}
+
+// LogoutAllSessions ends every session of the logged in account, including the current one.
+func LogoutAllSessions(env *HandlerConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		sessionID := r.Context().Value(sessionIDKey).(int64)
+		session, err := env.DBQueries.GetSession(context.Background(), sessionID)
+		if err != nil {
+			logError(r, "internal error", err)
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		err = revokeAccountSessions(context.Background(), env.DBQueries, session.AccountID, session.Role, 0)
+		if err != nil {
+			logError(r, "internal error", err)
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		err = writeJSON(w, map[string]any{"id": session.AccountID})
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+	}
+}
+
+// startSessionCleanup periodically deletes the expired sessions, until ctx is done.
+func startSessionCleanup(ctx context.Context, workers *sync.WaitGroup, dbQueries *db.Queries, interval time.Duration) {
+	runEvery(ctx, workers, interval, true, func() {
+		_, err := dbQueries.DeleteExpiredSessions(context.Background(), time.Now().UTC().Format(time.RFC3339))
+		if err != nil {
+			slog.Error("couldn't delete expired sessions", "error", err)
+		}
+	})
+}
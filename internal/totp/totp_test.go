@@ -0,0 +1,93 @@
+package totp_test
+
+import (
+	"encoding/base32"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gruyaume/lesvieux/internal/totp"
+)
+
+// rfcSecret is the SHA-1 secret of the test vectors of RFC 6238, "12345678901234567890", in base32.
+var rfcSecret = base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestCode(t *testing.T) {
+	// The 8 digit codes of RFC 6238, appendix B, truncated to 6 digits.
+	cases := []struct {
+		Time     int64
+		Expected string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, c := range cases {
+		code, err := totp.Code(rfcSecret, totp.Step(time.Unix(c.Time, 0)))
+		if err != nil {
+			t.Fatalf("couldn't compute code: %s", err)
+		}
+		if code != c.Expected {
+			t.Errorf("expected code %s at %d, got %s", c.Expected, c.Time, code)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	step := totp.Step(now)
+	cases := []struct {
+		Name     string
+		Step     int64
+		Expected bool
+	}{
+		{"current step", step, true},
+		{"previous step", step - 1, true},
+		{"next step", step + 1, true},
+		{"older step", step - 2, false},
+		{"later step", step + 2, false},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			code, err := totp.Code(secret, c.Step)
+			if err != nil {
+				t.Fatal(err)
+			}
+			validStep, ok := totp.Validate(secret, code, now)
+			if ok != c.Expected {
+				t.Fatalf("expected validation to be %t, got %t", c.Expected, ok)
+			}
+			if ok && validStep != c.Step {
+				t.Fatalf("expected step %d, got %d", c.Step, validStep)
+			}
+		})
+	}
+	if _, ok := totp.Validate(secret, "12345", now); ok {
+		t.Errorf("expected a short code to be refused")
+	}
+	if _, ok := totp.Validate("not base32!", "123456", now); ok {
+		t.Errorf("expected an invalid secret to be refused")
+	}
+}
+
+func TestProvisioningURI(t *testing.T) {
+	uri := totp.ProvisioningURI("LesVieux", "admin@example.com", "JBSWY3DPEHPK3PXP")
+	u, err := url.Parse(uri)
+	if err != nil {
+		t.Fatalf("couldn't parse URI: %s", err)
+	}
+	if u.Scheme != "otpauth" || u.Host != "totp" || u.Path != "/LesVieux:admin@example.com" {
+		t.Fatalf("unexpected URI %s", uri)
+	}
+	query := u.Query()
+	if query.Get("secret") != "JBSWY3DPEHPK3PXP" || query.Get("issuer") != "LesVieux" || query.Get("digits") != "6" || query.Get("period") != "30" {
+		t.Fatalf("unexpected URI parameters %s", u.RawQuery)
+	}
+}
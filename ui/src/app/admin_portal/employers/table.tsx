@@ -13,7 +13,7 @@ import {
     Form,
 } from "@canonical/react-components";
 import { useAuth } from "../auth/authContext";
-import { deleteEmployer, createEmployer } from "../../queries";
+import { deleteEmployer, createEmployer, getEmployerDependents } from "../../queries";
 
 export type ConfirmationModalData = {
     onMouseDownFunc: () => void;
@@ -36,13 +36,19 @@ export function EmployersTable({ employers }: TableProps) {
     });
 
     const handleDelete = (id: string, email: string) => {
-        setConfirmationModalData({
-            warningText: `Deleting employer: "${email}". This action cannot be undone.`,
-            onMouseDownFunc: () => {
-                const authToken = auth.user ? auth.user.authToken : "";
-                deleteMutation.mutate({ id: id, authToken });
-            },
-        });
+        const authToken = auth.user ? auth.user.authToken : "";
+        getEmployerDependents({ id: id, authToken })
+            .then((dependents) => {
+                setConfirmationModalData({
+                    warningText: `Deleting employer: "${email}", along with its ${dependents.employer_accounts} accounts and ${dependents.job_posts} job posts with ${dependents.applications} applications. This action cannot be undone.`,
+                    onMouseDownFunc: () => {
+                        deleteMutation.mutate({ id: id, authToken });
+                    },
+                });
+            })
+            .catch((error) => {
+                console.error("Failed to get employer dependents:", error);
+            });
     };
 
     const handleCreateEmployer = () => {
@@ -3,17 +3,18 @@
 import { getStatus, adminLogin } from "../../queries"
 import { useMutation, useQuery } from "react-query"
 import { useState, ChangeEvent, useEffect } from "react"
-import { useCookies } from "react-cookie"
 import { useRouter } from "next/navigation"
 import { useAuth } from "../auth/authContext"
 import { statusResponseResult } from "../../types"
 import Logo from "../../components/logo"
+import { SecondFactorStep } from "../../components/mfa"
+import { useSessionCookies } from "../../components/session"
 import { Navigation, Notification, Input, PasswordToggle, Button, Form, StatusLabel } from "@canonical/react-components";
 
 export default function AdminLogin() {
     const router = useRouter()
     const auth = useAuth()
-    const [cookies, setCookie, removeCookie] = useCookies(['user_token']);
+    const session = useSessionCookies('/admin_portal')
     const statusQuery = useQuery<statusResponseResult, Error>({
         queryFn: () => getStatus()
     })
@@ -26,18 +27,20 @@ export default function AdminLogin() {
     if (!auth.firstUserCreated && (statusQuery.data && !statusQuery.data.initialized)) {
         router.push("/admin_portal/initialize")
     }
+    const logIn = (token: string, refreshToken: string) => {
+        session.storeSession(token, refreshToken)
+        router.push('/admin_portal/employers')
+    }
+    const [mfaChallenge, setMFAChallenge] = useState<{ token: string, enrollmentRequired: boolean } | null>(null)
     const mutation = useMutation(adminLogin, {
         onSuccess: (response) => {
             const token = response.token;
-            if (token) {
+            if (response.mfa_token) {
+                setErrorText("")
+                setMFAChallenge({ token: response.mfa_token, enrollmentRequired: !!response.mfa_enrollment_required })
+            } else if (token && response.refresh_token) {
                 setErrorText("")
-                setCookie('user_token', token, {
-                    sameSite: true,
-                    secure: true,
-                    path: "/admin_portal",
-                    expires: new Date(new Date().getTime() + 60 * 60 * 1000),
-                })
-                router.push('/admin_portal/employers')
+                logIn(token, response.refresh_token)
             } else {
                 setErrorText("Failed to retrieve token.")
             }
@@ -100,38 +103,47 @@ export default function AdminLogin() {
                         <div className="u-fixed-width">
                             <Form>
                                 <fieldset>
-                                    <h2 className="p-panel__title">Login</h2>
-                                    <Input
-                                        id="InputEmail"
-                                        label="Email"
-                                        type="email"
-                                        onChange={handleEmailChange}
-                                    />
-                                    <PasswordToggle
-                                        id="InputPassword"
-                                        label="Password"
-                                        onChange={handlePasswordChange}
-                                    />
-                                    {errorText &&
-                                        <Notification
-                                            severity="negative"
-                                            title="Error"
-                                        >
-                                            {errorText.split("error: ")}
-                                        </Notification>
-                                    }
-                                    <Button
-                                        appearance="positive"
-                                        disabled={password.length == 0 || email.length == 0}
-                                        onClick={
-                                            (event) => {
-                                                event.preventDefault();
-                                                mutation.mutate({ email: email, password: password })
+                                    {mfaChallenge ?
+                                        <SecondFactorStep
+                                            mfaToken={mfaChallenge.token}
+                                            enrollmentRequired={mfaChallenge.enrollmentRequired}
+                                            onLoggedIn={logIn}
+                                        /> :
+                                        <>
+                                            <h2 className="p-panel__title">Login</h2>
+                                            <Input
+                                                id="InputEmail"
+                                                label="Email"
+                                                type="email"
+                                                onChange={handleEmailChange}
+                                            />
+                                            <PasswordToggle
+                                                id="InputPassword"
+                                                label="Password"
+                                                onChange={handlePasswordChange}
+                                            />
+                                            {errorText &&
+                                                <Notification
+                                                    severity="negative"
+                                                    title="Error"
+                                                >
+                                                    {errorText.split("error: ")}
+                                                </Notification>
                                             }
-                                        }
-                                    >
-                                        Log In
-                                    </Button>
+                                            <Button
+                                                appearance="positive"
+                                                disabled={password.length == 0 || email.length == 0}
+                                                onClick={
+                                                    (event) => {
+                                                        event.preventDefault();
+                                                        mutation.mutate({ email: email, password: password })
+                                                    }
+                                                }
+                                            >
+                                                Log In
+                                            </Button>
+                                        </>
+                                    }
                                 </fieldset>
                             </Form>
                         </div>
@@ -7,13 +7,13 @@ import { useAuth } from "./auth/authContext";
 import { ChangeAdminPasswordModalData, ChangeMyPasswordModal, ChangePasswordModalContext } from "./admins/components";
 import Logo from "../components/logo"
 import { Button, Panel, SideNavigation, StatusLabel } from "@canonical/react-components";
-import { useCookies } from "react-cookie";
+import { useSessionCookies } from "../components/session";
 import { getStatus } from "../queries"
 
 
 export function SideBar({ activePath, sidebarVisible }: { activePath: string, sidebarVisible: boolean, setSidebarVisible: Dispatch<SetStateAction<boolean>> }) {
     const changePasswordModalContext = useContext(ChangePasswordModalContext)
-    const [cookies, setCookie, removeCookie] = useCookies(['user_token']);
+    const session = useSessionCookies('/admin_portal')
     const [menuOpen, setMenuOpen] = useState<boolean>(false)
     const auth = useAuth()
 
@@ -109,7 +109,7 @@ export function SideBar({ activePath, sidebarVisible }: { activePath: string, si
                                                 </Button>
                                                 <Button
                                                     className="p-contextual-menu__link"
-                                                    onMouseDown={() => removeCookie("user_token")}>
+                                                    onMouseDown={() => session.endSession()}>
                                                     Log Out
                                                 </Button>
                                             </span>
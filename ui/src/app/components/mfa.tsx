@@ -0,0 +1,193 @@
+"use client"
+
+import { useState, ChangeEvent } from "react"
+import { useMutation } from "react-query"
+import { verifyMFA, enrollTOTP, confirmTOTP } from "../queries"
+import { TOTPConfirmation, TOTPEnrollment } from "../types"
+import { Input, Button, Notification } from "@canonical/react-components";
+
+interface SecondFactorStepProps {
+    mfaToken: string
+    enrollmentRequired: boolean
+    onLoggedIn: (token: string, refreshToken: string) => void
+}
+
+// SecondFactorStep completes a login whose password was right: with a code of the second factor of the account,
+// or by enrolling one first when its role requires it.
+export function SecondFactorStep({ mfaToken, enrollmentRequired, onLoggedIn }: SecondFactorStepProps) {
+    if (enrollmentRequired) {
+        return <EnrollSecondFactor mfaToken={mfaToken} onLoggedIn={onLoggedIn} />
+    }
+    return <VerifySecondFactor mfaToken={mfaToken} onLoggedIn={onLoggedIn} />
+}
+
+function VerifySecondFactor({ mfaToken, onLoggedIn }: Omit<SecondFactorStepProps, "enrollmentRequired">) {
+    const [code, setCode] = useState<string>("")
+    const [useRecoveryCode, setUseRecoveryCode] = useState<boolean>(false)
+    const [errorText, setErrorText] = useState<string>("")
+    const mutation = useMutation(verifyMFA, {
+        onSuccess: (response) => {
+            if (response.token && response.refresh_token) {
+                setErrorText("")
+                onLoggedIn(response.token, response.refresh_token)
+            } else {
+                setErrorText("Failed to retrieve token.")
+            }
+        },
+        onError: (e: Error) => {
+            setErrorText(e.message)
+        }
+    })
+    const handleCodeChange = (event: ChangeEvent<HTMLInputElement>) => { setCode(event.target.value.trim()) }
+    return (
+        <>
+            <h2 className="p-panel__title">Two-factor authentication</h2>
+            <Input
+                id="InputCode"
+                label={useRecoveryCode ? "Recovery code" : "Code of your authenticator app"}
+                type="text"
+                autoComplete="one-time-code"
+                value={code}
+                onChange={handleCodeChange}
+            />
+            {errorText &&
+                <Notification
+                    severity="negative"
+                    title="Error"
+                >
+                    {errorText.split("error: ")}
+                </Notification>
+            }
+            <Button
+                appearance="positive"
+                disabled={code.length == 0}
+                onClick={
+                    (event) => {
+                        event.preventDefault();
+                        mutation.mutate(useRecoveryCode ? { mfaToken: mfaToken, recoveryCode: code } : { mfaToken: mfaToken, code: code })
+                    }
+                }
+            >
+                Verify
+            </Button>
+            <Button
+                appearance="link"
+                onClick={
+                    (event) => {
+                        event.preventDefault();
+                        setCode("")
+                        setUseRecoveryCode(!useRecoveryCode)
+                    }
+                }
+            >
+                {useRecoveryCode ? "Use a code of your authenticator app" : "Use a recovery code"}
+            </Button>
+        </>
+    )
+}
+
+function EnrollSecondFactor({ mfaToken, onLoggedIn }: Omit<SecondFactorStepProps, "enrollmentRequired">) {
+    const [enrollment, setEnrollment] = useState<TOTPEnrollment | null>(null)
+    const [confirmation, setConfirmation] = useState<TOTPConfirmation | null>(null)
+    const [code, setCode] = useState<string>("")
+    const [errorText, setErrorText] = useState<string>("")
+    const enrollMutation = useMutation(enrollTOTP, {
+        onSuccess: (response) => {
+            setErrorText("")
+            setEnrollment(response)
+        },
+        onError: (e: Error) => {
+            setErrorText(e.message)
+        }
+    })
+    const confirmMutation = useMutation(confirmTOTP, {
+        onSuccess: (response) => {
+            if (response.token && response.refresh_token) {
+                setErrorText("")
+                setConfirmation(response)
+            } else {
+                setErrorText("Failed to retrieve token.")
+            }
+        },
+        onError: (e: Error) => {
+            setErrorText(e.message)
+        }
+    })
+    const handleCodeChange = (event: ChangeEvent<HTMLInputElement>) => { setCode(event.target.value.trim()) }
+    const errorNotification = errorText &&
+        <Notification
+            severity="negative"
+            title="Error"
+        >
+            {errorText.split("error: ")}
+        </Notification>
+    if (confirmation) {
+        return (
+            <>
+                <h2 className="p-panel__title">Recovery codes</h2>
+                <p>Each of these codes logs you in once if you lose your authenticator app. Keep them somewhere safe: they won&apos;t be shown again.</p>
+                <pre><code>{confirmation.recovery_codes.join("\n")}</code></pre>
+                <Button
+                    appearance="positive"
+                    onClick={
+                        (event) => {
+                            event.preventDefault();
+                            onLoggedIn(confirmation.token!, confirmation.refresh_token!)
+                        }
+                    }
+                >
+                    Continue
+                </Button>
+            </>
+        )
+    }
+    if (!enrollment) {
+        return (
+            <>
+                <h2 className="p-panel__title">Two-factor authentication</h2>
+                <p>Your account must have a second factor to log in. Set up an authenticator app to continue.</p>
+                {errorNotification}
+                <Button
+                    appearance="positive"
+                    onClick={
+                        (event) => {
+                            event.preventDefault();
+                            enrollMutation.mutate({ authToken: mfaToken })
+                        }
+                    }
+                >
+                    Set up
+                </Button>
+            </>
+        )
+    }
+    return (
+        <>
+            <h2 className="p-panel__title">Two-factor authentication</h2>
+            <p>Add this key to your authenticator app, or open the link on the device it runs on:</p>
+            <pre><code>{enrollment.secret}</code></pre>
+            <p><a href={enrollment.provisioning_uri}>{enrollment.provisioning_uri}</a></p>
+            <Input
+                id="InputCode"
+                label="Code of your authenticator app"
+                type="text"
+                autoComplete="one-time-code"
+                value={code}
+                onChange={handleCodeChange}
+            />
+            {errorNotification}
+            <Button
+                appearance="positive"
+                disabled={code.length == 0}
+                onClick={
+                    (event) => {
+                        event.preventDefault();
+                        confirmMutation.mutate({ authToken: mfaToken, code: code })
+                    }
+                }
+            >
+                Confirm
+            </Button>
+        </>
+    )
+}
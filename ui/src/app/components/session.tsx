@@ -0,0 +1,96 @@
+"use client"
+
+import { useEffect } from "react"
+import { useCookies } from "react-cookie"
+import { useRouter } from "next/navigation"
+import { jwtDecode } from "jwt-decode"
+import { refreshSession, logout } from "../queries"
+
+// Access tokens are refreshed this long before they expire.
+const refreshMargin = 60 * 1000
+
+// Sessions end on the server after 30 days without a refresh.
+const refreshTokenValidity = 30 * 24 * 60 * 60 * 1000
+
+type SessionCookies = 'user_token' | 'refresh_token'
+
+// tokenExpiry returns when an access token expires, in milliseconds since the epoch.
+function tokenExpiry(token: string): number {
+    try {
+        const exp = jwtDecode(token).exp
+        return exp ? exp * 1000 : 0
+    } catch {
+        return 0
+    }
+}
+
+// useSessionCookies stores the tokens of the session of a portal in its cookies, and removes them.
+export function useSessionCookies(portalPath: string) {
+    const [cookies, setCookie, removeCookie] = useCookies<SessionCookies>(['user_token', 'refresh_token'])
+
+    const storeSession = (token: string, refreshToken: string) => {
+        setCookie('user_token', token, {
+            sameSite: true,
+            secure: true,
+            path: portalPath,
+            expires: new Date(tokenExpiry(token)),
+        })
+        setCookie('refresh_token', refreshToken, {
+            sameSite: true,
+            secure: true,
+            path: portalPath,
+            expires: new Date(new Date().getTime() + refreshTokenValidity),
+        })
+    }
+
+    const clearSession = () => {
+        removeCookie('user_token', { path: portalPath })
+        removeCookie('refresh_token', { path: portalPath })
+    }
+
+    // endSession logs out on the server too, for the refresh token not to be usable anymore.
+    const endSession = () => {
+        if (cookies.user_token) {
+            logout(cookies.user_token).catch((error) => console.error('Error logging out:', error))
+        }
+        clearSession()
+    }
+
+    return { token: cookies.user_token as string | undefined, refreshToken: cookies.refresh_token as string | undefined, storeSession, clearSession, endSession }
+}
+
+// useSessionRefresh refreshes the access token of a portal before it expires, or as soon as it is removed after
+// being refused. When the refresh token is refused, the session ends and the user is sent to loginPath.
+// Refresh tokens can only be used once: only the AuthProvider of the portal calls it.
+export function useSessionRefresh(portalPath: string, loginPath: string) {
+    const session = useSessionCookies(portalPath)
+    const router = useRouter()
+    const { token, refreshToken } = session
+
+    useEffect(() => {
+        if (!refreshToken) {
+            return
+        }
+        const expiry = token ? tokenExpiry(token) : 0
+        const timer = setTimeout(() => {
+            refreshSession(refreshToken)
+                .then((result) => {
+                    if (!result.token || !result.refresh_token) {
+                        throw new Error("Failed to retrieve token.")
+                    }
+                    session.storeSession(result.token, result.refresh_token)
+                })
+                .catch((error) => {
+                    console.error('Error refreshing session:', error)
+                    session.clearSession()
+                    router.push(loginPath)
+                })
+        }, Math.max(0, expiry - new Date().getTime() - refreshMargin))
+        return () => clearTimeout(timer)
+        // eslint-disable-next-line react-hooks/exhaustive-deps
+    }, [token, refreshToken])
+
+    // refreshing is set while the access token is missing or expired but can still be refreshed.
+    const refreshing = !!refreshToken && (!token || tokenExpiry(token) <= new Date().getTime())
+    return { ...session, refreshing }
+}
@@ -2,10 +2,10 @@
 
 import { createContext, useContext, useState, useEffect, Dispatch, SetStateAction } from 'react';
 import { User } from '../../types';
-import { useCookies } from 'react-cookie';
 import { jwtDecode } from 'jwt-decode';
 import { useRouter } from 'next/navigation';
 import { isLoggedIn } from '../../queries';
+import { useSessionRefresh } from '../../components/session';
 
 type AuthContextType = {
     user: User | null
@@ -16,16 +16,19 @@ type AuthContextType = {
 const AuthContext = createContext<AuthContextType>({ user: null, firstUserCreated: false, setFirstUserCreated: () => { } });
 
 export const AuthProvider = ({ children }: Readonly<{ children: React.ReactNode }>) => {
-    const [cookies, setCookie, removeCookie] = useCookies(['user_token']);
+    const session = useSessionRefresh('/employer_portal', '/employer_portal/login');
     const [user, setUser] = useState<User | null>(null);
     const [firstUserCreated, setFirstUserCreated] = useState<boolean>(false);
     const router = useRouter();
 
     useEffect(() => {
-        const token = cookies.user_token;
+        const token = session.token;
+        if (session.refreshing) {
+            return
+        }
         if (token) {
-            let userObject = jwtDecode(cookies.user_token) as User;
-            userObject.authToken = cookies.user_token;
+            let userObject = jwtDecode(token) as User;
+            userObject.authToken = token;
 
             isLoggedIn(token)
                 .then((IsLoggedIn) => {
@@ -46,7 +49,7 @@ export const AuthProvider = ({ children }: Readonly<{ children: React.ReactNode
             setUser(null);
             router.push('/employer_portal/login');
         }
-    }, [cookies.user_token, router]);
+    }, [session.token, session.refreshing, router]);
 
     return (
         <AuthContext.Provider value={{ user, firstUserCreated, setFirstUserCreated }}>
@@ -2,7 +2,7 @@
 
 import { useMutation, useQuery, useQueryClient } from "react-query";
 import { ChangeEvent, useState, useEffect, Suspense } from "react";
-import { getJobPost, updateMyJobPost, deleteJobPost } from "../../../queries";
+import { getMyJobPost, updateMyJobPost, deleteMyJobPost } from "../../../queries";
 import { JobPost } from "../../../types";
 import { useCookies } from "react-cookie";
 import { useRouter, useSearchParams } from "next/navigation";
@@ -28,7 +28,7 @@ function DraftContent() {
 
     const { data: jobPostData, isLoading, isError } = useQuery<JobPost, Error>({
         queryKey: ['jobpost', postId],
-        queryFn: () => getJobPost({ authToken: cookies.user_token, id: postId as string }),
+        queryFn: () => getMyJobPost({ authToken: cookies.user_token, id: postId as string }),
         enabled: !!postId,
         retry: (failureCount, error): boolean => {
             if (error.message.includes("401")) {
@@ -71,7 +71,7 @@ function DraftContent() {
         }
     });
 
-    const deleteJobPostMutation = useMutation(deleteJobPost, {
+    const deleteJobPostMutation = useMutation(deleteMyJobPost, {
         onSuccess: () => {
             setErrorText("");
             queryClient.invalidateQueries('jobposts');
@@ -200,13 +200,13 @@ function DraftContent() {
                                 publishJobPostMutation.mutate({
                                     authToken: cookies.user_token,
                                     id: postId,
-                                    status: "published",
+                                    status: "pending_review",
                                     title: JobPostTitleString,
                                     content: JobPostContentString
                                 });
                             }}
                         >
-                            Publish
+                            Submit for review
                         </Button>
                         <Button
                             appearance="base"
@@ -15,8 +15,8 @@ interface JobPost {
     id: number;
     title: string;
     content: string;
-    created_at: string;  // RFC3339 date format
-    author: string;
+    published_at: string;  // RFC3339 date format
+    employer_name: string;
 }
 
 export default function FrontPage() {
@@ -63,8 +63,8 @@ export default function FrontPage() {
                 {processedPosts?.map((post) => (
                     <div key={post.id} className="p-section--shallow">
                         <h2>{post.title}</h2>
-                        <h5>By: {post.author}</h5>
-                        <h6>{formatDate(post.created_at)}</h6>
+                        <h5>By: {post.employer_name}</h5>
+                        <h6>{formatDate(post.published_at)}</h6>
                         <div className="job-post-content" dangerouslySetInnerHTML={{ __html: post.content }} />
                         <hr />
                     </div>
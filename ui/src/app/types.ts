@@ -4,8 +4,21 @@ export type JobPost = {
     content: string;
     status: string;
     created_at: string;
-    account_id: number;
-    author: string;
+    published_at?: string;
+    employer_id: number;
+    employer_name?: string;
+    employment_type?: "part_time" | "full_time";
+    weekly_hours?: number;
+    work_mode?: "remote" | "hybrid" | "on_site";
+    city?: string;
+    postal_code?: string;
+    salary_min?: number;
+    salary_max?: number;
+    salary_currency?: string;
+    salary_period?: "hour" | "month" | "year";
+    physical_demands?: "low" | "moderate" | "high";
+    flexible_schedule?: boolean;
+    accessible_workplace?: boolean;
 };
 
 export type User = {
@@ -16,6 +29,27 @@ export type User = {
     authToken: string
 }
 
+// LoginResult holds the tokens of a session, or the mfa_token of the second step of the login when
+// mfa_required or mfa_enrollment_required is set.
+export type LoginResult = {
+    token?: string
+    refresh_token?: string
+    mfa_required?: boolean
+    mfa_enrollment_required?: boolean
+    mfa_token?: string
+}
+
+export type TOTPEnrollment = {
+    secret: string
+    provisioning_uri: string
+}
+
+export type TOTPConfirmation = {
+    recovery_codes: string[]
+    token?: string
+    refresh_token?: string
+}
+
 export type UserEntry = {
     id: number
     email: string